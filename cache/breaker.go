@@ -0,0 +1,312 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("cache circuit breaker is open")
+
+const (
+	// defaultFailureThreshold is the number of consecutive failures that
+	// trips a breaker when BreakerConfig does not set one
+	defaultFailureThreshold = 5
+
+	// defaultOpenTimeout is how long a tripped breaker fails fast before
+	// letting a trial request through when BreakerConfig does not set one
+	defaultOpenTimeout = 30 * time.Second
+)
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed passes every operation through to the store
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen fails every operation fast, or sends it to the fallback
+	BreakerOpen
+
+	// BreakerHalfOpen lets a single trial operation through to find out
+	// whether the store has recovered
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a CircuitBreaker
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that trips the
+	// breaker. Defaults to 5.
+	FailureThreshold int
+
+	// OpenTimeout is how long the breaker stays open before a trial
+	// operation is let through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+
+	// Fallback, when set, serves operations while the breaker is open
+	// instead of failing them with ErrCircuitOpen
+	Fallback Store
+
+	// OnStateChange is called whenever the breaker changes state. It must
+	// not call back into the breaker.
+	OnStateChange func(from, to BreakerState)
+}
+
+// CircuitBreaker wraps a store, tripping after a number of consecutive
+// failures and then failing fast, or falling back to another store, until
+// the wrapped store recovers. Cache misses, nil callbacks, caller
+// cancellation and callback errors in Remember do not count as failures.
+type CircuitBreaker struct {
+	store    Store
+	fallback Store
+
+	threshold     int
+	openTimeout   time.Duration
+	onStateChange func(from, to BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var _ Store = (*CircuitBreaker)(nil)
+
+// NewCircuitBreaker wraps store in a circuit breaker
+func NewCircuitBreaker(store Store, cfg BreakerConfig) *CircuitBreaker {
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	openTimeout := cfg.OpenTimeout
+	if openTimeout <= 0 {
+		openTimeout = defaultOpenTimeout
+	}
+
+	return &CircuitBreaker{
+		store:         store,
+		fallback:      cfg.Fallback,
+		threshold:     threshold,
+		openTimeout:   openTimeout,
+		onStateChange: cfg.OnStateChange,
+	}
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether an operation may be sent to the wrapped store, and
+// whether it is the trial operation of a half-open breaker
+func (b *CircuitBreaker) allow() (allowed, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true, false
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false, false
+		}
+		b.setState(BreakerHalfOpen)
+	}
+
+	// Half-open: only one trial at a time
+	if b.probing {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// record updates the breaker with the outcome of an operation. Only the
+// trial decides whether an open or half-open breaker closes; operations let
+// through before it tripped and finishing after are ignored.
+func (b *CircuitBreaker) record(trial, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.probing = false
+	} else if b.state != BreakerClosed {
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		if b.state != BreakerOpen {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+// setState switches to state and notifies the callback. b.mu must be held.
+func (b *CircuitBreaker) setState(state BreakerState) {
+	from := b.state
+	b.state = state
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
+
+// do runs fn against the wrapped store when the breaker allows it, and
+// against the fallback or fails fast otherwise. ignore reports errors that
+// are not store failures.
+func (b *CircuitBreaker) do(fn func(Store) error, ignore func(error) bool) error {
+	allowed, trial := b.allow()
+	if !allowed {
+		if b.fallback != nil {
+			return fn(b.fallback)
+		}
+		return ErrCircuitOpen
+	}
+
+	err := fn(b.store)
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up, which says nothing about the store
+		b.abort(trial)
+		return err
+	}
+	b.record(trial, err != nil && !ignore(err))
+	return err
+}
+
+// abort ends an operation without recording its outcome
+func (b *CircuitBreaker) abort(trial bool) {
+	if trial {
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+	}
+}
+
+// isStoreFailure reports whether err indicates the store itself is failing
+func isStoreFailure(err error) bool {
+	return err != nil && !errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrNilCallback)
+}
+
+// notFailure is the ignore function for operations without callbacks
+func notFailure(err error) bool {
+	return !isStoreFailure(err)
+}
+
+// Get retrieves an item from the cache by key
+func (b *CircuitBreaker) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := b.do(func(s Store) (err error) {
+		value, err = s.Get(ctx, key)
+		return err
+	}, notFailure)
+	return value, err
+}
+
+// Put stores an item in the cache for a given duration
+func (b *CircuitBreaker) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	return b.do(func(s Store) error {
+		return s.Put(ctx, key, value, ttl)
+	}, notFailure)
+}
+
+// Has checks if an item exists in the cache
+func (b *CircuitBreaker) Has(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := b.do(func(s Store) (err error) {
+		exists, err = s.Has(ctx, key)
+		return err
+	}, notFailure)
+	return exists, err
+}
+
+// Remember gets an item from the cache, or stores the encoded result of
+// the callback
+func (b *CircuitBreaker) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	var value string
+	var callbackErr error
+	err := b.do(func(s Store) (err error) {
+		tracked := TrackCallback(callback)
+		value, err = s.Remember(ctx, key, ttl, tracked.Func())
+		_, _, callbackErr = tracked.Done()
+		return err
+	}, func(err error) bool {
+		return !isStoreFailure(err) || (callbackErr != nil && errors.Is(err, callbackErr))
+	})
+	return value, err
+}
+
+// Pull retrieves and deletes an item from the cache
+func (b *CircuitBreaker) Pull(ctx context.Context, key string) (string, error) {
+	var value string
+	err := b.do(func(s Store) (err error) {
+		value, err = s.Pull(ctx, key)
+		return err
+	}, notFailure)
+	return value, err
+}
+
+// Forever stores an item in the cache permanently
+func (b *CircuitBreaker) Forever(ctx context.Context, key, value string) error {
+	return b.do(func(s Store) error {
+		return s.Forever(ctx, key, value)
+	}, notFailure)
+}
+
+// Forget removes an item from the cache
+func (b *CircuitBreaker) Forget(ctx context.Context, key string) error {
+	return b.do(func(s Store) error {
+		return s.Forget(ctx, key)
+	}, notFailure)
+}
+
+// Flush removes all items from the cache
+func (b *CircuitBreaker) Flush(ctx context.Context) error {
+	return b.do(func(s Store) error {
+		return s.Flush(ctx)
+	}, notFailure)
+}
+
+// Codec returns the wrapped store's codec
+func (b *CircuitBreaker) Codec() Codec {
+	return CodecOf(b.store)
+}
+
+// Close closes the wrapped store and the fallback
+func (b *CircuitBreaker) Close() error {
+	err := b.store.Close()
+	if b.fallback != nil {
+		err = errors.Join(err, b.fallback.Close())
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStoreDown = errors.New("connection refused")
+
+// setFailing makes every operation on the stub store fail, or succeed again
+func (s *stubStore) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = nil
+	if failing {
+		s.err = errStoreDown
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trips after consecutive failures", func(t *testing.T) {
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Hour})
+
+		store.setFailing(true)
+		for i := 0; i < 3; i++ {
+			assert.ErrorIs(t, breaker.Put(ctx, "key", "value", time.Minute), errStoreDown)
+		}
+		assert.Equal(t, BreakerOpen, breaker.State())
+
+		// Fails fast without touching the store
+		store.setFailing(false)
+		_, err := breaker.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("successes reset the failure count", func(t *testing.T) {
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 2})
+
+		store.setFailing(true)
+		breaker.Forget(ctx, "key")
+		store.setFailing(false)
+		require.NoError(t, breaker.Forget(ctx, "key"))
+		store.setFailing(true)
+		breaker.Forget(ctx, "key")
+
+		assert.Equal(t, BreakerClosed, breaker.State())
+	})
+
+	t.Run("misses and callback errors are not failures", func(t *testing.T) {
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 1})
+
+		_, err := breaker.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+
+		_, err = breaker.Remember(ctx, "key", time.Minute, func() (interface{}, error) {
+			return nil, errors.New("query failed")
+		})
+		assert.EqualError(t, err, "query failed")
+
+		_, err = breaker.Remember(ctx, "key", time.Minute, nil)
+		assert.ErrorIs(t, err, ErrNilCallback)
+
+		assert.Equal(t, BreakerClosed, breaker.State())
+	})
+
+	t.Run("recovers through half-open", func(t *testing.T) {
+		var mu sync.Mutex
+		var transitions []string
+
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{
+			FailureThreshold: 1,
+			OpenTimeout:      20 * time.Millisecond,
+			OnStateChange: func(from, to BreakerState) {
+				mu.Lock()
+				defer mu.Unlock()
+				transitions = append(transitions, from.String()+"->"+to.String())
+			},
+		})
+
+		store.setFailing(true)
+		breaker.Put(ctx, "key", "value", time.Minute)
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+		// A failed trial opens the breaker again
+		assert.ErrorIs(t, breaker.Put(ctx, "key", "value", time.Minute), errStoreDown)
+		assert.Equal(t, BreakerOpen, breaker.State())
+
+		time.Sleep(30 * time.Millisecond)
+		store.setFailing(false)
+		require.NoError(t, breaker.Put(ctx, "key", "value", time.Minute))
+		assert.Equal(t, BreakerClosed, breaker.State())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{
+			"closed->open",
+			"open->half-open",
+			"half-open->open",
+			"open->half-open",
+			"half-open->closed",
+		}, transitions)
+	})
+
+	t.Run("late successes do not close a tripped breaker", func(t *testing.T) {
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour})
+
+		started, release := make(chan struct{}), make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			_, err := breaker.Remember(ctx, "slow", time.Minute, func() (interface{}, error) {
+				close(started)
+				<-release
+				return "value", nil
+			})
+			done <- err
+		}()
+		<-started
+
+		// The breaker trips while the slow operation is in flight
+		store.setFailing(true)
+		assert.ErrorIs(t, breaker.Put(ctx, "key", "value", time.Minute), errStoreDown)
+		require.Equal(t, BreakerOpen, breaker.State())
+		store.setFailing(false)
+
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, BreakerOpen, breaker.State())
+		_, err := breaker.Get(ctx, "slow")
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("falls back while open", func(t *testing.T) {
+		store := newStubStore()
+		fallback := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour, Fallback: fallback})
+
+		store.setFailing(true)
+		breaker.Get(ctx, "key")
+
+		require.NoError(t, breaker.Put(ctx, "key", "fallback-value", time.Minute))
+		value, err := breaker.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback-value", value)
+		assert.Empty(t, store.items)
+	})
+
+	t.Run("cancellation is not a failure", func(t *testing.T) {
+		store := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{FailureThreshold: 1})
+
+		store.err = context.Canceled
+		breaker.Get(ctx, "key")
+		assert.Equal(t, BreakerClosed, breaker.State())
+	})
+
+	t.Run("close closes both stores", func(t *testing.T) {
+		store := newStubStore()
+		fallback := newStubStore()
+		breaker := NewCircuitBreaker(store, BreakerConfig{Fallback: fallback})
+
+		require.NoError(t, breaker.Close())
+		assert.True(t, store.closed)
+		assert.True(t, fallback.closed)
+	})
+}
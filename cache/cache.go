@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrKeyNotFound = errors.New("key not found in cache")
+	ErrNilCallback = errors.New("callback function cannot be nil")
+)
+
+// Store is the interface implemented by every cache driver
+type Store interface {
+	// Get retrieves an item from the cache by key
+	Get(ctx context.Context, key string) (string, error)
+
+	// Put stores an item in the cache for a given duration
+	Put(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Has checks if an item exists in the cache
+	Has(ctx context.Context, key string) (bool, error)
+
+	// Remember gets an item from the cache, or stores the encoded result of
+	// the callback, JSON unless the driver is configured with another Codec
+	Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error)
+
+	// Pull retrieves and deletes an item from the cache
+	Pull(ctx context.Context, key string) (string, error)
+
+	// Forever stores an item in the cache permanently
+	Forever(ctx context.Context, key, value string) error
+
+	// Forget removes an item from the cache
+	Forget(ctx context.Context, key string) error
+
+	// Flush removes all items from the cache
+	Flush(ctx context.Context) error
+
+	// Close releases any resources held by the store
+	Close() error
+}
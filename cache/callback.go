@@ -0,0 +1,70 @@
+package cache
+
+import "sync"
+
+// Callback records the outcome of a Remember callback for the wrapper that
+// passed it to a store. Stores may still run a callback after Remember has
+// returned, for instance to refresh a stale value in the background, so a
+// wrapper must not share variables with the closure it hands over. Runs
+// finishing after Done are passed through without being recorded.
+type Callback struct {
+	fn func() (interface{}, error)
+
+	mu     sync.Mutex
+	done   bool
+	ran    bool
+	result interface{}
+	err    error
+}
+
+// TrackCallback returns a Callback recording the runs of fn. A nil fn gives
+// a nil Func, so stores still report ErrNilCallback.
+func TrackCallback(fn func() (interface{}, error)) *Callback {
+	return &Callback{fn: fn}
+}
+
+// Func returns the callback to pass to Remember. Until Done is called it
+// runs fn at most once, returning the recorded outcome on later calls, so a
+// wrapper may offer the same callback to several stores in turn.
+func (c *Callback) Func() func() (interface{}, error) {
+	if c.fn == nil {
+		return nil
+	}
+	return c.run
+}
+
+// run runs fn, recording its outcome unless Done has been called
+func (c *Callback) run() (interface{}, error) {
+	c.mu.Lock()
+	if c.ran && !c.done {
+		result, err := c.result, c.err
+		c.mu.Unlock()
+		return result, err
+	}
+	c.mu.Unlock()
+
+	result, err := c.fn()
+
+	c.mu.Lock()
+	if !c.done && !c.ran {
+		c.ran, c.result, c.err = true, result, err
+	}
+	c.mu.Unlock()
+	return result, err
+}
+
+// Result reports whether the callback has run to completion while being
+// recorded, with the result and error it returned
+func (c *Callback) Result() (ran bool, result interface{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ran, c.result, c.err
+}
+
+// Done stops recording and returns the recorded outcome, as Result does
+func (c *Callback) Done() (ran bool, result interface{}, err error) {
+	c.mu.Lock()
+	c.done = true
+	c.mu.Unlock()
+	return c.Result()
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackCallback(t *testing.T) {
+	t.Run("runs once while recording", func(t *testing.T) {
+		calls := 0
+		tracked := TrackCallback(func() (interface{}, error) {
+			calls++
+			return calls, nil
+		})
+
+		fn := tracked.Func()
+		fn()
+		result, err := fn()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+
+		ran, result, err := tracked.Done()
+		assert.True(t, ran)
+		assert.Equal(t, 1, result)
+		assert.NoError(t, err)
+	})
+
+	t.Run("late runs are not recorded", func(t *testing.T) {
+		calls := 0
+		tracked := TrackCallback(func() (interface{}, error) {
+			calls++
+			return calls, nil
+		})
+
+		fn := tracked.Func()
+		ran, _, _ := tracked.Done()
+		assert.False(t, ran)
+
+		result, err := fn()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+
+		ran, _, _ = tracked.Result()
+		assert.False(t, ran)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		assert.Nil(t, TrackCallback(nil).Func())
+	})
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ChainOption configures a ChainStore
+type ChainOption func(*ChainStore)
+
+// WithBackfill copies items found only in the fallback into the primary
+// store for ttl, so the primary warms up again after an outage or a flush
+func WithBackfill(ttl time.Duration) ChainOption {
+	return func(c *ChainStore) {
+		c.backfill = true
+		c.backfillTTL = ttl
+	}
+}
+
+// ChainStore reads from a primary store, falling through to a fallback on
+// errors and misses, and writes to both. When a write to the primary fails
+// the item is evicted from it, so that it cannot serve a stale value once it
+// recovers; writes only fail when the primary cannot be written to or
+// evicted from, or when both stores fail.
+type ChainStore struct {
+	primary  Store
+	fallback Store
+
+	backfill    bool
+	backfillTTL time.Duration
+}
+
+var _ Store = (*ChainStore)(nil)
+
+// Chain combines primary with fallback, for example a Redis store with an
+// in-memory store to degrade to during a Redis outage
+func Chain(primary, fallback Store, opts ...ChainOption) *ChainStore {
+	c := &ChainStore{primary: primary, fallback: fallback}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get retrieves an item from the primary store, or from the fallback when
+// the primary misses or fails
+func (c *ChainStore) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.primary.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+
+	value, fallbackErr := c.fallback.Get(ctx, key)
+	if fallbackErr != nil {
+		// A miss in both is a miss, otherwise report the primary's failure
+		if errors.Is(fallbackErr, ErrKeyNotFound) && !errors.Is(err, ErrKeyNotFound) {
+			return "", err
+		}
+		return "", fallbackErr
+	}
+
+	if c.backfill && errors.Is(err, ErrKeyNotFound) {
+		c.primary.Put(ctx, key, value, c.backfillTTL)
+	}
+	return value, nil
+}
+
+// Put stores an item in both stores for a given duration
+func (c *ChainStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	return both(c.primary.Put(ctx, key, value, ttl), c.fallback.Put(ctx, key, value, ttl), func() error {
+		return c.primary.Forget(ctx, key)
+	})
+}
+
+// Has checks if an item exists in either store
+func (c *ChainStore) Has(ctx context.Context, key string) (bool, error) {
+	exists, err := c.primary.Has(ctx, key)
+	if err == nil && exists {
+		return true, nil
+	}
+
+	fallbackExists, fallbackErr := c.fallback.Has(ctx, key)
+	if fallbackErr != nil && err != nil {
+		return false, err
+	}
+	return fallbackExists, nil
+}
+
+// Remember gets an item from either store, or stores the encoded result of
+// the callback in both. The callback runs at most once, even when the primary
+// fails and the fallback encodes the result instead.
+func (c *ChainStore) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	if value, err := c.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	tracked := TrackCallback(callback)
+	defer tracked.Done()
+
+	value, err := c.primary.Remember(ctx, key, ttl, tracked.Func())
+	if err == nil {
+		c.fallback.Put(ctx, key, value, ttl)
+		return value, nil
+	}
+	if ran, _, callbackErr := tracked.Result(); ran && callbackErr != nil {
+		return "", callbackErr
+	}
+
+	value, fallbackErr := c.fallback.Remember(ctx, key, ttl, tracked.Func())
+	if fallbackErr != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Pull retrieves an item from either store and deletes it from both
+func (c *ChainStore) Pull(ctx context.Context, key string) (string, error) {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return value, c.Forget(ctx, key)
+}
+
+// Forever stores an item in both stores permanently
+func (c *ChainStore) Forever(ctx context.Context, key, value string) error {
+	return both(c.primary.Forever(ctx, key, value), c.fallback.Forever(ctx, key, value), func() error {
+		return c.primary.Forget(ctx, key)
+	})
+}
+
+// Forget removes an item from both stores
+func (c *ChainStore) Forget(ctx context.Context, key string) error {
+	return both(c.primary.Forget(ctx, key), c.fallback.Forget(ctx, key), func() error {
+		return c.primary.Forget(ctx, key)
+	})
+}
+
+// Flush removes all items from both stores
+func (c *ChainStore) Flush(ctx context.Context) error {
+	return both(c.primary.Flush(ctx), c.fallback.Flush(ctx), func() error {
+		return c.primary.Flush(ctx)
+	})
+}
+
+// Codec returns the primary store's codec
+func (c *ChainStore) Codec() Codec {
+	return CodecOf(c.primary)
+}
+
+// Close closes both stores
+func (c *ChainStore) Close() error {
+	return errors.Join(c.primary.Close(), c.fallback.Close())
+}
+
+// both reports the outcome of a write to both stores. A failed write to the
+// primary is undone with evict, and is only forgiven when that succeeds and
+// the fallback was written; otherwise the primary's error is returned,
+// joined with the fallback's.
+func both(primaryErr, fallbackErr error, evict func() error) error {
+	if primaryErr == nil {
+		return nil
+	}
+	if fallbackErr != nil {
+		return errors.Join(primaryErr, fallbackErr)
+	}
+	if evictErr := evict(); evictErr != nil {
+		return errors.Join(primaryErr, evictErr)
+	}
+	return nil
+}
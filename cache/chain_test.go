@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFailingStore is a stub store whose Put and Forever fail, while
+// Forget still works
+type writeFailingStore struct {
+	*stubStore
+}
+
+func (s writeFailingStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	return errStoreDown
+}
+
+func (s writeFailingStore) Forever(ctx context.Context, key, value string) error {
+	return errStoreDown
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads prefer the primary", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		primary.items["key"] = "primary-value"
+		fallback.items["key"] = "fallback-value"
+
+		value, err := chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "primary-value", value)
+	})
+
+	t.Run("reads fall through on misses and errors", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		fallback.items["key"] = "fallback-value"
+		value, err := chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback-value", value)
+
+		primary.setFailing(true)
+		value, err = chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback-value", value)
+
+		exists, err := chain.Has(ctx, "key")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("misses", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		_, err := chain.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+
+		// The primary's failure is more useful than the fallback's miss
+		primary.setFailing(true)
+		_, err = chain.Get(ctx, "missing")
+		assert.ErrorIs(t, err, errStoreDown)
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback, WithBackfill(time.Minute))
+
+		fallback.items["key"] = "fallback-value"
+		_, err := chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "fallback-value", primary.items["key"])
+	})
+
+	t.Run("no backfill by default", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		fallback.items["key"] = "fallback-value"
+		_, err := chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.NotContains(t, primary.items, "key")
+	})
+
+	t.Run("writes go to both", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		require.NoError(t, chain.Put(ctx, "key", "value", time.Minute))
+		require.NoError(t, chain.Forever(ctx, "forever", "value"))
+		assert.Equal(t, primary.items, fallback.items)
+
+		require.NoError(t, chain.Forget(ctx, "key"))
+		assert.NotContains(t, primary.items, "key")
+		assert.NotContains(t, fallback.items, "key")
+
+		value, err := chain.Pull(ctx, "forever")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.Empty(t, primary.items)
+		assert.Empty(t, fallback.items)
+	})
+
+	t.Run("failed primary writes are evicted", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(writeFailingStore{primary}, fallback)
+
+		primary.items["key"] = "stale"
+		require.NoError(t, chain.Put(ctx, "key", "value", time.Minute))
+		assert.Equal(t, "value", fallback.items["key"])
+		assert.NotContains(t, primary.items, "key")
+
+		value, err := chain.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("failed primary writes are reported", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		// The item could be neither written nor evicted, and would be
+		// served stale once the primary recovers
+		primary.setFailing(true)
+		assert.ErrorIs(t, chain.Put(ctx, "key", "value", time.Minute), errStoreDown)
+		assert.Equal(t, "value", fallback.items["key"])
+		assert.ErrorIs(t, chain.Forget(ctx, "key"), errStoreDown)
+
+		// The primary is read first, so a failed fallback write is harmless
+		primary.setFailing(false)
+		fallback.setFailing(true)
+		assert.NoError(t, chain.Put(ctx, "key", "value", time.Minute))
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		calls := 0
+		callback := func() (interface{}, error) {
+			calls++
+			return map[string]int{"count": 1}, nil
+		}
+
+		value, err := chain.Remember(ctx, "key", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, `{"count":1}`, value)
+		assert.Equal(t, value, fallback.items["key"])
+
+		_, err = chain.Remember(ctx, "key", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("remember during a primary outage", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+		chain := Chain(primary, fallback)
+
+		primary.setFailing(true)
+		calls := 0
+		value, err := chain.Remember(ctx, "key", time.Minute, func() (interface{}, error) {
+			calls++
+			return "computed", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"computed"`, value)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("remember callback error", func(t *testing.T) {
+		chain := Chain(newStubStore(), newStubStore())
+
+		_, err := chain.Remember(ctx, "key", time.Minute, func() (interface{}, error) {
+			return nil, errors.New("query failed")
+		})
+		assert.EqualError(t, err, "query failed")
+
+		_, err = chain.Remember(ctx, "key", time.Minute, nil)
+		assert.ErrorIs(t, err, ErrNilCallback)
+	})
+
+	t.Run("close closes both stores", func(t *testing.T) {
+		primary, fallback := newStubStore(), newStubStore()
+
+		require.NoError(t, Chain(primary, fallback).Close())
+		assert.True(t, primary.closed)
+		assert.True(t, fallback.closed)
+	})
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type codecItem struct {
+	Name  string
+	Tags  []string
+	Count int
+}
+
+func TestCodecs(t *testing.T) {
+	codecs := map[string]Codec{
+		"json":    JSONCodec{},
+		"gob":     GobCodec{},
+		"msgpack": MsgpackCodec{},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			in := codecItem{Name: "report", Tags: []string{"a", "b"}, Count: 3}
+
+			data, err := codec.Marshal(in)
+			require.NoError(t, err)
+
+			var out codecItem
+			require.NoError(t, codec.Unmarshal(data, &out))
+			assert.Equal(t, in, out)
+		})
+	}
+
+	t.Run("json output", func(t *testing.T) {
+		data, err := JSONCodec{}.Marshal(map[string]int{"a": 1})
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		for name, codec := range codecs {
+			var out codecItem
+			assert.Error(t, codec.Unmarshal([]byte("\xff\x00garbage"), &out), name)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Event describes a completed cache operation. Op is the name of the Store
+// method, such as "get" or "remember", Key is empty for Flush, Size is the
+// length in bytes of the value read or written, and Err is set when the
+// operation failed for another reason than a miss.
+type Event struct {
+	Op       string
+	Key      string
+	Duration time.Duration
+	Size     int
+	Err      error
+}
+
+// Events holds callbacks invoked after cache operations. Nil callbacks are
+// skipped. Reads report a hit or a miss, failed reads counting as misses with
+// Err set; Remember reports a miss followed by a write when it runs its
+// callback. Callbacks run synchronously on the calling goroutine.
+type Events struct {
+	OnHit    func(Event)
+	OnMiss   func(Event)
+	OnWrite  func(Event)
+	OnForget func(Event)
+}
+
+// ObservedStore wraps a store, reporting every operation to Events
+type ObservedStore struct {
+	store  Store
+	events Events
+}
+
+var _ Store = (*ObservedStore)(nil)
+
+// Observe wraps store so every operation is reported to events
+func Observe(store Store, events Events) *ObservedStore {
+	return &ObservedStore{store: store, events: events}
+}
+
+// emit calls fn, when set, with the outcome of op on key started at start
+func emit(fn func(Event), op, key string, start time.Time, size int, err error) {
+	if fn != nil {
+		fn(Event{Op: op, Key: key, Duration: time.Since(start), Size: size, Err: err})
+	}
+}
+
+// read reports the outcome of a read returning value
+func (o *ObservedStore) read(op, key string, start time.Time, value string, err error) {
+	switch {
+	case err == nil:
+		emit(o.events.OnHit, op, key, start, len(value), nil)
+	case errors.Is(err, ErrKeyNotFound):
+		emit(o.events.OnMiss, op, key, start, 0, nil)
+	default:
+		emit(o.events.OnMiss, op, key, start, 0, err)
+	}
+}
+
+// Get retrieves an item from the cache by key
+func (o *ObservedStore) Get(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := o.store.Get(ctx, key)
+	o.read("get", key, start, value, err)
+	return value, err
+}
+
+// Put stores an item in the cache for a given duration
+func (o *ObservedStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	start := time.Now()
+	err := o.store.Put(ctx, key, value, ttl)
+	emit(o.events.OnWrite, "put", key, start, len(value), err)
+	return err
+}
+
+// Has checks if an item exists in the cache
+func (o *ObservedStore) Has(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	exists, err := o.store.Has(ctx, key)
+	switch {
+	case err != nil:
+		emit(o.events.OnMiss, "has", key, start, 0, err)
+	case exists:
+		emit(o.events.OnHit, "has", key, start, 0, nil)
+	default:
+		emit(o.events.OnMiss, "has", key, start, 0, nil)
+	}
+	return exists, err
+}
+
+// Remember gets an item from the cache, or stores the encoded result of
+// the callback
+func (o *ObservedStore) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	start := time.Now()
+	tracked := TrackCallback(callback)
+	value, err := o.store.Remember(ctx, key, ttl, tracked.Func())
+	missed, _, _ := tracked.Done()
+	switch {
+	case missed:
+		emit(o.events.OnMiss, "remember", key, start, 0, nil)
+		emit(o.events.OnWrite, "remember", key, start, len(value), err)
+	case err == nil:
+		emit(o.events.OnHit, "remember", key, start, len(value), nil)
+	default:
+		emit(o.events.OnMiss, "remember", key, start, 0, err)
+	}
+	return value, err
+}
+
+// Pull retrieves and deletes an item from the cache
+func (o *ObservedStore) Pull(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := o.store.Pull(ctx, key)
+	o.read("pull", key, start, value, err)
+	if err == nil {
+		emit(o.events.OnForget, "pull", key, start, 0, nil)
+	}
+	return value, err
+}
+
+// Forever stores an item in the cache permanently
+func (o *ObservedStore) Forever(ctx context.Context, key, value string) error {
+	start := time.Now()
+	err := o.store.Forever(ctx, key, value)
+	emit(o.events.OnWrite, "forever", key, start, len(value), err)
+	return err
+}
+
+// Forget removes an item from the cache
+func (o *ObservedStore) Forget(ctx context.Context, key string) error {
+	start := time.Now()
+	err := o.store.Forget(ctx, key)
+	emit(o.events.OnForget, "forget", key, start, 0, err)
+	return err
+}
+
+// Flush removes all items from the cache
+func (o *ObservedStore) Flush(ctx context.Context) error {
+	start := time.Now()
+	err := o.store.Flush(ctx)
+	emit(o.events.OnForget, "flush", "", start, 0, err)
+	return err
+}
+
+// Codec returns the wrapped store's codec
+func (o *ObservedStore) Codec() Codec {
+	return CodecOf(o.store)
+}
+
+// Close closes the wrapped store
+func (o *ObservedStore) Close() error {
+	return o.store.Close()
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedEvent is an Event tagged with the callback that received it
+type recordedEvent struct {
+	kind string
+	op   string
+	key  string
+	err  error
+}
+
+func recordEvents(events *[]recordedEvent) Events {
+	record := func(kind string) func(Event) {
+		return func(e Event) {
+			*events = append(*events, recordedEvent{kind: kind, op: e.Op, key: e.Key, err: e.Err})
+		}
+	}
+	return Events{
+		OnHit:    record("hit"),
+		OnMiss:   record("miss"),
+		OnWrite:  record("write"),
+		OnForget: record("forget"),
+	}
+}
+
+func TestObserve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads", func(t *testing.T) {
+		var events []recordedEvent
+		store := Observe(newStubStore(), recordEvents(&events))
+
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+		store.Get(ctx, "key")
+		store.Get(ctx, "missing")
+		store.Has(ctx, "key")
+		store.Has(ctx, "missing")
+
+		assert.Equal(t, []recordedEvent{
+			{kind: "write", op: "put", key: "key"},
+			{kind: "hit", op: "get", key: "key"},
+			{kind: "miss", op: "get", key: "missing"},
+			{kind: "hit", op: "has", key: "key"},
+			{kind: "miss", op: "has", key: "missing"},
+		}, events)
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		var events []recordedEvent
+		store := Observe(newStubStore(), recordEvents(&events))
+
+		callback := func() (interface{}, error) { return "value", nil }
+		_, err := store.Remember(ctx, "key", time.Minute, callback)
+		require.NoError(t, err)
+		_, err = store.Remember(ctx, "key", time.Minute, callback)
+		require.NoError(t, err)
+
+		assert.Equal(t, []recordedEvent{
+			{kind: "miss", op: "remember", key: "key"},
+			{kind: "write", op: "remember", key: "key"},
+			{kind: "hit", op: "remember", key: "key"},
+		}, events)
+	})
+
+	t.Run("removals", func(t *testing.T) {
+		var events []recordedEvent
+		store := Observe(newStubStore(), recordEvents(&events))
+
+		require.NoError(t, store.Forever(ctx, "key", "value"))
+		_, err := store.Pull(ctx, "key")
+		require.NoError(t, err)
+		require.NoError(t, store.Forget(ctx, "key"))
+		require.NoError(t, store.Flush(ctx))
+
+		assert.Equal(t, []recordedEvent{
+			{kind: "write", op: "forever", key: "key"},
+			{kind: "hit", op: "pull", key: "key"},
+			{kind: "forget", op: "pull", key: "key"},
+			{kind: "forget", op: "forget", key: "key"},
+			{kind: "forget", op: "flush"},
+		}, events)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var events []recordedEvent
+		stub := newStubStore()
+		store := Observe(stub, recordEvents(&events))
+
+		failure := errors.New("connection refused")
+		stub.err = failure
+		store.Get(ctx, "key")
+		store.Put(ctx, "key", "value", time.Minute)
+
+		assert.Equal(t, []recordedEvent{
+			{kind: "miss", op: "get", key: "key", err: failure},
+			{kind: "write", op: "put", key: "key", err: failure},
+		}, events)
+	})
+
+	t.Run("sizes", func(t *testing.T) {
+		var sizes []int
+		size := func(e Event) { sizes = append(sizes, e.Size) }
+		store := Observe(newStubStore(), Events{OnHit: size, OnWrite: size})
+
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+		store.Get(ctx, "key")
+		assert.Equal(t, []int{5, 5}, sizes)
+	})
+
+	t.Run("durations", func(t *testing.T) {
+		var duration time.Duration
+		store := Observe(newStubStore(), Events{OnMiss: func(e Event) { duration = e.Duration }})
+
+		store.Get(ctx, "key")
+		assert.Greater(t, duration, time.Duration(0))
+
+		// Nil callbacks are skipped
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+	})
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrNoDefaultStore = errors.New("no default cache store configured, call cache.SetDefault first")
+
+var (
+	defaultMu      sync.Mutex
+	defaultStore   Store
+	defaultFactory StoreFactory
+)
+
+// SetDefault sets the store used by the package-level cache functions
+func SetDefault(store Store) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultStore = store
+	defaultFactory = nil
+}
+
+// SetDefaultFactory sets a factory creating the default store the first
+// time a package-level cache function is called. If the factory fails, the
+// error is returned to the caller and the factory is tried again next time.
+func SetDefaultFactory(factory StoreFactory) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultStore = nil
+	defaultFactory = factory
+}
+
+// Default returns the store used by the package-level cache functions
+func Default() (Store, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultStore != nil {
+		return defaultStore, nil
+	}
+	if defaultFactory == nil {
+		return nil, ErrNoDefaultStore
+	}
+
+	store, err := defaultFactory()
+	if err != nil {
+		return nil, err
+	}
+	defaultStore = store
+
+	return store, nil
+}
+
+// Get retrieves an item from the default store by key
+func Get(ctx context.Context, key string) (string, error) {
+	store, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return store.Get(ctx, key)
+}
+
+// Put stores an item in the default store for a given duration
+func Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	store, err := Default()
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, key, value, ttl)
+}
+
+// Has checks if an item exists in the default store
+func Has(ctx context.Context, key string) (bool, error) {
+	store, err := Default()
+	if err != nil {
+		return false, err
+	}
+	return store.Has(ctx, key)
+}
+
+// Remember gets an item from the default store, or stores the result of the
+// callback
+func Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	store, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return store.Remember(ctx, key, ttl, callback)
+}
+
+// Pull retrieves and deletes an item from the default store
+func Pull(ctx context.Context, key string) (string, error) {
+	store, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return store.Pull(ctx, key)
+}
+
+// Forever stores an item in the default store permanently
+func Forever(ctx context.Context, key, value string) error {
+	store, err := Default()
+	if err != nil {
+		return err
+	}
+	return store.Forever(ctx, key, value)
+}
+
+// Forget removes an item from the default store
+func Forget(ctx context.Context, key string) error {
+	store, err := Default()
+	if err != nil {
+		return err
+	}
+	return store.Forget(ctx, key)
+}
+
+// Flush removes all items from the default store
+func Flush(ctx context.Context) error {
+	store, err := Default()
+	if err != nil {
+		return err
+	}
+	return store.Flush(ctx)
+}
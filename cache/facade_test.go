@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetDefault clears the default store once the test finishes
+func resetDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+}
+
+func TestFacade_NoDefault(t *testing.T) {
+	resetDefault(t)
+	SetDefault(nil)
+
+	ctx := context.Background()
+
+	_, err := Get(ctx, "key")
+	assert.Equal(t, ErrNoDefaultStore, err)
+	assert.Equal(t, ErrNoDefaultStore, Put(ctx, "key", "value", time.Hour))
+	_, err = Has(ctx, "key")
+	assert.Equal(t, ErrNoDefaultStore, err)
+	_, err = Remember(ctx, "key", time.Hour, func() (interface{}, error) { return 1, nil })
+	assert.Equal(t, ErrNoDefaultStore, err)
+	_, err = Pull(ctx, "key")
+	assert.Equal(t, ErrNoDefaultStore, err)
+	assert.Equal(t, ErrNoDefaultStore, Forever(ctx, "key", "value"))
+	assert.Equal(t, ErrNoDefaultStore, Forget(ctx, "key"))
+	assert.Equal(t, ErrNoDefaultStore, Flush(ctx))
+}
+
+func TestFacade_Delegates(t *testing.T) {
+	resetDefault(t)
+	store := newStubStore()
+	SetDefault(store)
+
+	ctx := context.Background()
+
+	require.NoError(t, Put(ctx, "key", "value", time.Hour))
+	assert.Equal(t, "value", store.items["key"])
+
+	val, err := Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", val)
+
+	exists, err := Has(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	val, err = Remember(ctx, "remembered", time.Hour, func() (interface{}, error) { return 42, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "42", val)
+
+	val, err = Pull(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", val)
+	assert.NotContains(t, store.items, "key")
+
+	require.NoError(t, Forever(ctx, "forever", "value"))
+	require.NoError(t, Forget(ctx, "forever"))
+	assert.NotContains(t, store.items, "forever")
+
+	require.NoError(t, Flush(ctx))
+	assert.Empty(t, store.items)
+}
+
+func TestFacade_LazyFactory(t *testing.T) {
+	resetDefault(t)
+
+	var calls int
+	var mu sync.Mutex
+	store := newStubStore()
+	SetDefaultFactory(func() (Store, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return store, nil
+	})
+	assert.Zero(t, calls)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = Put(ctx, "key", "value", time.Hour)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+
+	resolved, err := Default()
+	require.NoError(t, err)
+	assert.Same(t, store, resolved)
+}
+
+func TestFacade_FactoryErrorIsRetried(t *testing.T) {
+	resetDefault(t)
+
+	fail := true
+	SetDefaultFactory(func() (Store, error) {
+		if fail {
+			return nil, errors.New("redis unavailable")
+		}
+		return newStubStore(), nil
+	})
+
+	_, err := Default()
+	assert.EqualError(t, err, "redis unavailable")
+
+	fail = false
+	_, err = Default()
+	assert.NoError(t, err)
+}
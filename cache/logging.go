@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogConfig configures a logging store
+type LogConfig struct {
+	// Logger receives the records, defaulting to slog.Default()
+	Logger *slog.Logger
+
+	// SlowThreshold logs every operation taking at least this long at warn
+	// level. Zero disables slow operation logging.
+	SlowThreshold time.Duration
+}
+
+// Log wraps store so failed operations are logged at error level, and
+// operations slower than the threshold at warn level, with the operation,
+// key, duration and outcome as attributes
+func Log(store Store, cfg LogConfig) *ObservedStore {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	record := func(outcome string) func(Event) {
+		return func(e Event) {
+			switch {
+			case e.Err != nil:
+				logger.LogAttrs(context.Background(), slog.LevelError, "cache operation failed",
+					slog.String("op", e.Op),
+					slog.String("key", e.Key),
+					slog.Duration("duration", e.Duration),
+					slog.String("outcome", outcome),
+					slog.Any("error", e.Err),
+				)
+			case cfg.SlowThreshold > 0 && e.Duration >= cfg.SlowThreshold:
+				logger.LogAttrs(context.Background(), slog.LevelWarn, "slow cache operation",
+					slog.String("op", e.Op),
+					slog.String("key", e.Key),
+					slog.Duration("duration", e.Duration),
+					slog.String("outcome", outcome),
+				)
+			}
+		}
+	}
+
+	return Observe(store, Events{
+		OnHit:    record("hit"),
+		OnMiss:   record("miss"),
+		OnWrite:  record("write"),
+		OnForget: record("forget"),
+	})
+}
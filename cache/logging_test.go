@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowStore delays every Get to trip the slow operation threshold
+type slowStore struct {
+	*stubStore
+	delay time.Duration
+}
+
+func (s slowStore) Get(ctx context.Context, key string) (string, error) {
+	time.Sleep(s.delay)
+	return s.stubStore.Get(ctx, key)
+}
+
+// logRecords decodes the JSON log lines written to buf
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		stub := newStubStore()
+		store := Log(stub, LogConfig{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+		// Successful operations and misses are not logged
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+		store.Get(ctx, "missing")
+		assert.Empty(t, buf.String())
+
+		stub.err = errors.New("connection refused")
+		store.Put(ctx, "key", "value", time.Minute)
+
+		records := logRecords(t, &buf)
+		require.Len(t, records, 1)
+		assert.Equal(t, "ERROR", records[0]["level"])
+		assert.Equal(t, "cache operation failed", records[0]["msg"])
+		assert.Equal(t, "put", records[0]["op"])
+		assert.Equal(t, "key", records[0]["key"])
+		assert.Equal(t, "write", records[0]["outcome"])
+		assert.Equal(t, "connection refused", records[0]["error"])
+		assert.Contains(t, records[0], "duration")
+	})
+
+	t.Run("slow operations", func(t *testing.T) {
+		var buf bytes.Buffer
+		stub := newStubStore()
+		stub.items["key"] = "value"
+		store := Log(slowStore{stubStore: stub, delay: 5 * time.Millisecond}, LogConfig{
+			Logger:        slog.New(slog.NewJSONHandler(&buf, nil)),
+			SlowThreshold: time.Millisecond,
+		})
+
+		store.Get(ctx, "key")
+		require.NoError(t, store.Forget(ctx, "key"))
+
+		records := logRecords(t, &buf)
+		require.Len(t, records, 1)
+		assert.Equal(t, "WARN", records[0]["level"])
+		assert.Equal(t, "slow cache operation", records[0]["msg"])
+		assert.Equal(t, "get", records[0]["op"])
+		assert.Equal(t, "hit", records[0]["outcome"])
+	})
+}
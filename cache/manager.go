@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrStoreNotConfigured = errors.New("cache store is not configured")
+
+// StoreFactory creates a store the first time it is requested from a Manager
+type StoreFactory func() (Store, error)
+
+// ManagerConfig holds the stores known to a Manager
+type ManagerConfig struct {
+	// Default is the name of the store returned by Manager.Default
+	Default string
+
+	// Stores maps store names to the factories creating them
+	Stores map[string]StoreFactory
+}
+
+// Manager resolves named stores, creating each one lazily on first use and
+// reusing it afterwards
+type Manager struct {
+	mu          sync.Mutex
+	defaultName string
+	factories   map[string]StoreFactory
+	stores      map[string]Store
+
+	// versions counts the factories registered under each name, so stores
+	// built by a factory replaced meanwhile are discarded
+	versions map[string]int
+}
+
+// NewManager creates a new store manager
+func NewManager(cfg ManagerConfig) *Manager {
+	factories := make(map[string]StoreFactory, len(cfg.Stores))
+	for name, factory := range cfg.Stores {
+		factories[name] = factory
+	}
+
+	return &Manager{
+		defaultName: cfg.Default,
+		factories:   factories,
+		stores:      make(map[string]Store),
+		versions:    make(map[string]int),
+	}
+}
+
+// Store returns the store registered under name, creating it if needed.
+// The factory runs without holding the manager's lock, so slow factories do
+// not hold up other stores.
+func (m *Manager) Store(name string) (Store, error) {
+	m.mu.Lock()
+	store, ok := m.stores[name]
+	factory, registered := m.factories[name]
+	version := m.versions[name]
+	m.mu.Unlock()
+
+	if ok {
+		return store, nil
+	}
+	if !registered || factory == nil {
+		return nil, fmt.Errorf("%w: %q", ErrStoreNotConfigured, name)
+	}
+
+	store, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache store %q: %w", name, err)
+	}
+
+	m.mu.Lock()
+	existing, ok := m.stores[name]
+	current := m.versions[name] == version
+	if !ok && current {
+		m.stores[name] = store
+	}
+	m.mu.Unlock()
+
+	switch {
+	case ok:
+		// Another caller created the store first
+		store.Close()
+		return existing, nil
+	case !current:
+		// The factory was replaced while it ran
+		store.Close()
+		return m.Store(name)
+	}
+	return store, nil
+}
+
+// Default returns the store configured as the default
+func (m *Manager) Default() (Store, error) {
+	return m.Store(m.defaultName)
+}
+
+// Extend registers an additional store factory, replacing any store
+// previously registered under the same name. A store already created by the
+// replaced factory is closed, and the error closing it returned.
+func (m *Manager) Extend(name string, factory StoreFactory) error {
+	m.mu.Lock()
+	m.factories[name] = factory
+	m.versions[name]++
+	old, ok := m.stores[name]
+	delete(m.stores, name)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("failed to close cache store %q: %w", name, err)
+	}
+	return nil
+}
+
+// Close closes every store created by the manager
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for name, store := range m.stores {
+		if err := store.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close cache store %q: %w", name, err))
+		}
+		delete(m.stores, name)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubStore is a minimal map backed Store used to exercise the cache package
+// without depending on a driver
+type stubStore struct {
+	mu     sync.Mutex
+	items  map[string]string
+	closed bool
+	err    error
+}
+
+func newStubStore() *stubStore {
+	return &stubStore{items: make(map[string]string)}
+}
+
+func (s *stubStore) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.items[key]
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func (s *stubStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.items[key] = value
+	return nil
+}
+
+func (s *stubStore) Has(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *stubStore) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	if value, err := s.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+	result, err := callback()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), s.Put(ctx, key, string(data), ttl)
+}
+
+func (s *stubStore) Pull(ctx context.Context, key string) (string, error) {
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return value, s.Forget(ctx, key)
+}
+
+func (s *stubStore) Forever(ctx context.Context, key, value string) error {
+	return s.Put(ctx, key, value, 0)
+}
+
+func (s *stubStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.items, key)
+	return nil
+}
+
+func (s *stubStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.items = make(map[string]string)
+	return nil
+}
+
+func (s *stubStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestManager_Store(t *testing.T) {
+	created := map[string]int{}
+	factory := func(name string) StoreFactory {
+		return func() (Store, error) {
+			created[name]++
+			return newStubStore(), nil
+		}
+	}
+
+	m := NewManager(ManagerConfig{
+		Default: "redis",
+		Stores: map[string]StoreFactory{
+			"redis":  factory("redis"),
+			"memory": factory("memory"),
+			"broken": func() (Store, error) { return nil, errors.New("boom") },
+		},
+	})
+
+	t.Run("stores are created lazily and reused", func(t *testing.T) {
+		assert.Empty(t, created)
+
+		first, err := m.Store("memory")
+		require.NoError(t, err)
+		second, err := m.Store("memory")
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, created["memory"])
+		assert.Zero(t, created["redis"])
+	})
+
+	t.Run("stores are isolated", func(t *testing.T) {
+		ctx := context.Background()
+		redis, _ := m.Store("redis")
+		memory, _ := m.Store("memory")
+
+		require.NoError(t, redis.Put(ctx, "key", "redis-value", time.Hour))
+		_, err := memory.Get(ctx, "key")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("default store", func(t *testing.T) {
+		store, err := m.Default()
+		require.NoError(t, err)
+
+		redis, _ := m.Store("redis")
+		assert.Same(t, redis, store)
+	})
+
+	t.Run("unknown store", func(t *testing.T) {
+		_, err := m.Store("missing")
+		assert.ErrorIs(t, err, ErrStoreNotConfigured)
+	})
+
+	t.Run("factory error", func(t *testing.T) {
+		_, err := m.Store("broken")
+		assert.EqualError(t, err, `failed to create cache store "broken": boom`)
+	})
+
+	t.Run("extend", func(t *testing.T) {
+		custom := newStubStore()
+		m.Extend("sessions", func() (Store, error) { return custom, nil })
+
+		store, err := m.Store("sessions")
+		require.NoError(t, err)
+		assert.Same(t, custom, store)
+
+		// Replacing a store that was created closes it
+		replacement := newStubStore()
+		require.NoError(t, m.Extend("sessions", func() (Store, error) { return replacement, nil }))
+		assert.True(t, custom.closed)
+
+		store, err = m.Store("sessions")
+		require.NoError(t, err)
+		assert.Same(t, replacement, store)
+	})
+
+	t.Run("factories run without the lock", func(t *testing.T) {
+		// A factory may resolve the stores it wraps
+		m.Extend("chain", func() (Store, error) {
+			primary, err := m.Store("redis")
+			if err != nil {
+				return nil, err
+			}
+			return Chain(primary, newStubStore()), nil
+		})
+
+		store, err := m.Store("chain")
+		require.NoError(t, err)
+		assert.IsType(t, &ChainStore{}, store)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		memory, _ := m.Store("memory")
+
+		require.NoError(t, m.Close())
+		assert.True(t, memory.(*stubStore).closed)
+
+		// Stores are recreated after closing
+		_, err := m.Store("memory")
+		require.NoError(t, err)
+		assert.Equal(t, 2, created["memory"])
+	})
+}
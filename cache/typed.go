@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DecodeError is returned when a cached value cannot be decoded into the
+// requested type
+type DecodeError struct {
+	Key string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode cached value for key %q: %v", e.Key, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// CodecStore is implemented by stores that encode Remember results with a
+// configurable codec, and by wrappers passing their store's codec through
+type CodecStore interface {
+	Store
+
+	// Codec returns the codec Remember encodes callback results with
+	Codec() Codec
+}
+
+// CodecOf returns the codec store encodes Remember results with: its own
+// when it implements CodecStore, JSON otherwise
+func CodecOf(store Store) Codec {
+	if s, ok := store.(CodecStore); ok {
+		return s.Codec()
+	}
+	return JSONCodec{}
+}
+
+// RememberAs is Remember with a typed callback: it gets an item from store
+// and decodes it into T, or stores the encoded result of the callback and
+// returns it as is
+func RememberAs[T any](ctx context.Context, store Store, key string, ttl time.Duration, callback func() (T, error)) (T, error) {
+	var result T
+	if callback == nil {
+		return result, ErrNilCallback
+	}
+
+	tracked := TrackCallback(func() (interface{}, error) {
+		return callback()
+	})
+	value, err := store.Remember(ctx, key, ttl, tracked.Func())
+	ran, computed, callbackErr := tracked.Done()
+	if ran && callbackErr == nil {
+		result, _ = computed.(T)
+		return result, err
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if err := CodecOf(store).Unmarshal([]byte(value), &result); err != nil {
+		return result, &DecodeError{Key: key, Err: err}
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// msgpackStore is a stub store encoding Remember results as MessagePack
+type msgpackStore struct {
+	*stubStore
+}
+
+func (s msgpackStore) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	if value, err := s.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	result, err := callback()
+	if err != nil {
+		return "", err
+	}
+	data, err := MsgpackCodec{}.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), s.Put(ctx, key, string(data), ttl)
+}
+
+func (msgpackStore) Codec() Codec {
+	return MsgpackCodec{}
+}
+
+type user struct {
+	ID   int    `json:"id" msgpack:"id"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+func TestRememberAs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("computes then decodes", func(t *testing.T) {
+		store := newStubStore()
+
+		calls := 0
+		load := func() (user, error) {
+			calls++
+			return user{ID: 1, Name: "Ada"}, nil
+		}
+
+		u, err := RememberAs(ctx, store, "user:1", time.Minute, load)
+		require.NoError(t, err)
+		assert.Equal(t, user{ID: 1, Name: "Ada"}, u)
+		assert.Equal(t, `{"id":1,"name":"Ada"}`, store.items["user:1"])
+
+		u, err = RememberAs(ctx, store, "user:1", time.Minute, load)
+		require.NoError(t, err)
+		assert.Equal(t, user{ID: 1, Name: "Ada"}, u)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("uses the store's codec", func(t *testing.T) {
+		store := msgpackStore{newStubStore()}
+		wrapped := Observe(Chain(store, newStubStore()), Events{})
+		assert.Equal(t, MsgpackCodec{}, CodecOf(wrapped))
+
+		_, err := RememberAs(ctx, wrapped, "user:1", time.Minute, func() (user, error) {
+			return user{ID: 1, Name: "Ada"}, nil
+		})
+		require.NoError(t, err)
+
+		u, err := RememberAs(ctx, wrapped, "user:1", time.Minute, func() (user, error) {
+			return user{}, errors.New("not called")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, user{ID: 1, Name: "Ada"}, u)
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		store := newStubStore()
+		store.items["user:1"] = "not json"
+
+		_, err := RememberAs(ctx, store, "user:1", time.Minute, func() (user, error) {
+			return user{}, nil
+		})
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "user:1", decodeErr.Key)
+	})
+
+	t.Run("callback errors", func(t *testing.T) {
+		store := newStubStore()
+
+		_, err := RememberAs(ctx, store, "user:1", time.Minute, func() (user, error) {
+			return user{}, errors.New("query failed")
+		})
+		assert.EqualError(t, err, "query failed")
+
+		_, err = RememberAs[user](ctx, store, "user:1", time.Minute, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
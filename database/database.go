@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+var (
+	ErrKeyNotFound = cache.ErrKeyNotFound
+	ErrNilCallback = cache.ErrNilCallback
+)
+
+const (
+	defaultTable = "cache"
+
+	// defaultPruneProbability is used when Config.PruneProbability is not set
+	defaultPruneProbability = 0.02
+)
+
+// Dialect selects the SQL flavour used to talk to the database
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	MySQL
+	SQLite
+)
+
+var _ cache.Store = (*Store)(nil)
+
+// Store is a cache store persisting items in a SQL table
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+
+	pruneProbability float64
+
+	now func() time.Time
+}
+
+// Config holds the configuration for the database store
+type Config struct {
+	// DB is the connection pool to use. It is owned by the caller and is not
+	// closed by Close.
+	DB *sql.DB
+
+	// Dialect is the SQL flavour of DB, defaulting to Postgres
+	Dialect Dialect
+
+	// Table is the name of the cache table, defaulting to "cache"
+	Table string
+
+	// PruneProbability is the chance, between 0 and 1, that a write also
+	// deletes every expired row. Defaults to 2%; set it negative to disable
+	// lazy pruning and call Prune yourself.
+	PruneProbability float64
+}
+
+// New creates a new database store
+func New(cfg Config) (*Store, error) {
+	if cfg.DB == nil {
+		return nil, errors.New("database connection must be set")
+	}
+
+	s := &Store{
+		db:               cfg.DB,
+		dialect:          cfg.Dialect,
+		table:            cfg.Table,
+		pruneProbability: cfg.PruneProbability,
+		now:              time.Now,
+	}
+	if s.table == "" {
+		s.table = defaultTable
+	}
+	if s.pruneProbability == 0 {
+		s.pruneProbability = defaultPruneProbability
+	}
+
+	return s, nil
+}
+
+// quote quotes an identifier for the configured dialect
+func (s *Store) quote(name string) string {
+	if s.dialect == MySQL {
+		return "`" + name + "`"
+	}
+	return `"` + name + `"`
+}
+
+// query fills in the quoted table and column names referenced as {table},
+// {key}, {value} and {expiration}, and rewrites the ? placeholders of a
+// query for the configured dialect
+func (s *Store) query(q string) string {
+	q = strings.NewReplacer(
+		"{table}", s.quote(s.table),
+		"{key}", s.quote("key"),
+		"{value}", s.quote("value"),
+		"{expiration}", s.quote("expiration"),
+	).Replace(q)
+	if s.dialect != Postgres {
+		return q
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// expiration converts a TTL into the stored expiration in unix milliseconds,
+// zero meaning the item never expires
+func (s *Store) expiration(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return s.now().Add(ttl).UnixMilli()
+}
+
+// CreateTable creates the cache table if it does not exist
+func (s *Store) CreateTable(ctx context.Context) error {
+	valueType := "TEXT"
+	if s.dialect == MySQL {
+		valueType = "LONGTEXT"
+	}
+
+	_, err := s.db.ExecContext(ctx, s.query(
+		"CREATE TABLE IF NOT EXISTS {table} ({key} VARCHAR(255) NOT NULL PRIMARY KEY, {value} "+valueType+" NOT NULL, {expiration} BIGINT NOT NULL)",
+	))
+	return err
+}
+
+// Prune deletes every expired row, returning how many were removed
+func (s *Store) Prune(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		s.query(`DELETE FROM {table} WHERE {expiration} > 0 AND {expiration} <= ?`),
+		s.now().UnixMilli(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Get retrieves an item from the cache by key. Expired rows are deleted as
+// they are found.
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	var (
+		value      string
+		expiration int64
+	)
+	err := s.db.QueryRowContext(ctx,
+		s.query(`SELECT {value}, {expiration} FROM {table} WHERE {key} = ?`),
+		key,
+	).Scan(&value, &expiration)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if expiration > 0 && expiration <= s.now().UnixMilli() {
+		if err := s.forgetExpired(ctx, key, expiration); err != nil {
+			return "", err
+		}
+		return "", ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// forgetExpired deletes the row of key if it still has the expiration read,
+// leaving it alone if a concurrent Put wrote it again in the meantime
+func (s *Store) forgetExpired(ctx context.Context, key string, expiration int64) error {
+	_, err := s.db.ExecContext(ctx,
+		s.query(`DELETE FROM {table} WHERE {key} = ? AND {expiration} = ?`),
+		key, expiration,
+	)
+	return err
+}
+
+// Has checks if an item exists in the cache
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remember gets an item from the cache, or stores the result of the callback
+func (s *Store) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	// First, try to get the existing item
+	value, err := s.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+
+	// If callback is nil, return error
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result to JSON string
+	jsonValue, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	// Store the result in cache
+	if err := s.Put(ctx, key, string(jsonValue), ttl); err != nil {
+		return "", err
+	}
+
+	return string(jsonValue), nil
+}
+
+// Pull retrieves and deletes an item from the cache
+func (s *Store) Pull(ctx context.Context, key string) (string, error) {
+	// Get the value first
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Then delete it
+	if err := s.Forget(ctx, key); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Put stores an item in the cache for a given duration. A non-positive ttl
+// stores the item permanently.
+func (s *Store) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	upsert := `INSERT INTO {table} ({key}, {value}, {expiration}) VALUES (?, ?, ?)
+		ON CONFLICT ({key}) DO UPDATE SET {value} = excluded.{value}, {expiration} = excluded.{expiration}`
+	if s.dialect == MySQL {
+		upsert = `INSERT INTO {table} ({key}, {value}, {expiration}) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE {value} = VALUES({value}), {expiration} = VALUES({expiration})`
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.query(upsert), key, value, s.expiration(ttl)); err != nil {
+		return err
+	}
+
+	if s.pruneProbability > 0 && rand.Float64() < s.pruneProbability {
+		// Pruning is best effort: the item is stored whether or not it
+		// succeeds, and Get skips expired rows anyway
+		_, _ = s.Prune(ctx)
+	}
+
+	return nil
+}
+
+// Forever stores an item in the cache permanently
+func (s *Store) Forever(ctx context.Context, key, value string) error {
+	return s.Put(ctx, key, value, 0)
+}
+
+// Forget removes an item from the cache
+func (s *Store) Forget(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.query(`DELETE FROM {table} WHERE {key} = ?`), key)
+	return err
+}
+
+// Flush removes all items from the cache
+func (s *Store) Flush(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.query(`DELETE FROM {table}`))
+	return err
+}
+
+// Close is a no-op, as the connection pool belongs to the caller
+func (s *Store) Close() error {
+	return nil
+}
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type testStruct struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// setupTestStore creates a store over an in-memory SQLite database with a
+// controllable clock
+func setupTestStore(t *testing.T, dialect Dialect) (*Store, *time.Time) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// Every connection to :memory: opens a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s, err := New(Config{DB: db, Dialect: dialect, PruneProbability: -1})
+	require.NoError(t, err)
+	require.NoError(t, s.CreateTable(context.Background()))
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	return s, &now
+}
+
+// rowCount returns the number of rows in the cache table, expired or not
+func rowCount(t *testing.T, s *Store) int {
+	var count int
+	require.NoError(t, s.db.QueryRow(s.query("SELECT COUNT(*) FROM {table}")).Scan(&count))
+	return count
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+
+	s, err := New(Config{DB: &sql.DB{}})
+	require.NoError(t, err)
+	assert.Equal(t, "cache", s.table)
+	assert.Equal(t, defaultPruneProbability, s.pruneProbability)
+}
+
+func TestStore_Query(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    string
+	}{
+		{name: "postgres", dialect: Postgres, want: `SELECT "value" FROM "cache" WHERE "key" = $1 AND "expiration" > $2`},
+		{name: "mysql", dialect: MySQL, want: "SELECT `value` FROM `cache` WHERE `key` = ? AND `expiration` > ?"},
+		{name: "sqlite", dialect: SQLite, want: `SELECT "value" FROM "cache" WHERE "key" = ? AND "expiration" > ?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(Config{DB: &sql.DB{}, Dialect: tt.dialect})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.query("SELECT {value} FROM {table} WHERE {key} = ? AND {expiration} > ?"))
+		})
+	}
+}
+
+func TestStore_PutGet(t *testing.T) {
+	dialects := map[string]Dialect{"postgres placeholders": Postgres, "sqlite": SQLite}
+	for name, dialect := range dialects {
+		t.Run(name, func(t *testing.T) {
+			s, now := setupTestStore(t, dialect)
+			ctx := context.Background()
+
+			t.Run("get existing key", func(t *testing.T) {
+				require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+				val, err := s.Get(ctx, "test-key")
+				assert.NoError(t, err)
+				assert.Equal(t, "test-value", val)
+			})
+
+			t.Run("overwrite existing key", func(t *testing.T) {
+				require.NoError(t, s.Put(ctx, "test-key", "initial-value", time.Hour))
+				require.NoError(t, s.Put(ctx, "test-key", "new-value", time.Hour))
+
+				val, err := s.Get(ctx, "test-key")
+				assert.NoError(t, err)
+				assert.Equal(t, "new-value", val)
+			})
+
+			t.Run("get non-existent key", func(t *testing.T) {
+				val, err := s.Get(ctx, "non-existent-key")
+				assert.Equal(t, ErrKeyNotFound, err)
+				assert.Empty(t, val)
+			})
+
+			t.Run("expired rows are deleted when read", func(t *testing.T) {
+				require.NoError(t, s.Flush(ctx))
+				require.NoError(t, s.Put(ctx, "expired-key", "test-value", time.Minute))
+
+				*now = now.Add(2 * time.Minute)
+
+				_, err := s.Get(ctx, "expired-key")
+				assert.Equal(t, ErrKeyNotFound, err)
+				assert.Equal(t, 0, rowCount(t, s))
+			})
+
+			t.Run("forever", func(t *testing.T) {
+				require.NoError(t, s.Forever(ctx, "forever", "value"))
+
+				*now = now.Add(24 * 365 * time.Hour)
+				val, err := s.Get(ctx, "forever")
+				assert.NoError(t, err)
+				assert.Equal(t, "value", val)
+			})
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	s, now := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "short", "value", time.Minute))
+	require.NoError(t, s.Put(ctx, "long", "value", time.Hour))
+	require.NoError(t, s.Forever(ctx, "forever", "value"))
+
+	*now = now.Add(2 * time.Minute)
+
+	pruned, err := s.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+	assert.Equal(t, 2, rowCount(t, s))
+}
+
+func TestStore_LazyPrune(t *testing.T) {
+	s, now := setupTestStore(t, SQLite)
+	s.pruneProbability = 1
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "short", "value", time.Minute))
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, s.Put(ctx, "other", "value", time.Hour))
+
+	assert.Equal(t, 1, rowCount(t, s))
+}
+
+func TestStore_LazyPruneFailure(t *testing.T) {
+	s, now := setupTestStore(t, SQLite)
+	s.pruneProbability = 1
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "short", "value", time.Minute))
+	_, err := s.db.Exec(s.query(`CREATE TRIGGER keep BEFORE DELETE ON {table} BEGIN SELECT RAISE(ABORT, 'read only'); END`))
+	require.NoError(t, err)
+	*now = now.Add(2 * time.Minute)
+
+	// The write succeeded, so a failed prune is not reported
+	require.NoError(t, s.Put(ctx, "other", "value", time.Hour))
+	val, err := s.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "value", val)
+}
+
+func TestStore_ExpiredRowRewritten(t *testing.T) {
+	s, now := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "key", "stale", time.Minute))
+	expiration := now.Add(time.Minute).UnixMilli()
+	*now = now.Add(2 * time.Minute)
+
+	// A Put lands between Get reading the expired row and deleting it
+	require.NoError(t, s.Put(ctx, "key", "fresh", time.Hour))
+	require.NoError(t, s.forgetExpired(ctx, "key", expiration))
+
+	val, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", val)
+}
+
+func TestStore_Has(t *testing.T) {
+	s, _ := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	exists, err := s.Has(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.Has(ctx, "non-existent-key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStore_Remember(t *testing.T) {
+	s, _ := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	t.Run("remember new value", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		var result testStruct
+		require.NoError(t, json.Unmarshal([]byte(val), &result))
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+
+		_, err = s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := s.Remember(ctx, "nil-callback", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
+func TestStore_Pull(t *testing.T) {
+	s, _ := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	val, err := s.Pull(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-value", val)
+
+	_, err = s.Pull(ctx, "test-key")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestStore_ForgetFlush(t *testing.T) {
+	s, _ := setupTestStore(t, SQLite)
+	ctx := context.Background()
+
+	require.NoError(t, s.Forever(ctx, "forever", "value"))
+	require.NoError(t, s.Forget(ctx, "forever"))
+	exists, _ := s.Has(ctx, "forever")
+	assert.False(t, exists)
+
+	require.NoError(t, s.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, s.Forever(ctx, "key2", "value2"))
+	require.NoError(t, s.Flush(ctx))
+	assert.Equal(t, 0, rowCount(t, s))
+	assert.NoError(t, s.Close())
+}
@@ -0,0 +1,343 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+var (
+	ErrKeyNotFound = cache.ErrKeyNotFound
+	ErrNilCallback = cache.ErrNilCallback
+)
+
+const (
+	defaultKeyAttribute        = "key"
+	defaultValueAttribute      = "value"
+	defaultExpirationAttribute = "expires_at"
+
+	// batchWriteSize is the maximum number of requests DynamoDB accepts in a
+	// single BatchWriteItem call
+	batchWriteSize = 25
+)
+
+var _ cache.Store = (*Client)(nil)
+
+// API is the subset of the DynamoDB client used by the store, satisfied by
+// *dynamodb.Client
+type API interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// Client represents a DynamoDB backed cache
+type Client struct {
+	client API
+	table  string
+
+	keyAttribute        string
+	valueAttribute      string
+	expirationAttribute string
+
+	now func() time.Time
+}
+
+// Config holds the configuration for the DynamoDB store
+type Config struct {
+	// Client is the DynamoDB client to use, typically created with
+	// dynamodb.NewFromConfig
+	Client API
+
+	// Table is the name of the cache table. Its partition key must be a
+	// string attribute named after KeyAttribute.
+	Table string
+
+	// KeyAttribute is the partition key attribute, defaulting to "key"
+	KeyAttribute string
+
+	// ValueAttribute is the attribute holding cached values, defaulting to
+	// "value"
+	ValueAttribute string
+
+	// ExpirationAttribute holds the expiry as a unix timestamp in seconds,
+	// defaulting to "expires_at". Enable DynamoDB TTL on it so expired items
+	// are removed by the service.
+	ExpirationAttribute string
+}
+
+// New creates a new DynamoDB backed cache
+func New(cfg Config) (*Client, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("dynamodb client must be set")
+	}
+	if cfg.Table == "" {
+		return nil, errors.New("dynamodb table must be set")
+	}
+
+	c := &Client{
+		client:              cfg.Client,
+		table:               cfg.Table,
+		keyAttribute:        cfg.KeyAttribute,
+		valueAttribute:      cfg.ValueAttribute,
+		expirationAttribute: cfg.ExpirationAttribute,
+		now:                 time.Now,
+	}
+	if c.keyAttribute == "" {
+		c.keyAttribute = defaultKeyAttribute
+	}
+	if c.valueAttribute == "" {
+		c.valueAttribute = defaultValueAttribute
+	}
+	if c.expirationAttribute == "" {
+		c.expirationAttribute = defaultExpirationAttribute
+	}
+
+	return c, nil
+}
+
+// keyOf returns the primary key of the item holding key
+func (c *Client) keyOf(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		c.keyAttribute: &types.AttributeValueMemberS{Value: key},
+	}
+}
+
+// item builds the attributes stored for a cache entry
+func (c *Client) item(key, value string, ttl time.Duration) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		c.keyAttribute:   &types.AttributeValueMemberS{Value: key},
+		c.valueAttribute: &types.AttributeValueMemberS{Value: value},
+	}
+	if ttl > 0 {
+		// Round up so short TTLs do not expire immediately
+		expiresAt := c.now().Add(ttl + time.Second - 1).Unix()
+		item[c.expirationAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)}
+	}
+	return item
+}
+
+// value extracts the cached value from an item, reporting false if the item
+// is missing or has expired but not yet been removed by DynamoDB
+func (c *Client) value(item map[string]types.AttributeValue) (string, bool) {
+	if item == nil {
+		return "", false
+	}
+
+	if n, ok := item[c.expirationAttribute].(*types.AttributeValueMemberN); ok {
+		expiresAt, err := strconv.ParseInt(n.Value, 10, 64)
+		if err == nil && expiresAt <= c.now().Unix() {
+			return "", false
+		}
+	}
+
+	s, ok := item[c.valueAttribute].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", false
+	}
+	return s.Value, true
+}
+
+// Get retrieves an item from the cache by key
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	out, err := c.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.table),
+		Key:            c.keyOf(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := c.value(out.Item)
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return value, nil
+}
+
+// Has checks if an item exists in the cache
+func (c *Client) Has(ctx context.Context, key string) (bool, error) {
+	_, err := c.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remember gets an item from the cache, or stores the result of the callback
+func (c *Client) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	// First, try to get the existing item
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+
+	// If callback is nil, return error
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result to JSON string
+	jsonValue, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	// Store the result in cache
+	if err := c.Put(ctx, key, string(jsonValue), ttl); err != nil {
+		return "", err
+	}
+
+	return string(jsonValue), nil
+}
+
+// Pull retrieves and deletes an item from the cache in a single request
+func (c *Client) Pull(ctx context.Context, key string) (string, error) {
+	out, err := c.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(c.table),
+		Key:          c.keyOf(key),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := c.value(out.Attributes)
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return value, nil
+}
+
+// Put stores an item in the cache for a given duration
+func (c *Client) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.table),
+		Item:      c.item(key, value, ttl),
+	})
+	return err
+}
+
+// Add stores an item in the cache only if it does not already exist, or has
+// expired, using a conditional write. It reports whether the item was stored.
+func (c *Client) Add(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(c.table),
+		Item:                c.item(key, value, ttl),
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #expires_at <= :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":        c.keyAttribute,
+			"#expires_at": c.expirationAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(c.now().Unix(), 10)},
+		},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Forever stores an item in the cache permanently
+func (c *Client) Forever(ctx context.Context, key, value string) error {
+	return c.Put(ctx, key, value, 0)
+}
+
+// Forget removes an item from the cache
+func (c *Client) Forget(ctx context.Context, key string) error {
+	_, err := c.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(c.table),
+		Key:       c.keyOf(key),
+	})
+	return err
+}
+
+// Flush removes all items from the cache by scanning the table and deleting
+// its items in batches
+func (c *Client) Flush(ctx context.Context) error {
+	var startKey map[string]types.AttributeValue
+	for {
+		out, err := c.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:                aws.String(c.table),
+			ProjectionExpression:     aws.String("#key"),
+			ExpressionAttributeNames: map[string]string{"#key": c.keyAttribute},
+			ExclusiveStartKey:        startKey,
+		})
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(out.Items); start += batchWriteSize {
+			end := start + batchWriteSize
+			if end > len(out.Items) {
+				end = len(out.Items)
+			}
+			if err := c.deleteBatch(ctx, out.Items[start:end]); err != nil {
+				return err
+			}
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = out.LastEvaluatedKey
+	}
+}
+
+// deleteBatch deletes up to batchWriteSize items, retrying any the service
+// reports as unprocessed
+func (c *Client) deleteBatch(ctx context.Context, keys []map[string]types.AttributeValue) error {
+	requests := make([]types.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{Key: key},
+		})
+	}
+
+	pending := map[string][]types.WriteRequest{c.table: requests}
+	for len(pending[c.table]) > 0 {
+		out, err := c.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			return err
+		}
+		pending = out.UnprocessedItems
+	}
+	return nil
+}
+
+// Close is a no-op, as the DynamoDB client holds no connections of its own
+func (c *Client) Close() error {
+	return nil
+}
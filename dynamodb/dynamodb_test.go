@@ -0,0 +1,296 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// fakeDynamoDB is an in-memory table understanding just enough of the
+// DynamoDB API to exercise the store
+type fakeDynamoDB struct {
+	mu       sync.Mutex
+	items    map[string]map[string]types.AttributeValue
+	pageSize int
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[string]map[string]types.AttributeValue), pageSize: 2}
+}
+
+func keyValue(key map[string]types.AttributeValue) string {
+	return key[defaultKeyAttribute].(*types.AttributeValueMemberS).Value
+}
+
+func (f *fakeDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[keyValue(params.Key)]}, nil
+}
+
+func (f *fakeDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := keyValue(params.Item)
+	if params.ConditionExpression != nil {
+		// attribute_not_exists(#key) OR #expires_at <= :now
+		if existing, ok := f.items[key]; ok {
+			now, _ := strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+			n, hasExpiry := existing[defaultExpirationAttribute].(*types.AttributeValueMemberN)
+			expired := false
+			if hasExpiry {
+				expiresAt, _ := strconv.ParseInt(n.Value, 10, 64)
+				expired = expiresAt <= now
+			}
+			if !expired {
+				return nil, &types.ConditionalCheckFailedException{Message: aws.String("conditional request failed")}
+			}
+		}
+	}
+
+	f.items[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := keyValue(params.Key)
+	out := &dynamodb.DeleteItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = f.items[key]
+	}
+	delete(f.items, key)
+	return out, nil
+}
+
+func (f *fakeDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.items))
+	for key := range f.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if params.ExclusiveStartKey != nil {
+		after := keyValue(params.ExclusiveStartKey)
+		start = sort.Search(len(keys), func(i int) bool { return keys[i] > after })
+	}
+
+	out := &dynamodb.ScanOutput{}
+	for i := start; i < len(keys) && len(out.Items) < f.pageSize; i++ {
+		out.Items = append(out.Items, map[string]types.AttributeValue{
+			defaultKeyAttribute: &types.AttributeValueMemberS{Value: keys[i]},
+		})
+	}
+	if start+len(out.Items) < len(keys) {
+		out.LastEvaluatedKey = out.Items[len(out.Items)-1]
+	}
+	return out, nil
+}
+
+func (f *fakeDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, requests := range params.RequestItems {
+		for _, req := range requests {
+			delete(f.items, keyValue(req.DeleteRequest.Key))
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+// setupTestDynamoDB creates a store backed by a fake table and a controllable
+// clock
+func setupTestDynamoDB(t *testing.T) (*Client, *fakeDynamoDB, *time.Time) {
+	fake := newFakeDynamoDB()
+
+	client, err := New(Config{Client: fake, Table: "cache"})
+	require.NoError(t, err)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	client.now = func() time.Time { return now }
+
+	return client, fake, &now
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Table: "cache"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Client: newFakeDynamoDB()})
+	assert.Error(t, err)
+
+	client, err := New(Config{Client: newFakeDynamoDB(), Table: "cache"})
+	require.NoError(t, err)
+	assert.Equal(t, "key", client.keyAttribute)
+	assert.Equal(t, "value", client.valueAttribute)
+	assert.Equal(t, "expires_at", client.expirationAttribute)
+	assert.NoError(t, client.Close())
+}
+
+func TestClient_PutGet(t *testing.T) {
+	client, fake, now := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	t.Run("store string with TTL", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+		val, err := client.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-value", val)
+
+		expiresAt := fake.items["test-key"][defaultExpirationAttribute].(*types.AttributeValueMemberN).Value
+		assert.Equal(t, strconv.FormatInt(now.Add(time.Hour).Unix(), 10), expiresAt)
+	})
+
+	t.Run("get non-existent key", func(t *testing.T) {
+		val, err := client.Get(ctx, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("expired items are not returned before DynamoDB removes them", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "expired-key", "test-value", time.Minute))
+
+		*now = now.Add(2 * time.Minute)
+
+		_, err := client.Get(ctx, "expired-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("forever has no expiration attribute", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+
+		_, ok := fake.items["forever"][defaultExpirationAttribute]
+		assert.False(t, ok)
+
+		*now = now.Add(24 * 365 * time.Hour)
+		val, err := client.Get(ctx, "forever")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+}
+
+func TestClient_Add(t *testing.T) {
+	client, _, now := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	added, err := client.Add(ctx, "lock", "first", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	added, err = client.Add(ctx, "lock", "second", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	val, _ := client.Get(ctx, "lock")
+	assert.Equal(t, "first", val)
+
+	// An expired item may be replaced
+	*now = now.Add(2 * time.Minute)
+	added, err = client.Add(ctx, "lock", "third", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	val, _ = client.Get(ctx, "lock")
+	assert.Equal(t, "third", val)
+}
+
+func TestClient_Has(t *testing.T) {
+	client, _, _ := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+	exists, err := client.Has(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = client.Has(ctx, "non-existent-key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestClient_Remember(t *testing.T) {
+	client, _, _ := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	t.Run("remember new value", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := client.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		var result testStruct
+		require.NoError(t, json.Unmarshal([]byte(val), &result))
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+
+		_, err = client.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := client.Remember(ctx, "nil-callback", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
+func TestClient_Pull(t *testing.T) {
+	client, fake, _ := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+	val, err := client.Pull(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-value", val)
+	assert.NotContains(t, fake.items, "test-key")
+
+	_, err = client.Pull(ctx, "test-key")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestClient_ForgetFlush(t *testing.T) {
+	client, fake, _ := setupTestDynamoDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+	require.NoError(t, client.Forget(ctx, "test-key"))
+	assert.NotContains(t, fake.items, "test-key")
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, client.Forever(ctx, "key"+strconv.Itoa(i), "value"))
+	}
+
+	// The fake scans two items per page, so this exercises pagination
+	require.NoError(t, client.Flush(ctx))
+	assert.Empty(t, fake.items)
+}
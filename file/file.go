@@ -0,0 +1,417 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+var (
+	ErrKeyNotFound = cache.ErrKeyNotFound
+	ErrNilCallback = cache.ErrNilCallback
+)
+
+const (
+	// defaultCleanupInterval is used when Config.CleanupInterval is not set
+	defaultCleanupInterval = 10 * time.Minute
+
+	// headerSize is the length of the expiration header written before the
+	// value in every cache file
+	headerSize = 8
+
+	// tempFileMaxAge is how old a temporary file must be before the garbage
+	// collector treats it as left behind by an interrupted write
+	tempFileMaxAge = time.Hour
+)
+
+var _ cache.Store = (*Store)(nil)
+
+// Store is a cache store persisting items as files on disk
+type Store struct {
+	dir string
+
+	// mu orders renaming new files into place with removing expired ones,
+	// so a file just written is never mistaken for the expired one it
+	// replaced
+	mu sync.Mutex
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// Config holds the configuration for the file store
+type Config struct {
+	// Directory is where cache files are written. It is created if missing.
+	// Flush and the garbage collector only touch files laid out as the
+	// store writes them, but a directory dedicated to the store is still
+	// recommended.
+	Directory string
+
+	// CleanupInterval is how often expired files are garbage collected in
+	// the background, defaulting to ten minutes
+	CleanupInterval time.Duration
+}
+
+// New creates a new file store and starts its background garbage collector
+func New(cfg Config) (*Store, error) {
+	if cfg.Directory == "" {
+		return nil, errors.New("cache directory must be set")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	interval := cfg.CleanupInterval
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+
+	s := &Store{
+		dir:  cfg.Directory,
+		stop: make(chan struct{}),
+	}
+	go s.collectGarbage(interval)
+
+	return s, nil
+}
+
+// path returns the file holding key. Keys are hashed so any string is a
+// valid key, and sharded into two directory levels to keep directories small.
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[0:2], name[2:4], name)
+}
+
+// owned reports whether path, within the store directory, is a cache file
+// or a temporary file written by the store, as laid out by path
+func (s *Store) owned(path string) bool {
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 3 || !isHex(parts[0], 2) || !isHex(parts[1], 2) {
+		return false
+	}
+	name := parts[2]
+	if filepath.Ext(name) == ".tmp" {
+		return true
+	}
+	return isHex(name, sha256.Size*2) && name[0:2] == parts[0] && name[2:4] == parts[1]
+}
+
+// shard reports whether path is one of the two levels of directories the
+// store shards its files into
+func (s *Store) shard(path string) bool {
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) > 2 {
+		return false
+	}
+	for _, part := range parts {
+		if !isHex(part, 2) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHex reports whether name is n lowercase hexadecimal digits
+func isHex(name string, n int) bool {
+	if len(name) != n {
+		return false
+	}
+	for _, r := range name {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// read returns the value stored in a cache file and whether it has expired
+func read(path string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) < headerSize {
+		return "", false, fmt.Errorf("corrupt cache file %s", path)
+	}
+	return string(data[headerSize:]), expired(data[:headerSize]), nil
+}
+
+// expired reports whether the expiration header of a cache file has passed
+func expired(header []byte) bool {
+	expiresAt := int64(binary.BigEndian.Uint64(header))
+	return expiresAt != 0 && time.Now().UnixNano() >= expiresAt
+}
+
+// removeExpired removes the cache file at path if it holds an expired item.
+// The header is read again under the lock Put renames files into place
+// with, so a file written since the caller found it expired is kept.
+func (s *Store) removeExpired(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var header [headerSize]byte
+	_, err = io.ReadFull(f, header[:])
+	f.Close()
+	if err != nil || !expired(header[:]) {
+		return nil
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// collectGarbage periodically removes expired files until the store is closed
+func (s *Store) collectGarbage(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.deleteExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deleteExpired removes every expired cache file, and temporary files left
+// behind by writes interrupted over tempFileMaxAge ago. Only the header of
+// each cache file is read.
+func (s *Store) deleteExpired() error {
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !s.owned(path) {
+			return nil
+		}
+
+		if filepath.Ext(path) == ".tmp" {
+			if info, err := d.Info(); err == nil && time.Since(info.ModTime()) > tempFileMaxAge {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+		_ = s.removeExpired(path)
+		return nil
+	})
+}
+
+// Get retrieves an item from the cache by key
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	path := s.path(key)
+
+	value, expired, err := read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if expired {
+		_ = s.removeExpired(path)
+		return "", ErrKeyNotFound
+	}
+	return value, nil
+}
+
+// Has checks if an item exists in the cache
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remember gets an item from the cache, or stores the result of the callback
+func (s *Store) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	// First, try to get the existing item
+	value, err := s.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+
+	// If callback is nil, return error
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result to JSON string
+	jsonValue, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	// Store the result in cache
+	if err := s.Put(ctx, key, string(jsonValue), ttl); err != nil {
+		return "", err
+	}
+
+	return string(jsonValue), nil
+}
+
+// Pull retrieves and deletes an item from the cache
+func (s *Store) Pull(ctx context.Context, key string) (string, error) {
+	// Get the value first
+	value, err := s.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Then delete it
+	if err := s.Forget(ctx, key); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Put stores an item in the cache for a given duration. A non-positive ttl
+// stores the item permanently.
+func (s *Store) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	data := make([]byte, headerSize+len(value))
+	binary.BigEndian.PutUint64(data[:headerSize], uint64(expiresAt))
+	copy(data[headerSize:], value)
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial item
+	tmp, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Forever stores an item in the cache permanently
+func (s *Store) Forever(ctx context.Context, key, value string) error {
+	return s.Put(ctx, key, value, 0)
+}
+
+// Forget removes an item from the cache
+func (s *Store) Forget(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Flush removes all items from the cache. Files in the directory that the
+// store did not write are left alone, as are the directories holding them.
+func (s *Store) Flush(ctx context.Context) error {
+	var dirs []string
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if s.shard(path) {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if !s.owned(path) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the shard directories left empty, deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			_ = os.Remove(dirs[i])
+		}
+	}
+	return nil
+}
+
+// Close stops the background garbage collector
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
@@ -0,0 +1,306 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// setupTestStore creates a file store in a temporary directory
+func setupTestStore(t *testing.T) *Store {
+	s, err := New(Config{Directory: t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// countFiles returns the number of regular files under dir
+func countFiles(t *testing.T, dir string) int {
+	count := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return count
+}
+
+func TestNew(t *testing.T) {
+	t.Run("creates missing directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "nested", "cache")
+
+		s, err := New(Config{Directory: dir})
+		require.NoError(t, err)
+		defer s.Close()
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	})
+
+	t.Run("missing directory setting", func(t *testing.T) {
+		_, err := New(Config{})
+		assert.Error(t, err)
+	})
+}
+
+func TestStore_PutGet(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("get existing key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+		val, err := s.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-value", val)
+	})
+
+	t.Run("keys with path characters", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "../../etc/passwd", "safe", time.Hour))
+
+		val, err := s.Get(ctx, "../../etc/passwd")
+		assert.NoError(t, err)
+		assert.Equal(t, "safe", val)
+		assert.True(t, strings.HasPrefix(s.path("../../etc/passwd"), s.dir))
+	})
+
+	t.Run("overwrite existing key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "test-key", "initial-value", time.Hour))
+		require.NoError(t, s.Put(ctx, "test-key", "new-value", time.Hour))
+
+		val, err := s.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "new-value", val)
+	})
+
+	t.Run("get non-existent key", func(t *testing.T) {
+		val, err := s.Get(ctx, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("get expired key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "expired-key", "test-value", time.Millisecond*10))
+
+		time.Sleep(time.Millisecond * 20)
+
+		_, err := s.Get(ctx, "expired-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		_, err = os.Stat(s.path("expired-key"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("survives reopening", func(t *testing.T) {
+		require.NoError(t, s.Forever(ctx, "persistent", "value"))
+
+		reopened, err := New(Config{Directory: s.dir})
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		val, err := reopened.Get(ctx, "persistent")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+}
+
+func TestStore_Has(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	exists, err := s.Has(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.Has(ctx, "non-existent-key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStore_Remember(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("remember new value", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		var result testStruct
+		require.NoError(t, json.Unmarshal([]byte(val), &result))
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+
+		_, err = s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := s.Remember(ctx, "nil-callback", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
+func TestStore_Pull(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	val, err := s.Pull(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-value", val)
+
+	_, err = s.Pull(ctx, "test-key")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestStore_ForgetFlush(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Forever(ctx, "forever", "value"))
+	require.NoError(t, s.Forget(ctx, "forever"))
+	exists, _ := s.Has(ctx, "forever")
+	assert.False(t, exists)
+
+	// Forgetting a missing key succeeds
+	assert.NoError(t, s.Forget(ctx, "forever"))
+
+	require.NoError(t, s.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, s.Forever(ctx, "key2", "value2"))
+	require.NoError(t, s.Flush(ctx))
+
+	assert.Equal(t, 0, countFiles(t, s.dir))
+	_, err := os.Stat(s.dir)
+	assert.NoError(t, err)
+}
+
+func TestStore_FlushSharedDirectory(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	// Files the store did not write, some of them looking like its own
+	unrelated := []string{
+		filepath.Join(s.dir, "notes.txt"),
+		filepath.Join(s.dir, "docs", "readme.md"),
+		filepath.Join(s.dir, "ab", "cd", "backup.bin"),
+		filepath.Join(s.dir, "ab", "cd", strings.Repeat("0", 64)),
+	}
+	for _, path := range unrelated {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("keep me"), 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(s.dir, "empty"), 0o755))
+
+	require.NoError(t, s.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, s.Forever(ctx, "key2", "value2"))
+	require.NoError(t, s.Flush(ctx))
+
+	exists, err := s.Has(ctx, "key1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, len(unrelated), countFiles(t, s.dir))
+	for _, path := range unrelated {
+		assert.FileExists(t, path)
+	}
+	assert.DirExists(t, filepath.Join(s.dir, "empty"))
+
+	// The shard directories emptied by the flush are removed
+	_, err = os.Stat(filepath.Dir(s.path("key1")))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestStore_GarbageCollection(t *testing.T) {
+	s, err := New(Config{Directory: t.TempDir(), CleanupInterval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// A file the store did not write, whose first bytes read as a long past
+	// expiration
+	unrelated := filepath.Join(s.dir, "data.bin")
+	require.NoError(t, os.WriteFile(unrelated, []byte{0, 0, 0, 0, 0, 0, 0, 1, 42}, 0o644))
+
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "short", "value", time.Millisecond))
+	require.NoError(t, s.Put(ctx, "long", "value", time.Hour))
+
+	assert.Eventually(t, func() bool {
+		return countFiles(t, s.dir) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.FileExists(t, unrelated)
+
+	_, err = s.Get(ctx, "long")
+	assert.NoError(t, err)
+}
+
+func TestStore_CollectTempFiles(t *testing.T) {
+	s, err := New(Config{Directory: t.TempDir(), CleanupInterval: time.Hour})
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "key", "value", time.Hour))
+	shard := filepath.Dir(s.path("key"))
+
+	// Temporary files of interrupted writes are collected once old enough
+	stale := filepath.Join(shard, "123.tmp")
+	require.NoError(t, os.WriteFile(stale, []byte("partial"), 0o644))
+	old := time.Now().Add(-2 * tempFileMaxAge)
+	require.NoError(t, os.Chtimes(stale, old, old))
+	recent := filepath.Join(shard, "456.tmp")
+	require.NoError(t, os.WriteFile(recent, []byte("partial"), 0o644))
+
+	require.NoError(t, s.deleteExpired())
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, s.path("key"))
+}
+
+func TestStore_RemoveExpiredRechecks(t *testing.T) {
+	s, err := New(Config{Directory: t.TempDir(), CleanupInterval: time.Hour})
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "key", "old", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, expired, err := read(s.path("key"))
+	require.NoError(t, err)
+	require.True(t, expired)
+
+	// The item is rewritten after being found expired, and survives
+	require.NoError(t, s.Put(ctx, "key", "new", time.Hour))
+	require.NoError(t, s.removeExpired(s.path("key")))
+
+	value, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "new", value)
+}
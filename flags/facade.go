@@ -0,0 +1,52 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNoDefaultStore = errors.New("no default flag store configured, call flags.SetDefault first")
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore *Store
+)
+
+// SetDefault sets the store used by the package-level flag functions
+func SetDefault(store *Store) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultStore = store
+}
+
+// Default returns the store used by the package-level flag functions
+func Default() (*Store, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	if defaultStore == nil {
+		return nil, ErrNoDefaultStore
+	}
+	return defaultStore, nil
+}
+
+// Enabled reports whether the named flag of the default store is on for user
+func Enabled(ctx context.Context, name, user string) (bool, error) {
+	store, err := Default()
+	if err != nil {
+		return false, err
+	}
+	return store.Enabled(ctx, name, user)
+}
+
+// GetVariant returns the variant of the named flag of the default store user
+// is assigned
+func GetVariant(ctx context.Context, name, user string) (string, error) {
+	store, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return store.Variant(ctx, name, user)
+}
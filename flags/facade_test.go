@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacade(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	ctx := context.Background()
+
+	SetDefault(nil)
+	_, err := Enabled(ctx, "new-checkout", "1")
+	assert.Equal(t, ErrNoDefaultStore, err)
+	_, err = GetVariant(ctx, "new-checkout", "1")
+	assert.Equal(t, ErrNoDefaultStore, err)
+
+	store, _ := setupStore(t)
+	SetDefault(store)
+	require.NoError(t, store.Set(ctx, Flag{
+		Name:     "new-checkout",
+		Enabled:  true,
+		Variants: []Variant{{Name: "one-page", Weight: 1}},
+	}))
+
+	on, err := Enabled(ctx, "new-checkout", "1")
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	variant, err := GetVariant(ctx, "new-checkout", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "one-page", variant)
+}
@@ -0,0 +1,193 @@
+// Package flags stores feature flags in a cache store. Backed by a
+// redis.TieredStore, flags are read from process memory and every instance
+// picks up changes as soon as they are announced over pub/sub.
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+// defaultPrefix namespaces flags when Config does not set a prefix
+const defaultPrefix = "flags:"
+
+var (
+	// ErrFlagNotFound is returned when reading a flag that was never set
+	ErrFlagNotFound = errors.New("flag not found")
+
+	// ErrInvalidFlag is returned when setting a flag with an out of range
+	// percentage or variant weight
+	ErrInvalidFlag = errors.New("invalid flag")
+)
+
+// Flag is a feature flag. A disabled flag is off for every user. An enabled
+// flag is on for the given Percentage of users, or for everyone when
+// Percentage is nil, so a rollout at zero percent is off for everyone; users
+// keep the same answer as long as the flag is unchanged. Variants split the
+// users the flag is on for between named variants, in proportion to their
+// weights.
+type Flag struct {
+	Name       string    `json:"name"`
+	Enabled    bool      `json:"enabled"`
+	Percentage *int      `json:"percentage,omitempty"`
+	Variants   []Variant `json:"variants,omitempty"`
+}
+
+// Percent returns a Percentage rolling a flag out to n percent of users
+func Percent(n int) *int {
+	return &n
+}
+
+// Variant is one arm of a multivariate flag
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// validate checks the flag's percentage and variant weights
+func (f Flag) validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidFlag)
+	}
+	if f.Percentage != nil && (*f.Percentage < 0 || *f.Percentage > 100) {
+		return fmt.Errorf("%w: percentage %d is not between 0 and 100", ErrInvalidFlag, *f.Percentage)
+	}
+	for _, variant := range f.Variants {
+		if variant.Weight <= 0 {
+			return fmt.Errorf("%w: variant %q has weight %d", ErrInvalidFlag, variant.Name, variant.Weight)
+		}
+	}
+	return nil
+}
+
+// bucket maps user to a stable number in [0, n) for this flag, so the same
+// user lands in the same bucket across instances
+func (f Flag) bucket(user, salt string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(f.Name + ":" + salt + ":" + user))
+	return int(h.Sum32() % uint32(n))
+}
+
+// on reports whether the flag is on for user
+func (f Flag) on(user string) bool {
+	if !f.Enabled {
+		return false
+	}
+	return f.Percentage == nil || f.bucket(user, "rollout", 100) < *f.Percentage
+}
+
+// variant returns the variant user is assigned, or an empty string when the
+// flag is off for them or has no variants
+func (f Flag) variant(user string) string {
+	if !f.on(user) || len(f.Variants) == 0 {
+		return ""
+	}
+
+	total := 0
+	for _, variant := range f.Variants {
+		total += variant.Weight
+	}
+	n := f.bucket(user, "variant", total)
+	for _, variant := range f.Variants {
+		if n < variant.Weight {
+			return variant.Name
+		}
+		n -= variant.Weight
+	}
+	return ""
+}
+
+// Config configures a Store
+type Config struct {
+	// Prefix namespaces the flags within the cache store. Defaults to
+	// "flags:".
+	Prefix string
+}
+
+// Store reads and writes feature flags
+type Store struct {
+	store  cache.Store
+	prefix string
+}
+
+// New returns a flag store keeping its flags in store. Pass a
+// redis.TieredStore to serve flags from process memory with changes
+// propagated to every instance. Closing store is left to the caller.
+func New(store cache.Store, cfg Config) *Store {
+	s := &Store{store: store, prefix: cfg.Prefix}
+	if s.prefix == "" {
+		s.prefix = defaultPrefix
+	}
+	return s
+}
+
+// Set stores flag, replacing any flag with the same name
+func (s *Store) Set(ctx context.Context, flag Flag) error {
+	if err := flag.validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	return s.store.Forever(ctx, s.key(flag.Name), string(data))
+}
+
+// Get retrieves the flag with the given name
+func (s *Store) Get(ctx context.Context, name string) (Flag, error) {
+	var flag Flag
+	value, err := s.store.Get(ctx, s.key(name))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return flag, ErrFlagNotFound
+	}
+	if err != nil {
+		return flag, err
+	}
+	if err := json.Unmarshal([]byte(value), &flag); err != nil {
+		return flag, &cache.DecodeError{Key: name, Err: err}
+	}
+	return flag, nil
+}
+
+// Delete removes the flag with the given name, turning it off for everyone
+func (s *Store) Delete(ctx context.Context, name string) error {
+	return s.store.Forget(ctx, s.key(name))
+}
+
+// Enabled reports whether the named flag is on for user, an identifier such
+// as a user or account ID. Flags that were never set are off.
+func (s *Store) Enabled(ctx context.Context, name, user string) (bool, error) {
+	flag, err := s.Get(ctx, name)
+	if errors.Is(err, ErrFlagNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return flag.on(user), nil
+}
+
+// Variant returns the variant of the named flag user is assigned, or an
+// empty string when the flag is off for them, has no variants or was never
+// set
+func (s *Store) Variant(ctx context.Context, name, user string) (string, error) {
+	flag, err := s.Get(ctx, name)
+	if errors.Is(err, ErrFlagNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return flag.variant(user), nil
+}
+
+// key returns the cache key the named flag is stored under
+func (s *Store) key(name string) string {
+	return s.prefix + name
+}
@@ -0,0 +1,217 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/nanaaikinson/gofacades/memory"
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// setupStore creates a flag store kept in memory
+func setupStore(t *testing.T) (*Store, *memory.Store) {
+	backend := memory.New(memory.Config{})
+	t.Cleanup(func() { backend.Close() })
+	return New(backend, Config{}), backend
+}
+
+// setupTiered creates a tiered Redis store on mr, closed with the test
+func setupTiered(t *testing.T, mr *miniredis.Miniredis) *redis.TieredStore {
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+
+	tiered, err := client.Tiered(context.Background(), redis.TieredConfig{L1TTL: time.Hour})
+	require.NoError(t, err)
+	t.Cleanup(func() { tiered.Close() })
+	return tiered
+}
+
+func TestStore_SetGet(t *testing.T) {
+	ctx := context.Background()
+	store, backend := setupStore(t)
+
+	flag := Flag{Name: "new-checkout", Enabled: true, Percentage: Percent(25)}
+	require.NoError(t, store.Set(ctx, flag))
+
+	got, err := store.Get(ctx, "new-checkout")
+	require.NoError(t, err)
+	assert.Equal(t, flag, got)
+
+	exists, err := backend.Has(ctx, "flags:new-checkout")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, store.Delete(ctx, "new-checkout"))
+	_, err = store.Get(ctx, "new-checkout")
+	assert.ErrorIs(t, err, ErrFlagNotFound)
+}
+
+func TestStore_SetInvalid(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setupStore(t)
+
+	for _, flag := range []Flag{
+		{Enabled: true},
+		{Name: "flag", Percentage: Percent(-1)},
+		{Name: "flag", Percentage: Percent(101)},
+		{Name: "flag", Variants: []Variant{{Name: "a", Weight: 0}}},
+	} {
+		assert.ErrorIs(t, store.Set(ctx, flag), ErrInvalidFlag, "%+v", flag)
+	}
+}
+
+func TestStore_GetCorrupt(t *testing.T) {
+	ctx := context.Background()
+	store, backend := setupStore(t)
+
+	require.NoError(t, backend.Forever(ctx, "flags:broken", "not json"))
+	_, err := store.Get(ctx, "broken")
+	var decodeErr *cache.DecodeError
+	assert.ErrorAs(t, err, &decodeErr)
+}
+
+func TestStore_Enabled(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("boolean flags", func(t *testing.T) {
+		store, _ := setupStore(t)
+
+		require.NoError(t, store.Set(ctx, Flag{Name: "on", Enabled: true}))
+		require.NoError(t, store.Set(ctx, Flag{Name: "off", Enabled: false, Percentage: Percent(100)}))
+
+		for _, user := range []string{"", "1", "2"} {
+			on, err := store.Enabled(ctx, "on", user)
+			require.NoError(t, err)
+			assert.True(t, on)
+
+			on, err = store.Enabled(ctx, "off", user)
+			require.NoError(t, err)
+			assert.False(t, on)
+		}
+
+		on, err := store.Enabled(ctx, "missing", "1")
+		require.NoError(t, err)
+		assert.False(t, on)
+	})
+
+	t.Run("zero percent is off for everyone", func(t *testing.T) {
+		store, _ := setupStore(t)
+		require.NoError(t, store.Set(ctx, Flag{Name: "rollout", Enabled: true, Percentage: Percent(0)}))
+
+		for i := 0; i < 100; i++ {
+			on, err := store.Enabled(ctx, "rollout", strconv.Itoa(i))
+			require.NoError(t, err)
+			assert.False(t, on)
+		}
+
+		flag, err := store.Get(ctx, "rollout")
+		require.NoError(t, err)
+		assert.Equal(t, Percent(0), flag.Percentage)
+	})
+
+	t.Run("percentage rollout", func(t *testing.T) {
+		store, _ := setupStore(t)
+		require.NoError(t, store.Set(ctx, Flag{Name: "rollout", Enabled: true, Percentage: Percent(30)}))
+
+		enabled := 0
+		for i := 0; i < 1000; i++ {
+			user := fmt.Sprintf("user-%d", i)
+			on, err := store.Enabled(ctx, "rollout", user)
+			require.NoError(t, err)
+			if on {
+				enabled++
+			}
+
+			// Users keep their answer
+			again, err := store.Enabled(ctx, "rollout", user)
+			require.NoError(t, err)
+			assert.Equal(t, on, again)
+		}
+		assert.InDelta(t, 300, enabled, 60)
+	})
+
+	t.Run("raising the percentage keeps enabled users", func(t *testing.T) {
+		store, _ := setupStore(t)
+
+		require.NoError(t, store.Set(ctx, Flag{Name: "rollout", Enabled: true, Percentage: Percent(10)}))
+		var before []string
+		for i := 0; i < 200; i++ {
+			user := strconv.Itoa(i)
+			if on, _ := store.Enabled(ctx, "rollout", user); on {
+				before = append(before, user)
+			}
+		}
+
+		require.NoError(t, store.Set(ctx, Flag{Name: "rollout", Enabled: true, Percentage: Percent(50)}))
+		for _, user := range before {
+			on, err := store.Enabled(ctx, "rollout", user)
+			require.NoError(t, err)
+			assert.True(t, on, user)
+		}
+	})
+}
+
+func TestStore_Variant(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setupStore(t)
+
+	require.NoError(t, store.Set(ctx, Flag{
+		Name:     "button-color",
+		Enabled:  true,
+		Variants: []Variant{{Name: "blue", Weight: 3}, {Name: "green", Weight: 1}},
+	}))
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		variant, err := store.Variant(ctx, "button-color", user)
+		require.NoError(t, err)
+		counts[variant]++
+
+		again, err := store.Variant(ctx, "button-color", user)
+		require.NoError(t, err)
+		assert.Equal(t, variant, again)
+	}
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 750, counts["blue"], 75)
+
+	require.NoError(t, store.Set(ctx, Flag{Name: "plain", Enabled: true}))
+	variant, err := store.Variant(ctx, "plain", "1")
+	require.NoError(t, err)
+	assert.Empty(t, variant)
+
+	variant, err = store.Variant(ctx, "missing", "1")
+	require.NoError(t, err)
+	assert.Empty(t, variant)
+}
+
+func TestStore_Tiered(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	admin := New(setupTiered(t, mr), Config{})
+	app := New(setupTiered(t, mr), Config{})
+
+	require.NoError(t, admin.Set(ctx, Flag{Name: "new-checkout", Enabled: true}))
+	on, err := app.Enabled(ctx, "new-checkout", "1")
+	require.NoError(t, err)
+	assert.True(t, on)
+	assert.True(t, mr.Exists("app:flags:new-checkout"))
+
+	// The app serves the flag from memory until the change is announced
+	require.NoError(t, admin.Set(ctx, Flag{Name: "new-checkout", Enabled: false}))
+	assert.Eventually(t, func() bool {
+		on, err := app.Enabled(ctx, "new-checkout", "1")
+		return err == nil && !on
+	}, time.Second, 10*time.Millisecond)
+}
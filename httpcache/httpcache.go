@@ -0,0 +1,206 @@
+// Package httpcache caches whole HTTP responses in a cache store: pages
+// served by HTTP middleware, and the responses of upstream APIs fetched
+// through a caching http.RoundTripper
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hopHeaders are the headers describing a connection rather than the
+// response, which are not cached
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// entry is a cached response, with when it was stored (ms). Responses cached
+// by a Transport also record until when they are fresh and may be served
+// stale (ms).
+type entry struct {
+	Status     int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   int64       `json:"stored_at"`
+	FreshUntil int64       `json:"fresh_until,omitempty"`
+	StaleUntil int64       `json:"stale_until,omitempty"`
+}
+
+// variants is what is cached under a URL: the request headers its
+// responses vary on, which select the entry
+type variants struct {
+	Vary []string `json:"vary,omitempty"`
+}
+
+// cacheControl is a parsed Cache-Control header
+type cacheControl map[string]string
+
+// parseCacheControl parses the Cache-Control directives of header, with
+// lower-cased names
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				cc[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return cc
+}
+
+// has reports whether the directive is present
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// duration returns the number of seconds given to the directive, and
+// whether it was given a valid one
+func (cc cacheControl) duration(name string) (time.Duration, bool) {
+	arg, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(arg)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// cacheable reports whether responses with the given status may be cached
+// without explicit freshness information
+func cacheable(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// varyHeaders returns the canonical names of the request headers a
+// response varies on, merged with extra, sorted, and whether it varies on
+// "*", meaning it cannot be cached
+func varyHeaders(header http.Header, extra []string) ([]string, bool) {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.TrimSpace(name) == "*" {
+				return nil, true
+			}
+			add(name)
+		}
+	}
+	for _, name := range extra {
+		add(name)
+	}
+	sort.Strings(names)
+	return names, false
+}
+
+// urlKey returns the cache key of the variants of the request's URL
+func urlKey(prefix string, r *http.Request) string {
+	// Outbound requests usually only carry their host in the URL
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	return prefix + digest(r.Method+" "+host+r.URL.RequestURI())
+}
+
+// variantKey returns the cache key of the entry of the request's URL for
+// the values of the request headers named by vary
+func variantKey(prefix string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	for _, name := range vary {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+		b.WriteByte('\n')
+	}
+	return urlKey(prefix, r) + ":" + digest(b.String())
+}
+
+// digest returns a hex SHA-256 digest of s
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// etag returns a strong entity tag of body
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// matchesETag reports whether an If-None-Match header value matches tag,
+// using the weak comparison
+func matchesETag(ifNoneMatch, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneHeader copies header without the hop-by-hop headers
+func cloneHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	if clone == nil {
+		clone = http.Header{}
+	}
+	for _, name := range hopHeaders {
+		clone.Del(name)
+	}
+	return clone
+}
+
+// encode encodes a value cached by this package
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// age returns the value of the Age header of e served at now
+func age(now time.Time, e *entry) string {
+	seconds := (now.UnixMilli() - e.StoredAt) / 1000
+	if seconds < 0 {
+		seconds = 0
+	}
+	return strconv.FormatInt(seconds, 10)
+}
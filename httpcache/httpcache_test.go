@@ -0,0 +1,69 @@
+package httpcache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	header := http.Header{}
+	header.Add("Cache-Control", `public, Max-Age=60`)
+	header.Add("Cache-Control", `s-maxage="120", no-transform, max-stale=x`)
+	cc := parseCacheControl(header)
+
+	assert.True(t, cc.has("public"))
+	assert.True(t, cc.has("no-transform"))
+	assert.False(t, cc.has("private"))
+
+	ttl, ok := cc.duration("max-age")
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, ttl)
+	ttl, ok = cc.duration("s-maxage")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, ttl)
+	_, ok = cc.duration("max-stale")
+	assert.False(t, ok)
+	_, ok = cc.duration("min-fresh")
+	assert.False(t, ok)
+}
+
+func TestVaryHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Add("Vary", "accept-encoding, Accept-Language")
+	header.Add("Vary", "Accept-Encoding")
+
+	names, star := varyHeaders(header, []string{"X-Tenant", "accept-language"})
+	assert.False(t, star)
+	assert.Equal(t, []string{"Accept-Encoding", "Accept-Language", "X-Tenant"}, names)
+
+	header.Set("Vary", "Accept, *")
+	_, star = varyHeaders(header, nil)
+	assert.True(t, star)
+}
+
+func TestVariantKey(t *testing.T) {
+	english, _ := http.NewRequest(http.MethodGet, "https://example.com/posts?page=2", nil)
+	english.Header.Set("Accept-Language", "en")
+	french, _ := http.NewRequest(http.MethodGet, "https://example.com/posts?page=2", nil)
+	french.Header.Set("Accept-Language", "fr")
+	other, _ := http.NewRequest(http.MethodGet, "https://example.com/posts?page=3", nil)
+
+	assert.Equal(t, urlKey("c:", english), urlKey("c:", french))
+	assert.NotEqual(t, urlKey("c:", english), urlKey("c:", other))
+
+	vary := []string{"Accept-Language"}
+	assert.NotEqual(t, variantKey("c:", english, vary), variantKey("c:", french, vary))
+	assert.Equal(t, variantKey("c:", english, nil), variantKey("c:", french, nil))
+}
+
+func TestMatchesETag(t *testing.T) {
+	assert.True(t, matchesETag(`"abc"`, `"abc"`))
+	assert.True(t, matchesETag(`"xyz", W/"abc"`, `"abc"`))
+	assert.True(t, matchesETag(`"abc"`, `W/"abc"`))
+	assert.True(t, matchesETag(`*`, `"abc"`))
+	assert.False(t, matchesETag(`"xyz"`, `"abc"`))
+	assert.False(t, matchesETag(`"abc"`, ""))
+}
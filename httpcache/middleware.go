@@ -0,0 +1,267 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+const (
+	// defaultPrefix namespaces cached responses when Config does not set a
+	// prefix
+	defaultPrefix = "httpcache:"
+
+	// defaultTTL is how long responses without freshness information are
+	// cached when Config does not set a TTL
+	defaultTTL = time.Minute
+
+	// defaultMaxBodySize bounds the size of the cached bodies when Config
+	// does not set a maximum
+	defaultMaxBodySize = 1 << 20
+
+	// statusHeader reports whether a response was served from the cache
+	statusHeader = "X-Cache"
+)
+
+// Rule sets how long the responses to requests whose path starts with
+// PathPrefix are cached. A TTL of zero or less keeps them from being cached.
+type Rule struct {
+	PathPrefix string
+	TTL        time.Duration
+}
+
+// Config configures a Cache
+type Config struct {
+	// Prefix namespaces the responses within the cache store. Defaults to
+	// "httpcache:".
+	Prefix string
+
+	// TTL is how long responses are cached when neither their
+	// Cache-Control header nor a rule says otherwise. Defaults to one
+	// minute.
+	TTL time.Duration
+
+	// Rules override the TTL for some paths, the first matching rule
+	// applying
+	Rules []Rule
+
+	// Vary names request headers responses are always cached separately
+	// for, in addition to those named by their Vary header
+	Vary []string
+
+	// MaxBodySize bounds the size of the cached bodies, larger responses
+	// being served without being cached. Defaults to 1MiB.
+	MaxBodySize int
+
+	// CacheCookies caches the responses to requests carrying cookies, which
+	// otherwise skip the cache as cookie sessions make them personal. Only
+	// enable it when responses do not depend on the cookies, or are varied
+	// on them.
+	CacheCookies bool
+}
+
+// Cache caches HTTP responses in a cache store
+type Cache struct {
+	store cache.Store
+	cfg   Config
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// New returns a cache keeping its responses in store. Closing store is left
+// to the caller.
+func New(store cache.Store, cfg Config) *Cache {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBodySize
+	}
+	return &Cache{store: store, cfg: cfg, now: time.Now}
+}
+
+// Purge removes the cached responses to GET requests for url, such as
+// "https://example.com/posts?page=2"
+func (c *Cache) Purge(ctx context.Context, url string) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return c.store.Forget(ctx, urlKey(c.cfg.Prefix, r))
+}
+
+// recorder is a ResponseWriter buffering the response
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(p)
+}
+
+// Middleware returns HTTP middleware caching the responses to GET requests,
+// keyed by URL and by the request headers the responses vary on. Responses
+// are cached for their Cache-Control s-maxage or max-age when set, or for
+// the TTL of the first matching rule or of the config, and are given an ETag
+// unless they have one, so that conditional requests get 304 Not Modified.
+// Responses marked no-store, private or no-cache or setting cookies are not
+// cached. Requests marked no-cache or no-store skip the cache, as do
+// requests with credentials: an Authorization header or, unless
+// CacheCookies is set, cookies. Served responses carry an X-Cache
+// header of HIT or MISS. Responses are buffered, so streaming handlers
+// should not be wrapped.
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" ||
+			(!c.cfg.CacheCookies && r.Header.Get("Cookie") != "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		reqCC := parseCacheControl(r.Header)
+		if reqCC.has("no-store") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !reqCC.has("no-cache") {
+			if e, ok := c.lookup(r); ok {
+				c.serve(w, r, e, "HIT")
+				return
+			}
+		}
+
+		rec := &recorder{header: make(http.Header)}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		e := &entry{
+			Status:   rec.status,
+			Header:   cloneHeader(rec.header),
+			Body:     rec.body.Bytes(),
+			StoredAt: c.now().UnixMilli(),
+		}
+		if ttl, ok := c.ttl(r, e); ok {
+			if e.Header.Get("ETag") == "" {
+				e.Header.Set("ETag", etag(e.Body))
+			}
+			// A failure to cache the response does not affect serving it
+			_ = c.save(r, e, ttl)
+		}
+		c.serve(w, r, e, "MISS")
+	})
+}
+
+// lookup returns the cached response to r
+func (c *Cache) lookup(r *http.Request) (*entry, bool) {
+	data, err := c.store.Get(r.Context(), urlKey(c.cfg.Prefix, r))
+	if err != nil {
+		return nil, false
+	}
+	var v variants
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return nil, false
+	}
+
+	data, err = c.store.Get(r.Context(), variantKey(c.cfg.Prefix, r, v.Vary))
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
+// save caches e as the response to r for ttl
+func (c *Cache) save(r *http.Request, e *entry, ttl time.Duration) error {
+	vary, _ := varyHeaders(e.Header, c.cfg.Vary)
+	data, err := encode(variants{Vary: vary})
+	if err != nil {
+		return err
+	}
+	if err := c.store.Put(r.Context(), urlKey(c.cfg.Prefix, r), data, ttl); err != nil {
+		return err
+	}
+
+	data, err = encode(e)
+	if err != nil {
+		return err
+	}
+	return c.store.Put(r.Context(), variantKey(c.cfg.Prefix, r, vary), data, ttl)
+}
+
+// ttl returns how long e may be cached as the response to r, and whether
+// it may be at all
+func (c *Cache) ttl(r *http.Request, e *entry) (time.Duration, bool) {
+	if !cacheable(e.Status) || len(e.Body) > c.cfg.MaxBodySize || len(e.Header.Values("Set-Cookie")) > 0 {
+		return 0, false
+	}
+	if _, star := varyHeaders(e.Header, nil); star {
+		return 0, false
+	}
+
+	cc := parseCacheControl(e.Header)
+	if cc.has("no-store") || cc.has("private") || cc.has("no-cache") {
+		return 0, false
+	}
+	if ttl, ok := cc.duration("s-maxage"); ok {
+		return ttl, ttl > 0
+	}
+	if ttl, ok := cc.duration("max-age"); ok {
+		return ttl, ttl > 0
+	}
+
+	for _, rule := range c.cfg.Rules {
+		if strings.HasPrefix(r.URL.Path, rule.PathPrefix) {
+			return rule.TTL, rule.TTL > 0
+		}
+	}
+	return c.cfg.TTL, true
+}
+
+// serve writes e as the response to r, or 304 Not Modified when the
+// request's If-None-Match header matches its ETag
+func (c *Cache) serve(w http.ResponseWriter, r *http.Request, e *entry, status string) {
+	h := w.Header()
+	for name, values := range e.Header {
+		h[name] = values
+	}
+	h.Set(statusHeader, status)
+	if status == "HIT" {
+		h.Set("Age", age(c.now(), e))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" && e.Status == http.StatusOK && matchesETag(inm, e.Header.Get("ETag")) {
+		for _, name := range []string{"Content-Length", "Content-Type"} {
+			h.Del(name)
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(e.Status)
+	w.Write(e.Body)
+}
@@ -0,0 +1,242 @@
+package httpcache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/memory"
+)
+
+// setupCache creates a response cache kept in memory
+func setupCache(t *testing.T, cfg Config) *Cache {
+	backend := memory.New(memory.Config{})
+	t.Cleanup(func() { backend.Close() })
+	return New(backend, cfg)
+}
+
+// counter is a handler counting its calls and answering with the count,
+// with the headers set by header
+type counter struct {
+	calls  atomic.Int32
+	status int
+	header http.Header
+}
+
+func (h *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	for name, values := range h.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+	}
+	fmt.Fprintf(w, "response %d", n)
+}
+
+// get sends a GET request for path through handler, with the given header
+// name and value pairs
+func get(handler http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCache_Middleware(t *testing.T) {
+	t.Run("serves responses from the cache", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		now := time.Unix(1700000000, 0)
+		c.now = func() time.Time { return now }
+		h := &counter{}
+		handler := c.Middleware(h)
+
+		first := get(handler, "/posts")
+		assert.Equal(t, "response 1", first.Body.String())
+		assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+		assert.NotEmpty(t, first.Header().Get("ETag"))
+
+		now = now.Add(5 * time.Second)
+		second := get(handler, "/posts")
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "response 1", second.Body.String())
+		assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+		assert.Equal(t, "5", second.Header().Get("Age"))
+		assert.Equal(t, "text/plain", second.Header().Get("Content-Type"))
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+
+		assert.Equal(t, "response 2", get(handler, "/posts?page=2").Body.String())
+		assert.Equal(t, int32(2), h.calls.Load())
+	})
+
+	t.Run("conditional requests", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		h := &counter{header: http.Header{"Etag": {`"v1"`}}}
+		handler := c.Middleware(h)
+
+		rec := get(handler, "/posts", "If-None-Match", `"v1"`)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		rec = get(handler, "/posts", "If-None-Match", `"v0", W/"v1"`)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Equal(t, `"v1"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+
+		rec = get(handler, "/posts", "If-None-Match", `"v0"`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "response 1", rec.Body.String())
+	})
+
+	t.Run("varies on request headers", func(t *testing.T) {
+		c := setupCache(t, Config{Vary: []string{"X-Tenant"}})
+		h := &counter{header: http.Header{"Vary": {"Accept-Language"}}}
+		handler := c.Middleware(h)
+
+		assert.Equal(t, "response 1", get(handler, "/", "Accept-Language", "en").Body.String())
+		assert.Equal(t, "response 2", get(handler, "/", "Accept-Language", "fr").Body.String())
+		assert.Equal(t, "response 3", get(handler, "/", "Accept-Language", "fr", "X-Tenant", "acme").Body.String())
+		assert.Equal(t, "response 3", get(handler, "/", "Accept-Language", "fr", "X-Tenant", "acme").Body.String())
+		assert.Equal(t, "response 2", get(handler, "/", "Accept-Language", "fr").Body.String())
+	})
+
+	t.Run("respects the response's Cache-Control", func(t *testing.T) {
+		c := setupCache(t, Config{TTL: time.Hour})
+
+		for i, value := range []string{"no-store", "private, max-age=60", "no-cache", "max-age=0"} {
+			h := &counter{header: http.Header{"Cache-Control": {value}}}
+			handler := c.Middleware(h)
+			path := fmt.Sprintf("/%d", i)
+			get(handler, path)
+			rec := get(handler, path)
+			assert.Equal(t, "response 2", rec.Body.String(), value)
+			assert.Equal(t, "MISS", rec.Header().Get("X-Cache"), value)
+		}
+
+		h := &counter{header: http.Header{"Cache-Control": {"public, max-age=3600, s-maxage=1"}}}
+		handler := c.Middleware(h)
+		get(handler, "/short")
+		time.Sleep(1100 * time.Millisecond)
+		assert.Equal(t, "response 2", get(handler, "/short").Body.String())
+	})
+
+	t.Run("respects the request's Cache-Control", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		h := &counter{}
+		handler := c.Middleware(h)
+
+		get(handler, "/")
+		assert.Equal(t, "response 2", get(handler, "/", "Cache-Control", "no-cache").Body.String())
+		// The refreshed response was cached
+		assert.Equal(t, "response 2", get(handler, "/").Body.String())
+		assert.Equal(t, "response 3", get(handler, "/", "Cache-Control", "no-store").Body.String())
+		assert.Equal(t, "response 2", get(handler, "/").Body.String())
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		c := setupCache(t, Config{Rules: []Rule{
+			{PathPrefix: "/admin", TTL: 0},
+			{PathPrefix: "/live", TTL: time.Second},
+		}})
+		h := &counter{}
+		handler := c.Middleware(h)
+
+		get(handler, "/admin/users")
+		assert.Equal(t, "response 2", get(handler, "/admin/users").Body.String())
+
+		get(handler, "/live/scores")
+		assert.Equal(t, "response 3", get(handler, "/live/scores").Body.String())
+		time.Sleep(1100 * time.Millisecond)
+		assert.Equal(t, "response 4", get(handler, "/live/scores").Body.String())
+	})
+
+	t.Run("skips uncacheable requests and responses", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		tests := []struct {
+			name    string
+			handler *counter
+			header  []string
+		}{
+			{"server errors", &counter{status: http.StatusInternalServerError}, nil},
+			{"cookies", &counter{header: http.Header{"Set-Cookie": {"id=1"}}}, nil},
+			{"vary on anything", &counter{header: http.Header{"Vary": {"*"}}}, nil},
+			{"credentials", &counter{}, []string{"Authorization", "Bearer token"}},
+		}
+		for _, tt := range tests {
+			handler := c.Middleware(tt.handler)
+			get(handler, "/", tt.header...)
+			rec := get(handler, "/", tt.header...)
+			assert.Equal(t, int32(2), tt.handler.calls.Load(), tt.name)
+			assert.NotEqual(t, "HIT", rec.Header().Get("X-Cache"), tt.name)
+		}
+
+		h := &counter{}
+		handler := New(c.store, Config{MaxBodySize: 5}).Middleware(h)
+		get(handler, "/large")
+		assert.Equal(t, "MISS", get(handler, "/large").Header().Get("X-Cache"))
+
+		h = &counter{}
+		handler = c.Middleware(h)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body"))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, int32(2), h.calls.Load())
+	})
+
+	t.Run("requests with cookies", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		h := &counter{}
+		handler := c.Middleware(h)
+
+		// A cookie session personalises the response without saying so
+		assert.Equal(t, "response 1", get(handler, "/account", "Cookie", "session=ada").Body.String())
+		assert.Equal(t, "response 2", get(handler, "/account", "Cookie", "session=alan").Body.String())
+		assert.Equal(t, "response 3", get(handler, "/account").Body.String())
+		assert.Equal(t, "response 3", get(handler, "/account").Body.String())
+		assert.Equal(t, "response 4", get(handler, "/account", "Cookie", "session=ada").Body.String())
+
+		// Opting in caches them, here varied on the cookies
+		h = &counter{}
+		handler = setupCache(t, Config{CacheCookies: true, Vary: []string{"Cookie"}}).Middleware(h)
+		assert.Equal(t, "response 1", get(handler, "/account", "Cookie", "session=ada").Body.String())
+		assert.Equal(t, "response 2", get(handler, "/account", "Cookie", "session=alan").Body.String())
+		assert.Equal(t, "response 1", get(handler, "/account", "Cookie", "session=ada").Body.String())
+	})
+
+	t.Run("caches not found responses", func(t *testing.T) {
+		c := setupCache(t, Config{})
+		h := &counter{status: http.StatusNotFound}
+		handler := c.Middleware(h)
+
+		get(handler, "/missing")
+		rec := get(handler, "/missing")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	})
+}
+
+func TestCache_Purge(t *testing.T) {
+	c := setupCache(t, Config{})
+	h := &counter{}
+	handler := c.Middleware(h)
+
+	get(handler, "http://example.com/posts?page=2")
+	assert.Equal(t, "response 1", get(handler, "http://example.com/posts?page=2").Body.String())
+
+	require.NoError(t, c.Purge(context.Background(), "http://example.com/posts?page=2"))
+	assert.Equal(t, "response 2", get(handler, "http://example.com/posts?page=2").Body.String())
+
+	assert.Error(t, c.Purge(context.Background(), "://invalid"))
+}
@@ -0,0 +1,293 @@
+package httpcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+const (
+	// defaultTransportPrefix namespaces the responses cached by a Transport
+	// when TransportConfig does not set a prefix
+	defaultTransportPrefix = "httpcache:client:"
+
+	// revalidateTTL is how long responses with an ETag or Last-Modified
+	// header are kept for revalidation once they are no longer fresh
+	revalidateTTL = 24 * time.Hour
+)
+
+// TransportConfig configures a Transport
+type TransportConfig struct {
+	// Prefix namespaces the responses within the cache store. Defaults to
+	// "httpcache:client:".
+	Prefix string
+
+	// TTL is how long responses without Cache-Control max-age or Expires
+	// headers are fresh. Defaults to zero, not caching them.
+	TTL time.Duration
+
+	// StaleIfError is how long after they are no longer fresh responses
+	// may be served when the upstream fails, unless their stale-if-error
+	// directive says otherwise. Defaults to zero, never serving stale
+	// responses.
+	StaleIfError time.Duration
+
+	// MaxBodySize bounds the size of the cached bodies, larger responses
+	// being passed through without being cached. Defaults to 1MiB.
+	MaxBodySize int
+}
+
+// Transport is an http.RoundTripper caching the responses to GET requests in
+// a cache store. Responses are fresh for their Cache-Control max-age, their
+// Expires header or the configured TTL, and whilst fresh are served without
+// a request upstream. Stale responses with an ETag or Last-Modified header
+// are revalidated with a conditional request. Should the upstream fail, with
+// an error or a 5xx status, stale responses are served within their
+// stale-if-error window. Responses marked no-store are not cached, and no-cache
+// ones are always revalidated. The Authorization and Cookie headers are part
+// of the cache key, so credentials and sessions never share responses.
+type Transport struct {
+	store cache.Store
+	base  http.RoundTripper
+	cfg   TransportConfig
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport returns a transport caching the responses of base, or of
+// http.DefaultTransport when base is nil, in store. Closing store is left to
+// the caller.
+func NewTransport(store cache.Store, base http.RoundTripper, cfg TransportConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultTransportPrefix
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBodySize
+	}
+	return &Transport{store: store, base: base, cfg: cfg, now: time.Now}
+}
+
+// RoundTrip serves req from the cache or sends it upstream
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Conditional requests from the caller are theirs to handle
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.base.RoundTrip(req)
+	}
+	reqCC := parseCacheControl(req.Header)
+	if reqCC.has("no-store") {
+		return t.base.RoundTrip(req)
+	}
+
+	now := t.now()
+	cached, _ := t.lookup(req)
+	if cached != nil && !reqCC.has("no-cache") && now.UnixMilli() < cached.FreshUntil {
+		return t.response(req, cached, "HIT"), nil
+	}
+
+	upstream := req
+	if cached != nil {
+		upstream = conditional(req, cached)
+	}
+	resp, err := t.base.RoundTrip(upstream)
+	switch {
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		if cached != nil && now.UnixMilli() < cached.StaleUntil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return t.response(req, cached, "STALE"), nil
+		}
+		return resp, err
+	case resp.StatusCode == http.StatusNotModified && cached != nil && upstream != req:
+		resp.Body.Close()
+		// The cached response is still valid, with the new headers
+		for name, values := range cloneHeader(resp.Header) {
+			cached.Header[name] = values
+		}
+		cached.StoredAt = now.UnixMilli()
+		if t.freshen(cached, now) {
+			_ = t.save(req, cached)
+		}
+		return t.response(req, cached, "REVALIDATED"), nil
+	}
+
+	return t.keep(req, resp, now)
+}
+
+// keep caches resp when it may be, returning it with its body intact
+func (t *Transport) keep(req *http.Request, resp *http.Response, now time.Time) (*http.Response, error) {
+	if !cacheable(resp.StatusCode) || parseCacheControl(resp.Header).has("no-store") {
+		return resp, nil
+	}
+	if _, star := varyHeaders(resp.Header, nil); star {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.cfg.MaxBodySize)+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > t.cfg.MaxBodySize {
+		// Too large to cache, pass the rest of it through
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	e := &entry{
+		Status:   resp.StatusCode,
+		Header:   cloneHeader(resp.Header),
+		Body:     body,
+		StoredAt: now.UnixMilli(),
+	}
+	if t.freshen(e, now) {
+		_ = t.save(req, e)
+	}
+	resp.Header.Set(statusHeader, "MISS")
+	return resp, nil
+}
+
+// freshen sets until when e is fresh and may be served stale, from its
+// headers and the config, reporting whether it is worth caching
+func (t *Transport) freshen(e *entry, now time.Time) bool {
+	cc := parseCacheControl(e.Header)
+
+	var fresh time.Duration
+	if maxAge, ok := cc.duration("max-age"); ok {
+		fresh = maxAge
+	} else if expires := e.Header.Get("Expires"); expires != "" {
+		// An invalid Expires means already expired
+		if at, err := http.ParseTime(expires); err == nil {
+			date := now
+			if d, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+				date = d
+			}
+			fresh = at.Sub(date)
+		}
+	} else {
+		fresh = t.cfg.TTL
+	}
+	if fresh < 0 || cc.has("no-cache") {
+		fresh = 0
+	}
+
+	stale := t.cfg.StaleIfError
+	if d, ok := cc.duration("stale-if-error"); ok {
+		stale = d
+	}
+
+	e.FreshUntil = now.Add(fresh).UnixMilli()
+	e.StaleUntil = now.Add(fresh + stale).UnixMilli()
+	return fresh > 0 || stale > 0 || revalidatable(e)
+}
+
+// revalidatable reports whether e has a validator for conditional requests
+func revalidatable(e *entry) bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// lookup returns the cached response to req
+func (t *Transport) lookup(req *http.Request) (*entry, error) {
+	data, err := t.store.Get(req.Context(), urlKey(t.cfg.Prefix, req))
+	if err != nil {
+		return nil, err
+	}
+	var v variants
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return nil, err
+	}
+
+	data, err = t.store.Get(req.Context(), variantKey(t.cfg.Prefix, req, v.Vary))
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// save caches e as the response to req until it may no longer be served
+func (t *Transport) save(req *http.Request, e *entry) error {
+	now := t.now()
+	ttl := time.UnixMilli(e.StaleUntil).Sub(now)
+	if revalidatable(e) {
+		if kept := time.UnixMilli(e.FreshUntil).Sub(now) + revalidateTTL; kept > ttl {
+			ttl = kept
+		}
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+
+	vary, _ := varyHeaders(e.Header, []string{"Authorization", "Cookie"})
+	data, err := encode(variants{Vary: vary})
+	if err != nil {
+		return err
+	}
+	if err := t.store.Put(req.Context(), urlKey(t.cfg.Prefix, req), data, ttl); err != nil {
+		return err
+	}
+
+	data, err = encode(e)
+	if err != nil {
+		return err
+	}
+	return t.store.Put(req.Context(), variantKey(t.cfg.Prefix, req, vary), data, ttl)
+}
+
+// response returns e as the response to req
+func (t *Transport) response(req *http.Request, e *entry, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(statusHeader, status)
+	header.Set("Age", age(t.now(), e))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// conditional returns a copy of req revalidating e, or req itself when e
+// has no validator
+func conditional(req *http.Request, e *entry) *http.Request {
+	if !revalidatable(e) {
+		return req
+	}
+	tag, modified := e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+
+	clone := req.Clone(req.Context())
+	if tag != "" {
+		clone.Header.Set("If-None-Match", tag)
+	}
+	if modified != "" {
+		clone.Header.Set("If-Modified-Since", modified)
+	}
+	return clone
+}
+
+// readCloser reads from one reader and closes another
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
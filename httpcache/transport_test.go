@@ -0,0 +1,226 @@
+package httpcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/memory"
+)
+
+// upstream is a round tripper answering with a numbered response, the
+// headers set by header, and the status or error set by fail
+type upstream struct {
+	calls  atomic.Int32
+	header http.Header
+	status int
+	err    error
+
+	// requests holds the requests received
+	requests []*http.Request
+}
+
+func (u *upstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := u.calls.Add(1)
+	u.requests = append(u.requests, req)
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	status := u.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := u.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if tag := header.Get("ETag"); tag != "" && req.Header.Get("If-None-Match") == tag {
+		status = http.StatusNotModified
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(fmt.Sprintf("response %d", n))),
+		Request:    req,
+	}, nil
+}
+
+// setupTransport creates a transport in front of u caching in memory, with
+// a clock that tests move
+func setupTransport(t *testing.T, u *upstream, cfg TransportConfig) (*http.Client, *time.Time) {
+	backend := memory.New(memory.Config{})
+	t.Cleanup(func() { backend.Close() })
+	transport := NewTransport(backend, u, cfg)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+	return &http.Client{Transport: transport}, &now
+}
+
+// fetch sends a GET request for url with client, returning the response
+// body and X-Cache header
+func fetch(t *testing.T, client *http.Client, url string, header ...string) (string, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body), resp.Header.Get("X-Cache")
+}
+
+func TestTransport(t *testing.T) {
+	t.Run("serves fresh responses from the cache", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"max-age=60"}}}
+		client, now := setupTransport(t, u, TransportConfig{})
+
+		body, status := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 1", body)
+		assert.Equal(t, "MISS", status)
+
+		*now = now.Add(30 * time.Second)
+		body, status = fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 1", body)
+		assert.Equal(t, "HIT", status)
+
+		*now = now.Add(31 * time.Second)
+		body, _ = fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 2", body)
+		assert.Equal(t, int32(2), u.calls.Load())
+	})
+
+	t.Run("honours Expires", func(t *testing.T) {
+		u := &upstream{}
+		client, now := setupTransport(t, u, TransportConfig{})
+		u.header = http.Header{
+			"Date":    {now.UTC().Format(http.TimeFormat)},
+			"Expires": {now.Add(time.Minute).UTC().Format(http.TimeFormat)},
+		}
+
+		fetch(t, client, "https://api.example.com/rates")
+		_, status := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "HIT", status)
+
+		*now = now.Add(2 * time.Minute)
+		_, status = fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "MISS", status)
+	})
+
+	t.Run("without freshness information", func(t *testing.T) {
+		u := &upstream{}
+		client, _ := setupTransport(t, u, TransportConfig{})
+		fetch(t, client, "https://api.example.com/rates")
+		body, _ := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 2", body)
+
+		client, _ = setupTransport(t, u, TransportConfig{TTL: time.Minute})
+		fetch(t, client, "https://api.example.com/rates")
+		_, status := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "HIT", status)
+	})
+
+	t.Run("revalidates stale responses", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}}
+		client, _ := setupTransport(t, u, TransportConfig{})
+
+		fetch(t, client, "https://api.example.com/rates")
+		body, status := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 1", body)
+		assert.Equal(t, "REVALIDATED", status)
+		assert.Equal(t, `"v1"`, u.requests[1].Header.Get("If-None-Match"))
+		assert.Equal(t, int32(2), u.calls.Load())
+	})
+
+	t.Run("serves stale responses when the upstream fails", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"max-age=60"}}}
+		client, now := setupTransport(t, u, TransportConfig{StaleIfError: time.Hour})
+		fetch(t, client, "https://api.example.com/rates")
+
+		*now = now.Add(2 * time.Minute)
+		u.err = errors.New("connection refused")
+		body, status := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 1", body)
+		assert.Equal(t, "STALE", status)
+
+		u.err = nil
+		u.status = http.StatusBadGateway
+		body, status = fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 1", body)
+		assert.Equal(t, "STALE", status)
+
+		*now = now.Add(2 * time.Hour)
+		u.err = errors.New("connection refused")
+		_, err := client.Get("https://api.example.com/rates")
+		assert.Error(t, err)
+	})
+
+	t.Run("honours stale-if-error", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"max-age=60, stale-if-error=30"}}}
+		client, now := setupTransport(t, u, TransportConfig{StaleIfError: time.Hour})
+		fetch(t, client, "https://api.example.com/rates")
+
+		*now = now.Add(2 * time.Minute)
+		u.err = errors.New("connection refused")
+		_, err := client.Get("https://api.example.com/rates")
+		assert.Error(t, err)
+	})
+
+	t.Run("keys on credentials", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"max-age=60"}}}
+		client, _ := setupTransport(t, u, TransportConfig{})
+
+		first, _ := fetch(t, client, "https://api.example.com/me", "Authorization", "Bearer ada")
+		second, _ := fetch(t, client, "https://api.example.com/me", "Authorization", "Bearer grace")
+		again, _ := fetch(t, client, "https://api.example.com/me", "Authorization", "Bearer ada")
+		assert.Equal(t, "response 1", first)
+		assert.Equal(t, "response 2", second)
+		assert.Equal(t, "response 1", again)
+
+		first, _ = fetch(t, client, "https://api.example.com/me", "Cookie", "session=ada")
+		second, _ = fetch(t, client, "https://api.example.com/me", "Cookie", "session=grace")
+		again, _ = fetch(t, client, "https://api.example.com/me", "Cookie", "session=ada")
+		assert.Equal(t, "response 3", first)
+		assert.Equal(t, "response 4", second)
+		assert.Equal(t, "response 3", again)
+	})
+
+	t.Run("passes uncacheable requests and responses through", func(t *testing.T) {
+		u := &upstream{header: http.Header{"Cache-Control": {"no-store"}}}
+		client, _ := setupTransport(t, u, TransportConfig{TTL: time.Minute})
+		fetch(t, client, "https://api.example.com/rates")
+		body, _ := fetch(t, client, "https://api.example.com/rates")
+		assert.Equal(t, "response 2", body)
+
+		u = &upstream{}
+		client, _ = setupTransport(t, u, TransportConfig{TTL: time.Minute, MaxBodySize: 5})
+		fetch(t, client, "https://api.example.com/large")
+		body, _ = fetch(t, client, "https://api.example.com/large")
+		assert.Equal(t, "response 2", body)
+
+		u = &upstream{}
+		client, _ = setupTransport(t, u, TransportConfig{TTL: time.Minute})
+		fetch(t, client, "https://api.example.com/rates")
+		body, _ = fetch(t, client, "https://api.example.com/rates", "Cache-Control", "no-cache")
+		assert.Equal(t, "response 2", body)
+		body, _ = fetch(t, client, "https://api.example.com/rates", "If-None-Match", `"v1"`)
+		assert.Equal(t, "response 3", body)
+
+		resp, err := client.Post("https://api.example.com/rates", "text/plain", strings.NewReader("x"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, int32(4), u.calls.Load())
+	})
+}
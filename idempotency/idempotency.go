@@ -0,0 +1,227 @@
+// Package idempotency makes retried operations safe by recording their
+// results under a caller supplied idempotency key, so a duplicate request
+// gets the original result instead of repeating its side effects
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+const (
+	// defaultPrefix namespaces idempotency records when Config does not set
+	// a prefix
+	defaultPrefix = "idempotency:"
+
+	// defaultTTL is how long results are kept when Config does not set a TTL
+	defaultTTL = 24 * time.Hour
+
+	// defaultLockTTL bounds how long an operation is considered in flight
+	// when Config does not set a LockTTL
+	defaultLockTTL = time.Minute
+
+	// maxClaimAttempts bounds how often Do retries claiming a key whose
+	// record disappeared between the claim and the read
+	maxClaimAttempts = 3
+)
+
+var (
+	// ErrInFlight is returned when an operation with the same key is still
+	// running
+	ErrInFlight = errors.New("an operation with this idempotency key is in progress")
+
+	// ErrFingerprintMismatch is returned when a key is reused for a
+	// different request
+	ErrFingerprintMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// Record states
+const (
+	stateInFlight = "in_flight"
+	stateDone     = "done"
+)
+
+// record is what is stored under an idempotency key
+type record struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	Owner       string `json:"owner,omitempty"`
+	Result      []byte `json:"result,omitempty"`
+}
+
+// Config configures a Store
+type Config struct {
+	// Prefix namespaces the records, within the client's own prefix.
+	// Defaults to "idempotency:".
+	Prefix string
+
+	// TTL is how long the result of an operation is kept for replay.
+	// Defaults to 24 hours.
+	TTL time.Duration
+
+	// LockTTL bounds how long an operation is considered in flight, so a
+	// key is freed should the process running it crash. It should exceed
+	// the longest operation. Defaults to one minute.
+	LockTTL time.Duration
+}
+
+// Store records operation results by idempotency key
+type Store struct {
+	client  *redis.Client
+	prefix  string
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// New returns a store keeping its records with client
+func New(client *redis.Client, cfg Config) *Store {
+	s := &Store{client: client, prefix: cfg.Prefix, ttl: cfg.TTL, lockTTL: cfg.LockTTL}
+	if s.prefix == "" {
+		s.prefix = defaultPrefix
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultTTL
+	}
+	if s.lockTTL <= 0 {
+		s.lockTTL = defaultLockTTL
+	}
+	return s
+}
+
+// Fingerprint returns a digest of the parts identifying a request, such as
+// its method, path and body, to detect a key being reused for another one
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Length prefixes keep ("ab", "c") apart from ("a", "bc")
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Do runs fn once per key. The first call claims the key and records fn's
+// result for the configured TTL; later calls with the same key and
+// fingerprint get that result without running fn. Calls made while fn is
+// running get ErrInFlight, and calls with another fingerprint get
+// ErrFingerprintMismatch. If fn fails nothing is recorded, so the operation
+// can be retried.
+func (s *Store) Do(ctx context.Context, key, fingerprint string, fn func() ([]byte, error)) ([]byte, error) {
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return nil, err
+	}
+	claim, err := json.Marshal(record{State: stateInFlight, Fingerprint: fingerprint, Owner: hex.EncodeToString(owner)})
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		claimed, err := s.client.Add(ctx, s.key(key), string(claim), s.lockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return s.run(ctx, key, string(claim), fingerprint, fn)
+		}
+
+		rec, err := s.get(ctx, key)
+		if errors.Is(err, redis.ErrKeyNotFound) {
+			// The operation failed or expired since the claim, try again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return nil, ErrFingerprintMismatch
+		case rec.State == stateInFlight:
+			return nil, ErrInFlight
+		default:
+			return rec.Result, nil
+		}
+	}
+	return nil, ErrInFlight
+}
+
+// run executes fn for a key claimed with claim and records its result
+func (s *Store) run(ctx context.Context, key, claim, fingerprint string, fn func() ([]byte, error)) ([]byte, error) {
+	// Record or release the key even if the caller gives up meanwhile
+	storeCtx := context.WithoutCancel(ctx)
+
+	result, err := fn()
+	if err != nil {
+		_ = s.settle(storeCtx, key, claim, func(tx *redis.Tx) {
+			tx.Forget(storeCtx, s.key(key))
+		})
+		return nil, err
+	}
+
+	done, err := json.Marshal(record{State: stateDone, Fingerprint: fingerprint, Result: result})
+	if err != nil {
+		return nil, err
+	}
+	err = s.settle(storeCtx, key, claim, func(tx *redis.Tx) {
+		tx.Put(storeCtx, s.key(key), string(done), s.ttl)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record idempotent result: %w", err)
+	}
+	return result, nil
+}
+
+// settle queues write in a transaction if key still holds claim. A claim
+// that expired while the operation ran may have been taken over by another
+// call, whose record is left alone.
+func (s *Store) settle(ctx context.Context, key, claim string, write func(tx *redis.Tx)) error {
+	err := s.client.Transaction(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, s.key(key))
+		if errors.Is(err, redis.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if current == claim {
+			write(tx)
+		}
+		return nil
+	}, s.key(key))
+	if errors.Is(err, redis.ErrTxConflict) {
+		return nil
+	}
+	return err
+}
+
+// get reads the record stored under key
+func (s *Store) get(ctx context.Context, key string) (record, error) {
+	var rec record
+	value, err := s.client.Get(ctx, s.key(key))
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal([]byte(value), &rec); err != nil {
+		return rec, &redis.DecodeError{Key: key, Err: err}
+	}
+	return rec, nil
+}
+
+// Forget removes the record for key, so the next call runs the operation
+// again
+func (s *Store) Forget(ctx context.Context, key string) error {
+	return s.client.Forget(ctx, s.key(key))
+}
+
+// key returns the key the record for key is stored under
+func (s *Store) key(key string) string {
+	return s.prefix + key
+}
@@ -0,0 +1,158 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// setupStore creates a mock Redis server and a store using it
+func setupStore(t *testing.T, cfg Config) (*Store, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return New(client, cfg), mr
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint([]byte("POST"), []byte("/orders")), Fingerprint([]byte("POST"), []byte("/orders")))
+	assert.NotEqual(t, Fingerprint([]byte("POST"), []byte("/orders")), Fingerprint([]byte("POST"), []byte("/refunds")))
+	assert.NotEqual(t, Fingerprint([]byte("ab"), []byte("c")), Fingerprint([]byte("a"), []byte("bc")))
+}
+
+func TestStore_Do(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs once and replays the result", func(t *testing.T) {
+		store, mr := setupStore(t, Config{})
+
+		calls := 0
+		charge := func() ([]byte, error) {
+			calls++
+			return []byte("charge-1"), nil
+		}
+
+		result, err := store.Do(ctx, "key-1", "fp", charge)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("charge-1"), result)
+
+		result, err = store.Do(ctx, "key-1", "fp", charge)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("charge-1"), result)
+		assert.Equal(t, 1, calls)
+
+		assert.True(t, mr.Exists("app:idempotency:key-1"))
+		assert.Equal(t, 24*time.Hour, mr.TTL("app:idempotency:key-1"))
+	})
+
+	t.Run("rejects a key reused for another request", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+
+		_, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) { return []byte("ok"), nil })
+		require.NoError(t, err)
+
+		_, err = store.Do(ctx, "key-1", "other", func() ([]byte, error) {
+			t.Fatal("operation should not run")
+			return nil, nil
+		})
+		assert.ErrorIs(t, err, ErrFingerprintMismatch)
+	})
+
+	t.Run("rejects duplicates while in flight", func(t *testing.T) {
+		store, mr := setupStore(t, Config{LockTTL: 30 * time.Second})
+
+		result, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) {
+			assert.Equal(t, 30*time.Second, mr.TTL("app:idempotency:key-1"))
+
+			_, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) {
+				t.Fatal("operation should not run")
+				return nil, nil
+			})
+			assert.ErrorIs(t, err, ErrInFlight)
+
+			_, err = store.Do(ctx, "key-1", "other", func() ([]byte, error) { return nil, nil })
+			assert.ErrorIs(t, err, ErrFingerprintMismatch)
+			return []byte("done"), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("done"), result)
+	})
+
+	t.Run("failures are not recorded", func(t *testing.T) {
+		store, mr := setupStore(t, Config{})
+
+		_, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) {
+			return nil, errors.New("payment declined")
+		})
+		assert.EqualError(t, err, "payment declined")
+		assert.False(t, mr.Exists("app:idempotency:key-1"))
+
+		result, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) { return []byte("retried"), nil })
+		require.NoError(t, err)
+		assert.Equal(t, []byte("retried"), result)
+	})
+
+	t.Run("crashed operations are freed after the lock TTL", func(t *testing.T) {
+		store, mr := setupStore(t, Config{LockTTL: time.Second})
+
+		// The first operation outlives its claim, as after a long pause
+		ran := false
+		_, _ = store.Do(ctx, "key-1", "fp", func() ([]byte, error) {
+			ran = true
+			_, err := store.Do(ctx, "key-1", "fp", nil)
+			assert.ErrorIs(t, err, ErrInFlight)
+
+			mr.FastForward(time.Second)
+			result, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) { return []byte("recovered"), nil })
+			require.NoError(t, err)
+			assert.Equal(t, []byte("recovered"), result)
+			return []byte("late"), nil
+		})
+		assert.True(t, ran)
+
+		// The late result does not replace the one recorded by the takeover
+		result, err := store.Do(ctx, "key-1", "fp", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("recovered"), result)
+	})
+
+	t.Run("custom prefix and TTL", func(t *testing.T) {
+		store, mr := setupStore(t, Config{Prefix: "idem:", TTL: time.Hour})
+
+		_, err := store.Do(ctx, "key-1", "fp", func() ([]byte, error) { return []byte("ok"), nil })
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, mr.TTL("app:idem:key-1"))
+	})
+}
+
+func TestStore_Forget(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setupStore(t, Config{})
+
+	calls := 0
+	op := func() ([]byte, error) {
+		calls++
+		return []byte("ok"), nil
+	}
+
+	_, err := store.Do(ctx, "key-1", "fp", op)
+	require.NoError(t, err)
+	require.NoError(t, store.Forget(ctx, "key-1"))
+
+	_, err = store.Do(ctx, "key-1", "other", op)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// defaultHeader is the request header carrying the idempotency key when
+	// MiddlewareConfig does not name one
+	defaultHeader = "Idempotency-Key"
+
+	// replayedHeader is set on responses replayed from a recorded result
+	replayedHeader = "Idempotent-Replayed"
+)
+
+// errServerError keeps responses with a 5xx status from being recorded, so
+// the request can be retried
+var errServerError = errors.New("server error responses are not recorded")
+
+// response is a recorded HTTP response
+type response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// write sends the response to w
+func (resp *response) write(w http.ResponseWriter) {
+	h := w.Header()
+	for name, values := range resp.Header {
+		h[name] = values
+	}
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// recorder is a ResponseWriter buffering the response
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(p)
+}
+
+// MiddlewareConfig configures Middleware
+type MiddlewareConfig struct {
+	// Header is the request header carrying the idempotency key. Defaults to
+	// "Idempotency-Key".
+	Header string
+
+	// Methods lists the request methods made idempotent. Defaults to POST
+	// and PATCH, the other methods being idempotent by definition.
+	Methods []string
+
+	// Scope returns who a request is made on behalf of, such as the
+	// authenticated user or tenant, so that idempotency keys chosen by
+	// different clients never collide and one client's response is not
+	// replayed to another. Defaults to a single scope shared by everyone.
+	Scope func(*http.Request) string
+}
+
+// Middleware returns HTTP middleware making requests that carry an
+// idempotency key run once. The first request's response is recorded and
+// replayed, with an Idempotent-Replayed header, to retries with the same key,
+// scope, method, URL and body. Retries arriving while the first request is running
+// get 409 Conflict, and requests reusing a key for another request get 422
+// Unprocessable Entity. Responses with a 5xx status are not recorded, so the
+// request can be retried. Responses are buffered, so streaming handlers
+// should not be wrapped.
+func (s *Store) Middleware(cfg MiddlewareConfig) func(http.Handler) http.Handler {
+	header := cfg.Header
+	if header == "" {
+		header = defaultHeader
+	}
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	guarded := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		guarded[method] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(header)
+			if key == "" || !guarded[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if cfg.Scope != nil {
+				key = scopedKey(cfg.Scope(r), key)
+			}
+			fingerprint := Fingerprint([]byte(r.Method), []byte(r.URL.RequestURI()), body)
+
+			// executed is the response of this request, when it ran the handler
+			var executed *response
+			result, err := s.Do(r.Context(), key, fingerprint, func() ([]byte, error) {
+				rec := &recorder{header: make(http.Header)}
+				next.ServeHTTP(rec, r)
+				if rec.status == 0 {
+					rec.status = http.StatusOK
+				}
+				executed = &response{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+				if executed.Status >= http.StatusInternalServerError {
+					return nil, errServerError
+				}
+				return json.Marshal(executed)
+			})
+
+			switch {
+			case executed != nil:
+				// The handler ran, send its response even if it could not
+				// be recorded
+				executed.write(w)
+			case errors.Is(err, ErrInFlight):
+				http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+			case errors.Is(err, ErrFingerprintMismatch):
+				http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			default:
+				var replay response
+				if err := json.Unmarshal(result, &replay); err != nil {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set(replayedHeader, "true")
+				replay.write(w)
+			}
+		})
+	}
+}
+
+// scopedKey returns the record key of an idempotency key within scope. The
+// length prefix keeps ("a:b", "c") apart from ("a", "b:c").
+func scopedKey(scope, key string) string {
+	return fmt.Sprintf("%d:%s:%s", len(scope), scope, key)
+}
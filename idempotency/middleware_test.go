@@ -0,0 +1,146 @@
+package idempotency
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve sends a request with body and idempotency key through handler
+func serve(handler http.Handler, method, target, body, key string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestStore_Middleware(t *testing.T) {
+	t.Run("replays the original response", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+
+		calls := 0
+		handler := store.Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			w.Header().Set("Location", "/orders/1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write(append([]byte("created "), body...))
+		}))
+
+		w := serve(handler, http.MethodPost, "/orders", "book", "key-1")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "created book", w.Body.String())
+		assert.Equal(t, "/orders/1", w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+
+		w = serve(handler, http.MethodPost, "/orders", "book", "key-1")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "created book", w.Body.String())
+		assert.Equal(t, "/orders/1", w.Header().Get("Location"))
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("rejects a key reused for another request", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		handler := store.Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		serve(handler, http.MethodPost, "/orders", "book", "key-1")
+		w := serve(handler, http.MethodPost, "/orders", "pen", "key-1")
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("keys are scoped", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		handler := store.Middleware(MiddlewareConfig{
+			Scope: func(r *http.Request) string { return r.Header.Get("X-User") },
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "order for "+r.Header.Get("X-User"))
+		}))
+
+		send := func(user string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("book"))
+			r.Header.Set("Idempotency-Key", "key-1")
+			r.Header.Set("X-User", user)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w
+		}
+
+		assert.Equal(t, "order for alice", send("alice").Body.String())
+		w := send("bob")
+		assert.Equal(t, "order for bob", w.Body.String())
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+
+		w = send("alice")
+		assert.Equal(t, "order for alice", w.Body.String())
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("rejects duplicates while in flight", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+
+		var handler http.Handler
+		var duplicate *httptest.ResponseRecorder
+		handler = store.Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if duplicate == nil {
+				duplicate = serve(handler, http.MethodPost, "/orders", "book", "key-1")
+			}
+		}))
+
+		w := serve(handler, http.MethodPost, "/orders", "book", "key-1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, http.StatusConflict, duplicate.Code)
+	})
+
+	t.Run("server errors are not recorded", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+
+		calls := 0
+		handler := store.Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+
+		assert.Equal(t, http.StatusBadGateway, serve(handler, http.MethodPost, "/orders", "", "key-1").Code)
+		assert.Equal(t, http.StatusOK, serve(handler, http.MethodPost, "/orders", "", "key-1").Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("passes through requests without a key or guarded method", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+
+		calls := 0
+		handler := store.Middleware(MiddlewareConfig{Header: "X-Request-Id"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}))
+
+		serve(handler, http.MethodPost, "/orders", "", "")
+		serve(handler, http.MethodPost, "/orders", "", "")
+		r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		r.Header.Set("X-Request-Id", "key-1")
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("storage failures", func(t *testing.T) {
+		store, mr := setupStore(t, Config{})
+		handler := store.Middleware(MiddlewareConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		mr.Close()
+		w := serve(handler, http.MethodPost, "/orders", "", "key-1")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
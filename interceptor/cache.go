@@ -0,0 +1,141 @@
+// Package interceptor provides gRPC server interceptors caching the
+// responses of idempotent methods and rate limiting calls
+package interceptor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+// defaultCachePrefix namespaces cached responses when CacheConfig does not
+// set a prefix
+const defaultCachePrefix = "grpccache:"
+
+// CacheConfig configures Cache
+type CacheConfig struct {
+	// Prefix namespaces the responses within the cache store. Defaults to
+	// "grpccache:".
+	Prefix string
+
+	// Methods maps the full names of the methods to cache, such as
+	// "/catalog.Catalog/GetProduct", to how long their responses are
+	// cached. Only idempotent methods should be listed; others are never
+	// cached.
+	Methods map[string]time.Duration
+
+	// Vary names incoming metadata keys, such as "accept-language",
+	// responses are cached separately for
+	Vary []string
+
+	// CacheAuthorized caches calls carrying authorization metadata, which
+	// otherwise skip the cache as their responses may be personal. Their
+	// responses are cached separately for each authorization value.
+	CacheAuthorized bool
+}
+
+// authorizationKey is the metadata key of the credentials of a call
+const authorizationKey = "authorization"
+
+// Cache returns a unary server interceptor serving the responses of the
+// configured methods from store, keyed by method and by a hash of the
+// request message. Only successful responses are cached. Calls carrying
+// authorization metadata skip the cache unless cfg.CacheAuthorized is set.
+// A store that cannot be read or written is skipped, the call reaching the
+// handler.
+func Cache(store cache.Store, cfg CacheConfig) grpc.UnaryServerInterceptor {
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultCachePrefix
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ttl, ok := cfg.Methods[info.FullMethod]
+		msg, isProto := req.(proto.Message)
+		if !ok || ttl <= 0 || !isProto {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		vary := cfg.Vary
+		if len(md.Get(authorizationKey)) > 0 {
+			if !cfg.CacheAuthorized {
+				return handler(ctx, req)
+			}
+			vary = append(vary[:len(vary):len(vary)], authorizationKey)
+		}
+		key, err := cacheKey(md, prefix, info.FullMethod, msg, vary)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		if resp, ok := lookup(ctx, store, key); ok {
+			return resp, nil
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		if msg, ok := resp.(proto.Message); ok {
+			// A failure to cache the response does not affect returning it
+			_ = save(ctx, store, key, msg, ttl)
+		}
+		return resp, nil
+	}
+}
+
+// cacheKey returns the cache key of the response to req, given the incoming
+// metadata of the call
+func cacheKey(md metadata.MD, prefix, method string, req proto.Message, vary []string) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	for _, name := range vary {
+		h.Write([]byte("\n" + name + ":" + strings.Join(md.Get(name), ",")))
+	}
+	return prefix + method + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lookup returns the response cached under key
+func lookup(ctx context.Context, store cache.Store, key string) (proto.Message, bool) {
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	var wrapped anypb.Any
+	if err := proto.Unmarshal([]byte(data), &wrapped); err != nil {
+		return nil, false
+	}
+	resp, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+// save caches resp under key for ttl, wrapped with its type so it can be
+// decoded without knowing the method
+func save(ctx context.Context, store cache.Store, key string, resp proto.Message, ttl time.Duration) error {
+	wrapped, err := anypb.New(resp)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(wrapped)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, key, string(data), ttl)
+}
@@ -0,0 +1,130 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/nanaaikinson/gofacades/memory"
+)
+
+const getProduct = "/catalog.Catalog/GetProduct"
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(cfg CacheConfig) (grpc.UnaryServerInterceptor, *int) {
+		store := memory.New(memory.Config{})
+		t.Cleanup(func() { store.Close() })
+		return Cache(store, cfg), new(int)
+	}
+
+	call := func(interceptor grpc.UnaryServerInterceptor, ctx context.Context, method string, req proto.Message, calls *int) (interface{}, error) {
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			*calls++
+			return wrapperspb.String("product " + req.(*wrapperspb.StringValue).GetValue()), nil
+		})
+	}
+
+	t.Run("caches configured methods", func(t *testing.T) {
+		interceptor, calls := setup(CacheConfig{Methods: map[string]time.Duration{getProduct: time.Minute}})
+
+		resp, err := call(interceptor, ctx, getProduct, wrapperspb.String("1"), calls)
+		require.NoError(t, err)
+		assert.Equal(t, "product 1", resp.(*wrapperspb.StringValue).GetValue())
+
+		resp, err = call(interceptor, ctx, getProduct, wrapperspb.String("1"), calls)
+		require.NoError(t, err)
+		assert.Equal(t, "product 1", resp.(*wrapperspb.StringValue).GetValue())
+		assert.Equal(t, 1, *calls)
+
+		// Other requests have their own entry
+		resp, err = call(interceptor, ctx, getProduct, wrapperspb.String("2"), calls)
+		require.NoError(t, err)
+		assert.Equal(t, "product 2", resp.(*wrapperspb.StringValue).GetValue())
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("skips other methods", func(t *testing.T) {
+		interceptor, calls := setup(CacheConfig{Methods: map[string]time.Duration{getProduct: time.Minute}})
+
+		for i := 0; i < 2; i++ {
+			_, err := call(interceptor, ctx, "/catalog.Catalog/UpdateProduct", wrapperspb.String("1"), calls)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("varies on metadata", func(t *testing.T) {
+		interceptor, calls := setup(CacheConfig{
+			Methods: map[string]time.Duration{getProduct: time.Minute},
+			Vary:    []string{"accept-language"},
+		})
+
+		en := metadata.NewIncomingContext(ctx, metadata.Pairs("accept-language", "en"))
+		fr := metadata.NewIncomingContext(ctx, metadata.Pairs("accept-language", "fr"))
+		for _, ctx := range []context.Context{en, fr, en} {
+			_, err := call(interceptor, ctx, getProduct, wrapperspb.String("1"), calls)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("skips authorized calls", func(t *testing.T) {
+		interceptor, calls := setup(CacheConfig{Methods: map[string]time.Duration{getProduct: time.Minute}})
+
+		alice := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer alice"))
+		for i := 0; i < 2; i++ {
+			_, err := call(interceptor, alice, getProduct, wrapperspb.String("1"), calls)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *calls)
+
+		// Nor are they served the responses to anonymous calls
+		_, err := call(interceptor, ctx, getProduct, wrapperspb.String("1"), calls)
+		require.NoError(t, err)
+		_, err = call(interceptor, alice, getProduct, wrapperspb.String("1"), calls)
+		require.NoError(t, err)
+		assert.Equal(t, 4, *calls)
+	})
+
+	t.Run("caches authorized calls per caller when enabled", func(t *testing.T) {
+		interceptor, calls := setup(CacheConfig{
+			Methods:         map[string]time.Duration{getProduct: time.Minute},
+			CacheAuthorized: true,
+		})
+
+		alice := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer alice"))
+		bob := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer bob"))
+		for _, ctx := range []context.Context{alice, bob, alice, ctx} {
+			_, err := call(interceptor, ctx, getProduct, wrapperspb.String("1"), calls)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		interceptor, _ := setup(CacheConfig{Methods: map[string]time.Duration{getProduct: time.Minute}})
+		info := &grpc.UnaryServerInfo{FullMethod: getProduct}
+
+		calls := 0
+		failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, errors.New("database down")
+		}
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(ctx, wrapperspb.String("1"), info, failing)
+			assert.EqualError(t, err, "database down")
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
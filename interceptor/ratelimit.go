@@ -0,0 +1,114 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/nanaaikinson/gofacades/ratelimit"
+)
+
+// KeyFunc returns the key a call is limited under. Calls for which it
+// returns an empty key are not limited.
+type KeyFunc func(ctx context.Context, method string) string
+
+// ByPeer limits calls per client IP address, as seen by the server
+func ByPeer(ctx context.Context, _ string) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// ByMetadata limits calls per value of the named incoming metadata key,
+// such as an API key. Calls without it are not limited.
+func ByMetadata(name string) KeyFunc {
+	return func(ctx context.Context, _ string) string {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+}
+
+// RateLimitConfig configures RateLimit
+type RateLimitConfig struct {
+	// Max is the number of calls allowed per Window and key
+	Max    int
+	Window time.Duration
+
+	// Key returns the key a call is limited under. Defaults to ByPeer.
+	Key KeyFunc
+
+	// PerMethod counts the calls to each method separately, rather than
+	// every call of a key against one limit
+	PerMethod bool
+
+	// FailClosed rejects calls with Unavailable when attempts cannot be
+	// counted, such as during a Redis outage. By default they are let
+	// through.
+	FailClosed bool
+}
+
+// RateLimit returns a unary server interceptor counting each call as an
+// attempt under its key with limiter. Calls carry the ratelimit-limit,
+// ratelimit-remaining and ratelimit-reset header metadata; calls over the
+// limit fail with ResourceExhausted, a retry-after header and a RetryInfo
+// detail, without reaching the handler.
+func RateLimit(limiter *ratelimit.Limiter, cfg RateLimitConfig) grpc.UnaryServerInterceptor {
+	keyFunc := cfg.Key
+	if keyFunc == nil {
+		keyFunc = ByPeer
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		key := keyFunc(ctx, info.FullMethod)
+		if key == "" {
+			return handler(ctx, req)
+		}
+		if cfg.PerMethod {
+			key = info.FullMethod + ":" + key
+		}
+
+		result, err := limiter.Attempt(ctx, key, cfg.Max, cfg.Window)
+		if err != nil {
+			if cfg.FailClosed {
+				return nil, status.Error(codes.Unavailable, "rate limit unavailable")
+			}
+			return handler(ctx, req)
+		}
+
+		md := metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(result.Limit),
+			"ratelimit-remaining", strconv.Itoa(result.Remaining),
+			"ratelimit-reset", ratelimit.Seconds(result.ResetAfter),
+		)
+		if !result.Allowed {
+			md.Set("retry-after", ratelimit.Seconds(result.RetryAfter))
+			_ = grpc.SetHeader(ctx, md)
+
+			st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+			if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(result.RetryAfter)}); err == nil {
+				st = detailed
+			}
+			return nil, st.Err()
+		}
+		_ = grpc.SetHeader(ctx, md)
+		return handler(ctx, req)
+	}
+}
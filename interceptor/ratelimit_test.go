@@ -0,0 +1,138 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/nanaaikinson/gofacades/ratelimit"
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// headerStream records the header metadata set by interceptors
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func setupLimiter(t *testing.T) (*ratelimit.Limiter, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	limiter, err := ratelimit.New(context.Background(), client, ratelimit.Config{})
+	require.NoError(t, err)
+	return limiter, mr
+}
+
+func TestRateLimit(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: getProduct}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	call := func(interceptor grpc.UnaryServerInterceptor, ctx context.Context, addr string) (*headerStream, error) {
+		stream := &headerStream{}
+		ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+		if addr != "" {
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 1234}})
+		}
+		_, err := interceptor(ctx, nil, info, ok)
+		return stream, err
+	}
+
+	t.Run("limits per peer", func(t *testing.T) {
+		limiter, _ := setupLimiter(t)
+		interceptor := RateLimit(limiter, RateLimitConfig{Max: 2, Window: time.Minute})
+		ctx := context.Background()
+
+		stream, err := call(interceptor, ctx, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2"}, stream.header.Get("ratelimit-limit"))
+		assert.Equal(t, []string{"1"}, stream.header.Get("ratelimit-remaining"))
+		assert.Equal(t, []string{"60"}, stream.header.Get("ratelimit-reset"))
+
+		_, err = call(interceptor, ctx, "10.0.0.1")
+		require.NoError(t, err)
+		stream, err = call(interceptor, ctx, "10.0.0.1")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, []string{"0"}, stream.header.Get("ratelimit-remaining"))
+		assert.Equal(t, []string{"60"}, stream.header.Get("retry-after"))
+
+		details := status.Convert(err).Details()
+		require.Len(t, details, 1)
+		assert.Equal(t, time.Minute, details[0].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+
+		// Other clients have their own limit
+		_, err = call(interceptor, ctx, "10.0.0.2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("limits per metadata", func(t *testing.T) {
+		limiter, _ := setupLimiter(t)
+		interceptor := RateLimit(limiter, RateLimitConfig{Max: 1, Window: time.Minute, Key: ByMetadata("x-api-key")})
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "secret"))
+
+		_, err := call(interceptor, ctx, "")
+		require.NoError(t, err)
+		_, err = call(interceptor, ctx, "")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		// Calls without the key are not limited
+		for i := 0; i < 2; i++ {
+			_, err = call(interceptor, context.Background(), "")
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("per method", func(t *testing.T) {
+		limiter, _ := setupLimiter(t)
+		interceptor := RateLimit(limiter, RateLimitConfig{Max: 1, Window: time.Minute, PerMethod: true})
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}})
+
+		_, err := interceptor(ctx, nil, info, ok)
+		require.NoError(t, err)
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/catalog.Catalog/ListProducts"}, ok)
+		assert.NoError(t, err)
+		_, err = interceptor(ctx, nil, info, ok)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("fails open by default", func(t *testing.T) {
+		limiter, mr := setupLimiter(t)
+		mr.Close()
+
+		_, err := call(RateLimit(limiter, RateLimitConfig{Max: 1, Window: time.Minute}), context.Background(), "10.0.0.1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails closed", func(t *testing.T) {
+		limiter, mr := setupLimiter(t)
+		mr.Close()
+
+		interceptor := RateLimit(limiter, RateLimitConfig{Max: 1, Window: time.Minute, FailClosed: true})
+		_, err := call(interceptor, context.Background(), "10.0.0.1")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
@@ -0,0 +1,189 @@
+package memcached
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+var (
+	ErrKeyNotFound = cache.ErrKeyNotFound
+	ErrNilCallback = cache.ErrNilCallback
+)
+
+// maxRelativeExpiration is the longest expiration memcached accepts as a
+// number of seconds; anything longer must be sent as a unix timestamp
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+var _ cache.Store = (*Client)(nil)
+
+// Client represents a Memcached client
+type Client struct {
+	client *memcache.Client
+}
+
+// Config holds the configuration for Memcached connection
+type Config struct {
+	// Servers lists the host:port addresses of the Memcached servers
+	Servers []string
+
+	// Timeout is the socket read/write timeout, defaulting to the
+	// gomemcache default
+	Timeout time.Duration
+
+	// MaxIdleConns is the maximum number of idle connections kept per
+	// server, defaulting to the gomemcache default
+	MaxIdleConns int
+}
+
+// New creates a new Memcached client
+func New(cfg Config) (*Client, error) {
+	client := memcache.New(cfg.Servers...)
+	if cfg.Timeout > 0 {
+		client.Timeout = cfg.Timeout
+	}
+	if cfg.MaxIdleConns > 0 {
+		client.MaxIdleConns = cfg.MaxIdleConns
+	}
+
+	// Test the connection
+	if err := client.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Memcached: %v", err)
+	}
+
+	return &Client{
+		client: client,
+	}, nil
+}
+
+// expiration converts a TTL into a memcached expiration value
+func expiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiration {
+		return int32(time.Now().Add(ttl).Unix())
+	}
+
+	seconds := int32(ttl / time.Second)
+	if seconds == 0 {
+		// Round sub-second TTLs up rather than storing the item forever
+		seconds = 1
+	}
+	return seconds
+}
+
+// Get retrieves an item from the cache by key
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	item, err := c.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(item.Value), nil
+}
+
+// Has checks if an item exists in the cache
+func (c *Client) Has(ctx context.Context, key string) (bool, error) {
+	_, err := c.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remember gets an item from the cache, or stores the result of the callback
+func (c *Client) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	// First, try to get the existing item
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+
+	// If callback is nil, return error
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result to JSON string
+	jsonValue, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	// Store the result in cache
+	if err := c.Put(ctx, key, string(jsonValue), ttl); err != nil {
+		return "", err
+	}
+
+	return string(jsonValue), nil
+}
+
+// Pull retrieves and deletes an item from the cache
+func (c *Client) Pull(ctx context.Context, key string) (string, error) {
+	// Get the value first
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Then delete it
+	if err := c.Forget(ctx, key); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Put stores an item in the cache for a given duration
+func (c *Client) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.client.Set(&memcache.Item{
+		Key:        key,
+		Value:      []byte(value),
+		Expiration: expiration(ttl),
+	})
+}
+
+// Forever stores an item in the cache permanently
+func (c *Client) Forever(ctx context.Context, key, value string) error {
+	return c.Put(ctx, key, value, 0)
+}
+
+// Forget removes an item from the cache
+func (c *Client) Forget(ctx context.Context, key string) error {
+	err := c.client.Delete(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil
+	}
+	return err
+}
+
+// Flush removes all items from the cache
+func (c *Client) Flush(ctx context.Context) error {
+	return c.client.FlushAll()
+}
+
+// Close closes the Memcached connections
+func (c *Client) Close() error {
+	return c.client.Close()
+}
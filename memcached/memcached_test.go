@@ -0,0 +1,341 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// fakeItem is an entry held by fakeMemcached
+type fakeItem struct {
+	value     []byte
+	flags     uint32
+	expiresAt time.Time
+}
+
+// fakeMemcached is a minimal implementation of the memcached text protocol,
+// covering the commands used by the driver
+type fakeMemcached struct {
+	listener net.Listener
+
+	mu    sync.Mutex
+	items map[string]fakeItem
+	cas   uint64
+}
+
+func newFakeMemcached(t *testing.T) *fakeMemcached {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f := &fakeMemcached{listener: l, items: make(map[string]fakeItem)}
+	go f.serve()
+	t.Cleanup(func() { l.Close() })
+
+	return f
+}
+
+func (f *fakeMemcached) Addr() string {
+	return f.listener.Addr().String()
+}
+
+// expiresIn returns the remaining lifetime of key, zero meaning no expiry
+func (f *fakeMemcached) expiresIn(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	it, ok := f.items[key]
+	if !ok || it.expiresAt.IsZero() {
+		return 0
+	}
+	return time.Until(it.expiresAt)
+}
+
+func (f *fakeMemcached) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeMemcached) lookup(key string) (fakeItem, bool) {
+	it, ok := f.items[key]
+	if ok && !it.expiresAt.IsZero() && !time.Now().Before(it.expiresAt) {
+		delete(f.items, key)
+		return fakeItem{}, false
+	}
+	return it, ok
+}
+
+func expiresAt(exptime int64) time.Time {
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime > int64((30 * 24 * time.Hour).Seconds()):
+		return time.Unix(exptime, 0)
+	default:
+		return time.Now().Add(time.Duration(exptime) * time.Second)
+	}
+}
+
+func (f *fakeMemcached) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		f.mu.Lock()
+		switch fields[0] {
+		case "get", "gets":
+			for _, key := range fields[1:] {
+				if it, ok := f.lookup(key); ok {
+					fmt.Fprintf(w, "VALUE %s %d %d %d\r\n%s\r\n", key, it.flags, len(it.value), f.cas, it.value)
+				}
+			}
+			w.WriteString("END\r\n")
+		case "set", "add":
+			flags, _ := strconv.ParseUint(fields[2], 10, 32)
+			exptime, _ := strconv.ParseInt(fields[3], 10, 64)
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				f.mu.Unlock()
+				return
+			}
+			_, exists := f.lookup(fields[1])
+			if fields[0] == "add" && exists {
+				w.WriteString("NOT_STORED\r\n")
+				break
+			}
+			f.cas++
+			f.items[fields[1]] = fakeItem{value: data[:size], flags: uint32(flags), expiresAt: expiresAt(exptime)}
+			w.WriteString("STORED\r\n")
+		case "delete":
+			if _, ok := f.lookup(fields[1]); ok {
+				delete(f.items, fields[1])
+				w.WriteString("DELETED\r\n")
+			} else {
+				w.WriteString("NOT_FOUND\r\n")
+			}
+		case "touch":
+			it, ok := f.lookup(fields[1])
+			if !ok {
+				w.WriteString("NOT_FOUND\r\n")
+				break
+			}
+			exptime, _ := strconv.ParseInt(fields[2], 10, 64)
+			it.expiresAt = expiresAt(exptime)
+			f.items[fields[1]] = it
+			w.WriteString("TOUCHED\r\n")
+		case "incr", "decr":
+			it, ok := f.lookup(fields[1])
+			if !ok {
+				w.WriteString("NOT_FOUND\r\n")
+				break
+			}
+			current, _ := strconv.ParseUint(string(it.value), 10, 64)
+			delta, _ := strconv.ParseUint(fields[2], 10, 64)
+			if fields[0] == "incr" {
+				current += delta
+			} else if delta > current {
+				current = 0
+			} else {
+				current -= delta
+			}
+			it.value = []byte(strconv.FormatUint(current, 10))
+			f.items[fields[1]] = it
+			fmt.Fprintf(w, "%d\r\n", current)
+		case "flush_all":
+			f.items = make(map[string]fakeItem)
+			w.WriteString("OK\r\n")
+		case "version":
+			w.WriteString("VERSION fake\r\n")
+		default:
+			w.WriteString("ERROR\r\n")
+		}
+		f.mu.Unlock()
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// setupTestMemcached creates a fake Memcached server and a client for it
+func setupTestMemcached(t *testing.T) (*Client, *fakeMemcached) {
+	server := newFakeMemcached(t)
+
+	client, err := New(Config{Servers: []string{server.Addr()}})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client, server
+}
+
+func TestNew(t *testing.T) {
+	t.Run("valid configuration", func(t *testing.T) {
+		server := newFakeMemcached(t)
+
+		client, err := New(Config{Servers: []string{server.Addr()}, Timeout: time.Second, MaxIdleConns: 5})
+		assert.NoError(t, err)
+		client.Close()
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		l.Close()
+
+		_, err = New(Config{Servers: []string{addr}})
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_PutGet(t *testing.T) {
+	client, server := setupTestMemcached(t)
+	ctx := context.Background()
+
+	t.Run("store string with TTL", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+		val, err := client.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-value", val)
+		assert.True(t, server.expiresIn("test-key") > 0)
+	})
+
+	t.Run("get non-existent key", func(t *testing.T) {
+		val, err := client.Get(ctx, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("long TTL is sent as a timestamp", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "long-key", "value", 60*24*time.Hour))
+
+		remaining := server.expiresIn("long-key")
+		assert.InDelta(t, float64(60*24*time.Hour), float64(remaining), float64(time.Minute))
+	})
+}
+
+func TestExpiration(t *testing.T) {
+	assert.Equal(t, int32(0), expiration(0))
+	assert.Equal(t, int32(0), expiration(-time.Second))
+	assert.Equal(t, int32(1), expiration(10*time.Millisecond))
+	assert.Equal(t, int32(3600), expiration(time.Hour))
+	assert.True(t, expiration(31*24*time.Hour) > int32(time.Now().Unix()))
+}
+
+func TestClient_Has(t *testing.T) {
+	client, _ := setupTestMemcached(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+	exists, err := client.Has(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = client.Has(ctx, "non-existent-key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestClient_Remember(t *testing.T) {
+	client, _ := setupTestMemcached(t)
+	ctx := context.Background()
+
+	t.Run("remember new value", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := client.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		var result testStruct
+		require.NoError(t, json.Unmarshal([]byte(val), &result))
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+
+		_, err = client.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := client.Remember(ctx, "nil-callback", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
+func TestClient_Pull(t *testing.T) {
+	client, _ := setupTestMemcached(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+
+	val, err := client.Pull(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-value", val)
+
+	exists, _ := client.Has(ctx, "test-key")
+	assert.False(t, exists)
+
+	_, err = client.Pull(ctx, "test-key")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestClient_ForeverForgetFlush(t *testing.T) {
+	client, server := setupTestMemcached(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Forever(ctx, "forever", "value"))
+	assert.Equal(t, time.Duration(0), server.expiresIn("forever"))
+
+	require.NoError(t, client.Forget(ctx, "forever"))
+	exists, _ := client.Has(ctx, "forever")
+	assert.False(t, exists)
+
+	// Forgetting a missing key succeeds
+	assert.NoError(t, client.Forget(ctx, "forever"))
+
+	require.NoError(t, client.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, client.Forever(ctx, "key2", "value2"))
+	require.NoError(t, client.Flush(ctx))
+
+	exists1, _ := client.Has(ctx, "key1")
+	exists2, _ := client.Has(ctx, "key2")
+	assert.False(t, exists1)
+	assert.False(t, exists2)
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+var (
+	ErrKeyNotFound = cache.ErrKeyNotFound
+	ErrNilCallback = cache.ErrNilCallback
+)
+
+// defaultCleanupInterval is used when Config.CleanupInterval is not set
+const defaultCleanupInterval = time.Minute
+
+var _ cache.Store = (*Store)(nil)
+
+// Store is an in-process cache store
+type Store struct {
+	mu    sync.RWMutex
+	items map[string]item
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// Config holds the configuration for the in-memory store
+type Config struct {
+	// CleanupInterval is how often expired items are purged in the
+	// background, defaulting to one minute
+	CleanupInterval time.Duration
+}
+
+// item is a cached value with its expiration time, zero meaning never
+type item struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (i item) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+// New creates a new in-memory store and starts its background janitor
+func New(cfg Config) *Store {
+	interval := cfg.CleanupInterval
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+
+	s := &Store{
+		items: make(map[string]item),
+		stop:  make(chan struct{}),
+	}
+	go s.janitor(interval)
+
+	return s
+}
+
+// janitor periodically removes expired items until the store is closed
+func (s *Store) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deleteExpired removes every expired item
+func (s *Store) deleteExpired() {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, it := range s.items {
+		if it.expired(now) {
+			delete(s.items, key)
+		}
+	}
+}
+
+// Get retrieves an item from the cache by key
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	s.mu.RLock()
+	it, ok := s.items[key]
+	s.mu.RUnlock()
+
+	if !ok || it.expired(time.Now()) {
+		return "", ErrKeyNotFound
+	}
+	return it.value, nil
+}
+
+// Has checks if an item exists in the cache
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	_, err := s.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Remember gets an item from the cache, or stores the result of the callback
+func (s *Store) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	// First, try to get the existing item
+	value, err := s.Get(ctx, key)
+	if err == nil {
+		return value, nil
+	}
+
+	// If callback is nil, return error
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result to JSON string
+	jsonValue, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	// Store the result in cache
+	if err := s.Put(ctx, key, string(jsonValue), ttl); err != nil {
+		return "", err
+	}
+
+	return string(jsonValue), nil
+}
+
+// Pull retrieves and deletes an item from the cache
+func (s *Store) Pull(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	delete(s.items, key)
+
+	if !ok || it.expired(time.Now()) {
+		return "", ErrKeyNotFound
+	}
+	return it.value, nil
+}
+
+// Put stores an item in the cache for a given duration. A non-positive ttl
+// stores the item permanently.
+func (s *Store) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	it := item{value: value}
+	if ttl > 0 {
+		it.expiresAt = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	s.items[key] = it
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Forever stores an item in the cache permanently
+func (s *Store) Forever(ctx context.Context, key, value string) error {
+	return s.Put(ctx, key, value, 0)
+}
+
+// Forget removes an item from the cache
+func (s *Store) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Flush removes all items from the cache
+func (s *Store) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	s.items = make(map[string]item)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Close stops the background janitor
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStruct struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// setupTestStore creates an in-memory store that is closed with the test
+func setupTestStore(t *testing.T) *Store {
+	s := New(Config{})
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore_PutGet(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("get existing key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+		val, err := s.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "test-value", val)
+	})
+
+	t.Run("overwrite existing key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "test-key", "initial-value", time.Hour))
+		require.NoError(t, s.Put(ctx, "test-key", "new-value", time.Hour))
+
+		val, err := s.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "new-value", val)
+	})
+
+	t.Run("get non-existent key", func(t *testing.T) {
+		val, err := s.Get(ctx, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("get expired key", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "expired-key", "test-value", time.Millisecond*10))
+
+		time.Sleep(time.Millisecond * 20)
+
+		val, err := s.Get(ctx, "expired-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Empty(t, val)
+	})
+}
+
+func TestStore_Has(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	exists, err := s.Has(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.Has(ctx, "non-existent-key")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestStore_Remember(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	t.Run("remember new value", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		var result testStruct
+		require.NoError(t, json.Unmarshal([]byte(val), &result))
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+
+		_, err = s.Remember(ctx, "test-key", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		boom := errors.New("boom")
+		_, err := s.Remember(ctx, "failing", time.Hour, func() (interface{}, error) {
+			return nil, boom
+		})
+		assert.ErrorIs(t, err, boom)
+
+		exists, _ := s.Has(ctx, "failing")
+		assert.False(t, exists)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := s.Remember(ctx, "nil-callback", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
+func TestStore_Pull(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "test-key", "test-value", time.Hour))
+
+	val, err := s.Pull(ctx, "test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-value", val)
+
+	exists, _ := s.Has(ctx, "test-key")
+	assert.False(t, exists)
+
+	_, err = s.Pull(ctx, "test-key")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestStore_ForeverForgetFlush(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Forever(ctx, "forever", "value"))
+	assert.True(t, s.items["forever"].expiresAt.IsZero())
+
+	require.NoError(t, s.Forget(ctx, "forever"))
+	exists, _ := s.Has(ctx, "forever")
+	assert.False(t, exists)
+
+	require.NoError(t, s.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, s.Forever(ctx, "key2", "value2"))
+	require.NoError(t, s.Flush(ctx))
+
+	exists1, _ := s.Has(ctx, "key1")
+	exists2, _ := s.Has(ctx, "key2")
+	assert.False(t, exists1)
+	assert.False(t, exists2)
+}
+
+func TestStore_Janitor(t *testing.T) {
+	s := New(Config{CleanupInterval: 5 * time.Millisecond})
+	defer s.Close()
+
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "short", "value", time.Millisecond))
+	require.NoError(t, s.Put(ctx, "long", "value", time.Hour))
+
+	assert.Eventually(t, func() bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		_, ok := s.items["short"]
+		return !ok
+	}, time.Second, 5*time.Millisecond)
+
+	_, err := s.Get(ctx, "long")
+	assert.NoError(t, err)
+
+	// Closing twice is safe
+	assert.NoError(t, s.Close())
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	s := setupTestStore(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = s.Put(ctx, "shared", "value", time.Hour)
+				_, _ = s.Get(ctx, "shared")
+				_ = s.Forget(ctx, "shared")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+// Package metrics exposes cache store and queue metrics to Prometheus
+package metrics
+
+import (
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultNamespace prefixes every metric name when Config does not set one
+const defaultNamespace = "cache"
+
+// Config configures a Collector
+type Config struct {
+	// Namespace prefixes every metric name, defaulting to "cache"
+	Namespace string
+
+	// DurationBuckets are the upper bounds of the operation latency
+	// histogram in seconds. Defaults to 100µs up to roughly 1.6s.
+	DurationBuckets []float64
+
+	// SizeBuckets are the upper bounds of the value size histogram in bytes.
+	// Defaults to 64 bytes up to 1MiB.
+	SizeBuckets []float64
+}
+
+// Collector records hits, misses, errors, latency and value sizes of the
+// stores it instruments, labelled by store name and operation
+type Collector struct {
+	hits     *prometheus.CounterVec
+	misses   *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// NewCollector creates a collector and registers its metrics with reg
+func NewCollector(reg prometheus.Registerer, cfg Config) (*Collector, error) {
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	durationBuckets := cfg.DurationBuckets
+	if len(durationBuckets) == 0 {
+		durationBuckets = prometheus.ExponentialBuckets(0.0001, 2, 15)
+	}
+	sizeBuckets := cfg.SizeBuckets
+	if len(sizeBuckets) == 0 {
+		sizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+	}
+
+	labels := []string{"store", "operation"}
+	c := &Collector{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "hits_total",
+			Help:      "Number of cache reads that found the key.",
+		}, labels),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "misses_total",
+			Help:      "Number of cache reads that did not find the key.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of cache operations that failed.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of cache operations.",
+			Buckets:   durationBuckets,
+		}, labels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "value_size_bytes",
+			Help:      "Size of the values read from and written to the cache.",
+			Buckets:   sizeBuckets,
+		}, labels),
+	}
+
+	for _, collector := range []prometheus.Collector{c.hits, c.misses, c.errors, c.duration, c.size} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Instrument wraps store so its operations are recorded under name
+func (c *Collector) Instrument(name string, store cache.Store) cache.Store {
+	return cache.Observe(store, cache.Events{
+		OnHit: func(e cache.Event) {
+			c.hits.WithLabelValues(name, e.Op).Inc()
+			c.observe(name, e)
+		},
+		OnMiss: func(e cache.Event) {
+			// Failed reads are errors rather than misses
+			if e.Err != nil {
+				c.observe(name, e)
+				return
+			}
+			c.misses.WithLabelValues(name, e.Op).Inc()
+			// A Remember miss is timed by the write that follows it
+			if e.Op != "remember" {
+				c.observe(name, e)
+			}
+		},
+		OnWrite: func(e cache.Event) {
+			c.observe(name, e)
+		},
+		OnForget: func(e cache.Event) {
+			// A Pull is timed as a read
+			if e.Op != "pull" {
+				c.observe(name, e)
+			}
+		},
+	})
+}
+
+// observe records the latency, value size and failure of an operation
+func (c *Collector) observe(name string, e cache.Event) {
+	c.duration.WithLabelValues(name, e.Op).Observe(e.Duration.Seconds())
+	if e.Size > 0 {
+		c.size.WithLabelValues(name, e.Op).Observe(float64(e.Size))
+	}
+	if e.Err != nil {
+		c.errors.WithLabelValues(name, e.Op).Inc()
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/memory"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleCount returns the number of observations made by a histogram
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var m dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+
+	reg := prometheus.NewRegistry()
+	collector, err := NewCollector(reg, Config{})
+	require.NoError(t, err)
+
+	backend := memory.New(memory.Config{})
+	defer backend.Close()
+	store := collector.Instrument("sessions", backend)
+
+	t.Run("hits and misses", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+		store.Get(ctx, "key")
+		store.Get(ctx, "key")
+		store.Get(ctx, "missing")
+
+		assert.Equal(t, 2.0, testutil.ToFloat64(collector.hits.WithLabelValues("sessions", "get")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(collector.misses.WithLabelValues("sessions", "get")))
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		callback := func() (interface{}, error) { return "computed", nil }
+		store.Remember(ctx, "remembered", time.Minute, callback)
+		store.Remember(ctx, "remembered", time.Minute, callback)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(collector.hits.WithLabelValues("sessions", "remember")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(collector.misses.WithLabelValues("sessions", "remember")))
+		assert.Equal(t, uint64(2), sampleCount(t, collector.duration.WithLabelValues("sessions", "remember")))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		store.Remember(ctx, "failing", time.Minute, func() (interface{}, error) {
+			return nil, errors.New("query failed")
+		})
+		assert.Equal(t, 1.0, testutil.ToFloat64(collector.errors.WithLabelValues("sessions", "remember")))
+	})
+
+	t.Run("registered metrics", func(t *testing.T) {
+		families, err := reg.Gather()
+		require.NoError(t, err)
+
+		var names []string
+		for _, family := range families {
+			names = append(names, family.GetName())
+		}
+		assert.ElementsMatch(t, []string{
+			"cache_hits_total",
+			"cache_misses_total",
+			"cache_errors_total",
+			"cache_operation_duration_seconds",
+			"cache_value_size_bytes",
+		}, names)
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		_, err := NewCollector(reg, Config{})
+		assert.Error(t, err)
+
+		_, err = NewCollector(reg, Config{Namespace: "other"})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/queue"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultQueueNamespace prefixes every queue metric name when
+	// QueueConfig does not set one
+	defaultQueueNamespace = "queue"
+
+	// defaultQueueTimeout bounds reading the stats of the queues on each
+	// scrape when QueueConfig does not set a timeout
+	defaultQueueTimeout = 5 * time.Second
+)
+
+// QueueConfig configures a QueueCollector
+type QueueConfig struct {
+	// Namespace prefixes every metric name, defaulting to "queue"
+	Namespace string
+
+	// Queues are the names of the queues to report. Defaults to the
+	// queue's default.
+	Queues []string
+
+	// Timeout bounds reading the stats of the queues on each scrape.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// QueueCollector reports the depth, in-flight count, completions, failures
+// and oldest job age of queues, labelled by queue name. The stats are read
+// from Redis on every scrape.
+type QueueCollector struct {
+	queue   *queue.Queue
+	queues  []string
+	timeout time.Duration
+
+	jobs      *prometheus.Desc
+	completed *prometheus.Desc
+	failed    *prometheus.Desc
+	oldestAge *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*QueueCollector)(nil)
+
+// NewQueueCollector creates a collector reporting the stats of q and
+// registers it with reg
+func NewQueueCollector(reg prometheus.Registerer, q *queue.Queue, cfg QueueConfig) (*QueueCollector, error) {
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = defaultQueueNamespace
+	}
+	queues := cfg.Queues
+	if len(queues) == 0 {
+		queues = []string{""}
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultQueueTimeout
+	}
+
+	c := &QueueCollector{
+		queue:   q,
+		queues:  queues,
+		timeout: timeout,
+		jobs: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "jobs"),
+			"Number of jobs in the queue, by state.", []string{"queue", "state"}, nil),
+		completed: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "jobs_completed_total"),
+			"Number of jobs that succeeded.", []string{"queue"}, nil),
+		failed: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "jobs_failed_total"),
+			"Number of jobs that ran out of attempts.", []string{"queue"}, nil),
+		oldestAge: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "oldest_job_age_seconds"),
+			"Time since the job at the head of the queue was dispatched.", []string{"queue"}, nil),
+	}
+	if err := reg.Register(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Describe sends the descriptors of the queue metrics
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.jobs
+	ch <- c.completed
+	ch <- c.failed
+	ch <- c.oldestAge
+}
+
+// Collect reads the stats of every queue and sends them as metrics. A queue
+// whose stats cannot be read is reported as an invalid metric, failing the
+// scrape.
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	for _, name := range c.queues {
+		stats, err := c.queue.Stats(ctx, name)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(c.jobs, err)
+			continue
+		}
+
+		for state, n := range map[string]int{
+			"ready":    stats.Ready,
+			"reserved": stats.Reserved,
+			"delayed":  stats.Delayed,
+			"failed":   stats.Failed,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.jobs, prometheus.GaugeValue, float64(n), stats.Queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(c.completed, prometheus.CounterValue, float64(stats.Completed), stats.Queue)
+		ch <- prometheus.MustNewConstMetric(c.failed, prometheus.CounterValue, float64(stats.Failures), stats.Queue)
+		ch <- prometheus.MustNewConstMetric(c.oldestAge, prometheus.GaugeValue, stats.OldestAge.Seconds(), stats.Queue)
+	}
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nanaaikinson/gofacades/queue"
+	"github.com/nanaaikinson/gofacades/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueCollector(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	defer client.Close()
+
+	q, err := queue.New(ctx, client, queue.Config{})
+	require.NoError(t, err)
+	for _, name := range []string{"default", "default", "mail"} {
+		_, err := q.Dispatch(ctx, queue.Job{Name: "work", Queue: name})
+		require.NoError(t, err)
+	}
+
+	reg := prometheus.NewRegistry()
+	collector, err := NewQueueCollector(reg, q, QueueConfig{Queues: []string{"default", "mail"}})
+	require.NoError(t, err)
+
+	t.Run("reports every queue", func(t *testing.T) {
+		expected := `
+# HELP queue_jobs Number of jobs in the queue, by state.
+# TYPE queue_jobs gauge
+queue_jobs{queue="default",state="delayed"} 0
+queue_jobs{queue="default",state="failed"} 0
+queue_jobs{queue="default",state="ready"} 2
+queue_jobs{queue="default",state="reserved"} 0
+queue_jobs{queue="mail",state="delayed"} 0
+queue_jobs{queue="mail",state="failed"} 0
+queue_jobs{queue="mail",state="ready"} 1
+queue_jobs{queue="mail",state="reserved"} 0
+# HELP queue_jobs_completed_total Number of jobs that succeeded.
+# TYPE queue_jobs_completed_total counter
+queue_jobs_completed_total{queue="default"} 0
+queue_jobs_completed_total{queue="mail"} 0
+`
+		require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+			"queue_jobs", "queue_jobs_completed_total"))
+		assert.Equal(t, 2*(4+3), testutil.CollectAndCount(collector))
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		_, err := NewQueueCollector(reg, q, QueueConfig{})
+		assert.Error(t, err)
+
+		_, err = NewQueueCollector(reg, q, QueueConfig{Namespace: "jobs"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unreachable Redis", func(t *testing.T) {
+		mr.Close()
+		_, err := reg.Gather()
+		assert.Error(t, err)
+	})
+}
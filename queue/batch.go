@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// batchTTL is how long a batch's progress is kept after it is created
+const batchTTL = 7 * 24 * time.Hour
+
+// Batch is a group of jobs dispatched together, with follow-up jobs run
+// once they have all succeeded or as soon as one fails. Follow-ups are jobs
+// rather than functions because they run on whichever worker finishes the
+// batch, possibly in another process.
+type Batch struct {
+	queue       *Queue
+	jobs        []Job
+	then, catch *Job
+}
+
+// followUp is a follow-up job as stored with its batch
+type followUp struct {
+	Queue  string `json:"queue"`
+	Record record `json:"record"`
+}
+
+// BatchStatus reports the progress of a batch. Pending counts the jobs
+// that have neither succeeded nor failed yet.
+type BatchStatus struct {
+	ID        string
+	Total     int
+	Pending   int
+	Failed    int
+	CreatedAt time.Time
+}
+
+// Finished reports whether every job of the batch has succeeded or failed
+func (s BatchStatus) Finished() bool {
+	return s.Pending == 0
+}
+
+// Batch returns a batch of jobs, dispatched with Dispatch
+func (q *Queue) Batch(jobs ...Job) *Batch {
+	return &Batch{queue: q, jobs: jobs}
+}
+
+// Then sets the job dispatched once every job of the batch has succeeded,
+// including jobs retried after failing
+func (b *Batch) Then(job Job) *Batch {
+	b.then = &job
+	return b
+}
+
+// Catch sets the job dispatched when a job of the batch first fails for
+// good, after its last attempt
+func (b *Batch) Catch(job Job) *Batch {
+	b.catch = &job
+	return b
+}
+
+// Dispatch records the batch and pushes its jobs onto their queues,
+// returning the batch ID. Handlers find it in ReservedJob.BatchID. A batch
+// without jobs dispatches its Then job at once. Should a job fail to be
+// dispatched, the error is returned; the jobs already dispatched still run,
+// but the batch never succeeds.
+func (b *Batch) Dispatch(ctx context.Context) (string, error) {
+	q := b.queue
+	then, err := q.encodeFollowUp(b.then)
+	if err != nil {
+		return "", err
+	}
+	catch, err := q.encodeFollowUp(b.catch)
+	if err != nil {
+		return "", err
+	}
+
+	records := make([]record, len(b.jobs))
+	for i, job := range b.jobs {
+		if records[i], err = q.newRecord(job); err != nil {
+			return "", err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	_, err = q.client.RunScript(ctx, createBatchScriptName, []string{q.batchKey(id)},
+		len(b.jobs), then, catch, q.now().UnixMilli(), batchTTL.Milliseconds())
+	if err != nil {
+		return "", fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	if len(b.jobs) == 0 && b.then != nil {
+		if _, err := q.Dispatch(ctx, *b.then); err != nil {
+			return "", err
+		}
+	}
+	for i, job := range b.jobs {
+		records[i].BatchID = id
+		if _, err := q.push(ctx, job, records[i], time.Time{}); err != nil {
+			return "", fmt.Errorf("failed to dispatch batch %s: %w", id, err)
+		}
+	}
+	return id, nil
+}
+
+// encodeFollowUp encodes a follow-up job for storage with its batch, or
+// returns an empty string when there is none
+func (q *Queue) encodeFollowUp(job *Job) (string, error) {
+	if job == nil {
+		return "", nil
+	}
+	rec, err := q.newRecord(*job)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(followUp{Queue: job.Queue, Record: rec})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FindBatch returns the progress of the batch with the given ID
+func (q *Queue) FindBatch(ctx context.Context, id string) (BatchStatus, error) {
+	reply, err := q.client.RunScript(ctx, batchStatusScriptName, []string{q.batchKey(id)})
+	if err != nil {
+		return BatchStatus{}, err
+	}
+	if reply == nil {
+		return BatchStatus{}, ErrBatchNotFound
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 4 {
+		return BatchStatus{}, fmt.Errorf("unexpected batch status script reply: %v", reply)
+	}
+	var n [4]int64
+	for i, value := range values {
+		n[i], _ = value.(int64)
+	}
+	return BatchStatus{
+		ID:        id,
+		Total:     int(n[0]),
+		Pending:   int(n[1]),
+		Failed:    int(n[2]),
+		CreatedAt: time.UnixMilli(n[3]),
+	}, nil
+}
+
+// batchDone records the outcome of a job of the batch with the given ID and
+// dispatches the follow-up job it triggers, if any
+func (q *Queue) batchDone(ctx context.Context, batchID, jobID string, succeeded bool) error {
+	script := batchFailScriptName
+	if succeeded {
+		script = batchSucceedScriptName
+	}
+	reply, err := q.client.RunScript(ctx, script, []string{q.batchKey(batchID)}, jobID)
+	if err != nil {
+		return err
+	}
+	data, _ := reply.(string)
+	if data == "" {
+		return nil
+	}
+
+	var next followUp
+	if err := json.Unmarshal([]byte(data), &next); err != nil {
+		return err
+	}
+	_, err = q.push(ctx, Job{Queue: next.Queue}, next.Record, time.Time{})
+	return err
+}
+
+// batchKey returns the key the batch with the given ID is kept under
+func (q *Queue) batchKey(id string) string {
+	return q.prefix + "batch:" + id
+}
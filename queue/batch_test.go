@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain reserves and processes jobs until the queue is empty, returning the
+// names of the jobs processed
+func drain(t *testing.T, w *Worker, handlers Handlers) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	var names []string
+	for {
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		if job == nil {
+			return names
+		}
+		names = append(names, job.Name)
+		w.process(ctx, job, handlers)
+	}
+}
+
+func TestBatch_Then(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueue(t, Config{})
+
+	id, err := q.Batch(
+		Job{Name: "resize", Payload: 1},
+		Job{Name: "resize", Payload: 2},
+	).Then(Job{Name: "done", Payload: "all resized"}).
+		Catch(Job{Name: "failed"}).
+		Dispatch(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	var batchIDs []string
+	var message string
+	handlers := Handlers{
+		"resize": func(_ context.Context, job *ReservedJob) error {
+			batchIDs = append(batchIDs, job.BatchID)
+			return nil
+		},
+		"done": func(_ context.Context, job *ReservedJob) error {
+			assert.Empty(t, job.BatchID)
+			return job.Decode(&message)
+		},
+	}
+
+	names := drain(t, q.Worker(WorkerConfig{}), handlers)
+	assert.Equal(t, []string{"resize", "resize", "done"}, names)
+	assert.Equal(t, []string{id, id}, batchIDs)
+	assert.Equal(t, "all resized", message)
+
+	status, err := q.FindBatch(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Total)
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, 0, status.Failed)
+	assert.True(t, status.Finished())
+}
+
+func TestBatch_Catch(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueue(t, Config{})
+
+	id, err := q.Batch(
+		Job{Name: "broken"},
+		Job{Name: "broken"},
+		Job{Name: "ok"},
+	).Then(Job{Name: "done"}).
+		Catch(Job{Name: "failed", Queue: "alerts"}).
+		Dispatch(ctx)
+	require.NoError(t, err)
+
+	handlers := Handlers{
+		"broken": func(context.Context, *ReservedJob) error { return errors.New("broken") },
+		"ok":     func(context.Context, *ReservedJob) error { return nil },
+	}
+	names := drain(t, q.Worker(WorkerConfig{MaxAttempts: 1}), handlers)
+	assert.Equal(t, []string{"broken", "broken", "ok"}, names)
+
+	// Catch is dispatched once, on the first failure
+	names = drain(t, q.Worker(WorkerConfig{Queue: "alerts"}), Handlers{
+		"failed": func(context.Context, *ReservedJob) error { return nil },
+	})
+	assert.Equal(t, []string{"failed"}, names)
+
+	status, err := q.FindBatch(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Total)
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, 2, status.Failed)
+	assert.True(t, status.Finished())
+}
+
+func TestBatch_RetriedFailure(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueue(t, Config{})
+
+	id, err := q.Batch(Job{Name: "flaky"}, Job{Name: "ok"}).
+		Then(Job{Name: "done"}).
+		Dispatch(ctx)
+	require.NoError(t, err)
+
+	fail := true
+	done := 0
+	handlers := Handlers{
+		"flaky": func(context.Context, *ReservedJob) error {
+			if fail {
+				return errors.New("flaky")
+			}
+			return nil
+		},
+		"ok": func(context.Context, *ReservedJob) error { return nil },
+		"done": func(context.Context, *ReservedJob) error {
+			done++
+			return nil
+		},
+	}
+	w := q.Worker(WorkerConfig{MaxAttempts: 1})
+	drain(t, w, handlers)
+	assert.Equal(t, 0, done)
+
+	status, err := q.FindBatch(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Failed)
+
+	fail = false
+	n, err := q.RetryAll(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	drain(t, w, handlers)
+	assert.Equal(t, 1, done)
+
+	status, err = q.FindBatch(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Pending)
+	assert.Equal(t, 0, status.Failed)
+}
+
+func TestBatch_Empty(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueue(t, Config{})
+	q.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	id, err := q.Batch().Then(Job{Name: "done"}).Dispatch(ctx)
+	require.NoError(t, err)
+
+	names := drain(t, q.Worker(WorkerConfig{}), Handlers{
+		"done": func(context.Context, *ReservedJob) error { return nil },
+	})
+	assert.Equal(t, []string{"done"}, names)
+
+	status, err := q.FindBatch(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.Total)
+	assert.True(t, status.Finished())
+	assert.Equal(t, time.UnixMilli(1700000000000), status.CreatedAt)
+}
+
+func TestBatch_Errors(t *testing.T) {
+	ctx := context.Background()
+	q, mr := setupQueue(t, Config{})
+
+	_, err := q.FindBatch(ctx, "missing")
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+
+	_, err = q.Batch(Job{Name: "ok"}, Job{}).Dispatch(ctx)
+	assert.ErrorIs(t, err, ErrInvalidJob)
+	_, err = q.Batch().Then(Job{}).Dispatch(ctx)
+	assert.ErrorIs(t, err, ErrInvalidJob)
+	assert.Empty(t, mr.Keys())
+
+	id, err := q.Batch(Job{Name: "ok"}).Dispatch(ctx)
+	require.NoError(t, err)
+	mr.FastForward(batchTTL)
+	_, err = q.FindBatch(ctx, id)
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+}
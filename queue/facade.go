@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrNoDefaultQueue = errors.New("no default queue configured, call queue.SetDefault first")
+
+var (
+	defaultMu       sync.RWMutex
+	defaultInstance *Queue
+)
+
+// SetDefault sets the queue used by the package-level queue functions
+func SetDefault(q *Queue) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultInstance = q
+}
+
+// Default returns the queue used by the package-level queue functions
+func Default() (*Queue, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	if defaultInstance == nil {
+		return nil, ErrNoDefaultQueue
+	}
+	return defaultInstance, nil
+}
+
+// Dispatch pushes job onto the default queue and returns its ID
+func Dispatch(ctx context.Context, job Job) (string, error) {
+	q, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return q.Dispatch(ctx, job)
+}
+
+// DispatchAfter pushes job onto the default queue once delay has passed
+func DispatchAfter(ctx context.Context, job Job, delay time.Duration) (string, error) {
+	q, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return q.DispatchAfter(ctx, job, delay)
+}
+
+// DispatchAt pushes job onto the default queue at the given time
+func DispatchAt(ctx context.Context, job Job, at time.Time) (string, error) {
+	q, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return q.DispatchAt(ctx, job, at)
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacade(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	ctx := context.Background()
+
+	SetDefault(nil)
+	_, err := Dispatch(ctx, Job{Name: "send-email"})
+	assert.Equal(t, ErrNoDefaultQueue, err)
+	_, err = DispatchAfter(ctx, Job{Name: "send-email"}, time.Minute)
+	assert.Equal(t, ErrNoDefaultQueue, err)
+	_, err = DispatchAt(ctx, Job{Name: "send-email"}, time.Now())
+	assert.Equal(t, ErrNoDefaultQueue, err)
+
+	q, mr := setupQueue(t, Config{})
+	SetDefault(q)
+	id, err := Dispatch(ctx, Job{Name: "send-email"})
+	require.NoError(t, err)
+
+	ids, err := mr.List("app:queue:{default}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{id}, ids)
+
+	later, err := DispatchAfter(ctx, Job{Name: "send-email"}, time.Minute)
+	require.NoError(t, err)
+	at, err := DispatchAt(ctx, Job{Name: "send-email"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	members, err := mr.ZMembers("app:queue:{default}:delayed")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{later, at}, members)
+}
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// FailedJob is a job moved to the failed jobs after its last attempt
+type FailedJob struct {
+	ID           string
+	Name         string
+	Queue        string
+	Payload      []byte
+	Attempts     int
+	Error        string
+	DispatchedAt time.Time
+	FailedAt     time.Time
+}
+
+// Failed returns up to limit failed jobs of the named queue, or of the
+// default queue, most recent first, skipping the first offset
+func (q *Queue) Failed(ctx context.Context, queue string, offset, limit int) ([]FailedJob, error) {
+	if queue == "" {
+		queue = q.queue
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	k := q.keys(queue)
+	reply, err := q.client.RunScript(ctx, failedScriptName,
+		[]string{k.failed, k.jobs, k.attempts, k.errors}, offset, offset+limit-1)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values)%5 != 0 {
+		return nil, fmt.Errorf("unexpected failed jobs script reply: %v", reply)
+	}
+
+	jobs := make([]FailedJob, 0, len(values)/5)
+	for i := 0; i < len(values); i += 5 {
+		id, _ := values[i].(string)
+		data, _ := values[i+1].(string)
+		attempts, _ := values[i+2].(int64)
+		message, _ := values[i+3].(string)
+		failedAt, _ := values[i+4].(int64)
+
+		var rec record
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			return nil, &redis.DecodeError{Key: id, Err: err}
+		}
+		jobs = append(jobs, FailedJob{
+			ID:           id,
+			Name:         rec.Name,
+			Queue:        queue,
+			Payload:      rec.Payload,
+			Attempts:     int(attempts),
+			Error:        message,
+			DispatchedAt: time.UnixMilli(rec.DispatchedAt),
+			FailedAt:     time.UnixMilli(failedAt),
+		})
+	}
+	return jobs, nil
+}
+
+// Retry pushes the failed job with the given ID back onto its queue, with
+// its attempts reset
+func (q *Queue) Retry(ctx context.Context, queue, id string) error {
+	n, err := q.retry(ctx, queue, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// RetryAll pushes every failed job of the named queue back onto it and
+// returns how many were retried
+func (q *Queue) RetryAll(ctx context.Context, queue string) (int, error) {
+	return q.retry(ctx, queue)
+}
+
+// retry pushes the failed jobs with the given IDs, or every failed job,
+// back onto the queue
+func (q *Queue) retry(ctx context.Context, queue string, ids ...interface{}) (int, error) {
+	k := q.keys(queue)
+	reply, err := q.client.RunScript(ctx, retryScriptName, []string{k.failed, k.errors, k.attempts, k.ready}, ids...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
+
+// ForgetFailed deletes the failed job with the given ID
+func (q *Queue) ForgetFailed(ctx context.Context, queue, id string) error {
+	n, err := q.forget(ctx, queue, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// FlushFailed deletes every failed job of the named queue and returns how
+// many were deleted
+func (q *Queue) FlushFailed(ctx context.Context, queue string) (int, error) {
+	return q.forget(ctx, queue)
+}
+
+// forget deletes the failed jobs with the given IDs, or every failed job
+func (q *Queue) forget(ctx context.Context, queue string, ids ...interface{}) (int, error) {
+	k := q.keys(queue)
+	reply, err := q.client.RunScript(ctx, forgetScriptName, []string{k.failed, k.errors, k.attempts, k.jobs}, ids...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failJobs dispatches a job for each name and runs it to failure, returning
+// their IDs
+func failJobs(t *testing.T, q *Queue, names ...string) []string {
+	t.Helper()
+	ctx := context.Background()
+	w := q.Worker(WorkerConfig{MaxAttempts: 1})
+
+	var ids []string
+	for _, name := range names {
+		id, err := q.Dispatch(ctx, Job{Name: name, Payload: name})
+		require.NoError(t, err)
+		ids = append(ids, id)
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		w.process(ctx, job, Handlers{name: func(context.Context, *ReservedJob) error {
+			return errors.New(name + " failed")
+		}})
+	}
+	return ids
+}
+
+func TestQueue_Failed(t *testing.T) {
+	ctx := context.Background()
+	q, _ := setupQueue(t, Config{})
+	now := time.UnixMilli(1700000000000)
+	q.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	ids := failJobs(t, q, "first", "second", "third")
+
+	jobs, err := q.Failed(ctx, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, ids[2], jobs[0].ID)
+	assert.Equal(t, "third", jobs[0].Name)
+	assert.Equal(t, "default", jobs[0].Queue)
+	assert.Equal(t, `"third"`, string(jobs[0].Payload))
+	assert.Equal(t, 1, jobs[0].Attempts)
+	assert.Equal(t, "third failed", jobs[0].Error)
+	assert.True(t, jobs[0].FailedAt.After(jobs[1].FailedAt))
+	assert.True(t, jobs[0].FailedAt.After(jobs[0].DispatchedAt))
+
+	page, err := q.Failed(ctx, "", 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[1], page[0].ID)
+
+	page, err = q.Failed(ctx, "", 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	page, err = q.Failed(ctx, "other", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestQueue_Retry(t *testing.T) {
+	ctx := context.Background()
+	q, mr := setupQueue(t, Config{})
+	ids := failJobs(t, q, "first", "second", "third")
+
+	require.NoError(t, q.Retry(ctx, "", ids[1]))
+	assert.ErrorIs(t, q.Retry(ctx, "", ids[1]), ErrJobNotFound)
+
+	ready, err := mr.List("app:queue:{default}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ids[1]}, ready)
+
+	// Retried jobs start over with their attempts
+	job, err := q.Worker(WorkerConfig{}).reserve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ids[1], job.ID)
+	assert.Equal(t, 1, job.Attempts)
+
+	n, err := q.RetryAll(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	ready, err = mr.List("app:queue:{default}")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{ids[0], ids[2]}, ready)
+
+	jobs, err := q.Failed(ctx, "", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestQueue_ForgetFailed(t *testing.T) {
+	ctx := context.Background()
+	q, mr := setupQueue(t, Config{})
+	ids := failJobs(t, q, "first", "second", "third")
+
+	require.NoError(t, q.ForgetFailed(ctx, "", ids[0]))
+	assert.ErrorIs(t, q.ForgetFailed(ctx, "", ids[0]), ErrJobNotFound)
+	assert.Empty(t, mr.HGet("app:queue:{default}:jobs", ids[0]))
+
+	n, err := q.FlushFailed(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, key := range []string{"jobs", "attempts", "failed", "errors"} {
+		assert.False(t, mr.Exists("app:queue:{default}:"+key), key)
+	}
+}
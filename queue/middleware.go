@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// overlapLockTTL is how long a WithoutOverlapping lock outlives a worker
+	// that died holding it. Running jobs keep renewing it.
+	overlapLockTTL = 30 * time.Second
+
+	// defaultReleaseAfter is how long an overlapping job waits before it is
+	// run again when OverlapConfig does not set a delay
+	defaultReleaseAfter = 10 * time.Second
+)
+
+// Middleware wraps a handler, to run code around the jobs it processes
+type Middleware func(next Handler) Handler
+
+// releaseError puts a job back onto the queue without counting the attempt
+type releaseError struct {
+	delay time.Duration
+}
+
+func (e *releaseError) Error() string {
+	return fmt.Sprintf("job released for %s", e.delay)
+}
+
+// Release returns an error that, returned by a handler, puts the job back
+// onto its queue to be run again after delay, without counting the attempt
+func Release(delay time.Duration) error {
+	return &releaseError{delay: delay}
+}
+
+// OverlapConfig configures WithoutOverlapping
+type OverlapConfig struct {
+	// Key returns the key jobs must not overlap on. Defaults to the job
+	// name, so no two jobs of the same name run at once.
+	Key func(job *ReservedJob) string
+
+	// ReleaseAfter is how long a job that would overlap waits before it is
+	// run again. Defaults to 10 seconds.
+	ReleaseAfter time.Duration
+}
+
+// WithoutOverlapping returns middleware running at most one job per key at
+// a time, across every worker. A job whose key is held by another running
+// job is released back onto the queue, without counting the attempt.
+func (q *Queue) WithoutOverlapping(cfg OverlapConfig) Middleware {
+	keyFunc := cfg.Key
+	if keyFunc == nil {
+		keyFunc = func(job *ReservedJob) string { return job.Name }
+	}
+	releaseAfter := cfg.ReleaseAfter
+	if releaseAfter <= 0 {
+		releaseAfter = defaultReleaseAfter
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job *ReservedJob) error {
+			lock := q.client.Lock(q.prefix+"overlap:"+keyFunc(job), overlapLockTTL).WithAutoRenew()
+			acquired, err := lock.Acquire(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to lock job: %w", err)
+			}
+			if !acquired {
+				return Release(releaseAfter)
+			}
+			defer lock.Release(context.WithoutCancel(ctx))
+
+			return next(ctx, job)
+		}
+	}
+}
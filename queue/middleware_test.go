@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease(t *testing.T) {
+	ctx := context.Background()
+	q, mr := setupQueue(t, Config{})
+	now := time.UnixMilli(1700000000000)
+	q.now = func() time.Time { return now }
+	w := q.Worker(WorkerConfig{MaxAttempts: 1})
+
+	id, err := q.Dispatch(ctx, Job{Name: "sync"})
+	require.NoError(t, err)
+
+	job, err := w.reserve(ctx)
+	require.NoError(t, err)
+	w.process(ctx, job, Handlers{"sync": func(context.Context, *ReservedJob) error {
+		return fmt.Errorf("not yet: %w", Release(time.Minute))
+	}})
+
+	// Released jobs are not failed and keep their attempts
+	score, err := mr.ZScore("app:queue:{default}:delayed", id)
+	require.NoError(t, err)
+	assert.Equal(t, float64(now.Add(time.Minute).UnixMilli()), score)
+	assert.False(t, mr.Exists("app:queue:{default}:failed"))
+
+	now = now.Add(time.Minute)
+	job, err = w.reserve(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 1, job.Attempts)
+}
+
+func TestQueue_WithoutOverlapping(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("releases jobs whose key is taken", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.UnixMilli(1700000000000)
+		q.now = func() time.Time { return now }
+		w := q.Worker(WorkerConfig{})
+
+		runs := 0
+		handler := q.WithoutOverlapping(OverlapConfig{
+			Key:          func(job *ReservedJob) string { return "account:42" },
+			ReleaseAfter: time.Second,
+		})(func(ctx context.Context, job *ReservedJob) error {
+			runs++
+			assert.True(t, mr.Exists("app:queue:overlap:account:42"))
+			return nil
+		})
+
+		// Another worker is running a job for the account
+		running := q.client.Lock("queue:overlap:account:42", time.Minute)
+		acquired, err := running.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		id, err := q.Dispatch(ctx, Job{Name: "sync"})
+		require.NoError(t, err)
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		w.process(ctx, job, Handlers{"sync": handler})
+		assert.Zero(t, runs)
+		score, err := mr.ZScore("app:queue:{default}:delayed", id)
+		require.NoError(t, err)
+		assert.Equal(t, float64(now.Add(time.Second).UnixMilli()), score)
+
+		_, err = running.Release(ctx)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		job, err = w.reserve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, job.Attempts)
+		w.process(ctx, job, Handlers{"sync": handler})
+		assert.Equal(t, 1, runs)
+
+		// The lock is freed once the job is done
+		assert.False(t, mr.Exists("app:queue:overlap:account:42"))
+	})
+
+	t.Run("keys on the job name by default", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+
+		handler := q.WithoutOverlapping(OverlapConfig{})(func(ctx context.Context, job *ReservedJob) error {
+			assert.True(t, mr.Exists("app:queue:overlap:sync"))
+			return errors.New("failed")
+		})
+		err := handler(ctx, &ReservedJob{Name: "sync"})
+		assert.EqualError(t, err, "failed")
+		assert.False(t, mr.Exists("app:queue:overlap:sync"))
+	})
+}
@@ -0,0 +1,305 @@
+// Package queue pushes jobs onto Redis-backed queues and runs workers
+// processing them, in the manner of Laravel's Queue facade
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+const (
+	// defaultPrefix namespaces queue keys when Config does not set a prefix
+	defaultPrefix = "queue:"
+
+	// defaultQueue is the queue jobs are pushed onto when neither the job
+	// nor Config names one
+	defaultQueue = "default"
+
+	// promoteBatch bounds how many due delayed jobs are moved to the ready
+	// list at a time
+	promoteBatch = 100
+
+	// defaultUniqueFor bounds how long a unique job holds its key when the
+	// job does not set UniqueFor
+	defaultUniqueFor = 24 * time.Hour
+)
+
+var (
+	// ErrNoHandler is reported for jobs no handler is registered for
+	ErrNoHandler = errors.New("no handler registered for job")
+
+	// ErrInvalidJob is returned when dispatching a job without a name
+	ErrInvalidJob = errors.New("job name is required")
+
+	// ErrMaxAttemptsExceeded is reported for jobs reserved again after their
+	// last attempt, because the worker running it died or timed out
+	ErrMaxAttemptsExceeded = errors.New("job has been attempted too many times")
+
+	// ErrJobNotFound is returned when retrying or forgetting a failed job
+	// that does not exist
+	ErrJobNotFound = errors.New("failed job not found")
+
+	// ErrDuplicateJob is returned when dispatching a unique job while
+	// another job with the same key is queued or running
+	ErrDuplicateJob = errors.New("a job with this unique key is already queued")
+
+	// ErrBatchNotFound is returned when looking up a batch that does not
+	// exist or has expired
+	ErrBatchNotFound = errors.New("batch not found")
+)
+
+// Config configures a Queue
+type Config struct {
+	// Prefix namespaces the queue keys, within the client's own prefix.
+	// Defaults to "queue:".
+	Prefix string
+
+	// Queue is the queue jobs are pushed onto and workers read when none is
+	// named. Defaults to "default".
+	Queue string
+}
+
+// Job is a unit of work to push onto a queue
+type Job struct {
+	// Name selects the handler the job is passed to
+	Name string
+
+	// Payload is encoded with the client's codec and decoded by the handler
+	Payload interface{}
+
+	// Queue is the queue the job is pushed onto. Defaults to Config.Queue.
+	Queue string
+
+	// MaxAttempts is how often the job is attempted before it is moved to
+	// the failed jobs. Defaults to the worker's MaxAttempts.
+	MaxAttempts int
+
+	// Backoff is how long to wait before retrying the job after its first
+	// failure, doubling with each further failure. Defaults to the worker's
+	// Backoff.
+	Backoff time.Duration
+
+	// UniqueKey, when set, makes the job unique: dispatching it fails with
+	// ErrDuplicateJob until the job with the same key has completed or run
+	// out of attempts
+	UniqueKey string
+
+	// UniqueFor bounds how long a unique job holds its key, should it be
+	// lost. Defaults to one day.
+	UniqueFor time.Duration
+
+	// batchID is the batch the job belongs to, set by Batch
+	batchID string
+}
+
+// record is a job as stored in Redis
+type record struct {
+	Name         string `json:"name"`
+	Payload      []byte `json:"payload"`
+	DispatchedAt int64  `json:"dispatched_at"`
+	MaxAttempts  int    `json:"max_attempts,omitempty"`
+	Backoff      int64  `json:"backoff,omitempty"`
+	UniqueKey    string `json:"unique_key,omitempty"`
+	UniqueOwner  string `json:"unique_owner,omitempty"`
+	BatchID      string `json:"batch_id,omitempty"`
+}
+
+// Queue pushes jobs onto queues kept in Redis
+type Queue struct {
+	client *redis.Client
+	prefix string
+	queue  string
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// New returns a queue keeping its jobs with client, registering its scripts.
+// Reservations are timed with the clocks of the application instances,
+// which should be kept in sync.
+func New(ctx context.Context, client *redis.Client, cfg Config) (*Queue, error) {
+	q := &Queue{client: client, prefix: cfg.Prefix, queue: cfg.Queue, now: time.Now}
+	if q.prefix == "" {
+		q.prefix = defaultPrefix
+	}
+	if q.queue == "" {
+		q.queue = defaultQueue
+	}
+
+	for name, src := range map[string]string{
+		dispatchScriptName: dispatchScript,
+		reserveScriptName:  reserveScript,
+		deleteScriptName:   deleteScript,
+		releaseScriptName:  releaseScript,
+		failScriptName:     failScript,
+		statsScriptName:    statsScript,
+		failedScriptName:   failedScript,
+		retryScriptName:    retryScript,
+		forgetScriptName:   forgetScript,
+
+		createBatchScriptName:  createBatchScript,
+		batchSucceedScriptName: batchSucceedScript,
+		batchFailScriptName:    batchFailScript,
+		batchStatusScriptName:  batchStatusScript,
+	} {
+		if err := client.RegisterScript(ctx, name, src); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// keys names the Redis keys a queue is kept under
+type keys struct {
+	ready, reserved, jobs, attempts, delayed, failed, errors, stats string
+}
+
+// keys returns the keys of the named queue, or of the default queue
+func (q *Queue) keys(name string) keys {
+	if name == "" {
+		name = q.queue
+	}
+	base := q.prefix + "{" + name + "}"
+	return keys{
+		ready:    base,
+		reserved: base + ":reserved",
+		jobs:     base + ":jobs",
+		attempts: base + ":attempts",
+		delayed:  base + ":delayed",
+		failed:   base + ":failed",
+		errors:   base + ":errors",
+		stats:    base + ":stats",
+	}
+}
+
+// Dispatch pushes job onto its queue and returns its ID
+func (q *Queue) Dispatch(ctx context.Context, job Job) (string, error) {
+	return q.dispatch(ctx, job, time.Time{})
+}
+
+// DispatchAfter pushes job onto its queue once delay has passed and returns
+// its ID. A delay that is not positive dispatches the job at once.
+func (q *Queue) DispatchAfter(ctx context.Context, job Job, delay time.Duration) (string, error) {
+	if delay <= 0 {
+		return q.dispatch(ctx, job, time.Time{})
+	}
+	return q.dispatch(ctx, job, q.now().Add(delay))
+}
+
+// DispatchAt pushes job onto its queue at the given time and returns its
+// ID. A time in the past dispatches the job at once.
+func (q *Queue) DispatchAt(ctx context.Context, job Job, at time.Time) (string, error) {
+	if !at.After(q.now()) {
+		return q.dispatch(ctx, job, time.Time{})
+	}
+	return q.dispatch(ctx, job, at)
+}
+
+// dispatch stores job, pushing it onto its queue at once when due is zero
+// or delaying it until due. Delayed jobs are moved to the ready list by the
+// workers of their queue.
+func (q *Queue) dispatch(ctx context.Context, job Job, due time.Time) (string, error) {
+	rec, err := q.newRecord(job)
+	if err != nil {
+		return "", err
+	}
+	return q.push(ctx, job, rec, due)
+}
+
+// newRecord encodes job for storage
+func (q *Queue) newRecord(job Job) (record, error) {
+	if job.Name == "" {
+		return record{}, ErrInvalidJob
+	}
+
+	payload, err := q.client.Codec().Marshal(job.Payload)
+	if err != nil {
+		return record{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return record{
+		Name:        job.Name,
+		Payload:     payload,
+		MaxAttempts: job.MaxAttempts,
+		Backoff:     job.Backoff.Milliseconds(),
+		BatchID:     job.batchID,
+	}, nil
+}
+
+// push stores rec under a new ID on the queue named by job, taking the
+// job's unique key if it has one
+func (q *Queue) push(ctx context.Context, job Job, rec record, due time.Time) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	rec.DispatchedAt = q.now().UnixMilli()
+
+	if job.UniqueKey != "" {
+		lock, lockErr := q.lockUnique(ctx, job)
+		if lockErr != nil {
+			return "", lockErr
+		}
+		rec.UniqueKey, rec.UniqueOwner = job.UniqueKey, lock.Owner()
+		defer func() {
+			// Free the key should the job not be queued after all
+			if err != nil {
+				lock.Release(context.WithoutCancel(ctx))
+			}
+		}()
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+
+	var dueMs int64
+	if !due.IsZero() {
+		dueMs = due.UnixMilli()
+	}
+
+	k := q.keys(job.Queue)
+	if _, err = q.client.RunScript(ctx, dispatchScriptName, []string{k.ready, k.jobs, k.delayed}, id, data, dueMs); err != nil {
+		return "", fmt.Errorf("failed to dispatch job: %w", err)
+	}
+	return id, nil
+}
+
+// lockUnique takes the lock on a unique job's key
+func (q *Queue) lockUnique(ctx context.Context, job Job) (*redis.Lock, error) {
+	ttl := job.UniqueFor
+	if ttl <= 0 {
+		ttl = defaultUniqueFor
+	}
+
+	lock := q.client.Lock(q.uniqueLock(job.UniqueKey), ttl)
+	acquired, err := lock.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock unique job: %w", err)
+	}
+	if !acquired {
+		return nil, ErrDuplicateJob
+	}
+	return lock, nil
+}
+
+// uniqueLock returns the name of the lock held by unique jobs with key
+func (q *Queue) uniqueLock(key string) string {
+	return q.prefix + "unique:" + key
+}
+
+// newID returns a random job ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
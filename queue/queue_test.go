@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// setupQueue creates a mock Redis server and a queue using it
+func setupQueue(t *testing.T, cfg Config) (*Queue, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	q, err := New(context.Background(), client, cfg)
+	require.NoError(t, err)
+	return q, mr
+}
+
+// storedRecord reads the record of the job with the given ID from mr
+func storedRecord(t *testing.T, mr *miniredis.Miniredis, queue, id string) record {
+	t.Helper()
+	var rec record
+	require.NoError(t, json.Unmarshal([]byte(mr.HGet("app:queue:{"+queue+"}:jobs", id)), &rec))
+	return rec
+}
+
+func TestQueue_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pushes jobs onto the default queue", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		q.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+		first, err := q.Dispatch(ctx, Job{Name: "send-email", Payload: map[string]string{"to": "ada@example.com"}})
+		require.NoError(t, err)
+		second, err := q.Dispatch(ctx, Job{Name: "send-email"})
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+
+		ids, err := mr.List("app:queue:{default}")
+		require.NoError(t, err)
+		assert.Equal(t, []string{first, second}, ids)
+
+		rec := storedRecord(t, mr, "default", first)
+		assert.Equal(t, "send-email", rec.Name)
+		assert.JSONEq(t, `{"to":"ada@example.com"}`, string(rec.Payload))
+		assert.Equal(t, int64(1700000000000), rec.DispatchedAt)
+	})
+
+	t.Run("named queues", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{Prefix: "jobs:", Queue: "mail"})
+
+		_, err := q.Dispatch(ctx, Job{Name: "send-email"})
+		require.NoError(t, err)
+		_, err = q.Dispatch(ctx, Job{Name: "resize", Queue: "images"})
+		require.NoError(t, err)
+
+		assert.True(t, mr.Exists("app:jobs:{mail}"))
+		assert.True(t, mr.Exists("app:jobs:{images}"))
+	})
+
+	t.Run("invalid jobs", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+
+		_, err := q.Dispatch(ctx, Job{})
+		assert.ErrorIs(t, err, ErrInvalidJob)
+
+		_, err = q.Dispatch(ctx, Job{Name: "send-email", Payload: make(chan int)})
+		assert.ErrorContains(t, err, "failed to marshal payload")
+	})
+
+	t.Run("unreachable Redis", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		mr.Close()
+
+		_, err := q.Dispatch(ctx, Job{Name: "send-email"})
+		assert.ErrorContains(t, err, "failed to dispatch job")
+	})
+}
+
+func TestQueue_DispatchDelayed(t *testing.T) {
+	ctx := context.Background()
+	q, mr := setupQueue(t, Config{})
+	now := time.UnixMilli(1700000000000)
+	q.now = func() time.Time { return now }
+
+	after, err := q.DispatchAfter(ctx, Job{Name: "reminder"}, time.Hour)
+	require.NoError(t, err)
+	at, err := q.DispatchAt(ctx, Job{Name: "reminder"}, now.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	score, err := mr.ZScore("app:queue:{default}:delayed", after)
+	require.NoError(t, err)
+	assert.Equal(t, float64(now.Add(time.Hour).UnixMilli()), score)
+	score, err = mr.ZScore("app:queue:{default}:delayed", at)
+	require.NoError(t, err)
+	assert.Equal(t, float64(now.Add(2*time.Hour).UnixMilli()), score)
+	assert.False(t, mr.Exists("app:queue:{default}"))
+	assert.Equal(t, "reminder", storedRecord(t, mr, "default", after).Name)
+
+	// Jobs due now or earlier are pushed at once
+	immediate, err := q.DispatchAfter(ctx, Job{Name: "reminder"}, 0)
+	require.NoError(t, err)
+	past, err := q.DispatchAt(ctx, Job{Name: "reminder"}, now.Add(-time.Minute))
+	require.NoError(t, err)
+	ids, err := mr.List("app:queue:{default}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{immediate, past}, ids)
+}
+
+func TestQueue_DispatchUnique(t *testing.T) {
+	ctx := context.Background()
+	succeed := Handlers{"report": func(context.Context, *ReservedJob) error { return nil }}
+	fail := Handlers{"report": func(context.Context, *ReservedJob) error { return errors.New("failed") }}
+
+	t.Run("one job per key until done", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		w := q.Worker(WorkerConfig{})
+
+		_, err := q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily", UniqueFor: time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, mr.TTL("app:queue:unique:report:daily"))
+
+		_, err = q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily"})
+		assert.ErrorIs(t, err, ErrDuplicateJob)
+		_, err = q.DispatchAfter(ctx, Job{Name: "report", UniqueKey: "report:daily"}, time.Minute)
+		assert.ErrorIs(t, err, ErrDuplicateJob)
+		_, err = q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:weekly"})
+		require.NoError(t, err)
+
+		ids, err := mr.List("app:queue:{default}")
+		require.NoError(t, err)
+		assert.Len(t, ids, 2)
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "report:daily", job.UniqueKey)
+
+		// The key stays taken while the job runs
+		_, err = q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily"})
+		assert.ErrorIs(t, err, ErrDuplicateJob)
+
+		w.process(ctx, job, succeed)
+		assert.False(t, mr.Exists("app:queue:unique:report:daily"))
+		_, err = q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily"})
+		require.NoError(t, err)
+	})
+
+	t.Run("kept while retrying, freed once failed", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.Now()
+		q.now = func() time.Time { return now }
+		w := q.Worker(WorkerConfig{MaxAttempts: 2, Backoff: time.Second})
+
+		_, err := q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily"})
+		require.NoError(t, err)
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		w.process(ctx, job, fail)
+		assert.True(t, mr.Exists("app:queue:unique:report:daily"))
+
+		now = now.Add(time.Second)
+		job, err = w.reserve(ctx)
+		require.NoError(t, err)
+		w.process(ctx, job, fail)
+		assert.False(t, mr.Exists("app:queue:unique:report:daily"))
+	})
+
+	t.Run("freed when dispatch fails", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		require.NoError(t, q.client.RegisterScript(ctx, dispatchScriptName, "return redis.error_reply('ERR down')"))
+
+		_, err := q.Dispatch(ctx, Job{Name: "report", UniqueKey: "report:daily"})
+		assert.ErrorContains(t, err, "failed to dispatch job")
+		assert.False(t, mr.Exists("app:queue:unique:report:daily"))
+	})
+}
@@ -0,0 +1,254 @@
+package queue
+
+// Each queue is kept under keys sharing a hash tag, so its scripts work in
+// cluster mode: a list of ready job IDs, sorted sets of delayed job IDs
+// scored by the time they are due and of reserved job IDs scored by the
+// time their reservation expires, and hashes of job records and attempt
+// counts by ID. Jobs out of attempts are kept in a sorted set of failed job
+// IDs scored by the time they failed, with their last error in a hash.
+
+// Script names, registered with the client
+const (
+	dispatchScriptName = "gofacades:queue:dispatch"
+	reserveScriptName  = "gofacades:queue:reserve"
+	deleteScriptName   = "gofacades:queue:delete"
+	releaseScriptName  = "gofacades:queue:release"
+	failScriptName     = "gofacades:queue:fail"
+	failedScriptName   = "gofacades:queue:failed"
+	retryScriptName    = "gofacades:queue:retry"
+	forgetScriptName   = "gofacades:queue:forget-failed"
+	statsScriptName    = "gofacades:queue:stats"
+
+	createBatchScriptName  = "gofacades:queue:batch-create"
+	batchSucceedScriptName = "gofacades:queue:batch-succeed"
+	batchFailScriptName    = "gofacades:queue:batch-fail"
+	batchStatusScriptName  = "gofacades:queue:batch-status"
+)
+
+// dispatchScript stores a job record and appends its ID to the ready list,
+// or adds it to the delayed set when it is due later.
+// KEYS: ready, jobs, delayed. ARGV: id, record, due (ms, 0 for now).
+const dispatchScript = `
+redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+  redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+else
+  redis.call('RPUSH', KEYS[1], ARGV[1])
+end
+return 1
+`
+
+// reserveScript moves delayed jobs that are due to the back of the ready
+// list, at most a batch at a time, and returns jobs whose reservation
+// expired to its front. It then pops the next job, reserving it until the
+// given deadline and counting the attempt. It returns the job's ID, record
+// and attempts, or nil when no job is ready.
+// KEYS: ready, reserved, jobs, attempts, delayed. ARGV: now (ms), deadline
+// (ms), batch.
+const reserveScript = `
+local due = redis.call('ZRANGEBYSCORE', KEYS[5], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, id in ipairs(due) do
+  redis.call('ZREM', KEYS[5], id)
+  redis.call('RPUSH', KEYS[1], id)
+end
+
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+  redis.call('ZREM', KEYS[2], id)
+  redis.call('LPUSH', KEYS[1], id)
+end
+
+while true do
+  local id = redis.call('LPOP', KEYS[1])
+  if not id then
+    return nil
+  end
+  local record = redis.call('HGET', KEYS[3], id)
+  if record then
+    redis.call('ZADD', KEYS[2], ARGV[2], id)
+    local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+    return {id, record, attempts}
+  end
+end
+`
+
+// deleteScript removes a reserved job once it has completed, counting it.
+// KEYS: reserved, jobs, attempts, stats. ARGV: id.
+const deleteScript = `
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HINCRBY', KEYS[4], 'completed', 1)
+return 1
+`
+
+// releaseScript moves a reserved job to the delayed set, to be run again
+// once due, optionally not counting the attempt that released it.
+// KEYS: reserved, delayed, attempts. ARGV: id, due (ms), uncount (1 or 0).
+const releaseScript = `
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+if ARGV[3] == '1' then
+  redis.call('HINCRBY', KEYS[3], ARGV[1], -1)
+end
+return 1
+`
+
+// failScript moves a reserved job to the failed set, recording its error
+// and counting it.
+// KEYS: reserved, failed, errors, stats. ARGV: id, now (ms), error.
+const failScript = `
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[3])
+redis.call('HINCRBY', KEYS[4], 'failed', 1)
+return 1
+`
+
+// failedScript returns a page of failed jobs, most recent first, as a flat
+// list of ID, record, attempts, error and failure time (ms) for each.
+// KEYS: failed, jobs, attempts, errors. ARGV: start, stop.
+const failedScript = `
+local ids = redis.call('ZREVRANGE', KEYS[1], ARGV[1], ARGV[2], 'WITHSCORES')
+local result = {}
+for i = 1, #ids, 2 do
+  local id = ids[i]
+  table.insert(result, id)
+  table.insert(result, redis.call('HGET', KEYS[2], id) or '')
+  table.insert(result, tonumber(redis.call('HGET', KEYS[3], id) or 0))
+  table.insert(result, redis.call('HGET', KEYS[4], id) or '')
+  table.insert(result, tonumber(ids[i + 1]))
+end
+return result
+`
+
+// retryScript pushes failed jobs back onto the ready list with their
+// attempts reset, every failed job when no ID is given. It returns the
+// number of jobs retried.
+// KEYS: failed, errors, attempts, ready. ARGV: ids.
+const retryScript = `
+local ids = ARGV
+if #ids == 0 then
+  ids = redis.call('ZRANGE', KEYS[1], 0, -1)
+end
+local retried = 0
+for _, id in ipairs(ids) do
+  if redis.call('ZREM', KEYS[1], id) == 1 then
+    redis.call('HDEL', KEYS[2], id)
+    redis.call('HDEL', KEYS[3], id)
+    redis.call('RPUSH', KEYS[4], id)
+    retried = retried + 1
+  end
+end
+return retried
+`
+
+// forgetScript deletes failed jobs, every failed job when no ID is given.
+// It returns the number of jobs deleted.
+// KEYS: failed, errors, attempts, jobs. ARGV: ids.
+const forgetScript = `
+local ids = ARGV
+if #ids == 0 then
+  ids = redis.call('ZRANGE', KEYS[1], 0, -1)
+end
+local deleted = 0
+for _, id in ipairs(ids) do
+  if redis.call('ZREM', KEYS[1], id) == 1 then
+    redis.call('HDEL', KEYS[2], id)
+    redis.call('HDEL', KEYS[3], id)
+    redis.call('HDEL', KEYS[4], id)
+    deleted = deleted + 1
+  end
+end
+return deleted
+`
+
+// statsScript returns a queue's ready, reserved, delayed and failed counts,
+// its completed and failed totals, and the record of the job at the head of
+// the ready list, or false when it is empty.
+// KEYS: ready, reserved, delayed, failed, jobs, stats.
+const statsScript = `
+local totals = redis.call('HMGET', KEYS[6], 'completed', 'failed')
+local record = false
+local head = redis.call('LINDEX', KEYS[1], 0)
+if head then
+  record = redis.call('HGET', KEYS[5], head)
+end
+return {
+  redis.call('LLEN', KEYS[1]),
+  redis.call('ZCARD', KEYS[2]),
+  redis.call('ZCARD', KEYS[3]),
+  redis.call('ZCARD', KEYS[4]),
+  tonumber(totals[1]) or 0,
+  tonumber(totals[2]) or 0,
+  record,
+}
+`
+
+// Batches are hashes of their job counts, follow-up jobs and creation time,
+// plus a "failed:<id>" field per failed job so each failure is counted once.
+// Pending counts the jobs that have neither succeeded nor failed.
+
+// createBatchScript creates a batch of the given size.
+// KEYS: batch. ARGV: total, then, catch, created at (ms), ttl (ms).
+const createBatchScript = `
+redis.call('HSET', KEYS[1], 'total', ARGV[1], 'pending', ARGV[1], 'failed', 0,
+  'then', ARGV[2], 'catch', ARGV[3], 'created_at', ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+return 1
+`
+
+// batchSucceedScript records the success of a job, which may have failed
+// before and been retried. It returns the follow-up job to dispatch once
+// every job has succeeded, to a single caller, or nil.
+// KEYS: batch. ARGV: job id.
+const batchSucceedScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  return nil
+end
+if redis.call('HDEL', KEYS[1], 'failed:' .. ARGV[1]) == 1 then
+  redis.call('HINCRBY', KEYS[1], 'failed', -1)
+else
+  redis.call('HINCRBY', KEYS[1], 'pending', -1)
+end
+
+local counts = redis.call('HMGET', KEYS[1], 'pending', 'failed')
+if tonumber(counts[1]) <= 0 and tonumber(counts[2]) == 0
+  and redis.call('HSETNX', KEYS[1], 'then_dispatched', 1) == 1 then
+  return redis.call('HGET', KEYS[1], 'then')
+end
+return nil
+`
+
+// batchFailScript records the failure of a job. It returns the follow-up
+// job to dispatch on the batch's first failure, to a single caller, or nil.
+// KEYS: batch. ARGV: job id.
+const batchFailScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  return nil
+end
+if redis.call('HSETNX', KEYS[1], 'failed:' .. ARGV[1], 1) == 0 then
+  return nil
+end
+redis.call('HINCRBY', KEYS[1], 'pending', -1)
+redis.call('HINCRBY', KEYS[1], 'failed', 1)
+
+if redis.call('HSETNX', KEYS[1], 'catch_dispatched', 1) == 1 then
+  return redis.call('HGET', KEYS[1], 'catch')
+end
+return nil
+`
+
+// batchStatusScript returns a batch's total, pending and failed counts and
+// creation time, or nil when it does not exist.
+// KEYS: batch.
+const batchStatusScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+  return nil
+end
+local values = redis.call('HMGET', KEYS[1], 'total', 'pending', 'failed', 'created_at')
+for i, value in ipairs(values) do
+  values[i] = tonumber(value)
+end
+return values
+`
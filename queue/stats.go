@@ -0,0 +1,94 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// Stats is a snapshot of a queue, for dashboards and alerts
+type Stats struct {
+	// Queue is the name of the queue
+	Queue string
+
+	// Ready is the number of jobs waiting to be reserved
+	Ready int
+
+	// Reserved is the number of jobs being processed, including jobs whose
+	// worker died and which are handed out again once their reservation
+	// expires
+	Reserved int
+
+	// Delayed is the number of jobs waiting to be due, whether dispatched
+	// with a delay or released to be retried
+	Delayed int
+
+	// Failed is the number of failed jobs kept for inspection
+	Failed int
+
+	// Completed and Failures count the jobs that succeeded and the jobs that
+	// ran out of attempts since the queue was first used. Their rate of
+	// change gives the throughput and failure rate.
+	Completed int64
+	Failures  int64
+
+	// OldestAge is how long ago the job at the head of the ready list was
+	// dispatched, or zero when no job is ready. A growing age means the
+	// workers are falling behind.
+	OldestAge time.Duration
+}
+
+// FailureRate returns the share of finished jobs that failed, between 0 and
+// 1, or 0 when no job has finished yet
+func (s Stats) FailureRate() float64 {
+	finished := s.Completed + s.Failures
+	if finished == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(finished)
+}
+
+// Stats returns a snapshot of the named queue, or of the default queue
+func (q *Queue) Stats(ctx context.Context, queue string) (Stats, error) {
+	if queue == "" {
+		queue = q.queue
+	}
+	k := q.keys(queue)
+	reply, err := q.client.RunScript(ctx, statsScriptName,
+		[]string{k.ready, k.reserved, k.delayed, k.failed, k.jobs, k.stats})
+	if err != nil {
+		return Stats{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 7 {
+		return Stats{}, fmt.Errorf("unexpected stats script reply: %v", reply)
+	}
+	var n [6]int64
+	for i := range n {
+		n[i], _ = values[i].(int64)
+	}
+	stats := Stats{
+		Queue:     queue,
+		Ready:     int(n[0]),
+		Reserved:  int(n[1]),
+		Delayed:   int(n[2]),
+		Failed:    int(n[3]),
+		Completed: n[4],
+		Failures:  n[5],
+	}
+
+	if data, _ := values[6].(string); data != "" {
+		var rec record
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			return Stats{}, &redis.DecodeError{Key: k.ready, Err: err}
+		}
+		if age := q.now().Sub(time.UnixMilli(rec.DispatchedAt)); age > 0 {
+			stats.OldestAge = age
+		}
+	}
+	return stats, nil
+}
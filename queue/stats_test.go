@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty queue", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+
+		stats, err := q.Stats(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Queue: "default"}, stats)
+		assert.Equal(t, 0.0, stats.FailureRate())
+	})
+
+	t.Run("counts jobs by state", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		now := time.UnixMilli(1700000000000)
+		q.now = func() time.Time { return now }
+		w := q.Worker(WorkerConfig{MaxAttempts: 1})
+
+		for i := 0; i < 5; i++ {
+			_, err := q.Dispatch(ctx, Job{Name: "work"})
+			require.NoError(t, err)
+			now = now.Add(time.Second)
+		}
+		_, err := q.DispatchAfter(ctx, Job{Name: "later"}, time.Hour)
+		require.NoError(t, err)
+		_, err = q.Dispatch(ctx, Job{Name: "work", Queue: "other"})
+		require.NoError(t, err)
+
+		results := []error{nil, nil, errors.New("failed")}
+		for _, result := range results {
+			job, err := w.reserve(ctx)
+			require.NoError(t, err)
+			w.process(ctx, job, Handlers{"work": func(context.Context, *ReservedJob) error { return result }})
+		}
+		_, err = w.reserve(ctx)
+		require.NoError(t, err)
+		now = now.Add(10 * time.Second)
+
+		stats, err := q.Stats(ctx, "default")
+		require.NoError(t, err)
+		assert.Equal(t, "default", stats.Queue)
+		assert.Equal(t, 1, stats.Ready)
+		assert.Equal(t, 1, stats.Reserved)
+		assert.Equal(t, 1, stats.Delayed)
+		assert.Equal(t, 1, stats.Failed)
+		assert.Equal(t, int64(2), stats.Completed)
+		assert.Equal(t, int64(1), stats.Failures)
+		assert.InDelta(t, 1.0/3, stats.FailureRate(), 0.001)
+		// The last job was dispatched a second before the other queue's job
+		assert.Equal(t, 11*time.Second, stats.OldestAge)
+
+		stats, err = q.Stats(ctx, "other")
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Ready)
+		assert.Equal(t, 10*time.Second, stats.OldestAge)
+	})
+
+	t.Run("flushing failed jobs keeps the totals", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		failJobs(t, q, "first", "second")
+
+		_, err := q.FlushFailed(ctx, "")
+		require.NoError(t, err)
+
+		stats, err := q.Stats(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.Failed)
+		assert.Equal(t, int64(2), stats.Failures)
+		assert.Equal(t, 1.0, stats.FailureRate())
+	})
+
+	t.Run("unreachable Redis", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		mr.Close()
+
+		_, err := q.Stats(ctx, "")
+		assert.Error(t, err)
+	})
+}
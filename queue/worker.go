@@ -0,0 +1,340 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+const (
+	// defaultTimeout is how long a job may run when WorkerConfig does not
+	// set a timeout
+	defaultTimeout = time.Minute
+
+	// defaultPollInterval is how long a worker waits when its queue is empty
+	// and WorkerConfig does not set a poll interval
+	defaultPollInterval = time.Second
+
+	// defaultMaxAttempts is how often a job is attempted when neither it nor
+	// WorkerConfig sets a maximum
+	defaultMaxAttempts = 3
+
+	// defaultBackoff is how long to wait before a first retry when neither
+	// the job nor WorkerConfig sets a backoff
+	defaultBackoff = 10 * time.Second
+
+	// defaultMaxBackoff caps the wait between retries when WorkerConfig does
+	// not set a maximum
+	defaultMaxBackoff = time.Hour
+)
+
+// ReservedJob is a job handed to a handler
+type ReservedJob struct {
+	ID           string
+	Name         string
+	Queue        string
+	Payload      []byte
+	Attempts     int
+	MaxAttempts  int
+	UniqueKey    string
+	BatchID      string
+	DispatchedAt time.Time
+
+	backoff     time.Duration
+	uniqueOwner string
+	codec       cache.Codec
+}
+
+// Decode decodes the payload into v with the client's codec
+func (j *ReservedJob) Decode(v interface{}) error {
+	if err := j.codec.Unmarshal(j.Payload, v); err != nil {
+		return &redis.DecodeError{Key: j.ID, Err: err}
+	}
+	return nil
+}
+
+// Handler processes a job. Returning nil completes it.
+type Handler func(ctx context.Context, job *ReservedJob) error
+
+// Handlers maps job names to their handlers
+type Handlers map[string]Handler
+
+// WorkerConfig configures a Worker
+type WorkerConfig struct {
+	// Queue is the queue to process when Run is not given queue names.
+	// Defaults to the queue's default.
+	Queue string
+
+	// Concurrency is the number of jobs processed at once. Defaults to 1.
+	Concurrency int
+
+	// Timeout is how long a job may run. Its handler's context is cancelled
+	// once it is over, and the job is reserved for that long: should the
+	// worker die meanwhile, the job is handed to another worker after the
+	// timeout. Defaults to one minute.
+	Timeout time.Duration
+
+	// PollInterval is how long the worker waits before checking an empty
+	// queue again. Defaults to one second.
+	PollInterval time.Duration
+
+	// MaxAttempts is how often jobs that do not set their own maximum are
+	// attempted before they are moved to the failed jobs. Defaults to 3.
+	MaxAttempts int
+
+	// Backoff is how long to wait before retrying jobs that do not set
+	// their own backoff after their first failure, doubling with each
+	// further failure. Defaults to 10 seconds.
+	Backoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Defaults to one hour.
+	MaxBackoff time.Duration
+
+	// OnFailure is called with jobs whose last attempt failed, which are
+	// then moved to the failed jobs
+	OnFailure func(job *ReservedJob, err error)
+}
+
+// Worker processes the jobs of a queue
+type Worker struct {
+	queue *Queue
+	cfg   WorkerConfig
+}
+
+// Worker returns a worker processing jobs as configured by cfg
+func (q *Queue) Worker(cfg WorkerConfig) *Worker {
+	if cfg.Queue == "" {
+		cfg.Queue = q.queue
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	return &Worker{queue: q, cfg: cfg}
+}
+
+// Run processes jobs with handlers until ctx is cancelled, then waits for
+// the jobs being processed to finish before returning; their handlers keep
+// running until done or timed out. Failures to reach Redis are retried
+// after the poll interval.
+//
+// Given queue names, Run processes those queues rather than the configured
+// one, in strict priority order: a job is only taken from a queue when every
+// queue named before it is empty, so a busy queue starves the ones after it.
+func (w *Worker) Run(ctx context.Context, handlers Handlers, queues ...string) error {
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx, handlers, queues)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// loop reserves and processes jobs one at a time until ctx is cancelled
+func (w *Worker) loop(ctx context.Context, handlers Handlers, queues []string) {
+	for ctx.Err() == nil {
+		job, err := w.reserve(ctx, queues...)
+		if err != nil || job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+		w.process(ctx, job, handlers)
+	}
+}
+
+// reserve pops the next job off the first of the named queues, or the
+// configured queue, that is not empty, returning nil when they all are
+func (w *Worker) reserve(ctx context.Context, queues ...string) (*ReservedJob, error) {
+	if len(queues) == 0 {
+		queues = []string{w.cfg.Queue}
+	}
+	for _, queue := range queues {
+		job, err := w.reserveFrom(ctx, queue)
+		if err != nil || job != nil {
+			return job, err
+		}
+	}
+	return nil, nil
+}
+
+// reserveFrom moves the queue's due delayed jobs to its ready list and pops
+// the next job off it, returning nil when it is empty
+func (w *Worker) reserveFrom(ctx context.Context, queue string) (*ReservedJob, error) {
+	q := w.queue
+	k := q.keys(queue)
+	now := q.now()
+	reply, err := q.client.RunScript(ctx, reserveScriptName,
+		[]string{k.ready, k.reserved, k.jobs, k.attempts, k.delayed},
+		now.UnixMilli(), now.Add(w.cfg.Timeout).UnixMilli(), promoteBatch)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("unexpected reserve script reply: %v", reply)
+	}
+	id, _ := values[0].(string)
+	data, _ := values[1].(string)
+	attempts, _ := values[2].(int64)
+
+	var rec record
+	if err := json.Unmarshal([]byte(data), &rec); err != nil {
+		return nil, &redis.DecodeError{Key: id, Err: err}
+	}
+	job := &ReservedJob{
+		ID:           id,
+		Name:         rec.Name,
+		Queue:        queue,
+		Payload:      rec.Payload,
+		Attempts:     int(attempts),
+		MaxAttempts:  rec.MaxAttempts,
+		UniqueKey:    rec.UniqueKey,
+		BatchID:      rec.BatchID,
+		DispatchedAt: time.UnixMilli(rec.DispatchedAt),
+		backoff:      time.Duration(rec.Backoff) * time.Millisecond,
+		uniqueOwner:  rec.UniqueOwner,
+		codec:        q.client.Codec(),
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = w.cfg.MaxAttempts
+	}
+	if job.backoff <= 0 {
+		job.backoff = w.cfg.Backoff
+	}
+	return job, nil
+}
+
+// process runs the job's handler, then deletes the job when it succeeded,
+// puts it back when the handler released it, schedules a retry when it
+// failed with attempts left, or moves it to the failed jobs
+func (w *Worker) process(ctx context.Context, job *ReservedJob, handlers Handlers) {
+	// Shutting down lets the job finish, within its timeout
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.cfg.Timeout)
+	defer cancel()
+	storeCtx := context.WithoutCancel(ctx)
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// The previous attempt never finished
+		err = ErrMaxAttemptsExceeded
+	} else {
+		err = run(jobCtx, job, handlers)
+	}
+
+	var released *releaseError
+	switch {
+	case err == nil:
+		w.delete(storeCtx, job)
+		w.unlock(storeCtx, job)
+		w.batchDone(storeCtx, job, true)
+	case errors.As(err, &released):
+		w.release(storeCtx, job, released.delay, true)
+	case job.Attempts < job.MaxAttempts:
+		w.release(storeCtx, job, w.backoff(job), false)
+	default:
+		if w.cfg.OnFailure != nil {
+			w.cfg.OnFailure(job, err)
+		}
+		w.fail(storeCtx, job, err)
+		w.unlock(storeCtx, job)
+		w.batchDone(storeCtx, job, false)
+	}
+}
+
+// run passes job to its handler, turning a panic into an error
+func run(ctx context.Context, job *ReservedJob, handlers Handlers) (err error) {
+	handler, ok := handlers[job.Name]
+	if !ok || handler == nil {
+		return fmt.Errorf("%w %q", ErrNoHandler, job.Name)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %q panicked: %v", job.Name, r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// delete removes job from the queue. Should it fail, the job is handed out
+// again once its reservation expires.
+func (w *Worker) delete(ctx context.Context, job *ReservedJob) {
+	k := w.queue.keys(job.Queue)
+	_, _ = w.queue.client.RunScript(ctx, deleteScriptName, []string{k.reserved, k.jobs, k.attempts, k.stats}, job.ID)
+}
+
+// release puts job back to be run again after delay, not counting the
+// attempt when uncount is set. Should it fail, the job is run again once its
+// reservation expires.
+func (w *Worker) release(ctx context.Context, job *ReservedJob, delay time.Duration, uncount bool) {
+	k := w.queue.keys(job.Queue)
+	due := w.queue.now().Add(delay)
+	flag := 0
+	if uncount {
+		flag = 1
+	}
+	_, _ = w.queue.client.RunScript(ctx, releaseScriptName, []string{k.reserved, k.delayed, k.attempts},
+		job.ID, due.UnixMilli(), flag)
+}
+
+// backoff returns how long to wait before the next attempt of job, doubling
+// the job's backoff with each failed attempt
+func (w *Worker) backoff(job *ReservedJob) time.Duration {
+	delay := job.backoff
+	for i := 1; i < job.Attempts && delay < w.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.cfg.MaxBackoff {
+		delay = w.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// fail moves job to the failed jobs, recording err
+func (w *Worker) fail(ctx context.Context, job *ReservedJob, err error) {
+	k := w.queue.keys(job.Queue)
+	_, _ = w.queue.client.RunScript(ctx, failScriptName, []string{k.reserved, k.failed, k.errors, k.stats},
+		job.ID, w.queue.now().UnixMilli(), err.Error())
+}
+
+// unlock frees the key of a unique job that is done with
+func (w *Worker) unlock(ctx context.Context, job *ReservedJob) {
+	if job.UniqueKey != "" && job.uniqueOwner != "" {
+		_, _ = w.queue.client.RestoreLock(w.queue.uniqueLock(job.UniqueKey), job.uniqueOwner).Release(ctx)
+	}
+}
+
+// batchDone records the outcome of a job belonging to a batch
+func (w *Worker) batchDone(ctx context.Context, job *ReservedJob, succeeded bool) {
+	if job.BatchID != "" {
+		_ = w.queue.batchDone(ctx, job.BatchID, job.ID, succeeded)
+	}
+}
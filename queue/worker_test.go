@@ -0,0 +1,424 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processed collects the jobs passed to a handler
+type processed struct {
+	mu   sync.Mutex
+	jobs []*ReservedJob
+}
+
+func (p *processed) handler(err error) Handler {
+	return func(ctx context.Context, job *ReservedJob) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jobs = append(p.jobs, job)
+		return err
+	}
+}
+
+func (p *processed) list() []*ReservedJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*ReservedJob(nil), p.jobs...)
+}
+
+// runWorker runs w on queues in the background, stopping it when the test
+// finishes
+func runWorker(t *testing.T, w *Worker, handlers Handlers, queues ...string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx, handlers, queues...) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestWorker_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("processes jobs in order", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+
+		var ids []string
+		for _, to := range []string{"ada", "grace", "alan"} {
+			id, err := q.Dispatch(ctx, Job{Name: "send-email", Payload: to})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		var emails processed
+		runWorker(t, q.Worker(WorkerConfig{PollInterval: 10 * time.Millisecond}), Handlers{
+			"send-email": emails.handler(nil),
+		})
+
+		assert.Eventually(t, func() bool { return len(emails.list()) == 3 }, 2*time.Second, 10*time.Millisecond)
+		for i, job := range emails.list() {
+			assert.Equal(t, ids[i], job.ID)
+			assert.Equal(t, "send-email", job.Name)
+			assert.Equal(t, "default", job.Queue)
+			assert.Equal(t, 1, job.Attempts)
+			assert.False(t, job.DispatchedAt.IsZero())
+		}
+
+		var to string
+		require.NoError(t, emails.list()[1].Decode(&to))
+		assert.Equal(t, "grace", to)
+
+		// Completed jobs are deleted
+		assert.Eventually(t, func() bool {
+			return !mr.Exists("app:queue:{default}:jobs") && !mr.Exists("app:queue:{default}:reserved")
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("picks up jobs dispatched while running", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+
+		var images processed
+		runWorker(t, q.Worker(WorkerConfig{Queue: "images", Concurrency: 3, PollInterval: 10 * time.Millisecond}), Handlers{
+			"resize": images.handler(nil),
+		})
+
+		for i := 0; i < 10; i++ {
+			_, err := q.Dispatch(ctx, Job{Name: "resize", Queue: "images"})
+			require.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool { return len(images.list()) == 10 }, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("processes queues in priority order", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		for _, queue := range []string{"low", "default", "critical", "default", "low"} {
+			_, err := q.Dispatch(ctx, Job{Name: "work", Queue: queue})
+			require.NoError(t, err)
+		}
+
+		var jobs processed
+		runWorker(t, q.Worker(WorkerConfig{PollInterval: 10 * time.Millisecond}), Handlers{
+			"work": jobs.handler(nil),
+		}, "critical", "default", "low")
+
+		assert.Eventually(t, func() bool { return len(jobs.list()) == 5 }, 2*time.Second, 10*time.Millisecond)
+		var queues []string
+		for _, job := range jobs.list() {
+			queues = append(queues, job.Queue)
+		}
+		assert.Equal(t, []string{"critical", "default", "default", "low", "low"}, queues)
+	})
+
+	t.Run("takes urgent jobs first as they arrive", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		w := q.Worker(WorkerConfig{Timeout: time.Hour})
+		_, err := q.Dispatch(ctx, Job{Name: "report", Queue: "low"})
+		require.NoError(t, err)
+		_, err = q.Dispatch(ctx, Job{Name: "report", Queue: "low"})
+		require.NoError(t, err)
+
+		job, err := w.reserve(ctx, "critical", "low")
+		require.NoError(t, err)
+		assert.Equal(t, "low", job.Queue)
+
+		_, err = q.DispatchAfter(ctx, Job{Name: "alert", Queue: "critical"}, time.Second)
+		require.NoError(t, err)
+		q.now = func() time.Time { return time.Now().Add(time.Minute) }
+
+		job, err = w.reserve(ctx, "critical", "low")
+		require.NoError(t, err)
+		assert.Equal(t, "critical", job.Queue)
+		assert.Equal(t, "alert", job.Name)
+		w.process(ctx, job, Handlers{"alert": func(context.Context, *ReservedJob) error { return nil }})
+
+		// Only the named queues are processed
+		_, err = q.Dispatch(ctx, Job{Name: "ignored"})
+		require.NoError(t, err)
+		job, err = w.reserve(ctx, "critical", "low")
+		require.NoError(t, err)
+		assert.Equal(t, "report", job.Name)
+		job, err = w.reserve(ctx, "critical", "low")
+		require.NoError(t, err)
+		assert.Nil(t, job)
+	})
+
+	t.Run("reports failures", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+
+		var mu sync.Mutex
+		failures := map[string]error{}
+		w := q.Worker(WorkerConfig{
+			PollInterval: 10 * time.Millisecond,
+			MaxAttempts:  1,
+			OnFailure: func(job *ReservedJob, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failures[job.Name] = err
+			},
+		})
+
+		for _, name := range []string{"fails", "panics", "unknown"} {
+			_, err := q.Dispatch(ctx, Job{Name: name})
+			require.NoError(t, err)
+		}
+		runWorker(t, w, Handlers{
+			"fails":  func(context.Context, *ReservedJob) error { return errors.New("smtp down") },
+			"panics": func(context.Context, *ReservedJob) error { panic("boom") },
+		})
+
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(failures) == 3
+		}, 2*time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.EqualError(t, failures["fails"], "smtp down")
+		assert.EqualError(t, failures["panics"], `job "panics" panicked: boom`)
+		assert.ErrorIs(t, failures["unknown"], ErrNoHandler)
+
+		// Failed jobs are kept for inspection
+		assert.Eventually(t, func() bool {
+			members, _ := mr.ZMembers("app:queue:{default}:failed")
+			return len(members) == 3
+		}, time.Second, 10*time.Millisecond)
+		assert.False(t, mr.Exists("app:queue:{default}:reserved"))
+	})
+
+	t.Run("hands out jobs again once their reservation expires", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		now := time.Now()
+		q.now = func() time.Time { return now }
+
+		id, err := q.Dispatch(ctx, Job{Name: "send-email"})
+		require.NoError(t, err)
+
+		// A worker reserves the job and dies
+		crashed := q.Worker(WorkerConfig{Timeout: time.Minute})
+		job, err := crashed.reserve(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+
+		job, err = crashed.reserve(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, job)
+
+		now = now.Add(time.Minute)
+		job, err = q.Worker(WorkerConfig{}).reserve(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, id, job.ID)
+		assert.Equal(t, 2, job.Attempts)
+	})
+
+	t.Run("promotes delayed jobs once due", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.Now()
+		q.now = func() time.Time { return now }
+		w := q.Worker(WorkerConfig{Timeout: time.Hour})
+
+		later, err := q.DispatchAfter(ctx, Job{Name: "reminder"}, 2*time.Minute)
+		require.NoError(t, err)
+		sooner, err := q.DispatchAfter(ctx, Job{Name: "reminder"}, time.Minute)
+		require.NoError(t, err)
+		ready, err := q.Dispatch(ctx, Job{Name: "reminder"})
+		require.NoError(t, err)
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, ready, job.ID)
+		job, err = w.reserve(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, job)
+
+		now = now.Add(2 * time.Minute)
+		for _, want := range []string{sooner, later} {
+			job, err = w.reserve(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, job)
+			assert.Equal(t, want, job.ID)
+		}
+		assert.False(t, mr.Exists("app:queue:{default}:delayed"))
+	})
+
+	t.Run("shuts down gracefully", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+
+		_, err := q.Dispatch(ctx, Job{Name: "slow"})
+		require.NoError(t, err)
+
+		started := make(chan struct{})
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		finished := false
+		go func() {
+			done <- q.Worker(WorkerConfig{PollInterval: 10 * time.Millisecond}).Run(runCtx, Handlers{
+				"slow": func(ctx context.Context, job *ReservedJob) error {
+					close(started)
+					time.Sleep(50 * time.Millisecond)
+					finished = ctx.Err() == nil
+					return nil
+				},
+			})
+		}()
+
+		<-started
+		cancel()
+		require.NoError(t, <-done)
+		assert.True(t, finished)
+		assert.False(t, mr.Exists("app:queue:{default}:jobs"))
+	})
+
+	t.Run("times out jobs", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+
+		_, err := q.Dispatch(ctx, Job{Name: "stuck"})
+		require.NoError(t, err)
+
+		var jobErr error
+		failed := make(chan struct{})
+		runWorker(t, q.Worker(WorkerConfig{
+			Timeout:      20 * time.Millisecond,
+			PollInterval: 10 * time.Millisecond,
+			MaxAttempts:  1,
+			OnFailure: func(job *ReservedJob, err error) {
+				jobErr = err
+				close(failed)
+			},
+		}), Handlers{
+			"stuck": func(ctx context.Context, job *ReservedJob) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		<-failed
+		assert.ErrorIs(t, jobErr, context.DeadlineExceeded)
+	})
+}
+
+func TestWorker_Retries(t *testing.T) {
+	ctx := context.Background()
+	failing := Handlers{"charge": func(context.Context, *ReservedJob) error { return errors.New("card declined") }}
+
+	t.Run("retries with exponential backoff until out of attempts", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.UnixMilli(1700000000000)
+		q.now = func() time.Time { return now }
+
+		var failures []error
+		w := q.Worker(WorkerConfig{
+			Backoff:     time.Second,
+			MaxAttempts: 3,
+			OnFailure:   func(job *ReservedJob, err error) { failures = append(failures, err) },
+		})
+
+		id, err := q.Dispatch(ctx, Job{Name: "charge"})
+		require.NoError(t, err)
+
+		for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+			job, err := w.reserve(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, job)
+			assert.Equal(t, attempt+1, job.Attempts)
+			assert.Equal(t, 3, job.MaxAttempts)
+			w.process(ctx, job, failing)
+
+			score, err := mr.ZScore("app:queue:{default}:delayed", id)
+			require.NoError(t, err)
+			assert.Equal(t, float64(now.Add(backoff).UnixMilli()), score)
+			assert.Empty(t, failures)
+
+			// Not retried before the backoff is over
+			job, err = w.reserve(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, job)
+			now = now.Add(backoff)
+		}
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, 3, job.Attempts)
+		w.process(ctx, job, failing)
+
+		require.Len(t, failures, 1)
+		assert.EqualError(t, failures[0], "card declined")
+		members, err := mr.ZMembers("app:queue:{default}:failed")
+		require.NoError(t, err)
+		assert.Equal(t, []string{id}, members)
+		assert.False(t, mr.Exists("app:queue:{default}:delayed"))
+	})
+
+	t.Run("jobs override the attempts and backoff", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.UnixMilli(1700000000000)
+		q.now = func() time.Time { return now }
+		w := q.Worker(WorkerConfig{})
+
+		once, err := q.Dispatch(ctx, Job{Name: "charge", MaxAttempts: 1})
+		require.NoError(t, err)
+		slow, err := q.Dispatch(ctx, Job{Name: "charge", Backoff: time.Minute})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			job, err := w.reserve(ctx)
+			require.NoError(t, err)
+			w.process(ctx, job, failing)
+		}
+
+		members, err := mr.ZMembers("app:queue:{default}:failed")
+		require.NoError(t, err)
+		assert.Equal(t, []string{once}, members)
+		score, err := mr.ZScore("app:queue:{default}:delayed", slow)
+		require.NoError(t, err)
+		assert.Equal(t, float64(now.Add(time.Minute).UnixMilli()), score)
+	})
+
+	t.Run("fails jobs whose last attempt never finished", func(t *testing.T) {
+		q, mr := setupQueue(t, Config{})
+		now := time.Now()
+		q.now = func() time.Time { return now }
+
+		var failure error
+		w := q.Worker(WorkerConfig{
+			MaxAttempts: 1,
+			OnFailure:   func(job *ReservedJob, err error) { failure = err },
+		})
+		_, err := q.Dispatch(ctx, Job{Name: "charge"})
+		require.NoError(t, err)
+
+		// The first worker dies while running the job
+		_, err = w.reserve(ctx)
+		require.NoError(t, err)
+		now = now.Add(time.Minute)
+
+		job, err := w.reserve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, job.Attempts)
+		w.process(ctx, job, Handlers{"charge": func(context.Context, *ReservedJob) error {
+			t.Fatal("handler should not run")
+			return nil
+		}})
+
+		assert.ErrorIs(t, failure, ErrMaxAttemptsExceeded)
+		assert.True(t, mr.Exists("app:queue:{default}:failed"))
+	})
+
+	t.Run("caps the backoff", func(t *testing.T) {
+		q, _ := setupQueue(t, Config{})
+		w := q.Worker(WorkerConfig{Backoff: time.Second, MaxBackoff: 5 * time.Second})
+
+		for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 100: 5 * time.Second} {
+			assert.Equal(t, want, w.backoff(&ReservedJob{Attempts: attempts, backoff: time.Second}), attempts)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Algorithm selects how a Limiter counts attempts
+type Algorithm int
+
+const (
+	// FixedWindow counts attempts in windows starting with the first
+	// attempt. It needs a single counter per key, but lets up to twice the
+	// limit through around the end of a window.
+	FixedWindow Algorithm = iota
+
+	// SlidingWindowLog records the time of every allowed attempt and counts
+	// those in the last window. It is exact, at the cost of memory growing
+	// with the limit.
+	SlidingWindowLog
+
+	// SlidingWindowCounter estimates the attempts in the last window from
+	// the counts of the current and previous fixed windows, weighting the
+	// previous one by how much of it the sliding window still covers. It
+	// uses constant memory and is close to exact for steady traffic.
+	SlidingWindowCounter
+
+	// TokenBucket refills a bucket of Config.Burst tokens at max tokens per
+	// window; each attempt takes a token. Bursts up to the bucket size are
+	// allowed while the average rate stays within the limit.
+	TokenBucket
+)
+
+// String returns the name of the algorithm
+func (a Algorithm) String() string {
+	switch a {
+	case FixedWindow:
+		return "fixed-window"
+	case SlidingWindowLog:
+		return "sliding-window-log"
+	case SlidingWindowCounter:
+		return "sliding-window-counter"
+	case TokenBucket:
+		return "token-bucket"
+	default:
+		return "unknown"
+	}
+}
+
+// Every script replies whether the attempt was allowed, the attempts
+// remaining, and the milliseconds until the next attempt may be allowed and
+// until the limit is fully restored
+
+// fixedWindowScript takes max and the window in milliseconds
+const fixedWindowScript = `
+local max = tonumber(ARGV[1])
+local attempts = tonumber(redis.call('GET', KEYS[1]) or '0')
+local allowed = 0
+if attempts < max then
+	attempts = redis.call('INCR', KEYS[1])
+	if attempts == 1 then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	end
+	allowed = 1
+end
+local reset = redis.call('PTTL', KEYS[1])
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, math.max(max - attempts, 0), retry, reset}
+`
+
+// slidingLogScript takes max, the window and the current time in
+// milliseconds, and a unique member naming the attempt
+const slidingLogScript = `
+local max, window, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < max then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+if count == 0 then
+	return {allowed, max, 0, 0}
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+local retry = 0
+if allowed == 0 then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {allowed, max - count, retry, tonumber(newest[2]) + window - now}
+`
+
+// slidingCounterScript takes max, the window and the current time in
+// milliseconds
+const slidingCounterScript = `
+local max, window, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local start = tonumber(redis.call('HGET', KEYS[1], 'start') or '0')
+local cur = tonumber(redis.call('HGET', KEYS[1], 'cur') or '0')
+local prev = tonumber(redis.call('HGET', KEYS[1], 'prev') or '0')
+local current = now - (now % window)
+if current ~= start then
+	if current - start == window then
+		prev = cur
+	else
+		prev = 0
+	end
+	cur = 0
+	start = current
+end
+local elapsed = now - current
+local estimate = prev * (window - elapsed) / window + cur
+local allowed = 0
+if estimate + 1 <= max then
+	cur = cur + 1
+	estimate = estimate + 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'start', start, 'cur', cur, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], 2 * window)
+local retry = 0
+if allowed == 0 then
+	if prev > 0 and cur + 1 <= max then
+		retry = math.ceil(window - (max - cur - 1) * window / prev - elapsed)
+	else
+		retry = window - elapsed
+	end
+end
+local reset = window - elapsed
+if cur > 0 then
+	reset = reset + window
+end
+return {allowed, math.max(math.floor(max - estimate), 0), retry, reset}
+`
+
+// tokenBucketScript takes the bucket size, the refill rate as max tokens per
+// window in milliseconds, and the current time in milliseconds
+const tokenBucketScript = `
+local capacity, max, window, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+local rate = max / window
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens') or ARGV[1])
+local ts = tonumber(redis.call('HGET', KEYS[1], 'ts') or ARGV[4])
+tokens = math.min(capacity, tokens + math.max(now - ts, 0) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+local retry = 0
+if allowed == 0 then
+	retry = math.ceil((1 - tokens) / rate)
+end
+return {allowed, math.floor(tokens), retry, reset}
+`
+
+// script returns the name the algorithm's script is registered under and
+// its source
+func (a Algorithm) script() (name, src string) {
+	switch a {
+	case SlidingWindowLog:
+		return "gofacades:ratelimit:sliding-window-log", slidingLogScript
+	case SlidingWindowCounter:
+		return "gofacades:ratelimit:sliding-window-counter", slidingCounterScript
+	case TokenBucket:
+		return "gofacades:ratelimit:token-bucket", tokenBucketScript
+	default:
+		return "gofacades:ratelimit:fixed-window", fixedWindowScript
+	}
+}
+
+// args returns the script arguments for an attempt against a limit of max
+// per window at now
+func (l *Limiter) args(max int, window time.Duration, now time.Time) ([]interface{}, error) {
+	ms := window.Milliseconds()
+	switch l.algorithm {
+	case SlidingWindowLog:
+		member, err := attemptID(now)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{max, ms, now.UnixMilli(), member}, nil
+	case SlidingWindowCounter:
+		return []interface{}{max, ms, now.UnixMilli()}, nil
+	case TokenBucket:
+		burst := l.burst
+		if burst <= 0 {
+			burst = max
+		}
+		return []interface{}{burst, max, ms, now.UnixMilli()}, nil
+	default:
+		return []interface{}{max, ms}, nil
+	}
+}
+
+// attemptID returns a unique member for an attempt at now in a sliding log
+func attemptID(now time.Time) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(now.UnixMilli(), 10) + "-" + hex.EncodeToString(b), nil
+}
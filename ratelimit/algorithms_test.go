@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attempt makes an attempt against a limit of 3 per minute
+func attempt(t *testing.T, limiter *Limiter) Result {
+	t.Helper()
+	result, err := limiter.Attempt(context.Background(), "key", 3, time.Minute)
+	require.NoError(t, err)
+	return result
+}
+
+// setupClock replaces the limiter's clock with one advanced by the returned
+// function, starting at the beginning of a minute
+func setupClock(limiter *Limiter) func(time.Duration) {
+	now := time.Unix(1700000000, 0).Truncate(time.Minute)
+	limiter.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestLimiter_SlidingWindowLog(t *testing.T) {
+	limiter, mr := setupLimiter(t, Config{Algorithm: SlidingWindowLog})
+	advance := setupClock(limiter)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, attempt(t, limiter).Allowed)
+		advance(10 * time.Second)
+	}
+
+	// The oldest attempt leaves the window 30 seconds from now
+	result := attempt(t, limiter)
+	assert.False(t, result.Allowed)
+	assert.Zero(t, result.Remaining)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+	assert.Equal(t, 50*time.Second, result.ResetAfter)
+
+	advance(30 * time.Second)
+	result = attempt(t, limiter)
+	assert.True(t, result.Allowed)
+	assert.Zero(t, result.Remaining)
+
+	members, err := mr.ZMembers("app:ratelimit:key")
+	require.NoError(t, err)
+	assert.Len(t, members, 3)
+}
+
+func TestLimiter_SlidingWindowCounter(t *testing.T) {
+	limiter, _ := setupLimiter(t, Config{Algorithm: SlidingWindowCounter})
+	advance := setupClock(limiter)
+
+	for i := 3; i > 0; i-- {
+		result := attempt(t, limiter)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i-1, result.Remaining)
+	}
+	assert.False(t, attempt(t, limiter).Allowed)
+
+	// Halfway through the next window the previous one still counts for
+	// half of its 3 attempts
+	advance(90 * time.Second)
+	result := attempt(t, limiter)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result = attempt(t, limiter)
+	assert.False(t, result.Allowed)
+	// 1 + 3 * (60 - e) / 60 <= 2 once e reaches 40 seconds
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+	assert.Equal(t, 90*time.Second, result.ResetAfter)
+
+	advance(10 * time.Second)
+	assert.True(t, attempt(t, limiter).Allowed)
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	t.Run("refills at the limit rate", func(t *testing.T) {
+		limiter, _ := setupLimiter(t, Config{Algorithm: TokenBucket})
+		advance := setupClock(limiter)
+
+		for i := 2; i >= 0; i-- {
+			result := attempt(t, limiter)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		// One token every 20 seconds
+		result := attempt(t, limiter)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 20*time.Second, result.RetryAfter)
+		assert.Equal(t, time.Minute, result.ResetAfter)
+
+		advance(20 * time.Second)
+		assert.True(t, attempt(t, limiter).Allowed)
+		assert.False(t, attempt(t, limiter).Allowed)
+	})
+
+	t.Run("bursts", func(t *testing.T) {
+		limiter, mr := setupLimiter(t, Config{Algorithm: TokenBucket, Burst: 10})
+		advance := setupClock(limiter)
+
+		for i := 0; i < 10; i++ {
+			assert.True(t, attempt(t, limiter).Allowed)
+		}
+		assert.False(t, attempt(t, limiter).Allowed)
+
+		// The bucket refills up to its size, and is forgotten once full
+		assert.Equal(t, 200*time.Second, mr.TTL("app:ratelimit:key"))
+		advance(time.Hour)
+		result := attempt(t, limiter)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 9, result.Remaining)
+	})
+}
+
+func TestLimiter_FixedWindowOnly(t *testing.T) {
+	ctx := context.Background()
+	limiter, mr := setupLimiter(t, Config{Algorithm: TokenBucket})
+
+	_, err := limiter.Hit(ctx, "key", time.Minute)
+	assert.Equal(t, ErrUnsupportedAlgorithm, err)
+	_, err = limiter.Attempts(ctx, "key")
+	assert.Equal(t, ErrUnsupportedAlgorithm, err)
+	_, err = limiter.AvailableIn(ctx, "key")
+	assert.Equal(t, ErrUnsupportedAlgorithm, err)
+
+	attempt(t, limiter)
+	require.NoError(t, limiter.Clear(ctx, "key"))
+	assert.False(t, mr.Exists("app:ratelimit:key"))
+}
+
+func TestAlgorithm_String(t *testing.T) {
+	assert.Equal(t, "fixed-window", FixedWindow.String())
+	assert.Equal(t, "sliding-window-log", SlidingWindowLog.String())
+	assert.Equal(t, "sliding-window-counter", SlidingWindowCounter.String())
+	assert.Equal(t, "token-bucket", TokenBucket.String())
+	assert.Equal(t, "unknown", Algorithm(42).String())
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc returns the key a request is limited under. Requests for which it
+// returns an empty key are not limited.
+type KeyFunc func(r *http.Request) string
+
+// ByIP limits requests per client IP address, as seen in RemoteAddr. Behind
+// a proxy, use ByHeader with the header it sets, or a KeyFunc to parse it.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByHeader limits requests per value of the named header, such as an API
+// key. Requests without the header are not limited.
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// MiddlewareConfig configures Middleware
+type MiddlewareConfig struct {
+	// Max is the number of requests allowed per Window and key
+	Max    int
+	Window time.Duration
+
+	// Key returns the key a request is limited under. Defaults to ByIP.
+	Key KeyFunc
+
+	// Limited responds to requests over the limit, after the RateLimit and
+	// Retry-After headers are set. Defaults to a plain 429 Too Many
+	// Requests.
+	Limited http.Handler
+
+	// FailClosed rejects requests with 503 Service Unavailable when attempts
+	// cannot be counted, such as during a Redis outage. By default they are
+	// let through.
+	FailClosed bool
+}
+
+// Middleware returns HTTP middleware counting each request as an attempt
+// under its key. Responses carry the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers; requests over the limit get a Retry-After
+// header and are passed to cfg.Limited instead of the next handler.
+func (l *Limiter) Middleware(cfg MiddlewareConfig) func(http.Handler) http.Handler {
+	keyFunc := cfg.Key
+	if keyFunc == nil {
+		keyFunc = ByIP
+	}
+	limited := cfg.Limited
+	if limited == nil {
+		limited = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := l.Attempt(r.Context(), key, cfg.Max, cfg.Window)
+			if err != nil {
+				if cfg.FailClosed {
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", Seconds(result.ResetAfter))
+			if !result.Allowed {
+				h.Set("Retry-After", Seconds(result.RetryAfter))
+				limited.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Seconds formats d as a whole number of seconds, rounded up so clients do
+// not retry early, as the RateLimit-Reset and Retry-After headers expect
+func Seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
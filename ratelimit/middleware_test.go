@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Middleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	request := func(handler http.Handler, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for name, values := range header {
+			r.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("limits per IP", func(t *testing.T) {
+		limiter, _ := setupLimiter(t, Config{})
+		handler := limiter.Middleware(MiddlewareConfig{Max: 2, Window: time.Minute})(ok)
+
+		w := request(handler, "10.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+
+		request(handler, "10.0.0.1:5678", nil)
+		w = request(handler, "10.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+		// Other clients have their own limit
+		w = request(handler, "10.0.0.2:1234", nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("limits per header", func(t *testing.T) {
+		limiter, _ := setupLimiter(t, Config{})
+		limited := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		handler := limiter.Middleware(MiddlewareConfig{
+			Max:     1,
+			Window:  time.Minute,
+			Key:     ByHeader("X-API-Key"),
+			Limited: limited,
+		})(ok)
+
+		key := http.Header{"X-Api-Key": {"secret"}}
+		assert.Equal(t, http.StatusNoContent, request(handler, "10.0.0.1:1", key).Code)
+		assert.Equal(t, http.StatusTeapot, request(handler, "10.0.0.2:1", key).Code)
+
+		// Requests without a key are not limited
+		for i := 0; i < 3; i++ {
+			w := request(handler, "10.0.0.1:1", nil)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("redis errors", func(t *testing.T) {
+		limiter, mr := setupLimiter(t, Config{})
+		mr.SetError("LOADING")
+
+		handler := limiter.Middleware(MiddlewareConfig{Max: 1, Window: time.Minute})(ok)
+		assert.Equal(t, http.StatusNoContent, request(handler, "10.0.0.1:1", nil).Code)
+
+		handler = limiter.Middleware(MiddlewareConfig{Max: 1, Window: time.Minute, FailClosed: true})(ok)
+		assert.Equal(t, http.StatusServiceUnavailable, request(handler, "10.0.0.1:1", nil).Code)
+	})
+}
+
+func TestByIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal(t, "2001:db8::1", ByIP(r))
+
+	r.RemoteAddr = "unix"
+	assert.Equal(t, "unix", ByIP(r))
+}
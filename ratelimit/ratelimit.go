@@ -0,0 +1,185 @@
+// Package ratelimit limits how often actions may be performed, counting
+// attempts in Redis so limits hold across every instance of an application
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// defaultPrefix namespaces limiter keys when Config does not set a prefix
+const defaultPrefix = "ratelimit:"
+
+// ErrInvalidLimit is returned for limits whose maximum is not positive or
+// whose window is under a millisecond, the resolution attempts are counted at
+var ErrInvalidLimit = errors.New("rate limit max must be positive and window at least 1ms")
+
+// ErrUnsupportedAlgorithm is returned by the operations that only apply to
+// fixed windows when the limiter uses another algorithm
+var ErrUnsupportedAlgorithm = errors.New("operation is only supported by fixed window limiters")
+
+// Config configures a Limiter
+type Config struct {
+	// Prefix namespaces the keys attempts are counted under, within the
+	// client's own prefix. Defaults to "ratelimit:".
+	Prefix string
+
+	// Algorithm selects how attempts are counted. Defaults to FixedWindow.
+	Algorithm Algorithm
+
+	// Burst is the size of the bucket with TokenBucket, the number of
+	// attempts that may be made at once. Defaults to the max of each
+	// attempt.
+	Burst int
+}
+
+// Result is the outcome of an attempt
+type Result struct {
+	// Allowed reports whether the attempt was within the limit
+	Allowed bool
+
+	// Limit is the maximum number of attempts per window
+	Limit int
+
+	// Remaining is the number of attempts left in the current window
+	Remaining int
+
+	// RetryAfter is how long to wait before the next attempt may be
+	// allowed, zero when this one was
+	RetryAfter time.Duration
+
+	// ResetAfter is how long until the limit is fully restored, such as the
+	// end of the current fixed window
+	ResetAfter time.Duration
+}
+
+// Limiter counts attempts per key with the configured algorithm, in the
+// manner of Laravel's RateLimiter
+type Limiter struct {
+	client    *redis.Client
+	prefix    string
+	algorithm Algorithm
+	burst     int
+	script    string
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// New returns a limiter counting attempts with client, registering the
+// script of its algorithm. Sliding windows and token buckets are computed
+// from the clocks of the application instances, which should be kept in
+// sync.
+func New(ctx context.Context, client *redis.Client, cfg Config) (*Limiter, error) {
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+
+	name, src := cfg.Algorithm.script()
+	if err := client.RegisterScript(ctx, name, src); err != nil {
+		return nil, err
+	}
+	return &Limiter{
+		client:    client,
+		prefix:    prefix,
+		algorithm: cfg.Algorithm,
+		burst:     cfg.Burst,
+		script:    name,
+		now:       time.Now,
+	}, nil
+}
+
+// key returns the key attempts for key are counted under
+func (l *Limiter) key(key string) string {
+	return l.prefix + key
+}
+
+// Attempt counts an attempt for key if it is within the limit of max per
+// window. With FixedWindow the window starts with the first attempt.
+// Attempts over the limit are not counted.
+func (l *Limiter) Attempt(ctx context.Context, key string, max int, window time.Duration) (Result, error) {
+	if max <= 0 || window < time.Millisecond {
+		return Result{}, ErrInvalidLimit
+	}
+
+	args, err := l.args(max, window, l.now())
+	if err != nil {
+		return Result{}, err
+	}
+	reply, err := l.client.RunScript(ctx, l.script, []string{l.key(key)}, args...)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	var n [4]int64
+	for i, value := range values {
+		n[i], _ = value.(int64)
+	}
+
+	return Result{
+		Allowed:    n[0] == 1,
+		Limit:      max,
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Millisecond,
+		ResetAfter: time.Duration(n[3]) * time.Millisecond,
+	}, nil
+}
+
+// Hit counts an attempt for key whatever the limit, starting a window of
+// the given length if none is running, and returns the attempts made in the
+// window. It is only supported with FixedWindow.
+func (l *Limiter) Hit(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if l.algorithm != FixedWindow {
+		return 0, ErrUnsupportedAlgorithm
+	}
+	return l.client.IncrementWithTTL(ctx, l.key(key), 1, window)
+}
+
+// Attempts returns the number of attempts made for key in the current
+// window. It is only supported with FixedWindow.
+func (l *Limiter) Attempts(ctx context.Context, key string) (int64, error) {
+	if l.algorithm != FixedWindow {
+		return 0, ErrUnsupportedAlgorithm
+	}
+	value, err := l.client.Get(ctx, l.key(key))
+	if errors.Is(err, redis.ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	attempts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid attempt count for %q: %w", key, err)
+	}
+	return attempts, nil
+}
+
+// Clear resets the attempts for key, whatever the algorithm
+func (l *Limiter) Clear(ctx context.Context, key string) error {
+	return l.client.Forget(ctx, l.key(key))
+}
+
+// AvailableIn returns how long until the current window for key resets,
+// zero when no window is running. It is only supported with FixedWindow.
+func (l *Limiter) AvailableIn(ctx context.Context, key string) (time.Duration, error) {
+	if l.algorithm != FixedWindow {
+		return 0, ErrUnsupportedAlgorithm
+	}
+	ttl, err := l.client.GetTTL(ctx, l.key(key))
+	if errors.Is(err, redis.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return ttl, err
+}
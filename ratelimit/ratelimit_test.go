@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+// setupLimiter creates a mock Redis server and a limiter using it
+func setupLimiter(t *testing.T, cfg Config) (*Limiter, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	limiter, err := New(context.Background(), client, cfg)
+	require.NoError(t, err)
+	return limiter, mr
+}
+
+func TestLimiter_Attempt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allows up to max attempts per window", func(t *testing.T) {
+		limiter, mr := setupLimiter(t, Config{})
+
+		for i := 1; i <= 3; i++ {
+			result, err := limiter.Attempt(ctx, "login:ada", 3, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, 3-i, result.Remaining)
+			assert.Zero(t, result.RetryAfter)
+			assert.Equal(t, time.Minute, result.ResetAfter)
+		}
+
+		result, err := limiter.Attempt(ctx, "login:ada", 3, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Zero(t, result.Remaining)
+		assert.Equal(t, time.Minute, result.RetryAfter)
+
+		// Rejected attempts are not counted
+		attempts, err := limiter.Attempts(ctx, "login:ada")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), attempts)
+		assert.True(t, mr.Exists("app:ratelimit:login:ada"))
+
+		// A new window starts once the current one is over
+		mr.FastForward(time.Minute)
+		result, err = limiter.Attempt(ctx, "login:ada", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("keys are limited separately", func(t *testing.T) {
+		limiter, _ := setupLimiter(t, Config{Prefix: "limits:"})
+
+		result, err := limiter.Attempt(ctx, "a", 1, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = limiter.Attempt(ctx, "b", 1, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = limiter.Attempt(ctx, "a", 1, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("invalid limits", func(t *testing.T) {
+		limiter, _ := setupLimiter(t, Config{})
+
+		_, err := limiter.Attempt(ctx, "key", 0, time.Minute)
+		assert.Equal(t, ErrInvalidLimit, err)
+		_, err = limiter.Attempt(ctx, "key", 1, 0)
+		assert.Equal(t, ErrInvalidLimit, err)
+		_, err = limiter.Attempt(ctx, "key", 1, 500*time.Microsecond)
+		assert.Equal(t, ErrInvalidLimit, err)
+	})
+}
+
+func TestLimiter_HitClearAvailableIn(t *testing.T) {
+	ctx := context.Background()
+	limiter, mr := setupLimiter(t, Config{})
+
+	available, err := limiter.AvailableIn(ctx, "api")
+	require.NoError(t, err)
+	assert.Zero(t, available)
+
+	for i := int64(1); i <= 3; i++ {
+		hits, err := limiter.Hit(ctx, "api", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, hits)
+	}
+
+	mr.FastForward(20 * time.Second)
+	available, err = limiter.AvailableIn(ctx, "api")
+	require.NoError(t, err)
+	assert.Equal(t, 40*time.Second, available)
+
+	result, err := limiter.Attempt(ctx, "api", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	require.NoError(t, limiter.Clear(ctx, "api"))
+	attempts, err := limiter.Attempts(ctx, "api")
+	require.NoError(t, err)
+	assert.Zero(t, attempts)
+
+	result, err = limiter.Attempt(ctx, "api", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultArchiveTTL is used when Config.ArchiveTTL is not set
+const defaultArchiveTTL = 24 * time.Hour
+
+// ArchiveExpiringSoon copies keys matching pattern, relative to the client's
+// prefix, whose TTL runs out within the given window into coldDB, where they
+// are kept for the configured ArchiveTTL beyond their expiry in the hot
+// database. Keys without a TTL are left alone, as are keys written to while
+// being archived. It returns the number of keys archived. Redis Cluster has
+// a single database, so archiving returns ErrClusterUnsupported in cluster
+// mode, as it does behind a Ring.
+func (c *Client) ArchiveExpiringSoon(ctx context.Context, pattern string, within time.Duration, coldDB int) (int64, error) {
+	if c.sharded() {
+		return 0, ErrClusterUnsupported
+	}
+	hotDB := c.db
+
+	var archived int64
+	iter := c.client.Scan(ctx, 0, escapePattern(c.prefix)+pattern, c.scanPageSize).Iterator()
+	for iter.Next(ctx) {
+		ok, err := c.archive(ctx, iter.Val(), within, hotDB, coldDB)
+		if err != nil {
+			return archived, err
+		}
+		if ok {
+			archived++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return archived, err
+	}
+
+	return archived, nil
+}
+
+// archive copies a single key expiring within the window into coldDB, where
+// it expires the archive TTL after the original. The key is watched so its
+// TTL cannot change between reading it and applying it to the copy, and the
+// connection is switched back to hotDB before it is returned to the pool.
+func (c *Client) archive(ctx context.Context, key string, within time.Duration, hotDB, coldDB int) (bool, error) {
+	var copied *redis.IntCmd
+	err := c.client.Watch(ctx, func(tx *redis.Tx) error {
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		// Negative values mean the key has no TTL or no longer exists
+		if ttl <= 0 || ttl > within {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			copied = pipe.Copy(ctx, key, key, coldDB, true)
+			pipe.Select(ctx, coldDB)
+			pipe.PExpire(ctx, key, ttl+c.archiveTTL)
+			pipe.Select(ctx, hotDB)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return copied != nil && copied.Val() == 1, nil
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ArchiveExpiringSoon(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{ArchiveTTL: 48 * time.Hour})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "session:1", "soon", 30*time.Second))
+	require.NoError(t, client.Put(ctx, "session:2", "also-soon", 5*time.Minute))
+	require.NoError(t, client.Put(ctx, "session:3", "later", 2*time.Hour))
+	require.NoError(t, client.Forever(ctx, "session:4", "forever"))
+	require.NoError(t, client.Put(ctx, "other:1", "soon-but-unmatched", 30*time.Second))
+
+	t.Run("archives only keys expiring within the window", func(t *testing.T) {
+		count, err := client.ArchiveExpiringSoon(ctx, "session:*", 10*time.Minute, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		cold := mr.DB(1)
+		assert.True(t, cold.Exists("session:1"))
+		assert.True(t, cold.Exists("session:2"))
+		assert.False(t, cold.Exists("session:3"))
+		assert.False(t, cold.Exists("session:4"))
+		assert.False(t, cold.Exists("other:1"))
+
+		val, err := cold.Get("session:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "soon", val)
+		assert.Equal(t, 48*time.Hour+30*time.Second, cold.TTL("session:1"))
+		assert.Equal(t, 48*time.Hour+5*time.Minute, cold.TTL("session:2"))
+	})
+
+	t.Run("hot keys are kept", func(t *testing.T) {
+		val, err := client.Get(ctx, "session:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "soon", val)
+	})
+
+	t.Run("client stays on the hot database", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "after-archive", "value", time.Hour))
+
+		assert.True(t, mr.DB(0).Exists("after-archive"))
+		assert.False(t, mr.DB(1).Exists("after-archive"))
+	})
+
+	t.Run("no matching keys", func(t *testing.T) {
+		count, err := client.ArchiveExpiringSoon(ctx, "missing:*", 10*time.Minute, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetMany retrieves several items from the cache in a single round trip.
+// Keys that are not found are left out of the returned map.
+func (c *Client) GetMany(ctx context.Context, keys ...string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, err := c.mget(ctx, c.keys(keys)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		// MGET returns nil for missing keys
+		if s, ok := value.(string); ok {
+			decoded, err := c.decodeValue(keys[i], s)
+			if errors.Is(err, errCachedMiss) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			result[keys[i]] = decoded
+		}
+	}
+	c.stats.lookup(len(keys), len(result))
+
+	return result, nil
+}
+
+// PutMany stores several items in the cache for a given duration in a single
+// pipeline
+func (c *Client) PutMany(ctx context.Context, items map[string]string, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string]string, len(items))
+	for key, value := range items {
+		e, err := c.encodeValue(key, value)
+		if err != nil {
+			return err
+		}
+		encoded[key] = e
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range encoded {
+			pipe.Set(ctx, c.key(key), value, c.jitter(ttl))
+		}
+		return nil
+	})
+	return err
+}
+
+// ForgetMany removes several items from the cache, along with any items
+// depending on them, with a single DEL outside of cluster mode
+func (c *Client) ForgetMany(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	toDelete, err := c.collectDependents(ctx, keys...)
+	if err != nil {
+		return err
+	}
+	_, err = c.del(ctx, c.keys(toDelete)...)
+	return err
+}
+
+// RememberMany gets several items from the cache in a single round trip,
+// calling loader once with the keys that were not found and storing the
+// encoded results it returns in a single pipeline. Keys the loader leaves
+// out are left out of the returned map, and are not cached.
+func (c *Client) RememberMany(ctx context.Context, keys []string, ttl time.Duration, loader func(missing []string) (map[string]interface{}, error)) (map[string]string, error) {
+	result, err := c.GetMany(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := result[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	if loader == nil {
+		return nil, ErrNilCallback
+	}
+
+	loaded, err := loader(missing)
+	if err != nil {
+		return nil, fmt.Errorf("loader execution failed: %w", err)
+	}
+
+	items := make(map[string]string, len(loaded))
+	for key, value := range loaded {
+		encoded, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal loader result for %q: %w", key, err)
+		}
+		items[key] = string(encoded)
+	}
+	if err := c.PutMany(ctx, items, ttl); err != nil {
+		return nil, err
+	}
+
+	for key, value := range items {
+		result[key] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetMany(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "key1", "value1", time.Hour))
+	require.NoError(t, client.Put(ctx, "key2", "value2", time.Hour))
+
+	t.Run("existing and missing keys", func(t *testing.T) {
+		values, err := client.GetMany(ctx, "key1", "missing", "key2")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2"}, values)
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		values, err := client.GetMany(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+	})
+}
+
+func TestClient_PutMany(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	err := client.PutMany(ctx, map[string]string{"key1": "value1", "key2": "value2"}, time.Hour)
+	require.NoError(t, err)
+
+	values, err := client.GetMany(ctx, "key1", "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2"}, values)
+	assert.True(t, mr.TTL("key1") > 0)
+	assert.True(t, mr.TTL("key2") > 0)
+
+	assert.NoError(t, client.PutMany(ctx, nil, time.Hour))
+}
+
+func TestClient_ForgetMany(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, client.PutMany(ctx, map[string]string{"key1": "1", "key2": "2", "key3": "3"}, time.Hour))
+	require.NoError(t, client.PutDependent(ctx, "derived", "1+2", time.Hour, "key1"))
+
+	require.NoError(t, client.ForgetMany(ctx, "key1", "key2", "missing"))
+
+	assert.False(t, mr.Exists("key1"))
+	assert.False(t, mr.Exists("key2"))
+	assert.False(t, mr.Exists("derived"))
+	assert.True(t, mr.Exists("key3"))
+
+	assert.NoError(t, client.ForgetMany(ctx))
+}
+
+func TestClient_RememberMany(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("loads only the misses", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "user:1", `{"id":1}`, time.Hour))
+
+		var requested []string
+		loader := func(missing []string) (map[string]interface{}, error) {
+			requested = append(requested, missing...)
+			return map[string]interface{}{
+				"user:2": map[string]int{"id": 2},
+				"user:3": map[string]int{"id": 3},
+			}, nil
+		}
+
+		values, err := client.RememberMany(ctx, []string{"user:1", "user:2", "user:3", "user:4"}, time.Minute, loader)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:2", "user:3", "user:4"}, requested)
+		assert.Equal(t, map[string]string{
+			"user:1": `{"id":1}`,
+			"user:2": `{"id":2}`,
+			"user:3": `{"id":3}`,
+		}, values)
+
+		assert.Equal(t, time.Minute, mr.TTL("user:2"))
+		assert.False(t, mr.Exists("user:4"))
+	})
+
+	t.Run("all hits skip the loader", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "a", "1", time.Hour))
+
+		values, err := client.RememberMany(ctx, []string{"a"}, time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "1"}, values)
+	})
+
+	t.Run("loader errors", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		_, err := client.RememberMany(ctx, []string{"a"}, time.Minute, func([]string) (map[string]interface{}, error) {
+			return nil, errors.New("query failed")
+		})
+		assert.EqualError(t, err, "loader execution failed: query failed")
+
+		_, err = client.RememberMany(ctx, []string{"a"}, time.Minute, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
@@ -0,0 +1,202 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BitOp is a bitwise operation combining bitmaps
+type BitOp string
+
+const (
+	BitAnd BitOp = "AND"
+	BitOr  BitOp = "OR"
+	BitXor BitOp = "XOR"
+	BitNot BitOp = "NOT"
+)
+
+// Bitmap is a Redis string addressed bit by bit, such as one bit per user ID
+// recording who was active on a given day. A bitmap takes one bit per
+// offset up to the highest one set, so offsets should be dense integers
+// below 2^32. Operations combining several bitmaps require, in cluster mode,
+// that their keys share a hash tag.
+type Bitmap struct {
+	client *Client
+	key    string
+}
+
+// Bitmap returns a handle on the bitmap stored at key. No command is sent
+// until one of its methods is called.
+func (c *Client) Bitmap(key string) *Bitmap {
+	return &Bitmap{client: c, key: key}
+}
+
+// BitmapAt returns the bitmap of the period of bucket containing t, such as
+// the users active on a given day
+func (c *Client) BitmapAt(prefix string, bucket Bucket, t time.Time) *Bitmap {
+	return c.Bitmap(bucket.Key(prefix, t))
+}
+
+// Key returns the key of the bitmap, without the client prefix
+func (b *Bitmap) Key() string {
+	return b.key
+}
+
+// SetBit sets or clears the bit at offset and returns its previous value
+func (b *Bitmap) SetBit(ctx context.Context, offset int64, on bool) (bool, error) {
+	value := 0
+	if on {
+		value = 1
+	}
+	previous, err := b.client.client.SetBit(ctx, b.client.key(b.key), offset, value).Result()
+	return previous == 1, err
+}
+
+// GetBit returns the bit at offset. Bits past the end of the bitmap are
+// cleared.
+func (b *Bitmap) GetBit(ctx context.Context, offset int64) (bool, error) {
+	var value int64
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		value, err = cmd.GetBit(ctx, b.client.key(b.key), offset).Result()
+		return err
+	})
+	return value == 1, err
+}
+
+// BitCount returns the number of bits set
+func (b *Bitmap) BitCount(ctx context.Context) (int64, error) {
+	var n int64
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		n, err = cmd.BitCount(ctx, b.client.key(b.key), nil).Result()
+		return err
+	})
+	return n, err
+}
+
+// BitOp stores the result of op over sources in the bitmap, replacing its
+// bits, and returns the length in bytes of the result. NOT takes exactly one
+// source.
+func (b *Bitmap) BitOp(ctx context.Context, op BitOp, sources ...*Bitmap) (int64, error) {
+	dest := b.client.key(b.key)
+	keys := make([]string, len(sources))
+	for i, source := range sources {
+		keys[i] = source.client.key(source.key)
+	}
+
+	switch op {
+	case BitAnd:
+		return b.client.client.BitOpAnd(ctx, dest, keys...).Result()
+	case BitOr:
+		return b.client.client.BitOpOr(ctx, dest, keys...).Result()
+	case BitXor:
+		return b.client.client.BitOpXor(ctx, dest, keys...).Result()
+	case BitNot:
+		if len(keys) != 1 {
+			return 0, ErrInvalidBitOp
+		}
+		return b.client.client.BitOpNot(ctx, dest, keys[0]).Result()
+	default:
+		return 0, fmt.Errorf("unknown bit operation %q", string(op))
+	}
+}
+
+// Expire sets how long the bitmap is kept
+func (b *Bitmap) Expire(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return b.client.client.PExpire(ctx, b.client.key(b.key), ttl).Err()
+}
+
+// Clear removes the bitmap
+func (b *Bitmap) Clear(ctx context.Context) error {
+	return b.client.client.Del(ctx, b.client.key(b.key)).Err()
+}
+
+// MarkActive records id as active in the period of bucket containing t
+func (c *Client) MarkActive(ctx context.Context, prefix string, bucket Bucket, t time.Time, id int64) error {
+	_, err := c.BitmapAt(prefix, bucket, t).SetBit(ctx, id, true)
+	return err
+}
+
+// WasActive reports whether id was active in the period of bucket
+// containing t
+func (c *Client) WasActive(ctx context.Context, prefix string, bucket Bucket, t time.Time, id int64) (bool, error) {
+	return c.BitmapAt(prefix, bucket, t).GetBit(ctx, id)
+}
+
+// CountActive returns the number of ids active in the period of bucket
+// containing t
+func (c *Client) CountActive(ctx context.Context, prefix string, bucket Bucket, t time.Time) (int64, error) {
+	return c.BitmapAt(prefix, bucket, t).BitCount(ctx)
+}
+
+// Retention is how many of a cohort, the ids active in one period, were
+// active again in each of the following periods
+type Retention struct {
+	// Size is the number of ids in the cohort
+	Size int64
+
+	// Retained holds, for each following period, the number of ids of the
+	// cohort active in it
+	Retained []int64
+}
+
+// Rate returns the share of the cohort active in the following period at
+// index period, between 0 and 1
+func (r Retention) Rate(period int) float64 {
+	if r.Size == 0 || period < 0 || period >= len(r.Retained) {
+		return 0
+	}
+	return float64(r.Retained[period]) / float64(r.Size)
+}
+
+// Retention computes the retention of the cohort of ids marked active in
+// the period of bucket containing cohort, over the periods following it.
+// Each period is intersected with the cohort into a temporary key derived
+// from prefix, so in cluster mode prefix should carry a hash tag, such as
+// "{active}".
+func (c *Client) Retention(ctx context.Context, prefix string, bucket Bucket, cohort time.Time, periods int) (Retention, error) {
+	base := c.BitmapAt(prefix, bucket, cohort)
+	size, err := base.BitCount(ctx)
+	if err != nil {
+		return Retention{}, err
+	}
+
+	if periods < 0 {
+		periods = 0
+	}
+	result := Retention{Size: size, Retained: make([]int64, periods)}
+	if periods == 0 || size == 0 {
+		return result, nil
+	}
+
+	id, err := newLockOwner()
+	if err != nil {
+		return Retention{}, err
+	}
+	tmp := c.key(prefix + ":retention:" + id)
+
+	counts := make([]*redis.IntCmd, periods)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		period := bucket.start(cohort)
+		for i := range counts {
+			period = bucket.next(period)
+			pipe.BitOpAnd(ctx, tmp, c.key(base.key), c.key(bucket.Key(prefix, period)))
+			counts[i] = pipe.BitCount(ctx, tmp, nil)
+		}
+		pipe.Del(ctx, tmp)
+		return nil
+	})
+	if err != nil {
+		return Retention{}, fmt.Errorf("failed to compute retention: %w", err)
+	}
+
+	for i, count := range counts {
+		result.Retained[i] = count.Val()
+	}
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Bitmap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("bits", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		flags := client.Bitmap("flags")
+
+		previous, err := flags.SetBit(ctx, 7, true)
+		require.NoError(t, err)
+		assert.False(t, previous)
+
+		previous, err = flags.SetBit(ctx, 7, true)
+		require.NoError(t, err)
+		assert.True(t, previous)
+
+		on, err := flags.GetBit(ctx, 7)
+		require.NoError(t, err)
+		assert.True(t, on)
+
+		on, err = flags.GetBit(ctx, 1000)
+		require.NoError(t, err)
+		assert.False(t, on)
+		assert.True(t, mr.Exists("app:flags"))
+
+		_, err = flags.SetBit(ctx, 3, true)
+		require.NoError(t, err)
+		n, err := flags.BitCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		_, err = flags.SetBit(ctx, 7, false)
+		require.NoError(t, err)
+		n, err = flags.BitCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		require.NoError(t, flags.Clear(ctx))
+		assert.False(t, mr.Exists("app:flags"))
+	})
+
+	t.Run("operations", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		a, b, dest := client.Bitmap("a"), client.Bitmap("b"), client.Bitmap("dest")
+		for _, offset := range []int64{1, 2} {
+			_, err := a.SetBit(ctx, offset, true)
+			require.NoError(t, err)
+		}
+		for _, offset := range []int64{2, 3} {
+			_, err := b.SetBit(ctx, offset, true)
+			require.NoError(t, err)
+		}
+
+		count := func(op BitOp, sources ...*Bitmap) int64 {
+			_, err := dest.BitOp(ctx, op, sources...)
+			require.NoError(t, err)
+			n, err := dest.BitCount(ctx)
+			require.NoError(t, err)
+			return n
+		}
+		assert.Equal(t, int64(1), count(BitAnd, a, b))
+		assert.Equal(t, int64(3), count(BitOr, a, b))
+		assert.Equal(t, int64(2), count(BitXor, a, b))
+		assert.Equal(t, int64(6), count(BitNot, a))
+
+		_, err := dest.BitOp(ctx, BitNot, a, b)
+		assert.ErrorIs(t, err, ErrInvalidBitOp)
+		_, err = dest.BitOp(ctx, BitOp("NAND"), a, b)
+		assert.Error(t, err)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		flags := client.Bitmap("flags")
+		_, err := flags.SetBit(ctx, 1, true)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, flags.Expire(ctx, 0), ErrInvalidTTL)
+		require.NoError(t, flags.Expire(ctx, time.Hour))
+		assert.Equal(t, time.Hour, mr.TTL("flags"))
+	})
+
+	t.Run("daily activity", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		day := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+
+		require.NoError(t, client.MarkActive(ctx, "active", Daily, day, 42))
+		require.NoError(t, client.MarkActive(ctx, "active", Daily, day.Add(time.Hour), 7))
+		assert.True(t, mr.Exists("active:2024-03-09"))
+
+		active, err := client.WasActive(ctx, "active", Daily, day, 42)
+		require.NoError(t, err)
+		assert.True(t, active)
+
+		active, err = client.WasActive(ctx, "active", Daily, day.AddDate(0, 0, 1), 42)
+		require.NoError(t, err)
+		assert.False(t, active)
+
+		n, err := client.CountActive(ctx, "active", Daily, day)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		day := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+
+		activity := [][]int64{
+			{1, 2, 3, 4}, // the cohort
+			{1, 2, 9},
+			{},
+			{4, 8},
+		}
+		for i, ids := range activity {
+			for _, id := range ids {
+				require.NoError(t, client.MarkActive(ctx, "active", Daily, day.AddDate(0, 0, i), id))
+			}
+		}
+
+		retention, err := client.Retention(ctx, "active", Daily, day, 3)
+		require.NoError(t, err)
+		assert.Equal(t, Retention{Size: 4, Retained: []int64{2, 0, 1}}, retention)
+		assert.Equal(t, 0.5, retention.Rate(0))
+		assert.Equal(t, 0.25, retention.Rate(2))
+		assert.Zero(t, retention.Rate(3))
+
+		// The temporary key is removed, leaving the days with any activity
+		assert.Equal(t, []string{"app:active:2024-03-09", "app:active:2024-03-10", "app:active:2024-03-12"}, mr.Keys())
+
+		retention, err = client.Retention(ctx, "active", Daily, day.AddDate(0, 0, -1), 2)
+		require.NoError(t, err)
+		assert.Equal(t, Retention{Size: 0, Retained: []int64{0, 0}}, retention)
+
+		retention, err = client.Retention(ctx, "active", Daily, day, -1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), retention.Size)
+		assert.Empty(t, retention.Retained)
+	})
+}
@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bucket splits time into periods, naming a key per period so counters
+// such as daily active users can be kept for each day and combined over a
+// range. Periods are computed in the location of the times given, so
+// callers should settle on one, typically UTC.
+type Bucket int
+
+const (
+	// Hourly names keys like "visitors:2024-03-09T14"
+	Hourly Bucket = iota
+
+	// Daily names keys like "visitors:2024-03-09"
+	Daily
+
+	// Weekly names keys after ISO weeks, starting on Monday, like
+	// "visitors:2024-W10"
+	Weekly
+
+	// Monthly names keys like "visitors:2024-03"
+	Monthly
+)
+
+// String returns the name of the bucket
+func (b Bucket) String() string {
+	switch b {
+	case Hourly:
+		return "hourly"
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	default:
+		return fmt.Sprintf("Bucket(%d)", int(b))
+	}
+}
+
+// Key returns the key of the period containing t, prefix followed by a
+// colon and the period
+func (b Bucket) Key(prefix string, t time.Time) string {
+	return prefix + ":" + b.period(t)
+}
+
+// Keys returns the keys of every period from the one containing from to the
+// one containing to, oldest first. It returns nil when to is before from.
+func (b Bucket) Keys(prefix string, from, to time.Time) []string {
+	if to.Before(from) {
+		return nil
+	}
+
+	var keys []string
+	end := b.start(to)
+	for t := b.start(from); !t.After(end); t = b.next(t) {
+		// An hour repeated when clocks go back has a single key
+		if key := b.Key(prefix, t); len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// period formats the period containing t
+func (b Bucket) period(t time.Time) string {
+	switch b {
+	case Hourly:
+		return t.Format("2006-01-02T15")
+	case Weekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Monthly:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// start returns the start of the period containing t
+func (b Bucket) start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch b {
+	case Hourly:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case Weekly:
+		// Monday is day 1 of ISO weeks, Sunday day 7
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns the start of the period following the one starting at t
+func (b Bucket) next(t time.Time) time.Time {
+	switch b {
+	case Hourly:
+		return t.Add(time.Hour)
+	case Weekly:
+		return t.AddDate(0, 0, 7)
+	case Monthly:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
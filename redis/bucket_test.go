@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket(t *testing.T) {
+	at := time.Date(2024, time.March, 9, 14, 30, 0, 0, time.UTC)
+
+	t.Run("keys", func(t *testing.T) {
+		assert.Equal(t, "dau:2024-03-09T14", Hourly.Key("dau", at))
+		assert.Equal(t, "dau:2024-03-09", Daily.Key("dau", at))
+		assert.Equal(t, "dau:2024-W10", Weekly.Key("dau", at))
+		assert.Equal(t, "dau:2024-03", Monthly.Key("dau", at))
+
+		// ISO weeks can belong to the previous year
+		assert.Equal(t, "dau:2020-W53", Weekly.Key("dau", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		assert.Equal(t, []string{"dau:2024-03-09T14", "dau:2024-03-09T15", "dau:2024-03-09T16"},
+			Hourly.Keys("dau", at, at.Add(2*time.Hour)))
+		assert.Equal(t, []string{"dau:2024-02-28", "dau:2024-02-29", "dau:2024-03-01"},
+			Daily.Keys("dau", time.Date(2024, time.February, 28, 23, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 1, 0, 0, 0, time.UTC)))
+		assert.Equal(t, []string{"dau:2024-W10", "dau:2024-W11"},
+			Weekly.Keys("dau", at, at.AddDate(0, 0, 2)))
+		assert.Equal(t, []string{"dau:2023-12", "dau:2024-01"},
+			Monthly.Keys("dau", time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)))
+
+		assert.Equal(t, []string{"dau:2024-03-09"}, Daily.Keys("dau", at, at))
+		assert.Nil(t, Daily.Keys("dau", at, at.Add(-time.Hour)))
+	})
+
+	t.Run("daylight saving time", func(t *testing.T) {
+		loc, err := time.LoadLocation("Europe/London")
+		require.NoError(t, err)
+
+		// Clocks go back at 2:00 BST on 27 October 2024, repeating 1:00
+		from := time.Date(2024, time.October, 27, 0, 30, 0, 0, loc)
+		assert.Equal(t, []string{"dau:2024-10-27T00", "dau:2024-10-27T01", "dau:2024-10-27T02"},
+			Hourly.Keys("dau", from, from.Add(3*time.Hour)))
+
+		// Days stay whole when a day is 25 hours long
+		assert.Equal(t, []string{"dau:2024-10-27", "dau:2024-10-28"},
+			Daily.Keys("dau", from, time.Date(2024, time.October, 28, 23, 30, 0, 0, loc)))
+	})
+
+	t.Run("names", func(t *testing.T) {
+		assert.Equal(t, "daily", Daily.String())
+		assert.Equal(t, "Bucket(9)", Bucket(9).String())
+	})
+}
@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// GetBytes retrieves an item from the cache as raw bytes. Values are stored
+// as they are, so binary payloads such as protobufs or images need no
+// base64 encoding and may be written with either API.
+func (c *Client) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// PutBytes stores raw bytes in the cache for a given duration, compressed
+// and encrypted like any other value when configured
+func (c *Client) PutBytes(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.Put(ctx, key, string(value), ttl)
+}
+
+// ForeverBytes stores raw bytes in the cache permanently
+func (c *Client) ForeverBytes(ctx context.Context, key string, value []byte) error {
+	return c.Forever(ctx, key, string(value))
+}
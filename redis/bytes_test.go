@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Bytes(t *testing.T) {
+	ctx := context.Background()
+
+	// Invalid UTF-8, NUL bytes and a leading value header marker
+	payloads := map[string][]byte{
+		"binary": {0xff, 0xfe, 0x00, 0x01, 0x80},
+		"header": append([]byte(valueMagic+"g"), 0xff, 0x00),
+		"empty":  {},
+	}
+
+	t.Run("round trips unchanged", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		for name, payload := range payloads {
+			require.NoError(t, client.PutBytes(ctx, name, payload, time.Hour))
+
+			value, err := client.GetBytes(ctx, name)
+			require.NoError(t, err, name)
+			assert.Equal(t, payload, value, name)
+		}
+
+		raw, err := mr.Get("binary")
+		require.NoError(t, err)
+		assert.Equal(t, string(payloads["binary"]), raw)
+	})
+
+	t.Run("compressed and encrypted", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			Compression:          CompressionZstd,
+			CompressionThreshold: 1,
+			EncryptionKeys:       map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)},
+			EncryptionKeyID:      "v1",
+		})
+		defer mr.Close()
+
+		for name, payload := range payloads {
+			require.NoError(t, client.ForeverBytes(ctx, name, payload))
+
+			value, err := client.GetBytes(ctx, name)
+			require.NoError(t, err, name)
+			assert.Equal(t, payload, value, name)
+		}
+		assert.Zero(t, mr.TTL("binary"))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		value, err := client.GetBytes(ctx, "missing")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Nil(t, value)
+	})
+}
@@ -0,0 +1,227 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newUniversalClient builds the underlying go-redis client for cfg: a cluster
+// client when cluster mode is enabled, a Sentinel backed failover client when
+// a master name is set, otherwise a single node client. tlsConfig, when not
+// nil, enables TLS for every connection.
+func newUniversalClient(cfg Config, tlsConfig *tls.Config) (redis.UniversalClient, error) {
+	if cfg.URL != "" {
+		return newClientFromURL(cfg, tlsConfig)
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:          cfg.addrs(),
+		Password:       cfg.Password,
+		DB:             cfg.DB,
+		TLSConfig:      tlsConfig,
+		RouteByLatency: cfg.RouteByLatency,
+		RouteRandomly:  cfg.RouteRandomly,
+
+		PoolSize:        cfg.PoolSize,
+		MinIdleConns:    cfg.MinIdleConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		PoolTimeout:     cfg.PoolTimeout,
+		ConnMaxIdleTime: cfg.ConnMaxIdleTime,
+		DialTimeout:     cfg.DialTimeout,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		MaxRetries:      cfg.maxRetries(),
+
+		Dialer: cfg.Dialer,
+	}
+
+	switch {
+	case cfg.MasterName != "":
+		opts.MasterName = cfg.MasterName
+		opts.Addrs = cfg.SentinelAddrs
+		opts.SentinelPassword = cfg.SentinelPassword
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case cfg.Cluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		simple := opts.Simple()
+		if cfg.UnixSocket != "" {
+			simple.Network = "unix"
+			simple.Addr = cfg.UnixSocket
+		}
+		return redis.NewClient(simple), nil
+	}
+}
+
+// newClientFromURL builds the underlying go-redis client from cfg.URL, with
+// any pool and timeout settings in cfg taking precedence over the URL's
+func newClientFromURL(cfg Config, tlsConfig *tls.Config) (redis.UniversalClient, error) {
+	if cfg.Cluster {
+		opts, err := redis.ParseClusterURL(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis URL: %w", err)
+		}
+		opts.RouteByLatency = cfg.RouteByLatency
+		opts.RouteRandomly = cfg.RouteRandomly
+		if tlsConfig != nil {
+			opts.TLSConfig = tlsConfig
+		}
+		overrideInt(&opts.PoolSize, cfg.PoolSize)
+		overrideInt(&opts.MinIdleConns, cfg.MinIdleConns)
+		overrideInt(&opts.MaxIdleConns, cfg.MaxIdleConns)
+		overrideDuration(&opts.PoolTimeout, cfg.PoolTimeout)
+		overrideDuration(&opts.ConnMaxIdleTime, cfg.ConnMaxIdleTime)
+		overrideDuration(&opts.DialTimeout, cfg.DialTimeout)
+		overrideDuration(&opts.ReadTimeout, cfg.ReadTimeout)
+		overrideDuration(&opts.WriteTimeout, cfg.WriteTimeout)
+		overrideInt(&opts.MaxRetries, cfg.maxRetries())
+		if cfg.Dialer != nil {
+			opts.Dialer = cfg.Dialer
+		}
+		return redis.NewClusterClient(opts), nil
+	}
+
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if tlsConfig != nil {
+		opts.TLSConfig = tlsConfig
+	}
+	overrideInt(&opts.PoolSize, cfg.PoolSize)
+	overrideInt(&opts.MinIdleConns, cfg.MinIdleConns)
+	overrideInt(&opts.MaxIdleConns, cfg.MaxIdleConns)
+	overrideDuration(&opts.PoolTimeout, cfg.PoolTimeout)
+	overrideDuration(&opts.ConnMaxIdleTime, cfg.ConnMaxIdleTime)
+	overrideDuration(&opts.DialTimeout, cfg.DialTimeout)
+	overrideDuration(&opts.ReadTimeout, cfg.ReadTimeout)
+	overrideDuration(&opts.WriteTimeout, cfg.WriteTimeout)
+	overrideInt(&opts.MaxRetries, cfg.maxRetries())
+	if cfg.Dialer != nil {
+		opts.Dialer = cfg.Dialer
+	}
+	return redis.NewClient(opts), nil
+}
+
+// overrideInt replaces *dst with value when value is set
+func overrideInt(dst *int, value int) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+// overrideDuration replaces *dst with value when value is set
+func overrideDuration(dst *time.Duration, value time.Duration) {
+	if value != 0 {
+		*dst = value
+	}
+}
+
+// sharded reports whether keys are spread over several nodes, in cluster
+// mode or behind a Ring, so multi-key commands must be split
+func (c *Client) sharded() bool {
+	switch c.client.(type) {
+	case *redis.ClusterClient, *redis.Ring:
+		return true
+	default:
+		return false
+	}
+}
+
+// forEachShard calls fn with a client for every node holding part of the
+// keyspace: each master in cluster mode, each shard of a Ring, or the single
+// node otherwise. Other client types return ErrUnsupportedClient.
+func (c *Client) forEachShard(ctx context.Context, fn func(ctx context.Context, shard *redis.Client) error) error {
+	switch client := c.client.(type) {
+	case *redis.ClusterClient:
+		return client.ForEachMaster(ctx, fn)
+	case *redis.Ring:
+		return client.ForEachShard(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, client)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedClient, c.client)
+	}
+}
+
+// del removes keys, returning how many existed. In cluster mode or behind a
+// Ring the keys may live on different nodes, so each one is deleted with its
+// own DEL in a pipeline routed to the right node.
+func (c *Client) del(ctx context.Context, keys ...string) (int64, error) {
+	return c.multiKey(ctx, keys, c.client.Del, func(pipe redis.Pipeliner, key string) *redis.IntCmd {
+		return pipe.Del(ctx, key)
+	})
+}
+
+// unlink is del using UNLINK, which frees memory in the background
+func (c *Client) unlink(ctx context.Context, keys ...string) (int64, error) {
+	return c.multiKey(ctx, keys, c.client.Unlink, func(pipe redis.Pipeliner, key string) *redis.IntCmd {
+		return pipe.Unlink(ctx, key)
+	})
+}
+
+// multiKey runs a multi-key integer command such as DEL, splitting it into
+// single key commands when keys are sharded
+func (c *Client) multiKey(ctx context.Context, keys []string, all func(ctx context.Context, keys ...string) *redis.IntCmd, one func(pipe redis.Pipeliner, key string) *redis.IntCmd) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if !c.sharded() {
+		return all(ctx, keys...).Result()
+	}
+
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = one(pipe, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, cmd := range cmds {
+		n += cmd.Val()
+	}
+	return n, nil
+}
+
+// mget reads several keys, returning nil for missing ones. When keys are
+// sharded it pipelines one GET per key since they may live on different
+// nodes.
+func (c *Client) mget(ctx context.Context, keys ...string) ([]interface{}, error) {
+	if !c.sharded() {
+		var values []interface{}
+		err := c.read(func(cmd redis.Cmdable) (err error) {
+			values, err = cmd.MGet(ctx, keys...).Result()
+			return err
+		})
+		return values, err
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(keys))
+	for i, cmd := range cmds {
+		if value, err := cmd.Result(); err == nil {
+			values[i] = value
+		}
+	}
+	return values, nil
+}
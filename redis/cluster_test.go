@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestCluster creates a mock Redis server and a cluster mode client
+// pointed at it. The mock reports itself as the only master, owning every slot.
+func setupTestCluster(t *testing.T, cfg Config) (*Client, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	cfg.Cluster = true
+	cfg.Addrs = []string{mr.Addr()}
+	client, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client, mr
+}
+
+func TestClient_Cluster(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("basic operations", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{})
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		val, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+
+		require.NoError(t, client.Forget(ctx, "key"))
+		assert.False(t, mr.Exists("key"))
+	})
+
+	t.Run("batch operations across slots", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{})
+
+		require.NoError(t, client.PutMany(ctx, map[string]string{"a": "1", "b": "2", "c": "3"}, time.Hour))
+
+		values, err := client.GetMany(ctx, "a", "b", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+
+		require.NoError(t, client.ForgetMany(ctx, "a", "b"))
+		assert.False(t, mr.Exists("a"))
+		assert.True(t, mr.Exists("c"))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{})
+
+		require.NoError(t, client.Put(ctx, "a", "1", time.Hour))
+		require.NoError(t, client.Flush(ctx))
+		assert.Empty(t, mr.Keys())
+	})
+
+	t.Run("flush with prefix", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{Prefix: "app:"})
+		require.NoError(t, mr.Set("other", "kept"))
+
+		require.NoError(t, client.PutMany(ctx, map[string]string{"a": "1", "b": "2"}, time.Hour))
+		require.NoError(t, client.Flush(ctx))
+		assert.Equal(t, []string{"other"}, mr.Keys())
+	})
+
+	t.Run("archive is unsupported", func(t *testing.T) {
+		client, _ := setupTestCluster(t, Config{})
+
+		_, err := client.ArchiveExpiringSoon(ctx, "*", time.Hour, 1)
+		assert.Equal(t, ErrClusterUnsupported, err)
+	})
+
+	t.Run("non-zero DB is rejected", func(t *testing.T) {
+		_, err := New(Config{Cluster: true, Addrs: []string{"localhost:7000"}, DB: 1})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,211 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects the algorithm used to compress large values
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionSnappy
+	CompressionZstd
+)
+
+// defaultCompressionThreshold is the size in bytes above which values are
+// compressed when Config.CompressionThreshold is not set
+const defaultCompressionThreshold = 1024
+
+// valueMagic starts the header of every value the client transforms before
+// storing it. It is followed by a single byte naming the transformation.
+const valueMagic = "\x00gf"
+
+// Value header formats
+const (
+	formatRaw    byte = 'r'
+	formatGzip   byte = 'g'
+	formatSnappy byte = 's'
+	formatZstd   byte = 'z'
+	formatAESGCM byte = 'e'
+	formatMiss   byte = 'n'
+	formatSoft   byte = 'x'
+)
+
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil)
+	})
+)
+
+// encodeValue prepares value for storage under key, compressing it when it
+// is larger than the configured threshold and then encrypting it if an
+// encryption key is configured
+func (c *Client) encodeValue(key, value string) (string, error) {
+	encoded, err := c.compressValue(value)
+	if err != nil {
+		return "", err
+	}
+	if c.encryption == nil {
+		return encoded, nil
+	}
+	return c.encryption.encrypt(key, encoded)
+}
+
+// compressValue compresses value when it is larger than the configured
+// threshold
+func (c *Client) compressValue(value string) (string, error) {
+	if c.compression == CompressionNone || len(value) <= c.compressionThreshold {
+		if strings.HasPrefix(value, valueMagic) {
+			// Mark the value as raw so it is not mistaken for a header on read
+			return valueMagic + string(formatRaw) + value, nil
+		}
+		return value, nil
+	}
+
+	compressed, format, err := compress(c.compression, []byte(value))
+	if err != nil {
+		return "", fmt.Errorf("failed to compress value: %w", err)
+	}
+	return valueMagic + string(format) + string(compressed), nil
+}
+
+// decodeValue reverses encodeValue for a value read from key. Values without
+// a header are returned as is, so entries written before compression or
+// encryption was enabled remain readable.
+func (c *Client) decodeValue(key, value string) (string, error) {
+	if !strings.HasPrefix(value, valueMagic) {
+		return value, nil
+	}
+	if len(value) <= len(valueMagic) {
+		return "", ErrCorruptValue
+	}
+
+	format := value[len(valueMagic)]
+	data := []byte(value[len(valueMagic)+1:])
+
+	switch format {
+	case formatRaw:
+		return string(data), nil
+	case formatGzip, formatSnappy, formatZstd:
+		decompressed, err := decompress(format, data)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrCorruptValue, err)
+		}
+		return string(decompressed), nil
+	case formatMiss:
+		return "", errCachedMiss
+	case formatSoft:
+		if len(data) < softHeaderSize {
+			return "", ErrCorruptValue
+		}
+		// The soft expiry wraps the value as otherwise encoded
+		return c.decodeValue(key, string(data[softHeaderSize:]))
+	case formatAESGCM:
+		if c.encryption == nil {
+			return "", fmt.Errorf("%w: value is encrypted but no keys are configured", ErrDecryptionFailed)
+		}
+		plaintext, err := c.encryption.decrypt(key, data)
+		if err != nil {
+			return "", err
+		}
+		// The plaintext may itself be compressed
+		return c.decodeValue(key, plaintext)
+	default:
+		return "", ErrCorruptValue
+	}
+}
+
+// rekeyValue re-encrypts a value read from one key for storage under
+// another, leaving any other layers of its encoding as they are
+func (c *Client) rekeyValue(value, from, to string) (string, error) {
+	if !strings.HasPrefix(value, valueMagic) || len(value) <= len(valueMagic) {
+		return value, nil
+	}
+
+	format := value[len(valueMagic)]
+	data := value[len(valueMagic)+1:]
+
+	switch format {
+	case formatSoft:
+		if len(data) < softHeaderSize {
+			return "", ErrCorruptValue
+		}
+		inner, err := c.rekeyValue(data[softHeaderSize:], from, to)
+		if err != nil {
+			return "", err
+		}
+		return value[:len(valueMagic)+1+softHeaderSize] + inner, nil
+	case formatAESGCM:
+		if c.encryption == nil {
+			return "", fmt.Errorf("%w: value is encrypted but no keys are configured", ErrDecryptionFailed)
+		}
+		plaintext, err := c.encryption.decrypt(from, []byte(data))
+		if err != nil {
+			return "", err
+		}
+		return c.encryption.encrypt(to, plaintext)
+	default:
+		return value, nil
+	}
+}
+
+// compress compresses data with the given algorithm, returning the header
+// format identifying it
+func compress(algorithm Compression, data []byte) ([]byte, byte, error) {
+	switch algorithm {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, 0, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, 0, err
+		}
+		return buf.Bytes(), formatGzip, nil
+	case CompressionSnappy:
+		return s2.EncodeSnappy(nil, data), formatSnappy, nil
+	case CompressionZstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, 0, err
+		}
+		return enc.EncodeAll(data, nil), formatZstd, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown compression algorithm %d", algorithm)
+	}
+}
+
+// decompress reverses compress for the given header format
+func decompress(format byte, data []byte) ([]byte, error) {
+	switch format {
+	case formatGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case formatSnappy:
+		return s2.Decode(nil, data)
+	default:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(data, nil)
+	}
+}
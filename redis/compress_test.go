@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Compression(t *testing.T) {
+	ctx := context.Background()
+	large := strings.Repeat("<div>rendered fragment</div>", 200)
+
+	algorithms := map[string]Compression{
+		"gzip":   CompressionGzip,
+		"snappy": CompressionSnappy,
+		"zstd":   CompressionZstd,
+	}
+
+	for name, algorithm := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			client, mr := setupTestRedisWith(t, Config{Compression: algorithm})
+			defer mr.Close()
+
+			require.NoError(t, client.Put(ctx, "large", large, time.Hour))
+			raw, err := mr.Get("large")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(raw, valueMagic))
+			assert.Less(t, len(raw), len(large))
+
+			val, err := client.Get(ctx, "large")
+			require.NoError(t, err)
+			assert.Equal(t, large, val)
+
+			// Values below the threshold are stored as is
+			require.NoError(t, client.Put(ctx, "small", "tiny", time.Hour))
+			raw, err = mr.Get("small")
+			require.NoError(t, err)
+			assert.Equal(t, "tiny", raw)
+		})
+	}
+
+	t.Run("custom threshold", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Compression: CompressionGzip, CompressionThreshold: 8})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "more than eight bytes", time.Hour))
+		raw, err := mr.Get("key")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(raw, valueMagic+"g"))
+	})
+
+	t.Run("reads work regardless of the writer's configuration", func(t *testing.T) {
+		writer, mr := setupTestRedisWith(t, Config{Compression: CompressionZstd})
+		defer mr.Close()
+		reader := newClientFor(t, mr, Config{})
+
+		require.NoError(t, writer.PutMany(ctx, map[string]string{"a": large, "b": "small"}, time.Hour))
+
+		values, err := reader.GetMany(ctx, "a", "b")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": large, "b": "small"}, values)
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Compression: CompressionSnappy})
+		defer mr.Close()
+
+		first, err := client.Remember(ctx, "report", time.Hour, func() (interface{}, error) {
+			return large, nil
+		})
+		require.NoError(t, err)
+
+		second, err := client.Remember(ctx, "report", time.Hour, nil)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("values that look like a header round trip", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		tricky := valueMagic + "z not compressed"
+		require.NoError(t, client.Put(ctx, "key", tricky, time.Hour))
+
+		val, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, tricky, val)
+	})
+
+	t.Run("corrupt value", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, mr.Set("key", valueMagic+"gnot gzip"))
+		_, err := client.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrCorruptValue)
+	})
+}
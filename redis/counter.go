@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrementWithTTLScript increments a counter and sets its TTL only when the
+// increment created it and the TTL is positive
+var incrementWithTTLScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if existed == 0 and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// milliseconds converts ttl to whole milliseconds for PEXPIRE and friends,
+// rounding positive TTLs under a millisecond up rather than down to zero,
+// which would delete the key instead of expiring it
+func milliseconds(ttl time.Duration) int64 {
+	if ttl > 0 && ttl < time.Millisecond {
+		return 1
+	}
+	return ttl.Milliseconds()
+}
+
+// Increment atomically increments the integer stored at key by the given
+// amount and returns the new value. Missing keys start at zero.
+func (c *Client) Increment(ctx context.Context, key string, by int64) (int64, error) {
+	return c.client.IncrBy(ctx, c.key(key), by).Result()
+}
+
+// Decrement atomically decrements the integer stored at key by the given
+// amount and returns the new value. Missing keys start at zero.
+func (c *Client) Decrement(ctx context.Context, key string, by int64) (int64, error) {
+	return c.client.DecrBy(ctx, c.key(key), by).Result()
+}
+
+// IncrementWithTTL increments like Increment, and gives the counter a TTL if
+// this increment created it. Later increments leave the TTL untouched, which
+// makes it suitable for fixed window counters. A zero or negative ttl leaves
+// a new counter without expiry, and TTLs under a millisecond are rounded up.
+func (c *Client) IncrementWithTTL(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return incrementWithTTLScript.Run(ctx, c.client, []string{c.key(key)}, by, milliseconds(ttl)).Int64()
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Increment(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("missing key starts at zero", func(t *testing.T) {
+		val, err := client.Increment(ctx, "counter", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), val)
+
+		val, err = client.Increment(ctx, "counter", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), val)
+	})
+
+	t.Run("decrement", func(t *testing.T) {
+		val, err := client.Decrement(ctx, "counter", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), val)
+
+		val, err = client.Decrement(ctx, "new-counter", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-3), val)
+	})
+
+	t.Run("non-integer value", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "text", "abc", time.Hour))
+
+		_, err := client.Increment(ctx, "text", 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = client.Increment(ctx, "concurrent", 1)
+			}()
+		}
+		wg.Wait()
+
+		val, err := client.Get(ctx, "concurrent")
+		assert.NoError(t, err)
+		assert.Equal(t, "50", val)
+	})
+}
+
+func TestClient_IncrementWithTTL(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("ttl set on first increment", func(t *testing.T) {
+		val, err := client.IncrementWithTTL(ctx, "window", 1, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), val)
+		assert.Equal(t, time.Minute, mr.TTL("window"))
+	})
+
+	t.Run("ttl untouched on later increments", func(t *testing.T) {
+		mr.SetTTL("window", 30*time.Second)
+
+		val, err := client.IncrementWithTTL(ctx, "window", 2, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), val)
+		assert.Equal(t, 30*time.Second, mr.TTL("window"))
+	})
+
+	t.Run("existing counter without ttl", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "persistent", "10"))
+
+		val, err := client.IncrementWithTTL(ctx, "persistent", 1, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(11), val)
+		assert.Zero(t, mr.TTL("persistent"))
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		val, err := client.IncrementWithTTL(ctx, "unbounded", 1, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), val)
+		assert.True(t, mr.Exists("unbounded"))
+		assert.Zero(t, mr.TTL("unbounded"))
+	})
+
+	t.Run("sub-millisecond ttl", func(t *testing.T) {
+		val, err := client.IncrementWithTTL(ctx, "brief", 1, 500*time.Microsecond)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), val)
+		assert.True(t, mr.Exists("brief"))
+		assert.Equal(t, time.Millisecond, mr.TTL("brief"))
+	})
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// dependentsKeyPrefix namespaces the sets recording which keys depend on a key
+const dependentsKeyPrefix = "gofacades:dependents:"
+
+// dependentsKey returns the key of the set holding the dependents of key
+func dependentsKey(key string) string {
+	return dependentsKeyPrefix + key
+}
+
+// addDependentScript adds a member to a dependents set and keeps the set for
+// at least as long as the member, the TTL in milliseconds given as ARGV[2].
+// Members stored permanently make the set permanent, and a set that already
+// is stays so.
+var addDependentScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local current = redis.call('PTTL', KEYS[1])
+if existed == 0 or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// PutDependent stores an item in the cache for a given duration and records
+// it as depending on each of the dependsOn keys, so that forgetting any of
+// them also forgets this item. The record lasts as long as the item, so
+// items expiring on their own do not accumulate under long-lived keys.
+func (c *Client) PutDependent(ctx context.Context, key, value string, ttl time.Duration, dependsOn ...string) error {
+	encoded, err := c.encodeValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	ttl = c.jitter(ttl)
+	ttlMs := ttl.Milliseconds()
+	if ttl > 0 && ttlMs == 0 {
+		// Redis keeps keys for at least a millisecond
+		ttlMs = 1
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.key(key), encoded, ttl)
+		for _, parent := range dependsOn {
+			if parent == key {
+				continue
+			}
+			addDependentScript.Eval(ctx, pipe, []string{c.key(dependentsKey(parent))}, key, ttlMs)
+		}
+		return nil
+	})
+	return err
+}
+
+// collectDependents returns the given keys, every key transitively depending
+// on them and the dependency sets they own, all without the client prefix.
+// Dependency sets are read one level at a time in a single pipeline per
+// level, and each key is visited once so cyclic dependencies terminate.
+func (c *Client) collectDependents(ctx context.Context, roots ...string) ([]string, error) {
+	visited := make(map[string]struct{}, len(roots))
+	frontier := make([]string, 0, len(roots))
+	for _, key := range roots {
+		if _, ok := visited[key]; ok {
+			continue
+		}
+		visited[key] = struct{}{}
+		frontier = append(frontier, key)
+	}
+
+	keys := make([]string, 0, 2*len(frontier))
+	for len(frontier) > 0 {
+		cmds := make([]*redis.StringSliceCmd, len(frontier))
+		_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range frontier {
+				cmds[i] = pipe.SMembers(ctx, c.key(dependentsKey(key)))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var next []string
+		for i, key := range frontier {
+			keys = append(keys, key, dependentsKey(key))
+			for _, member := range cmds[i].Val() {
+				if _, ok := visited[member]; ok {
+					continue
+				}
+				visited[member] = struct{}{}
+				next = append(next, member)
+			}
+		}
+		frontier = next
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PutDependent(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("forgetting a parent forgets its dependents", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "user:1", "alice", time.Hour))
+		require.NoError(t, client.PutDependent(ctx, "user:1:profile", "<html>", time.Hour, "user:1"))
+		require.NoError(t, client.PutDependent(ctx, "user:1:summary", "alice (1)", time.Hour, "user:1"))
+		require.NoError(t, client.Put(ctx, "user:2", "bob", time.Hour))
+
+		require.NoError(t, client.Forget(ctx, "user:1"))
+
+		for _, key := range []string{"user:1", "user:1:profile", "user:1:summary"} {
+			exists, err := client.Has(ctx, key)
+			assert.NoError(t, err)
+			assert.False(t, exists, key)
+		}
+
+		exists, err := client.Has(ctx, "user:2")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.False(t, mr.Exists(dependentsKey("user:1")))
+	})
+
+	t.Run("dependents cascade transitively", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "catalog", "v1"))
+		require.NoError(t, client.PutDependent(ctx, "category:books", "books", time.Hour, "catalog"))
+		require.NoError(t, client.PutDependent(ctx, "page:books", "<html>", time.Hour, "category:books"))
+
+		require.NoError(t, client.Forget(ctx, "catalog"))
+
+		assert.False(t, mr.Exists("category:books"))
+		assert.False(t, mr.Exists("page:books"))
+	})
+
+	t.Run("multiple parents", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "from", "1"))
+		require.NoError(t, client.Forever(ctx, "to", "2"))
+		require.NoError(t, client.PutDependent(ctx, "transfer", "1->2", time.Hour, "from", "to"))
+
+		require.NoError(t, client.Forget(ctx, "to"))
+
+		assert.False(t, mr.Exists("transfer"))
+		assert.True(t, mr.Exists("from"))
+	})
+
+	t.Run("cyclic dependencies", func(t *testing.T) {
+		require.NoError(t, client.PutDependent(ctx, "a", "1", time.Hour, "b"))
+		require.NoError(t, client.PutDependent(ctx, "b", "2", time.Hour, "a"))
+		require.NoError(t, client.Forever(ctx, "unrelated", "3"))
+
+		require.NoError(t, client.Forget(ctx, "a"))
+
+		assert.False(t, mr.Exists("a"))
+		assert.False(t, mr.Exists("b"))
+		assert.True(t, mr.Exists("unrelated"))
+	})
+
+	t.Run("pulling a parent forgets its dependents", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "parent", "p"))
+		require.NoError(t, client.PutDependent(ctx, "child", "c", time.Hour, "parent"))
+
+		val, err := client.Pull(ctx, "parent")
+		assert.NoError(t, err)
+		assert.Equal(t, "p", val)
+		assert.False(t, mr.Exists("child"))
+	})
+
+	t.Run("dependency sets expire with their dependents", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "site", "v1"))
+		require.NoError(t, client.PutDependent(ctx, "page:short", "a", time.Minute, "site"))
+		assert.Equal(t, time.Minute, mr.TTL(dependentsKey("site")))
+
+		// Longer lived dependents extend the set, shorter ones do not shorten it
+		require.NoError(t, client.PutDependent(ctx, "page:long", "b", time.Hour, "site"))
+		assert.Equal(t, time.Hour, mr.TTL(dependentsKey("site")))
+		require.NoError(t, client.PutDependent(ctx, "page:brief", "c", time.Second, "site"))
+		assert.Equal(t, time.Hour, mr.TTL(dependentsKey("site")))
+
+		// Once every dependent has expired, so has the set
+		mr.FastForward(time.Hour)
+		assert.False(t, mr.Exists(dependentsKey("site")))
+		assert.True(t, mr.Exists("site"))
+
+		// Permanent dependents make the set permanent
+		require.NoError(t, client.PutDependent(ctx, "page:pinned", "d", 0, "site"))
+		require.NoError(t, client.PutDependent(ctx, "page:later", "e", time.Minute, "site"))
+		assert.Zero(t, mr.TTL(dependentsKey("site")))
+		require.NoError(t, client.Forget(ctx, "site"))
+		assert.False(t, mr.Exists("page:pinned"))
+		assert.False(t, mr.Exists("page:later"))
+	})
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxyUnixSocket listens on a Unix domain socket and forwards every
+// connection to the TCP address target, returning the socket path
+func proxyUnixSocket(t *testing.T, target string) string {
+	path := filepath.Join(t.TempDir(), "redis.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	return path
+}
+
+func TestClient_Dialing(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("unix socket", func(t *testing.T) {
+		socket := proxyUnixSocket(t, mr.Addr())
+
+		client, err := New(Config{UnixSocket: socket})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "unix", "value", time.Hour))
+		assert.True(t, mr.Exists("unix"))
+	})
+
+	t.Run("unix socket URL", func(t *testing.T) {
+		socket := proxyUnixSocket(t, mr.Addr())
+
+		client, err := NewFromURL("unix://" + socket + "?db=1")
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "unix", "value", time.Hour))
+		assert.True(t, mr.DB(1).Exists("unix"))
+	})
+
+	t.Run("custom dialer", func(t *testing.T) {
+		var dialed int32
+		var dialedAddr atomic.Value
+		client, err := New(Config{
+			Host: "redis.internal",
+			Port: 6379,
+			Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				atomic.AddInt32(&dialed, 1)
+				dialedAddr.Store(addr)
+				// Tunnel every connection to the mock server
+				var d net.Dialer
+				return d.DialContext(ctx, "tcp", mr.Addr())
+			},
+		})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "tunnelled", "value", time.Hour))
+		assert.True(t, mr.Exists("tunnelled"))
+		assert.Greater(t, atomic.LoadInt32(&dialed), int32(0))
+		assert.Equal(t, "redis.internal:6379", dialedAddr.Load())
+	})
+
+	t.Run("unix socket is single node only", func(t *testing.T) {
+		_, err := New(Config{UnixSocket: "/tmp/redis.sock", Cluster: true})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// maxKeyIDLength is the longest key ID that fits in the one byte length
+// prefix of an encrypted value
+const maxKeyIDLength = 255
+
+// encryptor encrypts values with AES-GCM, keeping every configured key so
+// that values written before a key rotation can still be read
+type encryptor struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// newEncryptor builds an encryptor from the configured keys, returning nil
+// when encryption is not configured
+func newEncryptor(keys map[string][]byte, active string) (*encryptor, error) {
+	if len(keys) == 0 && active == "" {
+		return nil, nil
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("encryption key ID %q is not among the configured keys", active)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if len(id) > maxKeyIDLength {
+			return nil, fmt.Errorf("encryption key ID %q is longer than %d bytes", id, maxKeyIDLength)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aeads[id] = aead
+	}
+
+	return &encryptor{active: active, aeads: aeads}, nil
+}
+
+// encrypt seals value, stored under key, with the active key. The result is
+// laid out as the value header, the key ID length and key ID, the nonce,
+// then the ciphertext.
+func (e *encryptor) encrypt(key, value string) (string, error) {
+	aead := e.aeads[e.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, len(valueMagic)+2+len(e.active)+len(nonce)+len(value)+aead.Overhead())
+	out = append(out, valueMagic...)
+	out = append(out, formatAESGCM, byte(len(e.active)))
+	out = append(out, e.active...)
+	// The header is authenticated so the key ID cannot be tampered with, and
+	// the key so the value cannot be moved to another one
+	additional := associatedData(out, key)
+	out = append(out, nonce...)
+	out = aead.Seal(out, nonce, []byte(value), additional)
+
+	return string(out), nil
+}
+
+// decrypt opens data, the part of an encrypted value stored under key
+// following its format byte
+func (e *encryptor) decrypt(key string, data []byte) (string, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "", ErrCorruptValue
+	}
+	id := string(data[1 : 1+int(data[0])])
+	rest := data[1+int(data[0]):]
+
+	aead, ok := e.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown key ID %q", ErrDecryptionFailed, id)
+	}
+	if len(rest) < aead.NonceSize() {
+		return "", ErrCorruptValue
+	}
+
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	header := make([]byte, 0, len(valueMagic)+2+len(id))
+	header = append(header, valueMagic...)
+	header = append(header, formatAESGCM, byte(len(id)))
+	header = append(header, id...)
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData(header, key))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+	return string(plaintext), nil
+}
+
+// associatedData returns the data authenticated along with a value: its
+// header followed by the key it is stored under. The header ends with the
+// length-prefixed key ID, so the two cannot run into each other.
+func associatedData(header []byte, key string) []byte {
+	additional := make([]byte, 0, len(header)+len(key))
+	additional = append(additional, header...)
+	return append(additional, key...)
+}
@@ -0,0 +1,181 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Encryption(t *testing.T) {
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+
+	t.Run("values are encrypted at rest", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "email", "user@example.com", time.Hour))
+
+		raw, err := mr.Get("email")
+		require.NoError(t, err)
+		assert.NotContains(t, raw, "user@example.com")
+		assert.True(t, strings.HasPrefix(raw, valueMagic+"e\x02v1"))
+
+		val, err := client.Get(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", val)
+	})
+
+	t.Run("rotated keys still decrypt old values", func(t *testing.T) {
+		before, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, before.Put(ctx, "email", "user@example.com", time.Hour))
+
+		after := newClientFor(t, mr, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey, "v2": newKey},
+			EncryptionKeyID: "v2",
+		})
+		val, err := after.Get(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", val)
+
+		require.NoError(t, after.Put(ctx, "phone", "555-0100", time.Hour))
+		raw, err := mr.Get("phone")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(raw, valueMagic+"e\x02v2"))
+	})
+
+	t.Run("combined with compression", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			Compression:     CompressionGzip,
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+
+		large := strings.Repeat("sensitive ", 500)
+		require.NoError(t, client.Put(ctx, "large", large, time.Hour))
+
+		raw, err := mr.Get("large")
+		require.NoError(t, err)
+		assert.Less(t, len(raw), len(large))
+
+		val, err := client.Get(ctx, "large")
+		require.NoError(t, err)
+		assert.Equal(t, large, val)
+	})
+
+	t.Run("unknown key ID", func(t *testing.T) {
+		writer, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, writer.Put(ctx, "email", "user@example.com", time.Hour))
+
+		reader := newClientFor(t, mr, Config{
+			EncryptionKeys:  map[string][]byte{"v2": newKey},
+			EncryptionKeyID: "v2",
+		})
+		_, err := reader.Get(ctx, "email")
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+		_, err = newClientFor(t, mr, Config{}).Get(ctx, "email")
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	})
+
+	t.Run("tampered value", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, client.Put(ctx, "email", "user@example.com", time.Hour))
+
+		raw, err := mr.Get("email")
+		require.NoError(t, err)
+		tampered := []byte(raw)
+		tampered[len(tampered)-1] ^= 0xff
+		require.NoError(t, mr.Set("email", string(tampered)))
+
+		_, err = client.Get(ctx, "email")
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	})
+
+	t.Run("values cannot be moved to another key", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, client.Put(ctx, "email", "user@example.com", time.Hour))
+
+		raw, err := mr.Get("email")
+		require.NoError(t, err)
+		require.NoError(t, mr.Set("other", raw))
+
+		_, err = client.Get(ctx, "other")
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	})
+
+	t.Run("renamed and copied values are re-encrypted", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, client.Put(ctx, "staged", "user@example.com", time.Hour))
+
+		require.NoError(t, client.Rename(ctx, "staged", "email"))
+		assert.False(t, mr.Exists("staged"))
+		assert.Equal(t, time.Hour, mr.TTL("email"))
+		val, err := client.Get(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", val)
+
+		require.NoError(t, client.Copy(ctx, "email", "backup", time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL("backup"))
+		val, err = client.Get(ctx, "backup")
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", val)
+
+		assert.Equal(t, ErrKeyNotFound, client.Rename(ctx, "missing", "email"))
+	})
+
+	t.Run("plaintext values remain readable", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": oldKey},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+		require.NoError(t, mr.Set("legacy", "plain"))
+
+		val, err := client.Get(ctx, "legacy")
+		require.NoError(t, err)
+		assert.Equal(t, "plain", val)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := newEncryptor(map[string][]byte{"v1": oldKey}, "v2")
+		assert.Error(t, err)
+
+		_, err = newEncryptor(map[string][]byte{"v1": []byte("short")}, "v1")
+		assert.Error(t, err)
+
+		e, err := newEncryptor(nil, "")
+		assert.NoError(t, err)
+		assert.Nil(t, e)
+	})
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// maxExpansionDepth bounds how deeply ${key} references may be nested
+const maxExpansionDepth = 10
+
+var referencePattern = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// GetExpanded retrieves an item from the cache and resolves any ${other_key}
+// references in it by looking the referenced keys up recursively
+func (c *Client) GetExpanded(ctx context.Context, key string) (string, error) {
+	return c.expand(ctx, key, nil)
+}
+
+// expand resolves the value stored at key, with seen holding the chain of
+// keys currently being resolved
+func (c *Client) expand(ctx context.Context, key string, seen []string) (string, error) {
+	if len(seen) >= maxExpansionDepth {
+		return "", ErrExpansionCycle
+	}
+	for _, k := range seen {
+		if k == key {
+			return "", ErrExpansionCycle
+		}
+	}
+
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	seen = append(seen, key)
+
+	var expandErr error
+	expanded := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+
+		ref := match[2 : len(match)-1]
+		resolved, err := c.expand(ctx, ref, seen)
+		if errors.Is(err, ErrKeyNotFound) {
+			if c.strictExpansion {
+				expandErr = fmt.Errorf("%w: %s", ErrUnresolvedReference, ref)
+			}
+			return match
+		}
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		return resolved
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	return expanded, nil
+}
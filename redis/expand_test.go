@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetExpanded(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("value referencing another key", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "base_url", "https://example.com"))
+		require.NoError(t, client.Forever(ctx, "api_url", "${base_url}/api"))
+
+		val, err := client.GetExpanded(ctx, "api_url")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/api", val)
+	})
+
+	t.Run("nested references", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "host", "example.com"))
+		require.NoError(t, client.Forever(ctx, "origin", "https://${host}"))
+		require.NoError(t, client.Forever(ctx, "users_url", "${origin}/api/users?page=${page_size}"))
+		require.NoError(t, client.Forever(ctx, "page_size", "50"))
+
+		val, err := client.GetExpanded(ctx, "users_url")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/api/users?page=50", val)
+	})
+
+	t.Run("unresolved reference left literal", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "dangling", "${missing}/api"))
+
+		val, err := client.GetExpanded(ctx, "dangling")
+		assert.NoError(t, err)
+		assert.Equal(t, "${missing}/api", val)
+	})
+
+	t.Run("cyclic reference", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "a", "${b}"))
+		require.NoError(t, client.Forever(ctx, "b", "${a}"))
+
+		_, err := client.GetExpanded(ctx, "a")
+		assert.True(t, errors.Is(err, ErrExpansionCycle))
+	})
+
+	t.Run("self reference", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "self", "x${self}"))
+
+		_, err := client.GetExpanded(ctx, "self")
+		assert.True(t, errors.Is(err, ErrExpansionCycle))
+	})
+
+	t.Run("non-existent key", func(t *testing.T) {
+		_, err := client.GetExpanded(ctx, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+}
+
+func TestClient_GetExpanded_Strict(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{StrictExpansion: true})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("unresolved reference errors", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "dangling", "${missing}/api"))
+
+		_, err := client.GetExpanded(ctx, "dangling")
+		assert.True(t, errors.Is(err, ErrUnresolvedReference))
+	})
+
+	t.Run("resolved references", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "name", "world"))
+		require.NoError(t, client.Forever(ctx, "greeting", "hello ${name}"))
+
+		val, err := client.GetExpanded(ctx, "greeting")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", val)
+	})
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetTTL returns how long an item has left before it expires, or zero for
+// items stored without an expiry
+func (c *Client) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, c.key(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL replies -2 for missing keys and -1 for keys without an expiry,
+	// which go-redis passes through as durations of that many nanoseconds
+	switch ttl {
+	case -2:
+		return 0, ErrKeyNotFound
+	case -1:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Touch sets the item to expire ttl from now, whatever its remaining TTL,
+// for sliding expiration and heartbeat keys. ttl must be positive.
+func (c *Client) Touch(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+
+	ok, err := c.client.PExpire(ctx, c.key(key), c.jitter(ttl)).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Persist removes the expiry of an existing item, keeping it until it is
+// forgotten. Items already stored forever are left as they are.
+func (c *Client) Persist(ctx context.Context, key string) error {
+	var exists *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Persist(ctx, c.key(key))
+		exists = pipe.Exists(ctx, c.key(key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if exists.Val() == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetTTL(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("expiring item", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "expiring", "value", time.Hour))
+
+		ttl, err := client.GetTTL(ctx, "expiring")
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, ttl)
+	})
+
+	t.Run("item without expiry", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+
+		ttl, err := client.GetTTL(ctx, "forever")
+		require.NoError(t, err)
+		assert.Zero(t, ttl)
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		_, err := client.GetTTL(ctx, "missing")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+}
+
+func TestClient_Touch(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("extends the lifetime", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "session", "value", time.Minute))
+		mr.FastForward(50 * time.Second)
+
+		require.NoError(t, client.Touch(ctx, "session", time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL("app:session"))
+
+		mr.FastForward(50 * time.Second)
+		value, err := client.Get(ctx, "session")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("sets an expiry on forever items", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+
+		require.NoError(t, client.Touch(ctx, "forever", time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL("app:forever"))
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		assert.Equal(t, ErrKeyNotFound, client.Touch(ctx, "missing", time.Minute))
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "kept", "value", time.Minute))
+
+		assert.Equal(t, ErrInvalidTTL, client.Touch(ctx, "kept", 0))
+		assert.True(t, mr.Exists("app:kept"))
+	})
+}
+
+func TestClient_Persist(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("removes the expiry", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "key", "value", time.Minute))
+
+		require.NoError(t, client.Persist(ctx, "key"))
+		assert.Zero(t, mr.TTL("key"))
+
+		mr.FastForward(time.Hour)
+		value, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("forever items", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+		assert.NoError(t, client.Persist(ctx, "forever"))
+	})
+
+	t.Run("missing item", func(t *testing.T) {
+		assert.Equal(t, ErrKeyNotFound, client.Persist(ctx, "missing"))
+	})
+}
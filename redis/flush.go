@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanBatchSize is the COUNT hint passed to SCAN, and the number of keys
+// unlinked per round trip when deleting scanned keys, unless
+// Config.ScanPageSize sets another
+const scanBatchSize = 100
+
+// maxUnlinkPasses caps the SCAN passes made over each shard when removing
+// the keys matching a pattern
+const maxUnlinkPasses = 8
+
+// patternEscaper escapes the characters SCAN MATCH treats as glob syntax
+var patternEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// escapePattern returns a MATCH pattern matching s literally
+func escapePattern(s string) string {
+	return patternEscaper.Replace(s)
+}
+
+// FlushPrefix removes every item whose key starts with prefix, relative to
+// the client's own prefix. Keys are found with SCAN and removed with UNLINK
+// in batches, so the server is never blocked for long, and keys outside the
+// prefix are untouched.
+func (c *Client) FlushPrefix(ctx context.Context, prefix string) error {
+	_, err := c.unlinkMatching(ctx, escapePattern(c.key(prefix))+"*")
+	return err
+}
+
+// ForgetPattern removes every item whose key matches pattern, relative to
+// the client prefix, using Redis glob syntax such as "user:123:*". Keys are
+// found with SCAN and removed with UNLINK a page at a time, see
+// Config.ScanPageSize. It returns the number of items removed.
+func (c *Client) ForgetPattern(ctx context.Context, pattern string) (int64, error) {
+	return c.unlinkMatching(ctx, escapePattern(c.prefix)+pattern)
+}
+
+// FlushAll removes every key from every database on the server. It must be
+// enabled with Config.AllowFlushAll.
+func (c *Client) FlushAll(ctx context.Context) error {
+	if !c.allowFlushAll {
+		return ErrFlushAllDisabled
+	}
+	return c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		return shard.FlushAll(ctx).Err()
+	})
+}
+
+// unlinkMatching removes every key matching pattern in batches, returning the
+// number of keys removed. In cluster mode every master is scanned.
+func (c *Client) unlinkMatching(ctx context.Context, pattern string) (int64, error) {
+	var total int64
+	err := c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		// Deleting keys while scanning can make some servers skip keys, so
+		// scan again while a pass still finds keys to remove. The passes are
+		// capped so that keys written concurrently cannot keep it going.
+		for pass := 0; pass < maxUnlinkPasses; pass++ {
+			n, err := c.unlinkScanPass(ctx, shard, pattern)
+			atomic.AddInt64(&total, n)
+			if err != nil || n == 0 {
+				return err
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// unlinkScanPass runs a single SCAN over the keyspace of shard, unlinking
+// matching keys as each page is returned
+func (c *Client) unlinkScanPass(ctx context.Context, shard *redis.Client, pattern string) (int64, error) {
+	var (
+		removed int64
+		cursor  uint64
+	)
+	for {
+		keys, next, err := shard.Scan(ctx, cursor, pattern, c.scanPageSize).Result()
+		if err != nil {
+			return removed, err
+		}
+
+		if len(keys) > 0 {
+			n, err := c.unlink(ctx, keys...)
+			if err != nil {
+				return removed, err
+			}
+			removed += n
+		}
+
+		if next == 0 {
+			return removed, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,163 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FlushPrefix(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("only keys with the prefix are removed", func(t *testing.T) {
+		// More keys than a single SCAN/UNLINK batch
+		for i := 0; i < scanBatchSize*2+5; i++ {
+			require.NoError(t, client.Put(ctx, fmt.Sprintf("app:%d", i), "value", time.Hour))
+		}
+		require.NoError(t, client.Put(ctx, "other:1", "value", time.Hour))
+		require.NoError(t, client.Put(ctx, "application", "value", time.Hour))
+
+		require.NoError(t, client.FlushPrefix(ctx, "app:"))
+
+		keys := mr.Keys()
+		assert.ElementsMatch(t, []string{"other:1", "application"}, keys)
+	})
+
+	t.Run("glob characters in the prefix are literal", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "a*:1", "value", time.Hour))
+		require.NoError(t, client.Put(ctx, "ab:1", "value", time.Hour))
+
+		require.NoError(t, client.FlushPrefix(ctx, "a*:"))
+
+		assert.False(t, mr.Exists("a*:1"))
+		assert.True(t, mr.Exists("ab:1"))
+	})
+
+	t.Run("returns while the prefix is written to", func(t *testing.T) {
+		stop := make(chan struct{})
+		var written atomic.Int64
+		var writers sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			writers.Add(1)
+			go func(w int) {
+				defer writers.Done()
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					client.Put(ctx, fmt.Sprintf("busy:%d:%d", w, i), "value", time.Hour)
+					written.Add(1)
+				}
+			}(w)
+		}
+		defer func() {
+			close(stop)
+			writers.Wait()
+		}()
+
+		require.Eventually(t, func() bool { return written.Load() > 100 }, time.Second, time.Millisecond)
+
+		done := make(chan error, 1)
+		go func() { done <- client.FlushPrefix(ctx, "busy:") }()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("FlushPrefix did not return")
+		}
+	})
+}
+
+func TestClient_ForgetPattern(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes matching keys", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:", ScanPageSize: 10})
+		defer mr.Close()
+
+		for i := 0; i < 35; i++ {
+			require.NoError(t, client.Put(ctx, fmt.Sprintf("user:123:%d", i), "value", time.Hour))
+		}
+		require.NoError(t, client.Put(ctx, "user:124:1", "value", time.Hour))
+		mr.Set("user:123:outside", "value")
+
+		n, err := client.ForgetPattern(ctx, "user:123:*")
+		require.NoError(t, err)
+		assert.Equal(t, int64(35), n)
+		assert.ElementsMatch(t, []string{"app:user:124:1", "user:123:outside"}, mr.Keys())
+	})
+
+	t.Run("patterns use glob syntax", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		for _, key := range []string{"report:2024-01", "report:2024-02", "report:2025-01"} {
+			require.NoError(t, client.Put(ctx, key, "value", time.Hour))
+		}
+
+		n, err := client.ForgetPattern(ctx, "report:2024-0?")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.Equal(t, []string{"report:2025-01"}, mr.Keys())
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		n, err := client.ForgetPattern(ctx, "missing:*")
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
+
+func TestClient_FlushScope(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("flush leaves other databases alone", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		require.NoError(t, mr.DB(1).Set("other-db", "value"))
+
+		require.NoError(t, client.Flush(ctx))
+
+		assert.False(t, mr.Exists("key"))
+		assert.True(t, mr.DB(1).Exists("other-db"))
+	})
+
+	t.Run("flush all is disabled by default", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, mr.DB(1).Set("other-db", "value"))
+
+		assert.Equal(t, ErrFlushAllDisabled, client.FlushAll(ctx))
+		assert.True(t, mr.DB(1).Exists("other-db"))
+	})
+
+	t.Run("flush all when enabled", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{AllowFlushAll: true})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		require.NoError(t, mr.DB(1).Set("other-db", "value"))
+
+		require.NoError(t, client.FlushAll(ctx))
+
+		assert.False(t, mr.Exists("key"))
+		assert.False(t, mr.DB(1).Exists("other-db"))
+	})
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HashMap stores the fields of one entity in a Redis hash, which takes far
+// less memory than a string key per field. Values are encoded with the
+// client's codec, but neither compressed nor encrypted, so that Increment
+// can operate on them in place.
+type HashMap struct {
+	client *Client
+	key    string
+}
+
+// HashMap returns a handle on the hash stored at key. No command is sent
+// until one of its methods is called.
+func (c *Client) HashMap(key string) *HashMap {
+	return &HashMap{client: c, key: key}
+}
+
+// Key returns the key of the hash, without the client prefix
+func (h *HashMap) Key() string {
+	return h.key
+}
+
+// encode encodes value with the client's codec
+func (h *HashMap) encode(value interface{}) (string, error) {
+	encoded, err := h.client.codec.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Set encodes value and stores it in field
+func (h *HashMap) Set(ctx context.Context, field string, value interface{}) error {
+	encoded, err := h.encode(value)
+	if err != nil {
+		return err
+	}
+	return h.client.client.HSet(ctx, h.client.key(h.key), field, encoded).Err()
+}
+
+// SetMany encodes and stores several fields in one command
+func (h *HashMap) SetMany(ctx context.Context, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(values)*2)
+	for field, value := range values {
+		encoded, err := h.encode(value)
+		if err != nil {
+			return err
+		}
+		args = append(args, field, encoded)
+	}
+	return h.client.client.HSet(ctx, h.client.key(h.key), args...).Err()
+}
+
+// Get decodes the value of field into v, a pointer. It returns
+// ErrHashFieldNotFound when the field or the hash does not exist.
+func (h *HashMap) Get(ctx context.Context, field string, v interface{}) error {
+	var value string
+	err := h.client.read(func(cmd redis.Cmdable) (err error) {
+		value, err = cmd.HGet(ctx, h.client.key(h.key), field).Result()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return ErrHashFieldNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := h.client.codec.Unmarshal([]byte(value), v); err != nil {
+		return &DecodeError{Key: h.key + "." + field, Err: err}
+	}
+	return nil
+}
+
+// Has checks if field exists in the hash
+func (h *HashMap) Has(ctx context.Context, field string) (bool, error) {
+	var exists bool
+	err := h.client.read(func(cmd redis.Cmdable) (err error) {
+		exists, err = cmd.HExists(ctx, h.client.key(h.key), field).Result()
+		return err
+	})
+	return exists, err
+}
+
+// Delete removes fields from the hash and returns how many existed. The
+// hash itself is removed with its last field.
+func (h *HashMap) Delete(ctx context.Context, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	return h.client.client.HDel(ctx, h.client.key(h.key), fields...).Result()
+}
+
+// Increment atomically increments the integer stored in field by the given
+// amount and returns the new value. Missing fields start at zero. Redis
+// stores the result as decimal text, which Get decodes with the JSON codec
+// but not with binary codecs such as msgpack.
+func (h *HashMap) Increment(ctx context.Context, field string, by int64) (int64, error) {
+	return h.client.client.HIncrBy(ctx, h.client.key(h.key), field, by).Result()
+}
+
+// Fields returns the names of the fields in the hash
+func (h *HashMap) Fields(ctx context.Context) ([]string, error) {
+	var fields []string
+	err := h.client.read(func(cmd redis.Cmdable) (err error) {
+		fields, err = cmd.HKeys(ctx, h.client.key(h.key)).Result()
+		return err
+	})
+	return fields, err
+}
+
+// Len returns the number of fields in the hash
+func (h *HashMap) Len(ctx context.Context) (int64, error) {
+	var n int64
+	err := h.client.read(func(cmd redis.Cmdable) (err error) {
+		n, err = cmd.HLen(ctx, h.client.key(h.key)).Result()
+		return err
+	})
+	return n, err
+}
+
+// Expire sets how long the whole hash is kept. Redis expires hashes as a
+// whole, not field by field.
+func (h *HashMap) Expire(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return h.client.client.PExpire(ctx, h.client.key(h.key), ttl).Err()
+}
+
+// Clear removes the whole hash
+func (h *HashMap) Clear(ctx context.Context) error {
+	return h.client.client.Del(ctx, h.client.key(h.key)).Err()
+}
+
+// HashGetAs decodes the value of field in h into T
+func HashGetAs[T any](ctx context.Context, h *HashMap, field string) (T, error) {
+	var result T
+	err := h.Get(ctx, field, &result)
+	return result, err
+}
+
+// HashGetAll decodes every field of h into T, keyed by field name. A missing
+// hash yields an empty map.
+func HashGetAll[T any](ctx context.Context, h *HashMap) (map[string]T, error) {
+	var values map[string]string
+	err := h.client.read(func(cmd redis.Cmdable) (err error) {
+		values, err = cmd.HGetAll(ctx, h.client.key(h.key)).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]T, len(values))
+	for field, value := range values {
+		var decoded T
+		if err := h.client.codec.Unmarshal([]byte(value), &decoded); err != nil {
+			return nil, &DecodeError{Key: h.key + "." + field, Err: err}
+		}
+		result[field] = decoded
+	}
+	return result, nil
+}
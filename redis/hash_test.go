@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+func TestClient_HashMap(t *testing.T) {
+	type address struct {
+		City string `json:"city" msgpack:"city"`
+	}
+
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		user := client.HashMap("user:1")
+
+		require.NoError(t, user.Set(ctx, "name", "Ada"))
+		require.NoError(t, user.Set(ctx, "address", address{City: "London"}))
+
+		var name string
+		require.NoError(t, user.Get(ctx, "name", &name))
+		assert.Equal(t, "Ada", name)
+
+		addr, err := HashGetAs[address](ctx, user, "address")
+		require.NoError(t, err)
+		assert.Equal(t, "London", addr.City)
+
+		// Fields live in one hash under the prefixed key
+		assert.Equal(t, `"Ada"`, mr.HGet("app:user:1", "name"))
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		user := client.HashMap("user:1")
+
+		var name string
+		assert.ErrorIs(t, user.Get(ctx, "name", &name), ErrHashFieldNotFound)
+
+		exists, err := user.Has(ctx, "name")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		user := client.HashMap("user:1")
+		mr.HSet("user:1", "address", "not json")
+
+		_, err := HashGetAs[address](ctx, user, "address")
+		var decodeErr *DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, "user:1.address", decodeErr.Key)
+	})
+
+	t.Run("set many and get all", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		scores := client.HashMap("scores")
+
+		require.NoError(t, scores.SetMany(ctx, map[string]interface{}{"ada": 3, "alan": 5}))
+		require.NoError(t, scores.SetMany(ctx, nil))
+
+		all, err := HashGetAll[int](ctx, scores)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"ada": 3, "alan": 5}, all)
+
+		fields, err := scores.Fields(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ada", "alan"}, fields)
+
+		n, err := scores.Len(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		empty, err := HashGetAll[int](ctx, client.HashMap("missing"))
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		user := client.HashMap("user:1")
+		require.NoError(t, user.SetMany(ctx, map[string]interface{}{"name": "Ada", "email": "ada@example.com"}))
+
+		n, err := user.Delete(ctx, "email", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		exists, err := user.Has(ctx, "name")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		require.NoError(t, user.Clear(ctx))
+		assert.False(t, mr.Exists("user:1"))
+	})
+
+	t.Run("increment", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		stats := client.HashMap("stats")
+
+		n, err := stats.Increment(ctx, "views", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		n, err = stats.Increment(ctx, "views", 4)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+
+		views, err := HashGetAs[int64](ctx, stats, "views")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), views)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		user := client.HashMap("user:1")
+		require.NoError(t, user.Set(ctx, "name", "Ada"))
+
+		assert.ErrorIs(t, user.Expire(ctx, 0), ErrInvalidTTL)
+		require.NoError(t, user.Expire(ctx, time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL("user:1"))
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Codec: cache.MsgpackCodec{}})
+		defer mr.Close()
+		user := client.HashMap("user:1")
+
+		require.NoError(t, user.Set(ctx, "address", address{City: "Paris"}))
+		addr, err := HashGetAs[address](ctx, user, "address")
+		require.NoError(t, err)
+		assert.Equal(t, "Paris", addr.City)
+		assert.NotContains(t, mr.HGet("user:1", "address"), "{")
+	})
+}
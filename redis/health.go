@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// healthCheckTimeout bounds the ping made by the Healthz handler
+const healthCheckTimeout = 2 * time.Second
+
+// Health describes the state of the connection to Redis. Latency is the
+// duration of the health check ping, encoded in JSON as nanoseconds.
+type Health struct {
+	Healthy bool          `json:"healthy"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+	Pool    PoolStats     `json:"pool"`
+}
+
+// PoolStats reports the state of the connection pool
+type PoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+}
+
+// Health pings Redis and reports whether it answered, how long the round
+// trip took and the current connection pool statistics
+func (c *Client) Health(ctx context.Context) Health {
+	start := time.Now()
+	err := c.Ping(ctx)
+
+	health := Health{
+		Healthy: err == nil,
+		Latency: time.Since(start),
+		Pool:    c.PoolStats(),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// CheckHealth returns an error when Redis cannot be reached, in the shape
+// expected by most health check frameworks
+func (c *Client) CheckHealth(ctx context.Context) error {
+	return c.Ping(ctx)
+}
+
+// PoolStats returns the current connection pool statistics
+func (c *Client) PoolStats() PoolStats {
+	stats := c.client.PoolStats()
+	return PoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+	}
+}
+
+// Healthz returns an HTTP handler suitable for readiness probes. It responds
+// with the JSON encoded Health and status 200 when Redis is reachable, or 503
+// when it is not.
+func (c *Client) Healthz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		health := c.Health(ctx)
+
+		w.Header().Set("Content-Type", "application/json")
+		if !health.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(health)
+	})
+}
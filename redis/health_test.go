@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Health(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("healthy", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		health := client.Health(ctx)
+		assert.True(t, health.Healthy)
+		assert.Empty(t, health.Error)
+		assert.Greater(t, health.Latency.Nanoseconds(), int64(0))
+		assert.GreaterOrEqual(t, health.Pool.TotalConns, uint32(1))
+		assert.NoError(t, client.CheckHealth(ctx))
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		mr.Close()
+
+		health := client.Health(ctx)
+		assert.False(t, health.Healthy)
+		assert.NotEmpty(t, health.Error)
+		assert.Error(t, client.CheckHealth(ctx))
+	})
+
+	t.Run("healthz handler", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+
+		rec := httptest.NewRecorder()
+		client.Healthz().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var health Health
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+		assert.True(t, health.Healthy)
+
+		mr.Close()
+		rec = httptest.NewRecorder()
+		client.Healthz().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+		assert.False(t, health.Healthy)
+		assert.NotEmpty(t, health.Error)
+	})
+}
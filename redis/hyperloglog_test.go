@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_UniqueCounter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("counts distinct members", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		viewers := client.UniqueCounter("viewers:video:1")
+
+		changed, err := viewers.Add(ctx, "ada", "alan", "ada")
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		changed, err = viewers.Add(ctx, "alan")
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		n, err := viewers.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.True(t, mr.Exists("app:viewers:video:1"))
+
+		n, err = client.UniqueCounter("missing").Count(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("large counts are approximate", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		visitors := client.UniqueCounter("visitors")
+
+		members := make([]string, 10000)
+		for i := range members {
+			members[i] = fmt.Sprintf("user:%d", i)
+		}
+		_, err := visitors.Add(ctx, members...)
+		require.NoError(t, err)
+
+		n, err := visitors.Count(ctx)
+		require.NoError(t, err)
+		assert.InEpsilon(t, 10000, n, 0.02)
+	})
+
+	t.Run("count and merge several counters", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		monday, tuesday := client.UniqueCounter("dau:monday"), client.UniqueCounter("dau:tuesday")
+
+		_, err := monday.Add(ctx, "ada", "alan")
+		require.NoError(t, err)
+		_, err = tuesday.Add(ctx, "alan", "grace")
+		require.NoError(t, err)
+
+		week := client.UniqueCounter("wau")
+		require.NoError(t, week.Merge(ctx, monday, tuesday))
+		n, err := week.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		// miniredis adds up the counts of several keys instead of estimating
+		// their union, so only check that every key is counted
+		n, err = monday.Count(ctx, tuesday, client.UniqueCounter("missing"))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, n, int64(3))
+
+		require.NoError(t, week.Clear(ctx))
+		assert.False(t, mr.Exists("wau"))
+	})
+
+	t.Run("time buckets", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		day := time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC)
+
+		// Users are distinct across days, as miniredis adds up the counts of
+		// several keys instead of estimating their union
+		for i, users := range [][]string{{"ada", "alan"}, {"linus"}, {"grace"}} {
+			counter := client.UniqueCounterAt("dau", Daily, day.AddDate(0, 0, i))
+			_, err := counter.Add(ctx, users...)
+			require.NoError(t, err)
+		}
+		assert.True(t, mr.Exists("dau:2024-03-09"))
+
+		n, err := client.CountUniqueBetween(ctx, "dau", Daily, day, day.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		n, err = client.CountUniqueBetween(ctx, "dau", Daily, day, day.AddDate(0, 0, 6))
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), n)
+
+		n, err = client.CountUniqueBetween(ctx, "dau", Daily, day, day.AddDate(0, 0, -1))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		counter := client.UniqueCounter("dau:2024-03-09")
+		_, err := counter.Add(ctx, "ada")
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, counter.Expire(ctx, 0), ErrInvalidTTL)
+		require.NoError(t, counter.Expire(ctx, 48*time.Hour))
+		assert.Equal(t, 48*time.Hour, mr.TTL("dau:2024-03-09"))
+	})
+}
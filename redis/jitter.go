@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"math/rand"
+	"time"
+)
+
+// jitter randomises ttl by up to the configured TTLJitter fraction in either
+// direction. Zero and negative TTLs, meaning no expiry or keep the current
+// one, are returned unchanged.
+func (c *Client) jitter(ttl time.Duration) time.Duration {
+	if c.ttlJitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+
+	offset := time.Duration((rand.Float64()*2 - 1) * c.ttlJitter * float64(ttl))
+	if jittered := ttl + offset; jittered > 0 {
+		return jittered
+	}
+	return ttl
+}
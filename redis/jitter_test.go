@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_TTLJitter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.Equal(t, time.Hour, mr.TTL("key"))
+	})
+
+	t.Run("ttls stay within the jitter range", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{TTLJitter: 0.1})
+		defer mr.Close()
+
+		distinct := make(map[time.Duration]struct{})
+		for i := 0; i < 20; i++ {
+			require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+			ttl := mr.TTL("key")
+			assert.GreaterOrEqual(t, ttl, 54*time.Minute)
+			assert.LessOrEqual(t, ttl, 66*time.Minute)
+			distinct[ttl] = struct{}{}
+		}
+		assert.Greater(t, len(distinct), 1)
+	})
+
+	t.Run("applies to remember", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{TTLJitter: 0.1})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "key", time.Hour, func() (interface{}, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, float64(time.Hour), float64(mr.TTL("key")), float64(6*time.Minute))
+	})
+
+	t.Run("items without expiry are unaffected", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{TTLJitter: 0.5})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", 0))
+		assert.Zero(t, mr.TTL("key"))
+	})
+
+	t.Run("jitter is clamped", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{TTLJitter: 5})
+		defer mr.Close()
+
+		for i := 0; i < 20; i++ {
+			ttl := client.jitter(time.Hour)
+			assert.Greater(t, ttl, time.Duration(0))
+			assert.LessOrEqual(t, ttl, 2*time.Hour)
+		}
+	})
+}
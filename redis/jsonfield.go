@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// jsonFieldScript walks a dot separated path through a JSON document stored
+// as a string and returns the value found there. Strings are returned as is,
+// every other value is returned JSON encoded. The first element of the reply
+// is 0 when the key is missing, 1 when the path is missing and 2 on success.
+var jsonFieldScript = redis.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+	return {0}
+end
+
+local node = cjson.decode(raw)
+for part in string.gmatch(ARGV[1], '[^.]+') do
+	if type(node) ~= 'table' then
+		return {1}
+	end
+	local child = node[part]
+	if child == nil then
+		local index = tonumber(part)
+		if index then
+			child = node[index + 1]
+		end
+	end
+	if child == nil then
+		return {1}
+	end
+	node = child
+end
+
+if type(node) == 'string' then
+	return {2, node}
+end
+return {2, cjson.encode(node)}
+`)
+
+// GetJSONField extracts a single field from a JSON document stored at key
+// without transferring the whole document. The path is dot separated, with
+// numeric segments indexing into arrays (e.g. "user.addresses.0.city").
+func (c *Client) GetJSONField(ctx context.Context, key, dotPath string) (string, error) {
+	reply, err := jsonFieldScript.Run(ctx, c.client, []string{c.key(key)}, dotPath).Slice()
+	if err != nil {
+		return "", fmt.Errorf("failed to extract JSON field: %w", err)
+	}
+
+	status, _ := reply[0].(int64)
+	switch status {
+	case 0:
+		return "", ErrKeyNotFound
+	case 1:
+		return "", ErrFieldNotFound
+	}
+
+	value, _ := reply[1].(string)
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetJSONField(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	doc := `{"name":"test","value":123,"active":true,"user":{"profile":{"city":"Accra"},"tags":["a","b"]}}`
+	require.NoError(t, client.Forever(ctx, "doc", doc))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "top-level string", path: "name", want: "test"},
+		{name: "top-level number", path: "value", want: "123"},
+		{name: "boolean", path: "active", want: "true"},
+		{name: "nested field", path: "user.profile.city", want: "Accra"},
+		{name: "array element", path: "user.tags.1", want: "b"},
+		{name: "nested object", path: "user.profile", want: `{"city":"Accra"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := client.GetJSONField(ctx, "doc", tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := client.GetJSONField(ctx, "doc", "user.profile.country")
+		assert.Equal(t, ErrFieldNotFound, err)
+	})
+
+	t.Run("path through a scalar", func(t *testing.T) {
+		_, err := client.GetJSONField(ctx, "doc", "name.first")
+		assert.Equal(t, ErrFieldNotFound, err)
+	})
+
+	t.Run("non-existent key", func(t *testing.T) {
+		_, err := client.GetJSONField(ctx, "non-existent-key", "name")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("value is not JSON", func(t *testing.T) {
+		require.NoError(t, client.Forever(ctx, "plain", "not json"))
+
+		_, err := client.GetJSONField(ctx, "plain", "name")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyEvent is a keyspace notification for a key under the client prefix.
+// Event is the name Redis gives the operation, such as "set", "del",
+// "expired" or "evicted".
+type KeyEvent struct {
+	Key   string
+	Event string
+}
+
+// KeyEventSubscription delivers keyspace notifications until closed
+type KeyEventSubscription struct {
+	pubsubs []*redis.PubSub
+	wg      sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+// OnKeyEvent calls handler for every keyspace notification about a key under
+// the client prefix, limited to the named events when any are given. The
+// server must have notifications enabled, for instance with
+// EnableKeyEvents or notify-keyspace-events "KA" in redis.conf. The handler
+// runs on one goroutine per node, so it should return quickly. In cluster
+// mode every master known at subscription time is subscribed to.
+func (c *Client) OnKeyEvent(ctx context.Context, handler func(KeyEvent), events ...string) (*KeyEventSubscription, error) {
+	if handler == nil {
+		return nil, ErrNilCallback
+	}
+
+	wanted := make(map[string]bool, len(events))
+	for _, event := range events {
+		wanted[event] = true
+	}
+
+	channelPrefix := fmt.Sprintf("__keyspace@%d__:", c.db)
+	pattern := channelPrefix + escapePattern(c.prefix) + "*"
+
+	var mu sync.Mutex
+	s := &KeyEventSubscription{}
+	err := c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		pubsub := shard.PSubscribe(ctx, pattern)
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+			return err
+		}
+
+		mu.Lock()
+		s.pubsubs = append(s.pubsubs, pubsub)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to subscribe to key events: %w", err)
+	}
+
+	for _, pubsub := range s.pubsubs {
+		s.wg.Add(1)
+		go func(pubsub *redis.PubSub) {
+			defer s.wg.Done()
+			for msg := range pubsub.Channel() {
+				if len(wanted) > 0 && !wanted[msg.Payload] {
+					continue
+				}
+				key := strings.TrimPrefix(msg.Channel, channelPrefix)
+				handler(KeyEvent{Key: strings.TrimPrefix(key, c.prefix), Event: msg.Payload})
+			}
+		}(pubsub)
+	}
+
+	return s, nil
+}
+
+// Close stops the notifications and waits for running handlers to return
+func (s *KeyEventSubscription) Close() error {
+	var errs []error
+	s.closeOnce.Do(func() {
+		for _, pubsub := range s.pubsubs {
+			if err := pubsub.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		s.wg.Wait()
+	})
+	return errors.Join(errs...)
+}
+
+// EnableKeyEvents configures every node to publish keyspace notifications
+// for all events, as needed by OnKeyEvent. Managed services often disallow
+// CONFIG SET; enable notifications in their settings instead.
+func (c *Client) EnableKeyEvents(ctx context.Context) error {
+	return c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		return shard.ConfigSet(ctx, "notify-keyspace-events", "KA").Err()
+	})
+}
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyEvents collects key events delivered to a handler
+type keyEvents struct {
+	mu     sync.Mutex
+	events []KeyEvent
+}
+
+func (k *keyEvents) handle(event KeyEvent) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.events = append(k.events, event)
+}
+
+func (k *keyEvents) list() []KeyEvent {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]KeyEvent(nil), k.events...)
+}
+
+func TestClient_OnKeyEvent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers events for prefixed keys", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		defer client.Close()
+
+		var received keyEvents
+		sub, err := client.OnKeyEvent(ctx, received.handle)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		// The mock server does not emit notifications, so publish them as
+		// Redis would
+		mr.Publish("__keyspace@0__:app:session", "expired")
+		mr.Publish("__keyspace@0__:other:session", "del")
+		mr.Publish("__keyspace@0__:app:user:1", "set")
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []KeyEvent{
+			{Key: "session", Event: "expired"},
+			{Key: "user:1", Event: "set"},
+		}, received.list())
+	})
+
+	t.Run("filters events", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		var received keyEvents
+		sub, err := client.OnKeyEvent(ctx, received.handle, "expired", "evicted")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		mr.Publish("__keyspace@0__:a", "set")
+		mr.Publish("__keyspace@0__:b", "evicted")
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []KeyEvent{{Key: "b", Event: "evicted"}}, received.list())
+	})
+
+	t.Run("uses the selected database", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{DB: 2})
+		defer mr.Close()
+		defer client.Close()
+
+		var received keyEvents
+		sub, err := client.OnKeyEvent(ctx, received.handle)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		mr.Publish("__keyspace@0__:key", "set")
+		mr.Publish("__keyspace@2__:key", "del")
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []KeyEvent{{Key: "key", Event: "del"}}, received.list())
+	})
+
+	t.Run("close stops delivery", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		var received keyEvents
+		sub, err := client.OnKeyEvent(ctx, received.handle)
+		require.NoError(t, err)
+		require.NoError(t, sub.Close())
+		require.NoError(t, sub.Close())
+
+		mr.Publish("__keyspace@0__:key", "set")
+		time.Sleep(20 * time.Millisecond)
+		assert.Empty(t, received.list())
+	})
+
+	t.Run("nil handler", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		_, err := client.OnKeyEvent(ctx, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+
+	t.Run("cluster mode", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{})
+		defer client.Close()
+
+		var received keyEvents
+		sub, err := client.OnKeyEvent(ctx, received.handle)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		mr.Publish("__keyspace@0__:key", "set")
+		assert.Eventually(t, func() bool { return len(received.list()) == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
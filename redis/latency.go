@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// latencyBucketMin is the upper bound of the lowest latency bucket
+	latencyBucketMin = 10 * time.Microsecond
+
+	// latencyBucketGrowth is the ratio between consecutive bucket bounds,
+	// which bounds the error of a reported percentile to 20%
+	latencyBucketGrowth = 1.2
+
+	// latencyBucketCount covers latencies from 10µs up to roughly 100s, with
+	// anything slower counted in the last bucket
+	latencyBucketCount = 90
+
+	// pipelineOp is the operation name pipelines and transactions are
+	// recorded under
+	pipelineOp = "pipeline"
+)
+
+// latencyBounds holds the upper bound of every latency bucket
+var latencyBounds = func() [latencyBucketCount]time.Duration {
+	var bounds [latencyBucketCount]time.Duration
+	for i := range bounds {
+		bounds[i] = time.Duration(float64(latencyBucketMin) * math.Pow(latencyBucketGrowth, float64(i)))
+	}
+	return bounds
+}()
+
+// latencyHistogram counts observed latencies in fixed exponential buckets so
+// memory use stays constant regardless of how many samples are recorded
+type latencyHistogram struct {
+	counts [latencyBucketCount]uint64
+	total  uint64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < latencyBucketCount-1 && d > latencyBounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+}
+
+// percentile returns the upper bound of the bucket holding the q-th quantile
+func (h *latencyHistogram) percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(h.total)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return latencyBounds[i]
+		}
+	}
+	return latencyBounds[latencyBucketCount-1]
+}
+
+// latencyTracker keeps a latency histogram per operation
+type latencyTracker struct {
+	mu         sync.Mutex
+	histograms map[string]*latencyHistogram
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{histograms: make(map[string]*latencyHistogram)}
+}
+
+func (t *latencyTracker) observe(op string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.histograms[op]
+	if !ok {
+		h = &latencyHistogram{}
+		t.histograms[op] = h
+	}
+	h.observe(d)
+}
+
+func (t *latencyTracker) percentiles(op string) (p50, p95, p99 time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.histograms[op]
+	if !ok {
+		return 0, 0, 0
+	}
+	return h.percentile(0.50), h.percentile(0.95), h.percentile(0.99)
+}
+
+func (t *latencyTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.histograms = make(map[string]*latencyHistogram)
+}
+
+// latencyHook records the duration of every command sent through the client
+type latencyHook struct {
+	tracker *latencyTracker
+}
+
+func (h latencyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h latencyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.tracker.observe(strings.ToLower(cmd.Name()), time.Since(start))
+		return err
+	}
+}
+
+func (h latencyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.tracker.observe(pipelineOp, time.Since(start))
+		return err
+	}
+}
+
+// LatencyPercentiles reports the p50, p95 and p99 latency observed for an
+// operation, named after the Redis command it sends (e.g. "get", "set",
+// "evalsha") or "pipeline" for pipelines and transactions. Values are
+// accurate to within 20% and are zero if the operation has not been seen.
+func (c *Client) LatencyPercentiles(op string) (p50, p95, p99 time.Duration) {
+	return c.latency.percentiles(strings.ToLower(op))
+}
+
+// ResetLatency discards all recorded latencies
+func (c *Client) ResetLatency() {
+	c.latency.reset()
+}
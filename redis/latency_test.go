@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertWithin checks that got is within 20% of want, the histogram's bucket
+// resolution
+func assertWithin(t *testing.T, want, got time.Duration) {
+	t.Helper()
+	assert.InDelta(t, float64(want), float64(got), float64(want)*0.2, "want ~%s, got %s", want, got)
+}
+
+func TestClient_LatencyPercentiles(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("known distribution", func(t *testing.T) {
+		client.ResetLatency()
+
+		// 1..100ms, one sample each
+		for i := 1; i <= 100; i++ {
+			client.latency.observe("stub", time.Duration(i)*time.Millisecond)
+		}
+
+		p50, p95, p99 := client.LatencyPercentiles("stub")
+		assertWithin(t, 50*time.Millisecond, p50)
+		assertWithin(t, 95*time.Millisecond, p95)
+		assertWithin(t, 99*time.Millisecond, p99)
+	})
+
+	t.Run("skewed distribution", func(t *testing.T) {
+		client.ResetLatency()
+
+		for i := 0; i < 980; i++ {
+			client.latency.observe("stub", time.Millisecond)
+		}
+		for i := 0; i < 20; i++ {
+			client.latency.observe("stub", time.Second)
+		}
+
+		p50, p95, p99 := client.LatencyPercentiles("stub")
+		assertWithin(t, time.Millisecond, p50)
+		assertWithin(t, time.Millisecond, p95)
+		assertWithin(t, time.Second, p99)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		p50, p95, p99 := client.LatencyPercentiles("never-called")
+		assert.Zero(t, p50)
+		assert.Zero(t, p95)
+		assert.Zero(t, p99)
+	})
+
+	t.Run("records real commands", func(t *testing.T) {
+		client.ResetLatency()
+
+		require.NoError(t, client.Put(ctx, "test-key", "test-value", time.Hour))
+		_, err := client.Get(ctx, "test-key")
+		require.NoError(t, err)
+
+		p50, _, p99 := client.LatencyPercentiles("GET")
+		assert.True(t, p50 > 0)
+		assert.True(t, p99 >= p50)
+
+		p50, _, _ = client.LatencyPercentiles("set")
+		assert.True(t, p50 > 0)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		client.latency.observe("stub", time.Millisecond)
+		client.ResetLatency()
+
+		p50, _, _ := client.LatencyPercentiles("stub")
+		assert.Zero(t, p50)
+	})
+
+	t.Run("concurrent observations", func(t *testing.T) {
+		client.ResetLatency()
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					client.latency.observe("stub", 5*time.Millisecond)
+					client.LatencyPercentiles("stub")
+				}
+			}()
+		}
+		wg.Wait()
+
+		p50, _, _ := client.LatencyPercentiles("stub")
+		assertWithin(t, 5*time.Millisecond, p50)
+	})
+}
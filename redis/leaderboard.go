@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Leaderboard ranks members of a Redis sorted set by score, highest first.
+// Members with equal scores are ordered in reverse lexicographic order, as
+// ZREVRANGE orders them.
+type Leaderboard struct {
+	client *Client
+	key    string
+}
+
+// LeaderboardEntry is a member of a leaderboard with its score and rank, 1
+// being the highest score
+type LeaderboardEntry struct {
+	Member string
+	Score  float64
+	Rank   int64
+}
+
+// Leaderboard returns a handle on the leaderboard stored at key. No command
+// is sent until one of its methods is called.
+func (c *Client) Leaderboard(key string) *Leaderboard {
+	return &Leaderboard{client: c, key: key}
+}
+
+// Key returns the key of the leaderboard, without the client prefix
+func (b *Leaderboard) Key() string {
+	return b.key
+}
+
+// AddScore adds by to the score of member, creating it with a score of by,
+// and returns its new score
+func (b *Leaderboard) AddScore(ctx context.Context, member string, by float64) (float64, error) {
+	return b.client.client.ZIncrBy(ctx, b.client.key(b.key), by, member).Result()
+}
+
+// SetScore sets the score of member, replacing any previous score
+func (b *Leaderboard) SetScore(ctx context.Context, member string, score float64) error {
+	return b.client.client.ZAdd(ctx, b.client.key(b.key), redis.Z{Score: score, Member: member}).Err()
+}
+
+// Score returns the score of member, or ErrMemberNotFound
+func (b *Leaderboard) Score(ctx context.Context, member string) (float64, error) {
+	var score float64
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		score, err = cmd.ZScore(ctx, b.client.key(b.key), member).Result()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMemberNotFound
+	}
+	return score, err
+}
+
+// RankOf returns the rank of member, 1 being the highest score, or
+// ErrMemberNotFound
+func (b *Leaderboard) RankOf(ctx context.Context, member string) (int64, error) {
+	var rank int64
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		rank, err = cmd.ZRevRank(ctx, b.client.key(b.key), member).Result()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMemberNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rank + 1, nil
+}
+
+// Top returns the n highest ranked entries
+func (b *Leaderboard) Top(ctx context.Context, n int64) ([]LeaderboardEntry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return b.between(ctx, 0, n-1)
+}
+
+// Page returns the entries of a page of perPage entries, pages counting
+// from 1. Pages past the end are empty.
+func (b *Leaderboard) Page(ctx context.Context, page, perPage int64) ([]LeaderboardEntry, error) {
+	if page < 1 || perPage <= 0 {
+		return nil, nil
+	}
+	start := (page - 1) * perPage
+	return b.between(ctx, start, start+perPage-1)
+}
+
+// Around returns member's entry with up to n entries ranked above and n
+// below it, or ErrMemberNotFound
+func (b *Leaderboard) Around(ctx context.Context, member string, n int64) ([]LeaderboardEntry, error) {
+	rank, err := b.RankOf(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		n = 0
+	}
+
+	start := rank - 1 - n
+	if start < 0 {
+		start = 0
+	}
+	return b.between(ctx, start, rank-1+n)
+}
+
+// Remove removes members from the leaderboard and returns how many existed
+func (b *Leaderboard) Remove(ctx context.Context, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	return b.client.client.ZRem(ctx, b.client.key(b.key), stringArgs(members)...).Result()
+}
+
+// Len returns the number of members in the leaderboard
+func (b *Leaderboard) Len(ctx context.Context) (int64, error) {
+	var n int64
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		n, err = cmd.ZCard(ctx, b.client.key(b.key)).Result()
+		return err
+	})
+	return n, err
+}
+
+// Clear removes the whole leaderboard
+func (b *Leaderboard) Clear(ctx context.Context) error {
+	return b.client.client.Del(ctx, b.client.key(b.key)).Err()
+}
+
+// between returns the entries ranked from start to stop, 0-based and
+// inclusive
+func (b *Leaderboard) between(ctx context.Context, start, stop int64) ([]LeaderboardEntry, error) {
+	var members []redis.Z
+	err := b.client.read(func(cmd redis.Cmdable) (err error) {
+		members, err = cmd.ZRevRangeWithScores(ctx, b.client.key(b.key), start, stop).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LeaderboardEntry, len(members))
+	for i, z := range members {
+		member, _ := z.Member.(string)
+		entries[i] = LeaderboardEntry{Member: member, Score: z.Score, Rank: start + int64(i) + 1}
+	}
+	return entries, nil
+}
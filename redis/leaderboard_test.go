@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Leaderboard(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Leaderboard, func()) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		board := client.Leaderboard("scores")
+		for i, member := range []string{"ada", "alan", "grace", "linus", "ken"} {
+			require.NoError(t, board.SetScore(ctx, member, float64(10*(i+1))))
+		}
+		return board, mr.Close
+	}
+
+	members := func(entries []LeaderboardEntry) []string {
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Member
+		}
+		return names
+	}
+
+	t.Run("top", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		top, err := board.Top(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []LeaderboardEntry{
+			{Member: "ken", Score: 50, Rank: 1},
+			{Member: "linus", Score: 40, Rank: 2},
+			{Member: "grace", Score: 30, Rank: 3},
+		}, top)
+
+		top, err = board.Top(ctx, 0)
+		require.NoError(t, err)
+		assert.Empty(t, top)
+	})
+
+	t.Run("add score", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		score, err := board.AddScore(ctx, "ada", 45)
+		require.NoError(t, err)
+		assert.Equal(t, float64(55), score)
+
+		rank, err := board.RankOf(ctx, "ada")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), rank)
+
+		score, err = board.AddScore(ctx, "barbara", 5)
+		require.NoError(t, err)
+		assert.Equal(t, float64(5), score)
+
+		n, err := board.Len(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), n)
+	})
+
+	t.Run("missing members", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		_, err := board.RankOf(ctx, "missing")
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+		_, err = board.Score(ctx, "missing")
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+		_, err = board.Around(ctx, "missing", 1)
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+	})
+
+	t.Run("around", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		around, err := board.Around(ctx, "grace", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"linus", "grace", "alan"}, members(around))
+		assert.Equal(t, int64(2), around[0].Rank)
+
+		// Neighbours are cut off at the ends
+		around, err = board.Around(ctx, "ken", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ken", "linus", "grace"}, members(around))
+
+		around, err = board.Around(ctx, "ada", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alan", "ada"}, members(around))
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		page, err := board.Page(ctx, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"grace", "alan"}, members(page))
+		assert.Equal(t, int64(3), page[0].Rank)
+
+		page, err = board.Page(ctx, 3, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ada"}, members(page))
+
+		page, err = board.Page(ctx, 4, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+
+		page, err = board.Page(ctx, 0, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		board, done := setup(t)
+		defer done()
+
+		n, err := board.Remove(ctx, "ken", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		score, err := board.Score(ctx, "linus")
+		require.NoError(t, err)
+		assert.Equal(t, float64(40), score)
+
+		rank, err := board.RankOf(ctx, "linus")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), rank)
+
+		require.NoError(t, board.Clear(ctx))
+		n, err = board.Len(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// listBlockInterval bounds each blocking pop BPop sends, so it notices a
+// cancelled context without waiting out its whole timeout
+const listBlockInterval = time.Second
+
+// List is a Redis list, usable as a FIFO work queue with Push and Pop or as
+// a capped activity feed with PushFront and Trim. Values are encoded with
+// the client's codec.
+type List struct {
+	client *Client
+	key    string
+}
+
+// List returns a handle on the list stored at key. No command is sent until
+// one of its methods is called.
+func (c *Client) List(key string) *List {
+	return &List{client: c, key: key}
+}
+
+// Key returns the key of the list, without the client prefix
+func (l *List) Key() string {
+	return l.key
+}
+
+// encode encodes values with the client's codec
+func (l *List) encode(values []interface{}) ([]interface{}, error) {
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		data, err := l.client.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+		encoded[i] = string(data)
+	}
+	return encoded, nil
+}
+
+// decode decodes a popped value into v
+func (l *List) decode(value string, v interface{}) error {
+	if err := l.client.codec.Unmarshal([]byte(value), v); err != nil {
+		return &DecodeError{Key: l.key, Err: err}
+	}
+	return nil
+}
+
+// Push appends values to the tail of the list and returns its new length
+func (l *List) Push(ctx context.Context, values ...interface{}) (int64, error) {
+	if len(values) == 0 {
+		return l.Len(ctx)
+	}
+	encoded, err := l.encode(values)
+	if err != nil {
+		return 0, err
+	}
+	return l.client.client.RPush(ctx, l.client.key(l.key), encoded...).Result()
+}
+
+// PushFront prepends values to the head of the list, the last one ending up
+// first, and returns its new length
+func (l *List) PushFront(ctx context.Context, values ...interface{}) (int64, error) {
+	if len(values) == 0 {
+		return l.Len(ctx)
+	}
+	encoded, err := l.encode(values)
+	if err != nil {
+		return 0, err
+	}
+	return l.client.client.LPush(ctx, l.client.key(l.key), encoded...).Result()
+}
+
+// Pop removes the value at the head of the list and decodes it into v,
+// returning ErrListEmpty when there is none
+func (l *List) Pop(ctx context.Context, v interface{}) error {
+	value, err := l.client.client.LPop(ctx, l.client.key(l.key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrListEmpty
+	}
+	if err != nil {
+		return err
+	}
+	return l.decode(value, v)
+}
+
+// BPop is Pop waiting up to timeout for a value to be pushed when the list
+// is empty, or until ctx is done when timeout is not positive. It returns
+// ErrListEmpty if none arrived in time. Redis blocks for whole seconds, so
+// the timeout and noticing a cancelled context may take up to a second
+// longer than asked.
+func (l *List) BPop(ctx context.Context, timeout time.Duration, v interface{}) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		wait := listBlockInterval
+		if !deadline.IsZero() {
+			wait = time.Until(deadline)
+			if wait <= 0 {
+				return ErrListEmpty
+			}
+			if wait > listBlockInterval {
+				wait = listBlockInterval
+			}
+			// Redis blocks for whole seconds, and go-redis would otherwise
+			// truncate the wait
+			wait = (wait + time.Second - 1).Truncate(time.Second)
+		}
+
+		reply, err := l.client.client.BLPop(ctx, wait, l.client.key(l.key)).Result()
+		if err == nil {
+			// The reply holds the key popped from, then the value
+			return l.decode(reply[1], v)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, redis.Nil) {
+			return err
+		}
+	}
+}
+
+// Trim keeps only the values from start to stop, both inclusive and
+// counted from the head, negative indexes counting from the tail. Trim(ctx,
+// 0, 99) caps a feed at its 100 newest entries.
+func (l *List) Trim(ctx context.Context, start, stop int64) error {
+	return l.client.client.LTrim(ctx, l.client.key(l.key), start, stop).Err()
+}
+
+// Len returns the number of values in the list
+func (l *List) Len(ctx context.Context) (int64, error) {
+	var n int64
+	err := l.client.read(func(cmd redis.Cmdable) (err error) {
+		n, err = cmd.LLen(ctx, l.client.key(l.key)).Result()
+		return err
+	})
+	return n, err
+}
+
+// Clear removes the whole list
+func (l *List) Clear(ctx context.Context) error {
+	return l.client.client.Del(ctx, l.client.key(l.key)).Err()
+}
+
+// ListRange decodes the values of l from start to stop into T, with the
+// same indexing as Trim. ListRange(ctx, l, 0, -1) returns the whole list.
+func ListRange[T any](ctx context.Context, l *List, start, stop int64) ([]T, error) {
+	var values []string
+	err := l.client.read(func(cmd redis.Cmdable) (err error) {
+		values, err = cmd.LRange(ctx, l.client.key(l.key), start, stop).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(values))
+	for i, value := range values {
+		if err := l.decode(value, &result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
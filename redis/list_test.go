@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_List(t *testing.T) {
+	type job struct {
+		ID int `json:"id"`
+	}
+
+	ctx := context.Background()
+
+	t.Run("push and pop in order", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		jobs := client.List("jobs")
+
+		n, err := jobs.Push(ctx, job{ID: 1}, job{ID: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.True(t, mr.Exists("app:jobs"))
+
+		var next job
+		require.NoError(t, jobs.Pop(ctx, &next))
+		assert.Equal(t, 1, next.ID)
+		require.NoError(t, jobs.Pop(ctx, &next))
+		assert.Equal(t, 2, next.ID)
+
+		assert.ErrorIs(t, jobs.Pop(ctx, &next), ErrListEmpty)
+	})
+
+	t.Run("feeds", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		feed := client.List("feed")
+
+		for _, event := range []string{"signed up", "posted", "commented", "liked"} {
+			_, err := feed.PushFront(ctx, event)
+			require.NoError(t, err)
+		}
+		require.NoError(t, feed.Trim(ctx, 0, 2))
+
+		events, err := ListRange[string](ctx, feed, 0, -1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"liked", "commented", "posted"}, events)
+
+		n, err := feed.Len(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		require.NoError(t, feed.Clear(ctx))
+		events, err = ListRange[string](ctx, feed, 0, -1)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		_, err := mr.Push("jobs", "not json")
+		require.NoError(t, err)
+
+		_, err = ListRange[job](ctx, client.List("jobs"), 0, -1)
+		var decodeErr *DecodeError
+		assert.ErrorAs(t, err, &decodeErr)
+	})
+
+	t.Run("blocking pop waits for a push", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		jobs := client.List("jobs")
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = client.List("jobs").Push(ctx, job{ID: 7})
+		}()
+
+		var next job
+		require.NoError(t, jobs.BPop(ctx, 5*time.Second, &next))
+		assert.Equal(t, 7, next.ID)
+	})
+
+	t.Run("blocking pop times out", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		var next job
+		err := client.List("jobs").BPop(ctx, 100*time.Millisecond, &next)
+		assert.ErrorIs(t, err, ErrListEmpty)
+	})
+
+	t.Run("blocking pop stops with the context", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		var next job
+		err := client.List("jobs").BPop(ctx, 0, &next)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
@@ -0,0 +1,301 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockRetryInterval is how long Block waits between attempts to acquire a
+// lock held by someone else
+const lockRetryInterval = 100 * time.Millisecond
+
+// minAutoRenewTTL is the shortest TTL of an auto-renewing lock, renewed every
+// 10ms; shorter ones would have the watchdog spin or fall behind the expiry
+const minAutoRenewTTL = 30 * time.Millisecond
+
+// releaseLockScript deletes a lock only if it is still held by the given owner
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript resets a lock's TTL only if it is still held by the given
+// owner
+var extendLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// newLockOwner generates a random token identifying the holder of a lock
+func newLockOwner() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// acquireLock tries to take the lock stored at key for owner without waiting
+func (c *Client) acquireLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, owner, ttl).Result()
+}
+
+// releaseLock frees the lock stored at key if it is still held by owner
+func (c *Client) releaseLock(ctx context.Context, key, owner string) (bool, error) {
+	released, err := releaseLockScript.Run(ctx, c.client, []string{key}, owner).Int64()
+	if err != nil {
+		return false, err
+	}
+	return released == 1, nil
+}
+
+// extendLock resets the TTL of the lock stored at key if it is still held by
+// owner
+func (c *Client) extendLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	extended, err := extendLockScript.Run(ctx, c.client, []string{key}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return extended == 1, nil
+}
+
+// LockAll tries to acquire a lock on every name without waiting. Names are
+// acquired in sorted order so that callers locking overlapping sets can never
+// deadlock each other. If any lock is already taken, the ones acquired so far
+// are released and acquired is false. The returned release function frees all
+// of the locks and is safe to call more than once.
+func (c *Client) LockAll(ctx context.Context, ttl time.Duration, names ...string) (release func(), acquired bool, err error) {
+	sorted := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, false, err
+	}
+
+	held := make([]string, 0, len(sorted))
+	releaseHeld := func() {
+		// Release even if the caller's context has been cancelled
+		releaseCtx := context.WithoutCancel(ctx)
+		for i := len(held) - 1; i >= 0; i-- {
+			_, _ = c.releaseLock(releaseCtx, held[i], owner)
+		}
+	}
+
+	for _, name := range sorted {
+		ok, err := c.acquireLock(ctx, c.key(name), owner, ttl)
+		if err != nil {
+			releaseHeld()
+			return nil, false, err
+		}
+		if !ok {
+			releaseHeld()
+			return nil, false, nil
+		}
+		held = append(held, c.key(name))
+	}
+
+	var once sync.Once
+	return func() { once.Do(releaseHeld) }, true, nil
+}
+
+// Lock is a distributed lock stored in Redis. Only the owner that acquired
+// the lock can release it, so a lock that expired and was taken over by
+// another process is never released by mistake.
+type Lock struct {
+	client *Client
+	name   string
+	ttl    time.Duration
+	owner  string
+
+	autoRenew bool
+	mu        sync.Mutex
+	stopRenew context.CancelFunc
+	renewDone chan struct{}
+}
+
+// Lock returns a lock on name that expires after ttl once acquired. The lock
+// is not acquired until Acquire, Block or Get is called.
+func (c *Client) Lock(name string, ttl time.Duration) *Lock {
+	return &Lock{client: c, name: name, ttl: ttl}
+}
+
+// RestoreLock returns a handle on a lock previously acquired with the given
+// owner token, typically in another process, so that it can be released
+func (c *Client) RestoreLock(name, owner string) *Lock {
+	return &Lock{client: c, name: name, owner: owner}
+}
+
+// Owner returns the token identifying this handle as the lock holder. It is
+// empty until the first acquisition attempt.
+func (l *Lock) Owner() string {
+	return l.owner
+}
+
+// WithAutoRenew makes the lock keep itself alive once acquired. A background
+// goroutine extends the TTL every third of it until the lock is released, the
+// context passed to Acquire is cancelled, or the lock is found to have been
+// lost to another owner. Acquiring an auto-renewing lock with a TTL under
+// 30ms returns ErrInvalidTTL.
+func (l *Lock) WithAutoRenew() *Lock {
+	l.autoRenew = true
+	return l
+}
+
+// Acquire tries to take the lock without waiting, reporting whether it did
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	if l.autoRenew && l.ttl > 0 && l.ttl < minAutoRenewTTL {
+		return false, ErrInvalidTTL
+	}
+	if l.owner == "" {
+		owner, err := newLockOwner()
+		if err != nil {
+			return false, err
+		}
+		l.owner = owner
+	}
+
+	acquired, err := l.client.acquireLock(ctx, l.client.key(l.name), l.owner, l.ttl)
+	if err != nil || !acquired {
+		return false, err
+	}
+	if l.autoRenew && l.ttl > 0 {
+		l.startRenew(ctx)
+	}
+	return true, nil
+}
+
+// Extend resets the lock's TTL to ttl if it is still held by this owner,
+// reporting whether it was extended. A ttl under a millisecond returns
+// ErrInvalidTTL, as Redis would delete the lock instead.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) (bool, error) {
+	if ttl < time.Millisecond {
+		return false, ErrInvalidTTL
+	}
+	if l.owner == "" {
+		return false, nil
+	}
+	return l.client.extendLock(ctx, l.client.key(l.name), l.owner, ttl)
+}
+
+// startRenew launches the watchdog that keeps the lock alive
+func (l *Lock) startRenew(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopRenew != nil {
+		return
+	}
+
+	renewCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	l.stopRenew = cancel
+	l.renewDone = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				extended, err := l.Extend(renewCtx, l.ttl)
+				if err == nil && !extended {
+					// The lock expired or was taken over, nothing left to renew
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopRenewing stops the watchdog, if running, and waits for it to exit
+func (l *Lock) stopRenewing() {
+	l.mu.Lock()
+	cancel, done := l.stopRenew, l.renewDone
+	l.stopRenew, l.renewDone = nil, nil
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Block waits up to timeout for the lock to become available and acquires
+// it, returning ErrLockTimeout if it could not be acquired in time
+func (l *Lock) Block(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := l.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return ErrLockTimeout
+		}
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Get acquires the lock without waiting and, if successful, runs fn before
+// releasing it. It reports whether the lock was acquired; fn's error is
+// returned as is.
+func (l *Lock) Get(ctx context.Context, fn func() error) (bool, error) {
+	acquired, err := l.Acquire(ctx)
+	if err != nil || !acquired {
+		return false, err
+	}
+	defer l.Release(context.WithoutCancel(ctx))
+
+	return true, fn()
+}
+
+// Release frees the lock if it is still held by this owner, reporting
+// whether it was released. Any auto-renewal is stopped first.
+func (l *Lock) Release(ctx context.Context) (bool, error) {
+	l.stopRenewing()
+	if l.owner == "" {
+		return false, nil
+	}
+	return l.client.releaseLock(ctx, l.client.key(l.name), l.owner)
+}
+
+// ForceRelease frees the lock regardless of who holds it
+func (l *Lock) ForceRelease(ctx context.Context) error {
+	l.stopRenewing()
+	return l.client.client.Del(ctx, l.client.key(l.name)).Err()
+}
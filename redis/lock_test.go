@@ -0,0 +1,328 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_LockAll(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("acquire and release", func(t *testing.T) {
+		release, acquired, err := client.LockAll(ctx, time.Minute, "account:2", "account:1")
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		assert.True(t, mr.Exists("account:1"))
+		assert.True(t, mr.Exists("account:2"))
+		assert.True(t, mr.TTL("account:1") > 0)
+
+		release()
+		assert.False(t, mr.Exists("account:1"))
+		assert.False(t, mr.Exists("account:2"))
+
+		// Releasing twice is a no-op
+		release()
+	})
+
+	t.Run("partial acquisition is rolled back", func(t *testing.T) {
+		release, acquired, err := client.LockAll(ctx, time.Minute, "account:2")
+		require.NoError(t, err)
+		require.True(t, acquired)
+		defer release()
+
+		_, acquired, err = client.LockAll(ctx, time.Minute, "account:1", "account:2", "account:3")
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+
+		assert.False(t, mr.Exists("account:1"))
+		assert.False(t, mr.Exists("account:3"))
+	})
+
+	t.Run("release does not free locks taken over by others", func(t *testing.T) {
+		release, acquired, err := client.LockAll(ctx, time.Minute, "account:9")
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		// Simulate the lock expiring and being taken by another owner
+		mr.Set("account:9", "someone-else")
+		release()
+
+		val, err := mr.Get("account:9")
+		assert.NoError(t, err)
+		assert.Equal(t, "someone-else", val)
+		mr.Del("account:9")
+	})
+
+	t.Run("duplicate names", func(t *testing.T) {
+		release, acquired, err := client.LockAll(ctx, time.Minute, "dup", "dup")
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		release()
+		assert.False(t, mr.Exists("dup"))
+	})
+}
+
+func TestClient_LockAll_Concurrent(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	var inside, overlaps, completed int32
+	worker := func(wg *sync.WaitGroup, names ...string) {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			for {
+				release, acquired, err := client.LockAll(ctx, time.Minute, names...)
+				if !assert.NoError(t, err) {
+					return
+				}
+				if !acquired {
+					time.Sleep(time.Millisecond)
+					continue
+				}
+
+				if atomic.AddInt32(&inside, 1) != 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				time.Sleep(100 * time.Microsecond)
+				atomic.AddInt32(&inside, -1)
+
+				release()
+				atomic.AddInt32(&completed, 1)
+				break
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go worker(&wg, "from", "to")
+	go worker(&wg, "to", "from")
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("workers deadlocked")
+	}
+
+	assert.Equal(t, int32(0), overlaps)
+	assert.Equal(t, int32(40), completed)
+}
+
+func TestLock(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("acquire and release", func(t *testing.T) {
+		lock := client.Lock("job", time.Minute)
+
+		acquired, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		assert.NotEmpty(t, lock.Owner())
+		assert.Equal(t, time.Minute, mr.TTL("job"))
+
+		// A second handle cannot take a held lock
+		acquired, err = client.Lock("job", time.Minute).Acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		released, err := lock.Release(ctx)
+		require.NoError(t, err)
+		assert.True(t, released)
+		assert.False(t, mr.Exists("job"))
+	})
+
+	t.Run("release is owner checked", func(t *testing.T) {
+		lock := client.Lock("job", time.Minute)
+		_, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+
+		other := client.Lock("job", time.Minute)
+		released, err := other.Release(ctx)
+		require.NoError(t, err)
+		assert.False(t, released)
+		assert.True(t, mr.Exists("job"))
+
+		require.NoError(t, other.ForceRelease(ctx))
+		assert.False(t, mr.Exists("job"))
+	})
+
+	t.Run("restore lock by owner", func(t *testing.T) {
+		lock := client.Lock("job", time.Minute)
+		_, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+
+		released, err := client.RestoreLock("job", lock.Owner()).Release(ctx)
+		require.NoError(t, err)
+		assert.True(t, released)
+	})
+
+	t.Run("block waits for release", func(t *testing.T) {
+		holder := client.Lock("job", time.Minute)
+		_, err := holder.Acquire(ctx)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(150 * time.Millisecond)
+			_, _ = holder.Release(ctx)
+		}()
+
+		waiter := client.Lock("job", time.Minute)
+		start := time.Now()
+		require.NoError(t, waiter.Block(ctx, 2*time.Second))
+		assert.True(t, time.Since(start) >= 150*time.Millisecond)
+
+		_, err = waiter.Release(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("block times out", func(t *testing.T) {
+		holder := client.Lock("job", time.Minute)
+		_, err := holder.Acquire(ctx)
+		require.NoError(t, err)
+		defer holder.Release(ctx)
+
+		err = client.Lock("job", time.Minute).Block(ctx, 150*time.Millisecond)
+		assert.Equal(t, ErrLockTimeout, err)
+	})
+
+	t.Run("block honours context cancellation", func(t *testing.T) {
+		holder := client.Lock("job", time.Minute)
+		_, err := holder.Acquire(ctx)
+		require.NoError(t, err)
+		defer holder.Release(ctx)
+
+		cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err = client.Lock("job", time.Minute).Block(cancelCtx, time.Minute)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("get runs the callback while holding the lock", func(t *testing.T) {
+		ran := false
+		acquired, err := client.Lock("job", time.Minute).Get(ctx, func() error {
+			ran = true
+			assert.True(t, mr.Exists("job"))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		assert.True(t, ran)
+		assert.False(t, mr.Exists("job"))
+	})
+
+	t.Run("get skips the callback when the lock is held", func(t *testing.T) {
+		holder := client.Lock("job", time.Minute)
+		_, err := holder.Acquire(ctx)
+		require.NoError(t, err)
+		defer holder.Release(ctx)
+
+		acquired, err := client.Lock("job", time.Minute).Get(ctx, func() error {
+			t.Fatal("callback should not run")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("get returns the callback error and releases", func(t *testing.T) {
+		boom := errors.New("boom")
+		acquired, err := client.Lock("job", time.Minute).Get(ctx, func() error {
+			return boom
+		})
+		assert.True(t, acquired)
+		assert.Equal(t, boom, err)
+		assert.False(t, mr.Exists("job"))
+	})
+
+	t.Run("extend", func(t *testing.T) {
+		lock := client.Lock("job", time.Minute)
+		_, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		defer lock.Release(ctx)
+
+		extended, err := lock.Extend(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, extended)
+		assert.Equal(t, time.Hour, mr.TTL("job"))
+
+		// Another owner cannot extend the lock
+		extended, err = client.RestoreLock("job", "someone-else").Extend(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.False(t, extended)
+
+		// Nor can a TTL too short to keep it
+		for _, ttl := range []time.Duration{0, -time.Second, 500 * time.Microsecond} {
+			extended, err = lock.Extend(ctx, ttl)
+			assert.Equal(t, ErrInvalidTTL, err)
+			assert.False(t, extended)
+		}
+		assert.Equal(t, time.Hour, mr.TTL("job"))
+	})
+
+	t.Run("auto renew keeps the lock alive until released", func(t *testing.T) {
+		lock := client.Lock("job", 300*time.Millisecond).WithAutoRenew()
+		acquired, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		mr.FastForward(250 * time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return mr.TTL("job") > 100*time.Millisecond
+		}, time.Second, 10*time.Millisecond)
+
+		released, err := lock.Release(ctx)
+		require.NoError(t, err)
+		assert.True(t, released)
+		assert.False(t, mr.Exists("job"))
+	})
+
+	t.Run("auto renew stops when the context is cancelled", func(t *testing.T) {
+		lockCtx, cancel := context.WithCancel(ctx)
+		lock := client.Lock("job", 300*time.Millisecond).WithAutoRenew()
+		_, err := lock.Acquire(lockCtx)
+		require.NoError(t, err)
+		defer lock.Release(ctx)
+
+		cancel()
+		mr.FastForward(250 * time.Millisecond)
+		time.Sleep(200 * time.Millisecond)
+		assert.LessOrEqual(t, mr.TTL("job"), 50*time.Millisecond)
+	})
+
+	t.Run("auto renew rejects tiny TTLs", func(t *testing.T) {
+		for _, ttl := range []time.Duration{time.Nanosecond, 2 * time.Nanosecond, time.Microsecond, 10 * time.Millisecond} {
+			acquired, err := client.Lock("tiny", ttl).WithAutoRenew().Acquire(ctx)
+			assert.ErrorIs(t, err, ErrInvalidTTL, ttl)
+			assert.False(t, acquired)
+		}
+		assert.False(t, mr.Exists("tiny"))
+
+		// Without auto renew they are left to Redis
+		acquired, err := client.Lock("tiny", time.Millisecond).Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruCache is a size bounded in-process cache evicting the least recently
+// used entry once full. Every entry carries its own expiry.
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	entries *list.List
+	index   map[string]*list.Element
+
+	// version is bumped by every removal, letting a reader that fetched a
+	// value before an invalidation notice it and skip caching it
+	version uint64
+}
+
+type lruEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+// get returns the value cached for key, if it has not expired
+func (l *lruCache) get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.index[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		l.entries.Remove(elem)
+		delete(l.index, key)
+		return "", false
+	}
+	l.entries.MoveToFront(elem)
+	return entry.value, true
+}
+
+// current returns the version to pass to setIfCurrent
+func (l *lruCache) current() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.version
+}
+
+// set caches value for key for ttl
+func (l *lruCache) set(key, value string, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store(key, value, ttl)
+}
+
+// setIfCurrent caches value for key for ttl unless an entry was removed since
+// version was read
+func (l *lruCache) setIfCurrent(version uint64, key, value string, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.version == version {
+		l.store(key, value, ttl)
+	}
+}
+
+// store caches value for key, evicting the oldest entry when full. l.mu must
+// be held.
+func (l *lruCache) store(key, value string, ttl time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := l.index[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.entries.MoveToFront(elem)
+		return
+	}
+
+	l.index[key] = l.entries.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	if l.entries.Len() > l.size {
+		oldest := l.entries.Back()
+		l.entries.Remove(oldest)
+		delete(l.index, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove drops key from the cache
+func (l *lruCache) remove(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.version++
+	if elem, ok := l.index[key]; ok {
+		l.entries.Remove(elem)
+		delete(l.index, key)
+	}
+}
+
+// purge drops every entry
+func (l *lruCache) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.version++
+	l.entries.Init()
+	l.index = make(map[string]*list.Element)
+}
+
+// len returns the number of cached entries, including expired ones not yet
+// evicted
+func (l *lruCache) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.entries.Len()
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		l := newLRUCache(2)
+		l.set("a", "1", time.Minute)
+		l.set("b", "2", time.Minute)
+		l.get("a")
+		l.set("c", "3", time.Minute)
+
+		_, ok := l.get("b")
+		assert.False(t, ok)
+		value, ok := l.get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "1", value)
+		assert.Equal(t, 2, l.len())
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		l := newLRUCache(10)
+		l.set("a", "1", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok := l.get("a")
+		assert.False(t, ok)
+		assert.Zero(t, l.len())
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		l := newLRUCache(10)
+		l.set("a", "1", time.Minute)
+		l.set("a", "2", time.Minute)
+
+		value, _ := l.get("a")
+		assert.Equal(t, "2", value)
+		assert.Equal(t, 1, l.len())
+	})
+
+	t.Run("removals invalidate pending sets", func(t *testing.T) {
+		l := newLRUCache(10)
+		version := l.current()
+		l.remove("a")
+		l.setIfCurrent(version, "a", "stale", time.Minute)
+
+		_, ok := l.get("a")
+		assert.False(t, ok)
+
+		version = l.current()
+		l.setIfCurrent(version, "a", "fresh", time.Minute)
+		value, _ := l.get("a")
+		assert.Equal(t, "fresh", value)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		l := newLRUCache(10)
+		for i := 0; i < 5; i++ {
+			l.set(strconv.Itoa(i), "v", time.Minute)
+		}
+		l.purge()
+		assert.Zero(t, l.len())
+	})
+}
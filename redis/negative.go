@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+	"errors"
+)
+
+// errCachedMiss is reported internally for keys holding the negative caching
+// marker, so Remember can tell them apart from keys that are absent
+var errCachedMiss = errors.New("key cached as not found")
+
+// missMarker is the value cached for keys whose Remember callback found
+// nothing. User values starting with valueMagic are stored with a raw header,
+// so it cannot be mistaken for one.
+const missMarker = valueMagic + string(formatMiss)
+
+// hideCachedMiss reports errCachedMiss as ErrKeyNotFound to callers outside
+// Remember
+func hideCachedMiss(err error) error {
+	if errors.Is(err, errCachedMiss) {
+		return ErrKeyNotFound
+	}
+	return err
+}
+
+// storeMiss caches key as not found for the negative TTL, returning
+// ErrKeyNotFound for the Remember call that found nothing
+func (c *Client) storeMiss(ctx context.Context, key string) error {
+	if err := c.client.Set(ctx, c.key(key), missMarker, c.jitter(c.negativeTTL)).Err(); err != nil {
+		return err
+	}
+	return ErrKeyNotFound
+}
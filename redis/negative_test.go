@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+
+	notFound := func(calls *int) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			*calls++
+			return nil, fmt.Errorf("user 42: %w", ErrKeyNotFound)
+		}
+	}
+
+	t.Run("caches not found results", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{NegativeTTL: 30 * time.Second})
+		defer mr.Close()
+
+		calls := 0
+		for i := 0; i < 3; i++ {
+			_, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+			assert.Equal(t, ErrKeyNotFound, err)
+		}
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, 30*time.Second, mr.TTL("user:42"))
+
+		// Once the marker expires the callback runs again
+		mr.FastForward(31 * time.Second)
+		value, err := client.Remember(ctx, "user:42", time.Hour, func() (interface{}, error) {
+			calls++
+			return "found", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"found"`, value)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("marked keys read as missing", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{NegativeTTL: time.Minute})
+		defer mr.Close()
+
+		calls := 0
+		_, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+		require.Equal(t, ErrKeyNotFound, err)
+		require.NoError(t, client.Put(ctx, "user:1", "value", time.Hour))
+
+		_, err = client.Get(ctx, "user:42")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		values, err := client.GetMany(ctx, "user:1", "user:42")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"user:1": "value"}, values)
+
+		// Writing the key replaces the marker
+		require.NoError(t, client.Put(ctx, "user:42", "created", time.Hour))
+		value, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+		require.NoError(t, err)
+		assert.Equal(t, "created", value)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{NegativeTTL: time.Minute})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "user:42", time.Hour, func() (interface{}, error) {
+			return nil, errors.New("database unavailable")
+		})
+		assert.EqualError(t, err, "callback execution failed: database unavailable")
+		assert.False(t, mr.Exists("user:42"))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+		}
+		assert.Equal(t, 2, calls)
+		assert.False(t, mr.Exists("user:42"))
+	})
+
+	t.Run("with early expiration", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{NegativeTTL: time.Minute, EarlyExpiration: 1})
+		defer mr.Close()
+
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+			assert.Equal(t, ErrKeyNotFound, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("with a remember lock", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{NegativeTTL: time.Minute, RememberLock: time.Second})
+		defer mr.Close()
+
+		calls := 0
+		for i := 0; i < 2; i++ {
+			_, err := client.Remember(ctx, "user:42", time.Hour, notFound(&calls))
+			assert.Equal(t, ErrKeyNotFound, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+// Option configures a client created with NewWithOptions
+type Option func(*Config)
+
+// NewWithOptions creates a new Redis client connected to localhost:6379
+// unless WithAddr or WithURL says otherwise, configured by opts
+func NewWithOptions(opts ...Option) (*Client, error) {
+	cfg := Config{Host: "localhost", Port: 6379}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return New(cfg)
+}
+
+// WithAddr sets the host and port to connect to
+func WithAddr(host string, port int) Option {
+	return func(cfg *Config) {
+		cfg.Host = host
+		cfg.Port = port
+	}
+}
+
+// WithURL connects from a connection string, see Config.URL
+func WithURL(url string) Option {
+	return func(cfg *Config) {
+		cfg.URL = url
+	}
+}
+
+// WithPassword sets the password used to authenticate
+func WithPassword(password string) Option {
+	return func(cfg *Config) {
+		cfg.Password = password
+	}
+}
+
+// WithDB selects the database to use
+func WithDB(db int) Option {
+	return func(cfg *Config) {
+		cfg.DB = db
+	}
+}
+
+// WithPoolSize sets the maximum number of connections per node
+func WithPoolSize(size int) Option {
+	return func(cfg *Config) {
+		cfg.PoolSize = size
+	}
+}
+
+// WithTimeouts sets the dial, read and write timeouts. Zero values keep the
+// defaults.
+func WithTimeouts(dial, read, write time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.DialTimeout = dial
+		cfg.ReadTimeout = read
+		cfg.WriteTimeout = write
+	}
+}
+
+// WithPrefix prepends prefix to every key, see Config.Prefix
+func WithPrefix(prefix string) Option {
+	return func(cfg *Config) {
+		cfg.Prefix = prefix
+	}
+}
+
+// WithCodec sets the codec used for Remember, PutAny and GetAs
+func WithCodec(codec cache.Codec) Option {
+	return func(cfg *Config) {
+		cfg.Codec = codec
+	}
+}
+
+// WithLazyConnect skips the connectivity check at construction time, see
+// Config.LazyConnect
+func WithLazyConnect() Option {
+	return func(cfg *Config) {
+		cfg.LazyConnect = true
+	}
+}
+
+// WithRetry retries operations that fail with a transient network error,
+// see RetryPolicy
+func WithRetry(policy RetryPolicy) Option {
+	return func(cfg *Config) {
+		cfg.Retry = policy
+	}
+}
+
+// WithNegativeTTL caches "not found" results of Remember callbacks for ttl,
+// see Config.NegativeTTL
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.NegativeTTL = ttl
+	}
+}
+
+// WithConfig applies fn to the configuration, giving access to settings that
+// have no dedicated option
+func WithConfig(fn func(*Config)) Option {
+	return Option(fn)
+}
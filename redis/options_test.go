@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("applies options", func(t *testing.T) {
+		mr.RequireAuth("secret")
+		defer mr.RequireAuth("")
+
+		client, err := NewWithOptions(
+			WithAddr(mr.Host(), port),
+			WithPassword("secret"),
+			WithDB(3),
+			WithPoolSize(42),
+			WithTimeouts(time.Second, 2*time.Second, 3*time.Second),
+			WithPrefix("app:"),
+			WithCodec(cache.MsgpackCodec{}),
+			WithNegativeTTL(time.Minute),
+		)
+		require.NoError(t, err)
+		defer client.Close()
+
+		opts := client.client.(*redis.Client).Options()
+		assert.Equal(t, 42, opts.PoolSize)
+		assert.Equal(t, time.Second, opts.DialTimeout)
+		assert.Equal(t, 2*time.Second, opts.ReadTimeout)
+		assert.Equal(t, 3*time.Second, opts.WriteTimeout)
+		assert.Equal(t, cache.MsgpackCodec{}, client.codec)
+		assert.Equal(t, time.Minute, client.negativeTTL)
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, mr.DB(3).Exists("app:key"))
+	})
+
+	t.Run("url", func(t *testing.T) {
+		client, err := NewWithOptions(WithURL(fmt.Sprintf("redis://%s/1", mr.Addr())), WithPoolSize(7))
+		require.NoError(t, err)
+		defer client.Close()
+
+		opts := client.client.(*redis.Client).Options()
+		assert.Equal(t, 1, opts.DB)
+		assert.Equal(t, 7, opts.PoolSize)
+	})
+
+	t.Run("lazy connect", func(t *testing.T) {
+		client, err := NewWithOptions(WithAddr("127.0.0.1", 1), WithLazyConnect())
+		require.NoError(t, err)
+		defer client.Close()
+
+		assert.Error(t, client.Ping(ctx))
+	})
+
+	t.Run("arbitrary config", func(t *testing.T) {
+		client, err := NewWithOptions(WithAddr(mr.Host(), port), WithConfig(func(cfg *Config) {
+			cfg.AllowFlushAll = true
+		}))
+		require.NoError(t, err)
+		defer client.Close()
+
+		assert.True(t, client.allowFlushAll)
+	})
+}
+
+func TestConfig_Pool(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	cfg := Config{
+		Host:            mr.Host(),
+		Port:            port,
+		PoolSize:        50,
+		MinIdleConns:    5,
+		MaxIdleConns:    20,
+		PoolTimeout:     time.Second,
+		ConnMaxIdleTime: time.Minute,
+		DialTimeout:     2 * time.Second,
+		ReadTimeout:     500 * time.Millisecond,
+		WriteTimeout:    750 * time.Millisecond,
+		MaxRetries:      -1,
+	}
+
+	t.Run("single node", func(t *testing.T) {
+		client, err := New(cfg)
+		require.NoError(t, err)
+		defer client.Close()
+
+		opts := client.client.(*redis.Client).Options()
+		assert.Equal(t, 50, opts.PoolSize)
+		assert.Equal(t, 5, opts.MinIdleConns)
+		assert.Equal(t, 20, opts.MaxIdleConns)
+		assert.Equal(t, time.Second, opts.PoolTimeout)
+		assert.Equal(t, time.Minute, opts.ConnMaxIdleTime)
+		assert.Equal(t, 2*time.Second, opts.DialTimeout)
+		assert.Equal(t, 500*time.Millisecond, opts.ReadTimeout)
+		assert.Equal(t, 750*time.Millisecond, opts.WriteTimeout)
+		// go-redis normalises -1 to zero retries
+		assert.Equal(t, 0, opts.MaxRetries)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		clusterCfg := cfg
+		clusterCfg.Cluster = true
+		client, err := New(clusterCfg)
+		require.NoError(t, err)
+		defer client.Close()
+
+		opts := client.client.(*redis.ClusterClient).Options()
+		assert.Equal(t, 50, opts.PoolSize)
+		assert.Equal(t, 5, opts.MinIdleConns)
+		assert.Equal(t, 500*time.Millisecond, opts.ReadTimeout)
+	})
+
+	t.Run("overrides URL settings", func(t *testing.T) {
+		urlCfg := cfg
+		urlCfg.URL = fmt.Sprintf("redis://%s?pool_size=10&read_timeout=5s&min_idle_conns=1", mr.Addr())
+		client, err := New(urlCfg)
+		require.NoError(t, err)
+		defer client.Close()
+
+		opts := client.client.(*redis.Client).Options()
+		assert.Equal(t, 50, opts.PoolSize)
+		assert.Equal(t, 5, opts.MinIdleConns)
+		assert.Equal(t, 500*time.Millisecond, opts.ReadTimeout)
+	})
+}
@@ -0,0 +1,193 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Result is the outcome of a command queued in a Pipeline or Tx, available
+// once the commands have been executed
+type Result[T any] struct {
+	val T
+	err error
+}
+
+// Result returns the command's value and error. Before the command is
+// executed the error is ErrNotExecuted.
+func (r *Result[T]) Result() (T, error) {
+	return r.val, r.err
+}
+
+// Val returns the command's value, the zero value when it failed
+func (r *Result[T]) Val() T {
+	return r.val
+}
+
+// Err returns the command's error
+func (r *Result[T]) Err() error {
+	return r.err
+}
+
+// Pipeline queues cache operations and sends them to Redis in a single round
+// trip when executed. Each operation returns a Result filled in by Exec.
+// Commands are not atomic: use Transaction for that. A Pipeline is not safe
+// for concurrent use.
+type Pipeline struct {
+	client *Client
+	pipe   redis.Pipeliner
+
+	// resolve fills in the results once the commands have run
+	resolve []func()
+
+	// err is the first error met while queueing, such as a value that could
+	// not be encoded
+	err error
+}
+
+// Pipeline returns an empty pipeline of operations on c
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c, pipe: c.client.Pipeline()}
+}
+
+// Len returns the number of operations queued
+func (p *Pipeline) Len() int {
+	return len(p.resolve)
+}
+
+// Exec sends the queued operations and fills in their results. It returns
+// the first error met by an operation, misses aside; every Result still
+// carries its own outcome. The pipeline is empty afterwards and may be
+// reused.
+func (p *Pipeline) Exec(ctx context.Context) error {
+	resolve, err := p.resolve, p.err
+	p.resolve, p.err = nil, nil
+
+	if len(resolve) == 0 {
+		return err
+	}
+	cmds, execErr := p.pipe.Exec(ctx)
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); err == nil && cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+		}
+	}
+	if err == nil && execErr != nil && !errors.Is(execErr, redis.Nil) {
+		err = execErr
+	}
+	for _, fn := range resolve {
+		fn()
+	}
+	return err
+}
+
+// queue registers fn to fill in r once the pipeline has run
+func queue[T any](p *Pipeline, r *Result[T], fn func() (T, error)) *Result[T] {
+	r.err = ErrNotExecuted
+	p.resolve = append(p.resolve, func() {
+		r.val, r.err = fn()
+	})
+	return r
+}
+
+// failed returns a result holding err, which Exec also reports, for an
+// operation that could not be queued
+func failed[T any](p *Pipeline, err error) *Result[T] {
+	if p.err == nil {
+		p.err = err
+	}
+	return &Result[T]{err: err}
+}
+
+// Get queues the retrieval of an item. Its result is ErrKeyNotFound when the
+// key does not exist.
+func (p *Pipeline) Get(ctx context.Context, key string) *Result[string] {
+	cmd := p.pipe.Get(ctx, p.client.key(key))
+	return queue(p, &Result[string]{}, func() (string, error) {
+		value, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			p.client.stats.lookup(1, 0)
+			return "", ErrKeyNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		value, err = p.client.decodeValue(key, value)
+		if errors.Is(err, errCachedMiss) {
+			p.client.stats.lookup(1, 0)
+			return "", ErrKeyNotFound
+		}
+		p.client.stats.lookup(1, 1)
+		return value, err
+	})
+}
+
+// Has queues a check for the existence of an item
+func (p *Pipeline) Has(ctx context.Context, key string) *Result[bool] {
+	cmd := p.pipe.Exists(ctx, p.client.key(key))
+	return queue(p, &Result[bool]{}, func() (bool, error) {
+		exists, err := cmd.Result()
+		if err != nil {
+			return false, err
+		}
+		p.client.stats.lookup(1, int(exists))
+		return exists > 0, nil
+	})
+}
+
+// Put queues storing an item for a given duration
+func (p *Pipeline) Put(ctx context.Context, key, value string, ttl time.Duration) *Result[struct{}] {
+	encoded, err := p.client.encodeValue(key, value)
+	if err != nil {
+		return failed[struct{}](p, err)
+	}
+
+	cmd := p.pipe.Set(ctx, p.client.key(key), encoded, p.client.jitter(ttl))
+	return queue(p, &Result[struct{}]{}, func() (struct{}, error) {
+		return struct{}{}, cmd.Err()
+	})
+}
+
+// Add queues storing an item only if the key does not already exist. Its
+// result reports whether the item was stored.
+func (p *Pipeline) Add(ctx context.Context, key, value string, ttl time.Duration) *Result[bool] {
+	encoded, err := p.client.encodeValue(key, value)
+	if err != nil {
+		return failed[bool](p, err)
+	}
+
+	cmd := p.pipe.SetNX(ctx, p.client.key(key), encoded, p.client.jitter(ttl))
+	return queue(p, &Result[bool]{}, cmd.Result)
+}
+
+// Forever queues storing an item permanently
+func (p *Pipeline) Forever(ctx context.Context, key, value string) *Result[struct{}] {
+	return p.Put(ctx, key, value, 0)
+}
+
+// Forget queues the removal of an item. Unlike Client.Forget, items
+// registered as depending on it are left in place. Its result reports
+// whether the item existed.
+func (p *Pipeline) Forget(ctx context.Context, key string) *Result[bool] {
+	cmd := p.pipe.Del(ctx, p.client.key(key))
+	return queue(p, &Result[bool]{}, func() (bool, error) {
+		n, err := cmd.Result()
+		return n > 0, err
+	})
+}
+
+// Increment queues incrementing the integer stored at key by the given
+// amount. Its result is the new value.
+func (p *Pipeline) Increment(ctx context.Context, key string, by int64) *Result[int64] {
+	cmd := p.pipe.IncrBy(ctx, p.client.key(key), by)
+	return queue(p, &Result[int64]{}, cmd.Result)
+}
+
+// Decrement queues decrementing the integer stored at key by the given
+// amount. Its result is the new value.
+func (p *Pipeline) Decrement(ctx context.Context, key string, by int64) *Result[int64] {
+	cmd := p.pipe.DecrBy(ctx, p.client.key(key), by)
+	return queue(p, &Result[int64]{}, cmd.Result)
+}
@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Pipeline(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("per command results", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "existing", "value", time.Hour))
+		mr.Set("app:counter", "41")
+
+		pipe := client.Pipeline()
+		put := pipe.Put(ctx, "key", "value", time.Minute)
+		get := pipe.Get(ctx, "existing")
+		missing := pipe.Get(ctx, "missing")
+		has := pipe.Has(ctx, "existing")
+		added := pipe.Add(ctx, "existing", "other", time.Minute)
+		incr := pipe.Increment(ctx, "counter", 1)
+		decr := pipe.Decrement(ctx, "other-counter", 2)
+		forgot := pipe.Forget(ctx, "existing")
+		assert.Equal(t, 8, pipe.Len())
+
+		_, err := get.Result()
+		assert.Equal(t, ErrNotExecuted, err)
+
+		require.NoError(t, pipe.Exec(ctx))
+		assert.Zero(t, pipe.Len())
+
+		assert.NoError(t, put.Err())
+		assert.Equal(t, time.Minute, mr.TTL("app:key"))
+
+		value, err := get.Result()
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		_, err = missing.Result()
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		assert.True(t, has.Val())
+		assert.False(t, added.Val())
+		assert.Equal(t, int64(42), incr.Val())
+		assert.Equal(t, int64(-2), decr.Val())
+		assert.True(t, forgot.Val())
+		assert.False(t, mr.Exists("app:existing"))
+
+		stats := client.Stats()
+		assert.Equal(t, uint64(2), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+	})
+
+	t.Run("command errors", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		mr.Set("text", "not a number")
+
+		pipe := client.Pipeline()
+		incr := pipe.Increment(ctx, "text", 1)
+		forever := pipe.Forever(ctx, "key", "value")
+
+		err := pipe.Exec(ctx)
+		require.Error(t, err)
+		assert.Equal(t, err, incr.Err())
+		assert.NoError(t, forever.Err())
+		assert.True(t, mr.Exists("key"))
+	})
+
+	t.Run("values are encoded", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Compression: CompressionGzip, CompressionThreshold: 1})
+		defer mr.Close()
+
+		pipe := client.Pipeline()
+		pipe.Put(ctx, "key", "value", time.Minute)
+		require.NoError(t, pipe.Exec(ctx))
+
+		raw, err := mr.Get("key")
+		require.NoError(t, err)
+		assert.NotEqual(t, "value", raw)
+
+		value, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("empty pipeline", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		assert.NoError(t, client.Pipeline().Exec(ctx))
+	})
+
+	t.Run("cluster mode", func(t *testing.T) {
+		client, mr := setupTestCluster(t, Config{})
+		defer mr.Close()
+
+		pipe := client.Pipeline()
+		pipe.Put(ctx, "a", "1", time.Minute)
+		pipe.Put(ctx, "b", "2", time.Minute)
+		a := pipe.Get(ctx, "a")
+		require.NoError(t, pipe.Exec(ctx))
+		assert.Equal(t, "1", a.Val())
+	})
+}
@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Prefix(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("keys are namespaced", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+		_, err := client.Add(ctx, "added", "value", time.Hour)
+		require.NoError(t, err)
+		_, err = client.Increment(ctx, "counter", 1)
+		require.NoError(t, err)
+		require.NoError(t, client.PutMany(ctx, map[string]string{"many": "value"}, time.Hour))
+
+		assert.ElementsMatch(t, []string{"app:key", "app:forever", "app:added", "app:counter", "app:many"}, mr.Keys())
+
+		val, err := client.Get(ctx, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", val)
+
+		exists, err := client.Has(ctx, "forever")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		values, err := client.GetMany(ctx, "key", "many")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"key": "value", "many": "value"}, values)
+	})
+
+	t.Run("unprefixed keys are invisible", func(t *testing.T) {
+		require.NoError(t, mr.Set("raw", "value"))
+
+		_, err := client.Get(ctx, "raw")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("dependencies and tags stay under the prefix", func(t *testing.T) {
+		require.NoError(t, client.PutDependent(ctx, "child", "value", time.Hour, "key"))
+		require.NoError(t, client.Tags("users").Put(ctx, "user:1", "alice", time.Hour))
+
+		for _, key := range mr.Keys() {
+			if key != "raw" {
+				assert.Regexp(t, "^app:", key)
+			}
+		}
+
+		require.NoError(t, client.Forget(ctx, "key"))
+		assert.False(t, mr.Exists("app:child"))
+
+		require.NoError(t, client.Tags("users").Flush(ctx))
+		_, err := client.Tags("users").Get(ctx, "user:1")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("locks are namespaced", func(t *testing.T) {
+		release, acquired, err := client.LockAll(ctx, time.Minute, "job")
+		require.NoError(t, err)
+		require.True(t, acquired)
+		assert.True(t, mr.Exists("app:job"))
+
+		release()
+		assert.False(t, mr.Exists("app:job"))
+	})
+
+	t.Run("flush only removes prefixed keys", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+
+		require.NoError(t, client.Flush(ctx))
+
+		assert.Equal(t, []string{"raw"}, mr.Keys())
+	})
+}
+
+func TestClient_WithPrefix(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	users := client.WithPrefix("users:")
+	sessions := client.WithPrefix("sessions:")
+	nested := users.WithPrefix("admins:")
+
+	require.NoError(t, users.Put(ctx, "1", "alice", time.Hour))
+	require.NoError(t, sessions.Put(ctx, "1", "token", time.Hour))
+	require.NoError(t, nested.Put(ctx, "1", "root", time.Hour))
+	require.NoError(t, client.Put(ctx, "plain", "value", time.Hour))
+
+	assert.ElementsMatch(t, []string{"users:1", "sessions:1", "users:admins:1", "plain"}, mr.Keys())
+
+	val, err := users.Get(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", val)
+
+	val, err = sessions.Get(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "token", val)
+
+	// Flushing a scoped client leaves everything else alone, while its own
+	// nested scopes are part of its namespace
+	require.NoError(t, users.Flush(ctx))
+	assert.ElementsMatch(t, []string{"sessions:1", "plain"}, mr.Keys())
+}
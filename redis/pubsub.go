@@ -0,0 +1,115 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// subscriptionBuffer is the number of messages a subscription holds for a
+// slow reader
+const subscriptionBuffer = 100
+
+// Message is a message received on a subscribed channel. Channel and
+// Pattern are given without the client prefix; Pattern is only set for
+// pattern subscriptions.
+type Message struct {
+	Channel string
+	Pattern string
+	Payload string
+
+	codec cache.Codec
+}
+
+// Decode decodes the payload into v with the publishing client's codec
+func (m Message) Decode(v interface{}) error {
+	if err := m.codec.Unmarshal([]byte(m.Payload), v); err != nil {
+		return &DecodeError{Key: m.Channel, Err: err}
+	}
+	return nil
+}
+
+// Publish encodes payload with the configured codec and publishes it on
+// channel, namespaced by the client prefix
+func (c *Client) Publish(ctx context.Context, channel string, payload interface{}) error {
+	encoded, err := c.codec.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return c.client.Publish(ctx, c.key(channel), encoded).Err()
+}
+
+// Subscribe delivers the messages published on channels until ctx is
+// cancelled, when the returned channel is closed. The connection is
+// re-established and the channels subscribed to again should it drop;
+// messages published meanwhile are lost. Messages wait in a small buffer
+// for the reader and are dropped after a minute should it not keep up.
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (<-chan Message, error) {
+	if len(channels) == 0 {
+		return nil, ErrNoChannels
+	}
+	return c.subscribe(ctx, c.client.Subscribe(ctx, c.keys(channels)...))
+}
+
+// PSubscribe is like Subscribe for the channels matching the glob-style
+// patterns, matched against channel names without the client prefix
+func (c *Client) PSubscribe(ctx context.Context, patterns ...string) (<-chan Message, error) {
+	if len(patterns) == 0 {
+		return nil, ErrNoChannels
+	}
+
+	prefixed := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		prefixed[i] = escapePattern(c.prefix) + pattern
+	}
+	return c.subscribe(ctx, c.client.PSubscribe(ctx, prefixed...))
+}
+
+// subscribe waits for pubsub to be subscribed and forwards its messages
+// until ctx is cancelled
+func (c *Client) subscribe(ctx context.Context, pubsub *redis.PubSub) (<-chan Message, error) {
+	// Wait for the subscription so no message published after Subscribe
+	// returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	out := make(chan Message, subscriptionBuffer)
+	in := pubsub.Channel(redis.WithChannelSize(subscriptionBuffer))
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- c.message(msg):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// message converts a go-redis message, removing the client prefix
+func (c *Client) message(msg *redis.Message) Message {
+	return Message{
+		Channel: strings.TrimPrefix(msg.Channel, c.prefix),
+		Pattern: strings.TrimPrefix(msg.Pattern, escapePattern(c.prefix)),
+		Payload: msg.Payload,
+		codec:   c.codec,
+	}
+}
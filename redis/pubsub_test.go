@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive waits for the next message on messages
+func receive(t *testing.T, messages <-chan Message) Message {
+	t.Helper()
+	select {
+	case msg, ok := <-messages:
+		require.True(t, ok, "subscription closed")
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+		return Message{}
+	}
+}
+
+func TestClient_PublishSubscribe(t *testing.T) {
+	ctx := context.Background()
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+	defer client.Close()
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages, err := client.Subscribe(subCtx, "orders", "refunds")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Publish(ctx, "orders", map[string]int{"id": 42}))
+	msg := receive(t, messages)
+	assert.Equal(t, "orders", msg.Channel)
+	assert.Empty(t, msg.Pattern)
+	assert.Equal(t, `{"id":42}`, msg.Payload)
+
+	var order struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, msg.Decode(&order))
+	assert.Equal(t, 42, order.ID)
+
+	require.NoError(t, client.Publish(ctx, "refunds", "full"))
+	msg = receive(t, messages)
+	assert.Equal(t, "refunds", msg.Channel)
+	var refund string
+	require.NoError(t, msg.Decode(&refund))
+	assert.Equal(t, "full", refund)
+
+	// Channels are namespaced by the prefix
+	mr.Publish("orders", "unprefixed")
+	mr.Publish("app:orders", `"prefixed"`)
+	assert.Equal(t, `"prefixed"`, receive(t, messages).Payload)
+
+	var number int
+	assert.Error(t, msg.Decode(&number))
+
+	// Cancelling the context closes the subscription
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, ok := <-messages
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestClient_PSubscribe(t *testing.T) {
+	ctx := context.Background()
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app*"})
+	defer mr.Close()
+	defer client.Close()
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages, err := client.PSubscribe(subCtx, "orders.*")
+	require.NoError(t, err)
+
+	// The prefix is matched literally
+	mr.Publish("apple:orders.created", `"other"`)
+	require.NoError(t, client.Publish(ctx, "orders.created", 1))
+
+	msg := receive(t, messages)
+	assert.Equal(t, "orders.created", msg.Channel)
+	assert.Equal(t, "orders.*", msg.Pattern)
+	assert.Equal(t, "1", msg.Payload)
+}
+
+func TestClient_SubscribeNoChannels(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+	defer client.Close()
+
+	_, err := client.Subscribe(context.Background())
+	assert.ErrorIs(t, err, ErrNoChannels)
+	_, err = client.PSubscribe(context.Background())
+	assert.ErrorIs(t, err, ErrNoChannels)
+}
+
+func TestClient_SubscribeReconnects(t *testing.T) {
+	ctx := context.Background()
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+	defer client.Close()
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages, err := client.Subscribe(subCtx, "orders")
+	require.NoError(t, err)
+
+	mr.Close()
+	require.NoError(t, mr.Restart())
+
+	// Publish until the subscription is re-established
+	assert.Eventually(t, func() bool {
+		mr.Publish("orders", `"after restart"`)
+		select {
+		case msg := <-messages:
+			return msg.Payload == `"after restart"`
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestClient_SubscribeFails(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer client.Close()
+	mr.Close()
+
+	_, err := client.Subscribe(context.Background(), "orders")
+	assert.ErrorContains(t, err, "failed to subscribe")
+}
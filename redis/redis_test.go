@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,16 +20,21 @@ type testStruct struct {
 
 // setupTestRedis creates a mock Redis server for testing
 func setupTestRedis(t *testing.T) (*Client, *miniredis.Miniredis) {
+	return setupTestRedisWith(t, Config{})
+}
+
+// setupTestRedisWith creates a mock Redis server and a client built from cfg
+// pointed at it
+func setupTestRedisWith(t *testing.T, cfg Config) (*Client, *miniredis.Miniredis) {
 	mr, err := miniredis.Run()
 	require.NoError(t, err)
 
 	p, err := strconv.Atoi(mr.Port())
 	require.NoError(t, err)
 
-	client, err := New(Config{
-		Host: mr.Host(),
-		Port: p,
-	})
+	cfg.Host = mr.Host()
+	cfg.Port = p
+	client, err := New(cfg)
 	require.NoError(t, err)
 
 	return client, mr
@@ -106,6 +112,34 @@ func TestClient_Put(t *testing.T) {
 	})
 }
 
+func TestClient_Add(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("add missing key", func(t *testing.T) {
+		added, err := client.Add(ctx, "test-key", "first", time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, added)
+
+		val, err := client.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", val)
+		assert.True(t, mr.TTL("test-key") > 0)
+	})
+
+	t.Run("existing key is not overwritten", func(t *testing.T) {
+		added, err := client.Add(ctx, "test-key", "second", time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, added)
+
+		val, err := client.Get(ctx, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", val)
+	})
+}
+
 func TestClient_Get(t *testing.T) {
 	client, mr := setupTestRedis(t)
 	defer mr.Close()
@@ -143,6 +177,35 @@ func TestClient_Get(t *testing.T) {
 	})
 }
 
+func TestClient_GetOr(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("existing key", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+
+		value, err := client.GetOr(ctx, "key", "fallback")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		value, err := client.GetOr(ctx, "missing", "fallback")
+		assert.NoError(t, err)
+		assert.Equal(t, "fallback", value)
+	})
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		mr.SetError("server unavailable")
+		defer mr.SetError("")
+
+		_, err := client.GetOr(ctx, "key", "fallback")
+		assert.Error(t, err)
+	})
+}
+
 func TestClient_Has(t *testing.T) {
 	client, mr := setupTestRedis(t)
 	defer mr.Close()
@@ -202,6 +265,37 @@ func TestClient_Remember(t *testing.T) {
 	})
 }
 
+func TestClient_RememberForever(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("remember new value without TTL", func(t *testing.T) {
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return testStruct{Name: "test", Value: 123}, nil
+		}
+
+		val, err := client.RememberForever(ctx, "test-key", callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+		assert.JSONEq(t, `{"name":"test","value":123}`, val)
+		assert.Zero(t, mr.TTL("test-key"))
+
+		// Second call should use cached value
+		_, err = client.RememberForever(ctx, "test-key", callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := client.RememberForever(ctx, "nil-callback", nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
+
 func TestClient_Pull(t *testing.T) {
 	client, mr := setupTestRedis(t)
 	defer mr.Close()
@@ -304,3 +398,94 @@ func TestClient_Flush(t *testing.T) {
 		assert.False(t, exists3)
 	})
 }
+
+func TestNewFromClient(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("single node", func(t *testing.T) {
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 2})
+		client := NewFromClient(rdb)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, mr.DB(2).Exists("key"))
+
+		// The wrapped client keeps working alongside the facade
+		val, err := rdb.Get(ctx, "key").Result()
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+		client := NewFromClient(rdb)
+		defer client.Close()
+
+		require.NoError(t, client.PutMany(ctx, map[string]string{"a": "1", "b": "2"}, time.Hour))
+		values, err := client.GetMany(ctx, "a", "b")
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("ring", func(t *testing.T) {
+		mr, other := miniredis.RunT(t), miniredis.RunT(t)
+		rdb := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"a": mr.Addr(), "b": other.Addr()}})
+		client := NewFromClient(rdb)
+		defer client.Close()
+
+		keys := []string{"ring:1", "ring:2", "ring:3", "ring:4", "ring:5", "ring:6"}
+		for _, key := range keys {
+			require.NoError(t, client.Put(ctx, key, "value", time.Hour))
+		}
+		// The keys are spread over both shards
+		require.NotEmpty(t, mr.Keys())
+		require.NotEmpty(t, other.Keys())
+
+		found, err := client.Keys(ctx, "ring:*")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, keys, found)
+
+		n, err := client.ForgetPattern(ctx, "ring:*")
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(keys)), n)
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		require.NoError(t, client.Flush(ctx))
+		assert.Empty(t, mr.Keys())
+		assert.Empty(t, other.Keys())
+	})
+}
+
+func TestClient_LazyConnect(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	addr := mr.Addr()
+	mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("fails without lazy connect", func(t *testing.T) {
+		_, err := New(Config{URL: "redis://" + addr})
+		assert.Error(t, err)
+	})
+
+	t.Run("connects once the server is available", func(t *testing.T) {
+		client, err := New(Config{URL: "redis://" + addr, LazyConnect: true})
+		require.NoError(t, err)
+		defer client.Close()
+
+		assert.Error(t, client.Ping(ctx))
+
+		mr := miniredis.NewMiniRedis()
+		require.NoError(t, mr.StartAddr(addr))
+		defer mr.Close()
+
+		assert.NoError(t, client.Ping(ctx))
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, mr.Exists("key"))
+	})
+}
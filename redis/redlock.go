@@ -0,0 +1,181 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// redlockNodeTimeout bounds how long Redlock waits on a single node, so
+	// an unreachable node cannot eat up the lock's validity time
+	redlockNodeTimeout = 50 * time.Millisecond
+
+	// redlockDriftFactor accounts for clock drift between nodes as a
+	// fraction of the lock TTL
+	redlockDriftFactor = 0.01
+)
+
+// Redlock is a distributed lock held across a quorum of independent Redis
+// instances, following the Redlock algorithm. It stays safe as long as a
+// majority of the instances are available, unlike Lock which relies on a
+// single instance.
+type Redlock struct {
+	clients []*Client
+	name    string
+	ttl     time.Duration
+	owner   string
+}
+
+// NewRedlock returns a lock on name across the given independent instances
+// that expires after ttl once acquired
+func NewRedlock(clients []*Client, name string, ttl time.Duration) *Redlock {
+	return &Redlock{clients: clients, name: name, ttl: ttl}
+}
+
+// Owner returns the token identifying this handle as the lock holder. It is
+// empty until the first acquisition attempt.
+func (r *Redlock) Owner() string {
+	return r.owner
+}
+
+// quorum is the number of instances that must be locked to hold the lock
+func (r *Redlock) quorum() int {
+	return len(r.clients)/2 + 1
+}
+
+// eachNode runs fn against every instance in parallel with a per-node
+// timeout, returning the number of instances for which fn reported success
+// and the errors encountered
+func (r *Redlock) eachNode(ctx context.Context, fn func(ctx context.Context, c *Client) (bool, error)) (int, []error) {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		succeeded int
+		errs      []error
+	)
+
+	for _, c := range r.clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+
+			nodeCtx, cancel := context.WithTimeout(ctx, redlockNodeTimeout)
+			defer cancel()
+
+			ok, err := fn(nodeCtx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if ok {
+				succeeded++
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return succeeded, errs
+}
+
+// Acquire tries to take the lock on a quorum of instances without waiting.
+// The lock only counts as acquired if the quorum was reached with time to
+// spare before the TTL runs out; otherwise every instance is unlocked again.
+// Unreachable instances count as failed attempts, and an error is only
+// returned when every instance failed.
+func (r *Redlock) Acquire(ctx context.Context) (bool, error) {
+	if len(r.clients) == 0 {
+		return false, errors.New("redlock requires at least one instance")
+	}
+	if r.owner == "" {
+		owner, err := newLockOwner()
+		if err != nil {
+			return false, err
+		}
+		r.owner = owner
+	}
+
+	start := time.Now()
+	locked, errs := r.eachNode(ctx, func(ctx context.Context, c *Client) (bool, error) {
+		return c.acquireLock(ctx, c.key(r.name), r.owner, r.ttl)
+	})
+
+	drift := time.Duration(float64(r.ttl)*redlockDriftFactor) + 2*time.Millisecond
+	validity := r.ttl - time.Since(start) - drift
+	if locked >= r.quorum() && validity > 0 {
+		return true, nil
+	}
+
+	r.releaseAll(context.WithoutCancel(ctx))
+	if len(errs) == len(r.clients) {
+		return false, errors.Join(errs...)
+	}
+	return false, nil
+}
+
+// Block waits up to timeout for the lock to become available and acquires
+// it, returning ErrLockTimeout if it could not be acquired in time
+func (r *Redlock) Block(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := r.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return ErrLockTimeout
+		}
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Get acquires the lock without waiting and, if successful, runs fn before
+// releasing it. It reports whether the lock was acquired; fn's error is
+// returned as is.
+func (r *Redlock) Get(ctx context.Context, fn func() error) (bool, error) {
+	acquired, err := r.Acquire(ctx)
+	if err != nil || !acquired {
+		return false, err
+	}
+	defer r.Release(context.WithoutCancel(ctx))
+
+	return true, fn()
+}
+
+// Release frees the lock on every instance where it is still held by this
+// owner. Like Acquire, it only returns an error when every instance failed;
+// locks left on unreachable instances expire with their TTL.
+func (r *Redlock) Release(ctx context.Context) error {
+	if r.owner == "" {
+		return nil
+	}
+
+	_, errs := r.eachNode(ctx, func(ctx context.Context, c *Client) (bool, error) {
+		return c.releaseLock(ctx, c.key(r.name), r.owner)
+	})
+	if len(errs) == len(r.clients) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// releaseAll unlocks every instance, ignoring failures
+func (r *Redlock) releaseAll(ctx context.Context) {
+	_ = r.Release(ctx)
+}
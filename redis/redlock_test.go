@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestRedlock creates n independent mock Redis servers with a client for
+// each
+func setupTestRedlock(t *testing.T, n int) ([]*Client, []*miniredis.Miniredis) {
+	clients := make([]*Client, n)
+	servers := make([]*miniredis.Miniredis, n)
+	for i := range clients {
+		clients[i], servers[i] = setupTestRedis(t)
+		t.Cleanup(servers[i].Close)
+	}
+	return clients, servers
+}
+
+func TestRedlock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("acquires on every instance", func(t *testing.T) {
+		clients, servers := setupTestRedlock(t, 3)
+
+		lock := NewRedlock(clients, "job", time.Minute)
+		acquired, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		for _, mr := range servers {
+			val, err := mr.Get("job")
+			assert.NoError(t, err)
+			assert.Equal(t, lock.Owner(), val)
+		}
+
+		require.NoError(t, lock.Release(ctx))
+		for _, mr := range servers {
+			assert.False(t, mr.Exists("job"))
+		}
+	})
+
+	t.Run("tolerates a minority of failed instances", func(t *testing.T) {
+		clients, servers := setupTestRedlock(t, 3)
+		servers[0].Close()
+
+		lock := NewRedlock(clients, "job", time.Minute)
+		acquired, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+
+		require.NoError(t, lock.Release(ctx))
+		assert.False(t, servers[1].Exists("job"))
+	})
+
+	t.Run("fails without a quorum and rolls back", func(t *testing.T) {
+		clients, servers := setupTestRedlock(t, 3)
+		// Another owner holds the lock on two of the three instances
+		require.NoError(t, servers[0].Set("job", "someone-else"))
+		require.NoError(t, servers[1].Set("job", "someone-else"))
+
+		lock := NewRedlock(clients, "job", time.Minute)
+		acquired, err := lock.Acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, acquired)
+
+		// The instance that was locked is released again, others untouched
+		assert.False(t, servers[2].Exists("job"))
+		val, _ := servers[0].Get("job")
+		assert.Equal(t, "someone-else", val)
+	})
+
+	t.Run("errors when every instance is down", func(t *testing.T) {
+		clients, servers := setupTestRedlock(t, 3)
+		for _, mr := range servers {
+			mr.Close()
+		}
+
+		acquired, err := NewRedlock(clients, "job", time.Minute).Acquire(ctx)
+		assert.Error(t, err)
+		assert.False(t, acquired)
+	})
+
+	t.Run("no instances", func(t *testing.T) {
+		_, err := NewRedlock(nil, "job", time.Minute).Acquire(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("block times out while held", func(t *testing.T) {
+		clients, _ := setupTestRedlock(t, 3)
+
+		holder := NewRedlock(clients, "job", time.Minute)
+		acquired, err := holder.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		err = NewRedlock(clients, "job", time.Minute).Block(ctx, 150*time.Millisecond)
+		assert.Equal(t, ErrLockTimeout, err)
+	})
+
+	t.Run("mutual exclusion", func(t *testing.T) {
+		clients, _ := setupTestRedlock(t, 3)
+
+		var inside, overlaps, runs int32
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lock := NewRedlock(clients, "job", time.Minute)
+				if err := lock.Block(ctx, 5*time.Second); err != nil {
+					return
+				}
+				if atomic.AddInt32(&inside, 1) != 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&inside, -1)
+				atomic.AddInt32(&runs, 1)
+				_ = lock.Release(ctx)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(0), overlaps)
+		assert.Equal(t, int32(5), runs)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		clients, servers := setupTestRedlock(t, 3)
+
+		ran := false
+		acquired, err := NewRedlock(clients, "job", time.Minute).Get(ctx, func() error {
+			ran = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, acquired)
+		assert.True(t, ran)
+		assert.False(t, servers[0].Exists("job"))
+	})
+}
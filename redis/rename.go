@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renameScript renames a key, replying 0 rather than an error when the
+// source is missing
+var renameScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('RENAME', KEYS[1], KEYS[2])
+return 1
+`)
+
+// copyScript copies a key over any existing destination, then sets the
+// copy's TTL when one is given
+var copyScript = redis.NewScript(`
+if redis.call('COPY', KEYS[1], KEYS[2], 'REPLACE') == 0 then
+	return 0
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// Rename atomically moves an item to a new key, replacing any item already
+// stored there and keeping its remaining TTL. Staging a recomputed value
+// under a temporary key and renaming it into place swaps it in without
+// readers ever seeing a miss. In cluster mode both keys must hash to the
+// same slot, for instance by sharing a {hash tag}.
+func (c *Client) Rename(ctx context.Context, oldKey, newKey string) error {
+	if c.encryption != nil {
+		return c.rekey(ctx, oldKey, newKey, 0, true)
+	}
+
+	renamed, err := renameScript.Run(ctx, c.client, []string{c.key(oldKey), c.key(newKey)}).Int64()
+	if err != nil {
+		return err
+	}
+	if renamed == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Copy atomically copies an item to dst, replacing any item already stored
+// there. The copy expires after ttl, or keeps the source's remaining TTL
+// when ttl is zero. It requires Redis 6.2 or later, and in cluster mode both
+// keys must hash to the same slot.
+func (c *Client) Copy(ctx context.Context, src, dst string, ttl time.Duration) error {
+	if ttl < 0 {
+		return ErrInvalidTTL
+	}
+	if c.encryption != nil {
+		return c.rekey(ctx, src, dst, c.jitter(ttl), false)
+	}
+
+	copied, err := copyScript.Run(ctx, c.client, []string{c.key(src), c.key(dst)}, c.jitter(ttl).Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if copied == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// rekey copies an encrypted item from src to dst, re-encrypting it for its
+// new key since values are bound to the key they are stored under, and
+// removes src when move is set. The copy expires after ttl, or keeps the
+// source's remaining TTL when ttl is zero. A concurrent write to src makes
+// it fail with redis.TxFailedErr.
+func (c *Client) rekey(ctx context.Context, src, dst string, ttl time.Duration, move bool) error {
+	from, to := c.key(src), c.key(dst)
+	return c.client.Watch(ctx, func(tx *redis.Tx) error {
+		value, err := tx.Get(ctx, from).Result()
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if ttl == 0 {
+			remaining, err := tx.PTTL(ctx, from).Result()
+			if err != nil {
+				return err
+			}
+			if remaining > 0 {
+				ttl = remaining
+			}
+		}
+
+		value, err = c.rekeyValue(value, src, dst)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, to, value, ttl)
+			if move {
+				pipe.Del(ctx, from)
+			}
+			return nil
+		})
+		return err
+	}, from)
+}
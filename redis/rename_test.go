@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Rename(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("swaps in a staged value", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "report", "old", time.Hour))
+		require.NoError(t, client.Put(ctx, "report:staging", "new", time.Minute))
+
+		require.NoError(t, client.Rename(ctx, "report:staging", "report"))
+
+		value, err := client.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, "new", value)
+		assert.Equal(t, time.Minute, mr.TTL("app:report"))
+		assert.False(t, mr.Exists("app:report:staging"))
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		err := client.Rename(ctx, "missing", "report")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		value, err := client.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, "new", value)
+	})
+}
+
+func TestClient_Copy(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("copy with a new ttl", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "src", "value", time.Minute))
+		require.NoError(t, client.Put(ctx, "dst", "old", time.Minute))
+
+		require.NoError(t, client.Copy(ctx, "src", "dst", time.Hour))
+
+		value, err := client.Get(ctx, "dst")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+		assert.Equal(t, time.Hour, mr.TTL("app:dst"))
+		assert.Equal(t, time.Minute, mr.TTL("app:src"))
+	})
+
+	t.Run("zero ttl keeps the source's expiry", func(t *testing.T) {
+		require.NoError(t, client.Copy(ctx, "src", "kept", 0))
+		assert.Equal(t, time.Minute, mr.TTL("app:kept"))
+
+		require.NoError(t, client.Forever(ctx, "forever", "value"))
+		require.NoError(t, client.Copy(ctx, "forever", "forever:copy", 0))
+		assert.Zero(t, mr.TTL("app:forever:copy"))
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		err := client.Copy(ctx, "missing", "dst", time.Hour)
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("negative ttl", func(t *testing.T) {
+		err := client.Copy(ctx, "src", "dst", -time.Second)
+		assert.Equal(t, ErrInvalidTTL, err)
+	})
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplicaRouting selects how reads are spread over read replicas
+type ReplicaRouting int
+
+const (
+	// RoundRobin sends each read to the next replica in turn
+	RoundRobin ReplicaRouting = iota
+
+	// LowestLatency sends reads to the replica that answered the most
+	// recent health probe the fastest
+	LowestLatency
+)
+
+// replicaProbeInterval is how often replica latencies are measured for
+// LowestLatency routing
+const replicaProbeInterval = time.Second
+
+// replicaSet holds the read replicas of a client and picks one per read
+type replicaSet struct {
+	clients []*redis.Client
+	routing ReplicaRouting
+
+	next      uint64
+	latencies []int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newReplicaSet connects to the replicas at addrs using the same options as
+// primary, adding hooks to each. With LowestLatency routing a background probe
+// keeps the measured latencies up to date until the set is closed.
+func newReplicaSet(primary *redis.Client, addrs []string, routing ReplicaRouting, hooks ...redis.Hook) *replicaSet {
+	r := &replicaSet{
+		clients:   make([]*redis.Client, len(addrs)),
+		routing:   routing,
+		latencies: make([]int64, len(addrs)),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for i, addr := range addrs {
+		opts := *primary.Options()
+		opts.Network = "tcp"
+		opts.Addr = addr
+		r.clients[i] = redis.NewClient(&opts)
+		for _, hook := range hooks {
+			r.clients[i].AddHook(hook)
+		}
+	}
+
+	if routing == LowestLatency {
+		go r.run()
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// pick returns the replica the next read should be sent to
+func (r *replicaSet) pick() *redis.Client {
+	if r.routing != LowestLatency {
+		i := atomic.AddUint64(&r.next, 1) - 1
+		return r.clients[i%uint64(len(r.clients))]
+	}
+
+	best := 0
+	for i := range r.latencies {
+		if atomic.LoadInt64(&r.latencies[i]) < atomic.LoadInt64(&r.latencies[best]) {
+			best = i
+		}
+	}
+	return r.clients[best]
+}
+
+// run probes the replicas periodically until the set is closed
+func (r *replicaSet) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(replicaProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		r.probe()
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe pings every replica and records how long it took. Replicas that fail
+// to answer are ranked last.
+func (r *replicaSet) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaProbeInterval)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, client := range r.clients {
+		wg.Add(1)
+		go func(i int, client *redis.Client) {
+			defer wg.Done()
+
+			start := time.Now()
+			latency := int64(math.MaxInt64)
+			if client.Ping(ctx).Err() == nil {
+				latency = int64(time.Since(start))
+			}
+			atomic.StoreInt64(&r.latencies[i], latency)
+		}(i, client)
+	}
+	wg.Wait()
+}
+
+// close stops the latency probe and closes every replica connection
+func (r *replicaSet) close() error {
+	var errs []error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+		for _, client := range r.clients {
+			if err := client.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// read runs fn against a read replica when any are configured, falling back
+// to the primary if the replica cannot serve it. Without replicas fn runs
+// against the primary.
+func (c *Client) read(fn func(cmd redis.Cmdable) error) error {
+	if c.replicas == nil {
+		return fn(c.client)
+	}
+
+	err := fn(c.replicas.pick())
+	if err == nil || errors.Is(err, redis.Nil) {
+		return err
+	}
+	return fn(c.client)
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestReplicas creates a primary and n replica mock servers and a client
+// routing reads to the replicas
+func setupTestReplicas(t *testing.T, n int, routing ReplicaRouting) (*Client, *miniredis.Miniredis, []*miniredis.Miniredis) {
+	replicas := make([]*miniredis.Miniredis, n)
+	addrs := make([]string, n)
+	for i := range replicas {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		t.Cleanup(mr.Close)
+		replicas[i], addrs[i] = mr, mr.Addr()
+	}
+
+	client, primary := setupTestRedisWith(t, Config{ReadReplicas: addrs, ReplicaRouting: routing})
+	t.Cleanup(primary.Close)
+	t.Cleanup(func() { client.Close() })
+
+	return client, primary, replicas
+}
+
+func TestClient_ReadReplicas(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes go to the primary", func(t *testing.T) {
+		client, primary, replicas := setupTestReplicas(t, 2, RoundRobin)
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, primary.Exists("key"))
+		for _, mr := range replicas {
+			assert.False(t, mr.Exists("key"))
+		}
+	})
+
+	t.Run("reads are spread round robin", func(t *testing.T) {
+		client, _, replicas := setupTestReplicas(t, 2, RoundRobin)
+		require.NoError(t, replicas[0].Set("key", "first"))
+		require.NoError(t, replicas[1].Set("key", "second"))
+
+		seen := make(map[string]int)
+		for i := 0; i < 4; i++ {
+			val, err := client.Get(ctx, "key")
+			require.NoError(t, err)
+			seen[val]++
+		}
+		assert.Equal(t, map[string]int{"first": 2, "second": 2}, seen)
+	})
+
+	t.Run("has and get many use replicas", func(t *testing.T) {
+		client, _, replicas := setupTestReplicas(t, 1, RoundRobin)
+		require.NoError(t, replicas[0].Set("a", "1"))
+
+		exists, err := client.Has(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		values, err := client.GetMany(ctx, "a", "b")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "1"}, values)
+	})
+
+	t.Run("misses on a replica are not retried on the primary", func(t *testing.T) {
+		client, primary, _ := setupTestReplicas(t, 1, RoundRobin)
+		require.NoError(t, primary.Set("key", "not yet replicated"))
+
+		_, err := client.Get(ctx, "key")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("failed replicas fall back to the primary", func(t *testing.T) {
+		client, primary, replicas := setupTestReplicas(t, 1, RoundRobin)
+		require.NoError(t, primary.Set("key", "primary"))
+		replicas[0].Close()
+
+		val, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "primary", val)
+	})
+
+	t.Run("lowest latency", func(t *testing.T) {
+		client, _, replicas := setupTestReplicas(t, 2, LowestLatency)
+		require.NoError(t, replicas[0].Set("key", "slow"))
+		require.NoError(t, replicas[1].Set("key", "fast"))
+
+		// Wait for the initial probe, then pin the measured latencies
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt64(&client.replicas.latencies[0]) > 0 &&
+				atomic.LoadInt64(&client.replicas.latencies[1]) > 0
+		}, time.Second, 10*time.Millisecond)
+		atomic.StoreInt64(&client.replicas.latencies[0], int64(time.Second))
+		atomic.StoreInt64(&client.replicas.latencies[1], int64(time.Millisecond))
+
+		val, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "fast", val)
+	})
+
+	t.Run("unreachable replicas are ranked last", func(t *testing.T) {
+		client, _, replicas := setupTestReplicas(t, 2, LowestLatency)
+		replicas[0].Close()
+
+		client.replicas.probe()
+		assert.Equal(t, int64(math.MaxInt64), atomic.LoadInt64(&client.replicas.latencies[0]))
+		assert.Same(t, client.replicas.clients[1], client.replicas.pick())
+	})
+
+	t.Run("rejected with cluster mode", func(t *testing.T) {
+		_, err := New(Config{Cluster: true, ReadReplicas: []string{"localhost:6380"}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultRetryInitialBackoff is the wait before the first retry when the
+	// policy does not set one
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+
+	// defaultRetryMaxBackoff caps the wait between retries when the policy
+	// does not set a cap
+	defaultRetryMaxBackoff = 2 * time.Second
+)
+
+// RetryPolicy retries operations that fail with a transient network error,
+// such as a dropped connection or a server that is still loading its
+// dataset. The wait between attempts starts at InitialBackoff and doubles up
+// to MaxBackoff, each wait being randomised between half and all of its
+// value. Retrying stops after MaxAttempts attempts, once MaxElapsedTime has
+// passed since the first one, or when the context is done. The zero value
+// disables retries.
+//
+// Commands that reached the server before the connection dropped may be
+// applied twice, so non-idempotent operations such as Increment can over
+// count when retried.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxElapsedTime time.Duration
+}
+
+// enabled reports whether the policy allows more than one attempt
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// backoff returns the randomised wait before retry number attempt, counting
+// from zero
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = defaultRetryMaxBackoff
+	}
+
+	wait := initial
+	for i := 0; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// transient, or the policy gives up, returning fn's last error
+func (p RetryPolicy) retry(ctx context.Context, fn func() error) error {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt+1 >= p.MaxAttempts || !isTransient(err) {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		if p.MaxElapsedTime > 0 && time.Since(start)+wait > p.MaxElapsedTime {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// transientPrefixes are the Redis error replies that clear up on their own
+var transientPrefixes = []string{"LOADING ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN ", "READONLY "}
+
+// isTransient reports whether err is a network or server condition worth
+// retrying. Cache misses, context cancellation and command errors are not.
+func isTransient(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, redis.Nil), errors.Is(err, redis.ErrClosed):
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE):
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, prefix := range transientPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryHook retries every command and pipeline sent through the client
+// according to policy. Replicas go without it, as failed reads already fall
+// back to the primary.
+type retryHook struct {
+	policy RetryPolicy
+}
+
+func (h retryHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h retryHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.policy.retry(ctx, func() error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h retryHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.policy.retry(ctx, func() error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+// maxRetries returns the go-redis MaxRetries setting for cfg. The built in
+// retries are disabled when a RetryPolicy is configured, unless MaxRetries
+// is set explicitly, so the two do not multiply.
+func (cfg Config) maxRetries() int {
+	if cfg.MaxRetries == 0 && cfg.Retry.enabled() {
+		return -1
+	}
+	return cfg.MaxRetries
+}
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		calls := 0
+		err := policy.retry(ctx, func() error {
+			calls++
+			if calls < 3 {
+				return io.EOF
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := policy.retry(ctx, func() error {
+			calls++
+			return syscall.ECONNRESET
+		})
+		assert.ErrorIs(t, err, syscall.ECONNRESET)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		err := policy.retry(ctx, func() error {
+			calls++
+			return redis.Nil
+		})
+		assert.ErrorIs(t, err, redis.Nil)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("max elapsed time", func(t *testing.T) {
+		bounded := RetryPolicy{MaxAttempts: 100, InitialBackoff: 20 * time.Millisecond, MaxElapsedTime: 50 * time.Millisecond}
+		calls := 0
+		start := time.Now()
+		err := bounded.retry(ctx, func() error {
+			calls++
+			return io.EOF
+		})
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Less(t, calls, 100)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		slow := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		start := time.Now()
+		err := slow.retry(ctx, func() error {
+			calls++
+			return io.EOF
+		})
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		assert.InDelta(t, 750*time.Microsecond, policy.backoff(0), float64(250*time.Microsecond))
+		assert.InDelta(t, 3*time.Millisecond, policy.backoff(2), float64(time.Millisecond))
+		assert.InDelta(t, 3*time.Millisecond, policy.backoff(10), float64(time.Millisecond))
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{io.EOF, true},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{errors.New("LOADING Redis is loading the dataset in memory"), true},
+		{errors.New("CLUSTERDOWN The cluster is down"), true},
+		{redis.Nil, false},
+		{context.Canceled, false},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.transient, isTransient(tt.err), tt.err.Error())
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := newClientFor(t, mr, Config{
+		Retry: RetryPolicy{MaxAttempts: 10, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond},
+	})
+
+	ctx := context.Background()
+
+	// Drop the server and bring it back while the client is retrying
+	mr.Close()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mr.Restart()
+	}()
+
+	require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+	assert.True(t, mr.Exists("key"))
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Iterator walks the keys matching a pattern with SCAN, one page at a time.
+// Keys are reported without the client prefix. As with SCAN, a key may be
+// reported more than once, and keys added or removed during the iteration
+// may or may not be seen.
+type Iterator struct {
+	client  *Client
+	pattern string
+
+	shards []*redis.Client
+	shard  int
+	scan   *redis.ScanIterator
+
+	key string
+	err error
+}
+
+// Scan returns an iterator over the keys matching pattern, relative to the
+// client prefix, using Redis glob syntax. It never uses KEYS, so the server
+// is not blocked however large the keyspace. In cluster mode every master is
+// scanned in turn.
+func (c *Client) Scan(ctx context.Context, pattern string) *Iterator {
+	it := &Iterator{client: c, pattern: escapePattern(c.prefix) + pattern}
+
+	var mu sync.Mutex
+	it.err = c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		it.shards = append(it.shards, shard)
+		return nil
+	})
+	return it
+}
+
+// Next advances to the next key, returning false when the iteration is over
+// or failed
+func (it *Iterator) Next(ctx context.Context) bool {
+	for it.err == nil {
+		if it.scan == nil {
+			if it.shard >= len(it.shards) {
+				return false
+			}
+			it.scan = it.shards[it.shard].Scan(ctx, 0, it.pattern, it.client.scanPageSize).Iterator()
+		}
+
+		if it.scan.Next(ctx) {
+			it.key = strings.TrimPrefix(it.scan.Val(), it.client.prefix)
+			return true
+		}
+		if err := it.scan.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		it.scan = nil
+		it.shard++
+	}
+	return false
+}
+
+// Key returns the key the iterator is positioned at
+func (it *Iterator) Key() string {
+	return it.key
+}
+
+// Err returns the error that ended the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Keys returns every key matching pattern, relative to the client prefix,
+// without duplicates. Prefer Scan for large keyspaces.
+func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
+	seen := make(map[string]bool)
+	keys := []string{}
+
+	it := c.Scan(ctx, pattern)
+	for it.Next(ctx) {
+		if !seen[it.Key()] {
+			seen[it.Key()] = true
+			keys = append(keys, it.Key())
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Scan(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 250; i++ {
+		require.NoError(t, client.Put(ctx, fmt.Sprintf("user:%d", i), "value", time.Hour))
+	}
+	require.NoError(t, client.Put(ctx, "session:1", "value", time.Hour))
+	mr.Set("other:user:1", "value")
+
+	t.Run("iterates matching keys", func(t *testing.T) {
+		seen := map[string]bool{}
+		it := client.Scan(ctx, "user:*")
+		for it.Next(ctx) {
+			seen[it.Key()] = true
+		}
+		require.NoError(t, it.Err())
+
+		assert.Len(t, seen, 250)
+		assert.True(t, seen["user:0"])
+		assert.True(t, seen["user:249"])
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		keys, err := client.Keys(ctx, "session:*")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"session:1"}, keys)
+
+		keys, err = client.Keys(ctx, "*")
+		require.NoError(t, err)
+		assert.Len(t, keys, 251)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		keys, err := client.Keys(ctx, "missing:*")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("prefix is matched literally", func(t *testing.T) {
+		globbed := client.WithPrefix("[a]:")
+		require.NoError(t, globbed.Put(ctx, "key", "value", time.Hour))
+		mr.Set("app:a:key", "value")
+
+		keys, err := globbed.Keys(ctx, "*")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key"}, keys)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mr.SetError("server unavailable")
+		defer mr.SetError("")
+
+		_, err := client.Keys(ctx, "*")
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_ScanCluster(t *testing.T) {
+	client, _ := setupTestCluster(t, Config{})
+	defer client.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		require.NoError(t, client.Put(ctx, fmt.Sprintf("key:%d", i), "value", time.Hour))
+	}
+
+	keys, err := client.Keys(ctx, "key:*")
+	require.NoError(t, err)
+	assert.Len(t, keys, 20)
+}
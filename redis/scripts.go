@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptRegistry holds the named scripts of a client and the clients scoped
+// from it with WithPrefix
+type scriptRegistry struct {
+	mu      sync.RWMutex
+	scripts map[string]*redis.Script
+}
+
+// newScriptRegistry returns a registry holding the given sources by name
+func newScriptRegistry(sources map[string]string) *scriptRegistry {
+	r := &scriptRegistry{scripts: make(map[string]*redis.Script, len(sources))}
+	for name, src := range sources {
+		r.scripts[name] = redis.NewScript(src)
+	}
+	return r
+}
+
+// get returns the script registered under name
+func (r *scriptRegistry) get(name string) (*redis.Script, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	script, ok := r.scripts[name]
+	return script, ok
+}
+
+// all returns the registered scripts by name
+func (r *scriptRegistry) all() map[string]*redis.Script {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scripts := make(map[string]*redis.Script, len(r.scripts))
+	for name, script := range r.scripts {
+		scripts[name] = script
+	}
+	return scripts
+}
+
+// RegisterScript registers a Lua script under name, replacing any script
+// registered under it before, and loads it into Redis with SCRIPT LOAD so
+// syntax errors surface at startup rather than on first use
+func (c *Client) RegisterScript(ctx context.Context, name, src string) error {
+	script := redis.NewScript(src)
+	if err := c.loadScript(ctx, name, script); err != nil {
+		return err
+	}
+
+	c.scripts.mu.Lock()
+	c.scripts.scripts[name] = script
+	c.scripts.mu.Unlock()
+	return nil
+}
+
+// LoadScripts loads every registered script into Redis, for instance after
+// a SCRIPT FLUSH. RunScript falls back to sending the source when a script
+// is missing from the server, so this is an optimisation rather than a
+// requirement.
+func (c *Client) LoadScripts(ctx context.Context) error {
+	for name, script := range c.scripts.all() {
+		if err := c.loadScript(ctx, name, script); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadScript loads script into every shard
+func (c *Client) loadScript(ctx context.Context, name string, script *redis.Script) error {
+	err := c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		return script.Load(ctx, shard).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load script %q: %w", name, err)
+	}
+	return nil
+}
+
+// RunScript runs the script registered under name with EVALSHA, resending
+// its source with EVAL when Redis reports NOSCRIPT. The keys are namespaced
+// under the client prefix; args are passed as they are. A nil reply is
+// returned as a nil value.
+func (c *Client) RunScript(ctx context.Context, name string, keys []string, args ...interface{}) (interface{}, error) {
+	script, ok := c.scripts.get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrScriptNotFound, name)
+	}
+
+	result, err := script.Run(ctx, c.client, c.keys(keys), args...).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return result, err
+}
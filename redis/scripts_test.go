@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const getOrZeroScript = `
+local value = redis.call('GET', KEYS[1])
+if not value then
+	return 0
+end
+return tonumber(value) * tonumber(ARGV[1])
+`
+
+func TestClient_RunScript(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("registered script", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+
+		require.NoError(t, client.RegisterScript(ctx, "multiply", getOrZeroScript))
+		require.NoError(t, client.Put(ctx, "count", "21", time.Hour))
+
+		result, err := client.RunScript(ctx, "multiply", []string{"count"}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), result)
+
+		result, err = client.RunScript(ctx, "multiply", []string{"missing"}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result)
+	})
+
+	t.Run("scripts from the config are loaded by New", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client := newClientFor(t, mr, Config{Scripts: map[string]string{"multiply": getOrZeroScript}})
+
+		require.NoError(t, client.Put(ctx, "count", "2", time.Hour))
+		result, err := client.RunScript(ctx, "multiply", []string{"count"}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), result)
+	})
+
+	t.Run("invalid scripts fail to load", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		err := client.RegisterScript(ctx, "broken", "return (")
+		assert.ErrorContains(t, err, `failed to load script "broken"`)
+
+		_, err = client.RunScript(ctx, "broken", nil)
+		assert.ErrorIs(t, err, ErrScriptNotFound)
+
+		p, err := strconv.Atoi(mr.Port())
+		require.NoError(t, err)
+		_, err = New(Config{Host: mr.Host(), Port: p, Scripts: map[string]string{"broken": "return ("}})
+		assert.ErrorContains(t, err, `failed to load script "broken"`)
+	})
+
+	t.Run("falls back to EVAL after a script flush", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.RegisterScript(ctx, "multiply", getOrZeroScript))
+		require.NoError(t, client.client.ScriptFlush(ctx).Err())
+
+		result, err := client.RunScript(ctx, "multiply", []string{"missing"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result)
+
+		require.NoError(t, client.client.ScriptFlush(ctx).Err())
+		require.NoError(t, client.LoadScripts(ctx))
+		script, ok := client.scripts.get("multiply")
+		require.True(t, ok)
+		exists, err := client.client.ScriptExists(ctx, script.Hash()).Result()
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true}, exists)
+	})
+
+	t.Run("nil replies", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.RegisterScript(ctx, "nothing", "return nil"))
+		result, err := client.RunScript(ctx, "nothing", nil)
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("scripts are shared with prefixed clients", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.RegisterScript(ctx, "multiply", getOrZeroScript))
+		scoped := client.WithPrefix("tenant:")
+		mr.Set("tenant:count", "5")
+
+		result, err := scoped.RunScript(ctx, "multiply", []string{"count"}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), result)
+	})
+}
@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireSlotScript drops expired slots from a semaphore and takes one for
+// the given owner if fewer than the limit are held. Slots are scored by
+// their expiry on the server clock, so holders never need in-sync clocks.
+var acquireSlotScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[2])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// extendSlotScript resets the expiry of a slot still held by the given owner
+var extendSlotScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local expiry = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expiry or tonumber(expiry) <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// Semaphore caps how many holders across every process may run a task at
+// once. Each holder takes a slot that expires after the semaphore's TTL, so
+// slots held by crashed workers are freed automatically.
+type Semaphore struct {
+	client *Client
+	name   string
+	limit  int
+	ttl    time.Duration
+}
+
+// Permit is a slot held in a Semaphore
+type Permit struct {
+	semaphore *Semaphore
+	owner     string
+}
+
+// Semaphore returns a semaphore on name admitting up to limit holders at
+// once, each slot expiring ttl after it was acquired or last extended.
+// Acquiring returns ErrInvalidLimit if limit is not positive and
+// ErrInvalidTTL if ttl is under a millisecond.
+func (c *Client) Semaphore(name string, limit int, ttl time.Duration) *Semaphore {
+	return &Semaphore{client: c, name: name, limit: limit, ttl: ttl}
+}
+
+// Acquire tries to take a slot without waiting, reporting whether it did
+func (s *Semaphore) Acquire(ctx context.Context) (*Permit, bool, error) {
+	if s.limit <= 0 {
+		return nil, false, ErrInvalidLimit
+	}
+	if s.ttl < time.Millisecond {
+		return nil, false, ErrInvalidTTL
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, false, err
+	}
+
+	acquired, err := acquireSlotScript.Run(ctx, s.client.client, []string{s.client.key(s.name)}, s.limit, owner, s.ttl.Milliseconds()).Int64()
+	if err != nil || acquired == 0 {
+		return nil, false, err
+	}
+	return &Permit{semaphore: s, owner: owner}, true, nil
+}
+
+// Block waits up to timeout for a slot to become available and takes it,
+// returning ErrLockTimeout if none could be taken in time
+func (s *Semaphore) Block(ctx context.Context, timeout time.Duration) (*Permit, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		permit, acquired, err := s.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return permit, nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil, ErrLockTimeout
+		}
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Funnel waits up to timeout for a slot, then runs fn and frees the slot,
+// in the manner of Laravel's Redis::funnel. It returns ErrLockTimeout
+// without running fn if no slot could be taken in time; fn's error is
+// returned as is.
+func (s *Semaphore) Funnel(ctx context.Context, timeout time.Duration, fn func() error) error {
+	permit, err := s.Block(ctx, timeout)
+	if err != nil {
+		return err
+	}
+	defer permit.Release(context.WithoutCancel(ctx))
+
+	return fn()
+}
+
+// Extend resets the permit's expiry to the semaphore's TTL from now if the
+// slot has not expired, reporting whether it was extended
+func (p *Permit) Extend(ctx context.Context) (bool, error) {
+	s := p.semaphore
+	extended, err := extendSlotScript.Run(ctx, s.client.client, []string{s.client.key(s.name)}, p.owner, s.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return extended == 1, nil
+}
+
+// Release frees the slot. It reports false when the slot had expired and
+// was reclaimed by a later acquisition.
+func (p *Permit) Release(ctx context.Context) (bool, error) {
+	s := p.semaphore
+	removed, err := s.client.client.ZRem(ctx, s.client.key(s.name), p.owner).Result()
+	if err != nil {
+		return false, err
+	}
+	return removed == 1, nil
+}
@@ -0,0 +1,156 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("admits up to the limit", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+
+		sem := client.Semaphore("exports", 2, time.Minute)
+
+		first, ok, err := sem.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		_, ok, err = sem.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, ok, err = sem.Acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		released, err := first.Release(ctx)
+		require.NoError(t, err)
+		assert.True(t, released)
+
+		_, ok, err = sem.Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, mr.Exists("app:exports"))
+	})
+
+	t.Run("invalid limits and TTLs", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		_, _, err := client.Semaphore("exports", 0, time.Minute).Acquire(ctx)
+		assert.Equal(t, ErrInvalidLimit, err)
+		_, _, err = client.Semaphore("exports", 1, 0).Acquire(ctx)
+		assert.Equal(t, ErrInvalidTTL, err)
+		_, err = client.Semaphore("exports", 1, 500*time.Microsecond).Block(ctx, time.Second)
+		assert.Equal(t, ErrInvalidTTL, err)
+		assert.False(t, mr.Exists("exports"))
+	})
+
+	t.Run("expired slots are reclaimed", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		now := time.Now()
+		mr.SetTime(now)
+
+		sem := client.Semaphore("exports", 1, time.Minute)
+		stale, ok, err := sem.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		mr.SetTime(now.Add(30 * time.Second))
+		extended, err := stale.Extend(ctx)
+		require.NoError(t, err)
+		assert.True(t, extended)
+
+		// The extension keeps the slot past its original expiry
+		mr.SetTime(now.Add(80 * time.Second))
+		_, ok, err = sem.Acquire(ctx)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		mr.SetTime(now.Add(91 * time.Second))
+		_, ok, err = sem.Acquire(ctx)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		extended, err = stale.Extend(ctx)
+		require.NoError(t, err)
+		assert.False(t, extended)
+		released, err := stale.Release(ctx)
+		require.NoError(t, err)
+		assert.False(t, released)
+	})
+
+	t.Run("block times out", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		sem := client.Semaphore("exports", 1, time.Minute)
+		_, ok, err := sem.Acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = sem.Block(ctx, 150*time.Millisecond)
+		assert.Equal(t, ErrLockTimeout, err)
+
+		err = sem.Funnel(ctx, 0, func() error {
+			t.Fatal("fn must not run without a slot")
+			return nil
+		})
+		assert.Equal(t, ErrLockTimeout, err)
+	})
+
+	t.Run("funnel caps concurrency", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		sem := client.Semaphore("exports", 2, time.Minute)
+
+		var running, peak atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := sem.Funnel(ctx, 5*time.Second, func() error {
+					n := running.Add(1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					running.Add(-1)
+					return nil
+				})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), peak.Load())
+		assert.Zero(t, client.client.ZCard(ctx, "exports").Val())
+	})
+
+	t.Run("funnel returns fn's error", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		failure := errors.New("export failed")
+		err := client.Semaphore("exports", 1, time.Minute).Funnel(ctx, time.Second, func() error {
+			return failure
+		})
+		assert.Equal(t, failure, err)
+	})
+}
@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSentinel is a minimal Sentinel implementation that reports a fixed
+// master address, covering the commands the failover client sends
+type fakeSentinel struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	master string
+}
+
+func newFakeSentinel(t *testing.T, master string) *fakeSentinel {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSentinel{listener: l, master: master}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+
+	return s
+}
+
+func (s *fakeSentinel) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeSentinel) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSentinel) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "HELLO":
+			io.WriteString(conn, "-ERR unknown command 'HELLO'\r\n")
+		case "PING":
+			io.WriteString(conn, "+PONG\r\n")
+		case "SENTINEL":
+			s.sentinel(conn, args[1:])
+		case "SUBSCRIBE", "PSUBSCRIBE":
+			for i, channel := range args[1:] {
+				fmt.Fprintf(conn, "*3\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n:%d\r\n",
+					len(strings.ToLower(args[0])), strings.ToLower(args[0]), len(channel), channel, i+1)
+			}
+		default:
+			io.WriteString(conn, "+OK\r\n")
+		}
+	}
+}
+
+func (s *fakeSentinel) sentinel(w io.Writer, args []string) {
+	if len(args) == 0 {
+		io.WriteString(w, "-ERR wrong number of arguments\r\n")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "get-master-addr-by-name":
+		s.mu.Lock()
+		host, port, _ := net.SplitHostPort(s.master)
+		s.mu.Unlock()
+		fmt.Fprintf(w, "*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(host), host, len(port), port)
+	default:
+		// sentinels, replicas and similar discovery commands
+		io.WriteString(w, "*0\r\n")
+	}
+}
+
+// readCommand reads a single RESP array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "$")))
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestClient_Sentinel(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("connects to the master reported by sentinel", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+
+		sentinel := newFakeSentinel(t, mr.Addr())
+		client, err := New(Config{MasterName: "mymaster", SentinelAddrs: []string{sentinel.Addr()}})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		val, err := mr.Get("key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+
+	t.Run("requires sentinel addresses", func(t *testing.T) {
+		_, err := New(Config{MasterName: "mymaster"})
+		assert.Error(t, err)
+	})
+
+	t.Run("cannot be combined with cluster mode", func(t *testing.T) {
+		_, err := New(Config{MasterName: "mymaster", SentinelAddrs: []string{"localhost:26379"}, Cluster: true})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Set is a Redis set of string members, for membership tracking and
+// deduplication. Operations combining several sets run in Redis; in cluster
+// mode their keys must share a hash tag, such as "{audience}:buyers" and
+// "{audience}:subscribers".
+type Set struct {
+	client *Client
+	key    string
+}
+
+// Set returns a handle on the set stored at key. No command is sent until
+// one of its methods is called.
+func (c *Client) Set(key string) *Set {
+	return &Set{client: c, key: key}
+}
+
+// Key returns the key of the set, without the client prefix
+func (s *Set) Key() string {
+	return s.key
+}
+
+// Add adds members to the set and returns how many were not already in it
+func (s *Set) Add(ctx context.Context, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	return s.client.client.SAdd(ctx, s.client.key(s.key), stringArgs(members)...).Result()
+}
+
+// Remove removes members from the set and returns how many were in it
+func (s *Set) Remove(ctx context.Context, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	return s.client.client.SRem(ctx, s.client.key(s.key), stringArgs(members)...).Result()
+}
+
+// Contains checks if member is in the set
+func (s *Set) Contains(ctx context.Context, member string) (bool, error) {
+	var found bool
+	err := s.client.read(func(cmd redis.Cmdable) (err error) {
+		found, err = cmd.SIsMember(ctx, s.client.key(s.key), member).Result()
+		return err
+	})
+	return found, err
+}
+
+// Members returns every member of the set, in no particular order
+func (s *Set) Members(ctx context.Context) ([]string, error) {
+	var members []string
+	err := s.client.read(func(cmd redis.Cmdable) (err error) {
+		members, err = cmd.SMembers(ctx, s.client.key(s.key)).Result()
+		return err
+	})
+	return members, err
+}
+
+// Len returns the number of members in the set
+func (s *Set) Len(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.client.read(func(cmd redis.Cmdable) (err error) {
+		n, err = cmd.SCard(ctx, s.client.key(s.key)).Result()
+		return err
+	})
+	return n, err
+}
+
+// Clear removes the whole set
+func (s *Set) Clear(ctx context.Context) error {
+	return s.client.client.Del(ctx, s.client.key(s.key)).Err()
+}
+
+// Union returns the members of the set or any of others
+func (s *Set) Union(ctx context.Context, others ...*Set) ([]string, error) {
+	return s.client.client.SUnion(ctx, s.keys(others)...).Result()
+}
+
+// Intersect returns the members of the set that are in every one of others
+func (s *Set) Intersect(ctx context.Context, others ...*Set) ([]string, error) {
+	return s.client.client.SInter(ctx, s.keys(others)...).Result()
+}
+
+// Diff returns the members of the set that are in none of others
+func (s *Set) Diff(ctx context.Context, others ...*Set) ([]string, error) {
+	return s.client.client.SDiff(ctx, s.keys(others)...).Result()
+}
+
+// UnionInto stores the union of the set and others in dest, replacing its
+// members, and returns the number of members stored
+func (s *Set) UnionInto(ctx context.Context, dest *Set, others ...*Set) (int64, error) {
+	return s.client.client.SUnionStore(ctx, dest.client.key(dest.key), s.keys(others)...).Result()
+}
+
+// IntersectInto stores the intersection of the set and others in dest,
+// replacing its members, and returns the number of members stored
+func (s *Set) IntersectInto(ctx context.Context, dest *Set, others ...*Set) (int64, error) {
+	return s.client.client.SInterStore(ctx, dest.client.key(dest.key), s.keys(others)...).Result()
+}
+
+// DiffInto stores the members of the set that are in none of others in
+// dest, replacing its members, and returns the number of members stored
+func (s *Set) DiffInto(ctx context.Context, dest *Set, others ...*Set) (int64, error) {
+	return s.client.client.SDiffStore(ctx, dest.client.key(dest.key), s.keys(others)...).Result()
+}
+
+// keys returns the prefixed keys of the set followed by others
+func (s *Set) keys(others []*Set) []string {
+	keys := make([]string, 0, len(others)+1)
+	keys = append(keys, s.client.key(s.key))
+	for _, other := range others {
+		keys = append(keys, other.client.key(other.key))
+	}
+	return keys
+}
+
+// stringArgs converts values to command arguments
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Set(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("membership", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		seen := client.Set("seen")
+
+		n, err := seen.Add(ctx, "a", "b", "a")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.True(t, mr.Exists("app:seen"))
+
+		// Adding a member again reports it as a duplicate
+		n, err = seen.Add(ctx, "b")
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		found, err := seen.Contains(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		n, err = seen.Remove(ctx, "a", "missing")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		found, err = seen.Contains(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		members, err := seen.Members(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, members)
+
+		n, err = seen.Len(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		require.NoError(t, seen.Clear(ctx))
+		assert.False(t, mr.Exists("app:seen"))
+	})
+
+	t.Run("combinations", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		buyers, subscribers, unsubscribed := client.Set("buyers"), client.Set("subscribers"), client.Set("unsubscribed")
+
+		_, err := buyers.Add(ctx, "ada", "alan", "grace")
+		require.NoError(t, err)
+		_, err = subscribers.Add(ctx, "alan", "grace", "linus")
+		require.NoError(t, err)
+		_, err = unsubscribed.Add(ctx, "grace")
+		require.NoError(t, err)
+
+		members, err := buyers.Union(ctx, subscribers)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ada", "alan", "grace", "linus"}, members)
+
+		members, err = buyers.Intersect(ctx, subscribers)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"alan", "grace"}, members)
+
+		members, err = buyers.Diff(ctx, subscribers)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ada"}, members)
+
+		audience := client.Set("audience")
+		n, err := buyers.IntersectInto(ctx, audience, subscribers)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+
+		n, err = audience.DiffInto(ctx, audience, unsubscribed)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		members, err = audience.Members(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alan"}, members)
+
+		n, err = buyers.UnionInto(ctx, audience, subscribers)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), n)
+		assert.True(t, mr.Exists("app:audience"))
+	})
+}
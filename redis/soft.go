@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// softHeaderSize is the length of the soft expiry, in Unix milliseconds,
+// following the header of values written with stale-while-revalidate
+const softHeaderSize = 8
+
+// softRefreshSuffix names the background refreshes of a key, so they are
+// not shared with callers waiting for a missing value
+const softRefreshSuffix = ":soft-refresh"
+
+// withSoftExpiry wraps an encoded value in a header recording its soft
+// expiry
+func withSoftExpiry(encoded string, expiry time.Time) string {
+	var header [softHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(expiry.UnixMilli()))
+	return valueMagic + string(formatSoft) + string(header[:]) + encoded
+}
+
+// softExpiry returns the soft expiry recorded in a stored value, if any
+func softExpiry(value string) (time.Time, bool) {
+	prefix := valueMagic + string(formatSoft)
+	if !strings.HasPrefix(value, prefix) || len(value) < len(prefix)+softHeaderSize {
+		return time.Time{}, false
+	}
+	ms := binary.BigEndian.Uint64([]byte(value[len(prefix) : len(prefix)+softHeaderSize]))
+	return time.UnixMilli(int64(ms)), true
+}
+
+// rememberSoft is Remember with stale-while-revalidate: values past their
+// soft expiry are served while being refreshed in the background
+func (c *Client) rememberSoft(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	var raw string
+	err := c.read(func(cmd redis.Cmdable) (err error) {
+		raw, err = cmd.Get(ctx, c.key(key)).Result()
+		return err
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	if err == nil {
+		value, err := c.decodeValue(key, raw)
+		if err != nil {
+			c.stats.lookup(1, 0)
+			return "", hideCachedMiss(err)
+		}
+		c.stats.lookup(1, 1)
+
+		if expiry, ok := softExpiry(raw); ok && c.now().After(expiry) && callback != nil {
+			// Refresh detached from the caller's context so that the refresh
+			// outlives the request that triggered it
+			refreshCtx := context.WithoutCancel(ctx)
+			c.flight.DoChan(c.key(key)+softRefreshSuffix, func() (interface{}, error) {
+				return c.storeSoft(refreshCtx, key, ttl, callback)
+			})
+		}
+		return value, nil
+	}
+	c.stats.lookup(1, 0)
+
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+	return c.share(ctx, key, func(ctx context.Context) (string, error) {
+		return c.storeSoft(ctx, key, ttl, callback)
+	})
+}
+
+// storeSoft executes callback and stores its result with a soft expiry ttl
+// from now, keeping it in Redis for the stale window beyond that
+func (c *Client) storeSoft(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	value, err := c.runCallback(callback)
+	if c.negativeTTL > 0 && errors.Is(err, ErrKeyNotFound) {
+		return "", c.storeMiss(ctx, key)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := c.encodeValue(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	soft := withSoftExpiry(encoded, c.now().Add(ttl))
+	if err := c.client.Set(ctx, c.key(key), soft, c.jitter(ttl+c.staleWindow)).Err(); err != nil {
+		return "", err
+	}
+	if err := stored(ctx, key); err != nil {
+		return "", err
+	}
+	return value, nil
+}
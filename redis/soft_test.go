@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+func TestClient_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores a soft expiry", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Hour})
+		defer mr.Close()
+
+		start := time.Now()
+		client.now = func() time.Time { return start }
+
+		value, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			return "v1", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, value)
+		assert.Equal(t, time.Hour+time.Minute, mr.TTL("report"))
+
+		raw, err := mr.Get("report")
+		require.NoError(t, err)
+		expiry, ok := softExpiry(raw)
+		require.True(t, ok)
+		assert.Equal(t, start.Add(time.Minute).UnixMilli(), expiry.UnixMilli())
+
+		// Plain reads see the value without the header
+		value, err = client.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, value)
+	})
+
+	t.Run("serves stale values while refreshing", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Hour})
+		defer mr.Close()
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		var calls atomic.Int32
+		callback := func() (interface{}, error) {
+			return calls.Add(1), nil
+		}
+
+		value, err := client.Remember(ctx, "report", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", value)
+
+		// Still fresh
+		value, err = client.Remember(ctx, "report", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", value)
+		assert.Equal(t, int32(1), calls.Load())
+
+		now = now.Add(2 * time.Minute)
+		value, err = client.Remember(ctx, "report", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", value)
+
+		assert.Eventually(t, func() bool {
+			value, err := client.Get(ctx, "report")
+			return err == nil && value == "2"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("failed refreshes keep the stale value", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Hour})
+		defer mr.Close()
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		_, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			return "v1", nil
+		})
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Minute)
+		refreshed := make(chan struct{})
+		value, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			defer close(refreshed)
+			return nil, errors.New("backend down")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, value)
+
+		<-refreshed
+		value, err = client.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, `"v1"`, value)
+	})
+
+	t.Run("waits once the value is gone", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Minute})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			return "v1", nil
+		})
+		require.NoError(t, err)
+
+		mr.FastForward(3 * time.Minute)
+		value, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			return "v2", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"v2"`, value)
+	})
+
+	t.Run("encoded values", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			StaleWhileRevalidate: time.Minute,
+			Compression:          CompressionGzip,
+			CompressionThreshold: 1,
+		})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "report", time.Minute, func() (interface{}, error) {
+			return "a long enough report", nil
+		})
+		require.NoError(t, err)
+
+		value, err := client.Remember(ctx, "report", time.Minute, nil)
+		require.NoError(t, err)
+		assert.Equal(t, `"a long enough report"`, value)
+	})
+
+	t.Run("typed callbacks refreshing after the call", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Hour})
+		defer mr.Close()
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		report, err := cache.RememberAs(ctx, client, "report", time.Minute, func() (int, error) {
+			return 1, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report)
+
+		// The refresh runs concurrently with the rest of RememberAs, which
+		// must not share state with it; run with -race
+		now = now.Add(2 * time.Minute)
+		refreshed := make(chan struct{})
+		report, err = cache.RememberAs(ctx, client, "report", time.Minute, func() (int, error) {
+			defer close(refreshed)
+			return 2, nil
+		})
+		require.NoError(t, err)
+		assert.Contains(t, []int{1, 2}, report)
+
+		<-refreshed
+		assert.Eventually(t, func() bool {
+			value, err := client.Get(ctx, "report")
+			return err == nil && value == "2"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Minute})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "missing", time.Minute, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
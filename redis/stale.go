@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// freshKeyPrefix namespaces the markers RememberStale uses to track whether a
+// value is still within its fresh window
+const freshKeyPrefix = "gofacades:fresh:"
+
+// freshKey returns the key of the marker recording that key is still fresh
+func freshKey(key string) string {
+	return freshKeyPrefix + key
+}
+
+// RememberStale gets an item from the cache, or stores the result of the
+// callback. A value is fresh for freshTTL and is then kept for a further
+// staleTTL, during which it is still returned immediately while the callback
+// refreshes it in the background. Once both windows have passed the callback
+// runs synchronously as with Remember. Errors from background refreshes are
+// discarded and the stale value keeps being served until it expires.
+func (c *Client) RememberStale(ctx context.Context, key string, freshTTL, staleTTL time.Duration, callback func() (interface{}, error)) (string, error) {
+	if callback == nil {
+		return "", ErrNilCallback
+	}
+
+	var get *redis.StringCmd
+	var fresh *redis.IntCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.key(key))
+		fresh = pipe.Exists(ctx, c.key(freshKey(key)))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	value, err := get.Result()
+	if errors.Is(err, redis.Nil) {
+		return c.share(ctx, key, func(ctx context.Context) (string, error) {
+			return c.storeStale(ctx, key, freshTTL, staleTTL, callback)
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+	value, err = c.decodeValue(key, value)
+	if err != nil {
+		return "", hideCachedMiss(err)
+	}
+
+	if fresh.Val() == 0 {
+		// Refresh in the background, detached from the caller's context so
+		// that the refresh outlives the request that triggered it
+		refreshCtx := context.WithoutCancel(ctx)
+		c.flight.DoChan(c.key(freshKey(key)), func() (interface{}, error) {
+			return c.storeStale(refreshCtx, key, freshTTL, staleTTL, callback)
+		})
+	}
+
+	return value, nil
+}
+
+// storeStale executes callback and stores its result for freshTTL+staleTTL,
+// marking it fresh for freshTTL
+func (c *Client) storeStale(ctx context.Context, key string, freshTTL, staleTTL time.Duration, callback func() (interface{}, error)) (string, error) {
+	value, err := c.runCallback(callback)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := c.encodeValue(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.key(key), encoded, c.jitter(freshTTL+staleTTL))
+		pipe.Set(ctx, c.key(freshKey(key)), 1, freshTTL)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
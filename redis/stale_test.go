@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RememberStale(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	var calls int32
+	callback := func() (interface{}, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	}
+
+	t.Run("computes a missing value synchronously", func(t *testing.T) {
+		val, err := client.RememberStale(ctx, "report", time.Minute, time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", val)
+		assert.Equal(t, time.Minute+time.Hour, mr.TTL("report"))
+		assert.Equal(t, time.Minute, mr.TTL(freshKey("report")))
+	})
+
+	t.Run("serves a fresh value without refreshing", func(t *testing.T) {
+		val, err := client.RememberStale(ctx, "report", time.Minute, time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", val)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("serves a stale value and refreshes in the background", func(t *testing.T) {
+		mr.FastForward(2 * time.Minute)
+		require.False(t, mr.Exists(freshKey("report")))
+
+		val, err := client.RememberStale(ctx, "report", time.Minute, time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, "1", val)
+
+		assert.Eventually(t, func() bool {
+			val, err := client.Get(ctx, "report")
+			return err == nil && val == "2"
+		}, time.Second, 10*time.Millisecond)
+		assert.True(t, mr.Exists(freshKey("report")))
+	})
+
+	t.Run("failed refresh keeps serving the stale value", func(t *testing.T) {
+		mr.FastForward(2 * time.Minute)
+
+		refreshed := make(chan struct{})
+		val, err := client.RememberStale(ctx, "report", time.Minute, time.Hour, func() (interface{}, error) {
+			defer close(refreshed)
+			return nil, errors.New("backend down")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2", val)
+
+		<-refreshed
+		val, err = client.Get(ctx, "report")
+		require.NoError(t, err)
+		assert.Equal(t, "2", val)
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		_, err := client.RememberStale(ctx, "report", time.Minute, time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
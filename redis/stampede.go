@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// rememberLockPrefix namespaces the distributed locks Remember takes when
+// Config.RememberLock is set
+const rememberLockPrefix = "gofacades:remember:"
+
+// rememberLockKey returns the name of the lock guarding the computation of key
+func rememberLockKey(key string) string {
+	return rememberLockPrefix + key
+}
+
+// share runs fn once for all the concurrent callers asking for key in this
+// process. It runs detached from the callers' contexts, so the first caller
+// giving up does not fail the others, while each caller stops waiting as
+// soon as its own context is done.
+func (c *Client) share(ctx context.Context, key string, fn func(ctx context.Context) (string, error)) (string, error) {
+	detached := context.WithoutCancel(ctx)
+	done := c.flight.DoChan(c.key(key), func() (interface{}, error) {
+		return fn(detached)
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-done:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// compute runs callback and stores its encoded result under key. When a
+// remember lock is configured, only the caller holding the lock runs the
+// callback; the others wait for the value to appear and fall back to running
+// it themselves once the lock would have expired.
+func (c *Client) compute(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	if c.rememberLock <= 0 {
+		return c.store(ctx, key, ttl, callback)
+	}
+
+	lock := c.Lock(rememberLockKey(key), c.rememberLock)
+	acquired, err := lock.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	if acquired {
+		defer lock.Release(context.WithoutCancel(ctx))
+
+		// Another process may have stored the value while we were acquiring
+		value, err := c.get(ctx, key)
+		if !errors.Is(err, ErrKeyNotFound) {
+			return value, err
+		}
+		return c.store(ctx, key, ttl, callback)
+	}
+
+	deadline := time.Now().Add(c.rememberLock)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+
+		value, err := c.get(ctx, key)
+		if !errors.Is(err, ErrKeyNotFound) {
+			return value, err
+		}
+	}
+
+	// The lock holder did not store a value in time, compute it ourselves
+	return c.store(ctx, key, ttl, callback)
+}
+
+// store executes callback and stores its encoded result under key
+func (c *Client) store(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	start := time.Now()
+	value, err := c.runCallback(callback)
+	if c.negativeTTL > 0 && errors.Is(err, ErrKeyNotFound) {
+		return "", c.storeMiss(ctx, key)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if c.earlyExpiration > 0 && ttl > 0 {
+		err = c.putWithDelta(ctx, key, value, ttl, time.Since(start))
+	} else {
+		// Store the result in cache
+		err = c.Put(ctx, key, value, ttl)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := stored(ctx, key); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// runCallback executes callback and encodes its result with the client's codec
+func (c *Client) runCallback(callback func() (interface{}, error)) (string, error) {
+	// Execute callback
+	result, err := callback()
+	if err != nil {
+		return "", fmt.Errorf("callback execution failed: %w", err)
+	}
+
+	// Marshal the result with the configured codec
+	encoded, err := c.codec.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback result: %w", err)
+	}
+
+	return string(encoded), nil
+}
@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClientFor connects an additional client to an existing mock server,
+// standing in for another process sharing the cache
+func newClientFor(t *testing.T, mr *miniredis.Miniredis, cfg Config) *Client {
+	p, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	cfg.Host = mr.Host()
+	cfg.Port = p
+	client, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestClient_RememberStampede(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrent callers share one computation", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		var calls int32
+		callback := func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(50 * time.Millisecond)
+			return "computed", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				val, err := client.Remember(ctx, "report", time.Hour, callback)
+				assert.NoError(t, err)
+				assert.Equal(t, `"computed"`, val)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("callers giving up do not fail the others", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		callback := func() (interface{}, error) {
+			close(started)
+			<-release
+			return "computed", nil
+		}
+
+		firstCtx, cancel := context.WithCancel(ctx)
+		first := make(chan error, 1)
+		go func() {
+			_, err := client.Remember(firstCtx, "report", time.Hour, callback)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan string, 1)
+		go func() {
+			val, err := client.Remember(ctx, "report", time.Hour, callback)
+			assert.NoError(t, err)
+			second <- val
+		}()
+		// Let the second caller join the computation
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		close(release)
+		assert.Equal(t, `"computed"`, <-second)
+		assert.True(t, mr.Exists("report"))
+	})
+
+	t.Run("distributed lock makes other processes wait", func(t *testing.T) {
+		first, mr := setupTestRedisWith(t, Config{RememberLock: time.Second})
+		defer mr.Close()
+		second := newClientFor(t, mr, Config{RememberLock: time.Second})
+
+		var calls int32
+		started := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := first.Remember(ctx, "report", time.Hour, func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				close(started)
+				time.Sleep(150 * time.Millisecond)
+				return "computed", nil
+			})
+			assert.NoError(t, err)
+		}()
+
+		<-started
+		val, err := second.Remember(ctx, "report", time.Hour, func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return "duplicate", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"computed"`, val)
+		<-done
+
+		assert.Equal(t, int32(1), calls)
+		assert.False(t, mr.Exists(rememberLockKey("report")))
+	})
+
+	t.Run("computes once the lock holder gives up", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{RememberLock: 200 * time.Millisecond})
+		defer mr.Close()
+
+		// A crashed process left its lock behind without storing a value
+		require.NoError(t, mr.Set(rememberLockKey("report"), "someone-else"))
+
+		val, err := client.Remember(ctx, "report", time.Hour, func() (interface{}, error) {
+			return "computed", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"computed"`, val)
+	})
+}
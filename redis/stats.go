@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stats is a snapshot of the cache activity of a client since it was created
+// or its stats were last reset. Hits and misses count the keys looked up by
+// Get, GetMany and Has, including the lookups made by Remember and Pull.
+// Errors counts failed Redis commands, cache misses aside.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Errors uint64 `json:"errors"`
+}
+
+// HitRatio returns the fraction of lookups that found the key, or zero
+// before any lookup
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// ServerStats holds figures reported by the Redis server, summed over every
+// master in cluster mode. Keys counts every key in the database, whatever its
+// prefix.
+type ServerStats struct {
+	UsedMemory     int64 `json:"used_memory"`
+	Keys           int64 `json:"keys"`
+	ExpiredKeys    int64 `json:"expired_keys"`
+	EvictedKeys    int64 `json:"evicted_keys"`
+	KeyspaceHits   int64 `json:"keyspace_hits"`
+	KeyspaceMisses int64 `json:"keyspace_misses"`
+}
+
+// clientStats holds the counters behind Stats
+type clientStats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// lookup counts a lookup of n keys of which found existed
+func (s *clientStats) lookup(n, found int) {
+	s.hits.Add(uint64(found))
+	s.misses.Add(uint64(n - found))
+}
+
+// Stats returns the cache activity of the client. Clients derived with
+// WithPrefix share the counters of the client they were derived from.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Hits:   c.stats.hits.Load(),
+		Misses: c.stats.misses.Load(),
+		Errors: c.stats.errors.Load(),
+	}
+}
+
+// ResetStats sets the counters returned by Stats back to zero
+func (c *Client) ResetStats() {
+	c.stats.hits.Store(0)
+	c.stats.misses.Store(0)
+	c.stats.errors.Store(0)
+}
+
+// ServerStats queries Redis for memory use and keyspace figures
+func (c *Client) ServerStats(ctx context.Context) (ServerStats, error) {
+	var mu sync.Mutex
+	var stats ServerStats
+	err := c.forEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+		info, err := shard.Info(ctx).Result()
+		if err != nil {
+			return err
+		}
+		keys, err := shard.DBSize(ctx).Result()
+		if err != nil {
+			return err
+		}
+
+		fields := parseInfo(info)
+		mu.Lock()
+		defer mu.Unlock()
+		stats.UsedMemory += fields["used_memory"]
+		stats.Keys += keys
+		stats.ExpiredKeys += fields["expired_keys"]
+		stats.EvictedKeys += fields["evicted_keys"]
+		stats.KeyspaceHits += fields["keyspace_hits"]
+		stats.KeyspaceMisses += fields["keyspace_misses"]
+		return nil
+	})
+	return stats, err
+}
+
+// parseInfo extracts the integer fields of an INFO reply
+func parseInfo(info string) map[string]int64 {
+	fields := make(map[string]int64)
+	for _, line := range strings.Split(info, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[name] = n
+		}
+	}
+	return fields
+}
+
+// statsHook counts the commands that fail for another reason than a miss
+type statsHook struct {
+	stats *clientStats
+}
+
+func (h statsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h statsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			h.stats.errors.Add(1)
+		}
+		return err
+	}
+}
+
+func (h statsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			h.stats.errors.Add(1)
+		}
+		return err
+	}
+}
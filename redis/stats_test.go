@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Stats(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+
+	t.Run("hits and misses", func(t *testing.T) {
+		client.ResetStats()
+		require.NoError(t, client.Put(ctx, "a", "1", time.Hour))
+		require.NoError(t, client.Put(ctx, "b", "2", time.Hour))
+
+		client.Get(ctx, "a")
+		client.Get(ctx, "missing")
+		client.Has(ctx, "b")
+		client.GetMany(ctx, "a", "b", "c")
+		client.Remember(ctx, "a", time.Hour, func() (interface{}, error) { return "x", nil })
+
+		stats := client.Stats()
+		assert.Equal(t, uint64(5), stats.Hits)
+		assert.Equal(t, uint64(2), stats.Misses)
+		assert.Zero(t, stats.Errors)
+		assert.InDelta(t, 5.0/7.0, stats.HitRatio(), 0.0001)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		client.ResetStats()
+		mr.Set("text", "not a number")
+
+		_, err := client.Increment(ctx, "text", 1)
+		assert.Error(t, err)
+		assert.Equal(t, uint64(1), client.Stats().Errors)
+	})
+
+	t.Run("derived clients share stats", func(t *testing.T) {
+		client.ResetStats()
+		client.WithPrefix("scoped:").Get(ctx, "missing")
+		assert.Equal(t, uint64(1), client.Stats().Misses)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		client.Get(ctx, "a")
+		client.ResetStats()
+		assert.Equal(t, Stats{}, client.Stats())
+		assert.Zero(t, client.Stats().HitRatio())
+	})
+}
+
+func TestClient_ServerStats(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	require.NoError(t, client.Put(ctx, "a", "1", time.Hour))
+	require.NoError(t, client.Put(ctx, "b", "2", time.Hour))
+
+	stats, err := client.ServerStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Keys)
+}
+
+func TestParseInfo(t *testing.T) {
+	info := "# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\n\r\n# Stats\r\nexpired_keys:12\r\nevicted_keys:3\r\nkeyspace_hits:90\r\nkeyspace_misses:10\r\n"
+
+	fields := parseInfo(info)
+	assert.Equal(t, int64(1048576), fields["used_memory"])
+	assert.Equal(t, int64(12), fields["expired_keys"])
+	assert.Equal(t, int64(3), fields["evicted_keys"])
+	assert.Equal(t, int64(90), fields["keyspace_hits"])
+	assert.NotContains(t, fields, "used_memory_human")
+}
@@ -0,0 +1,312 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// streamPayloadField is the entry field XAdd stores the encoded payload
+	// under
+	streamPayloadField = "payload"
+
+	// streamOriginField is the field dead letters carry the ID of the entry
+	// they were copied from in
+	streamOriginField = "origin_id"
+
+	// defaultStreamBatchSize is the number of entries read at a time when
+	// ConsumerConfig does not set a batch size
+	defaultStreamBatchSize = 10
+
+	// defaultStreamBlock is how long a read waits for new entries when
+	// ConsumerConfig does not set a block duration
+	defaultStreamBlock = 5 * time.Second
+
+	// defaultStreamClaimIdle is how long an entry stays pending before it is
+	// claimed again when ConsumerConfig does not set a claim idle time
+	defaultStreamClaimIdle = time.Minute
+
+	// defaultStreamMaxDeliveries is how often an entry is delivered before
+	// being dead-lettered when ConsumerConfig does not set a maximum
+	defaultStreamMaxDeliveries = 5
+
+	// streamRetryInterval is how long a consumer waits after a failed read
+	streamRetryInterval = time.Second
+)
+
+// StreamEntry is an entry read from a stream. Payload is the value encoded
+// by XAdd, empty for entries added without it; Values holds every field.
+// Deliveries counts how often the entry was delivered, including this time.
+type StreamEntry struct {
+	ID         string
+	Stream     string
+	Payload    string
+	Values     map[string]interface{}
+	Deliveries int64
+
+	codec cache.Codec
+}
+
+// Decode decodes the payload into v with the client's codec
+func (e StreamEntry) Decode(v interface{}) error {
+	if err := e.codec.Unmarshal([]byte(e.Payload), v); err != nil {
+		return &DecodeError{Key: e.Stream, Err: err}
+	}
+	return nil
+}
+
+// StreamHandler processes a stream entry. Returning nil acknowledges the
+// entry; returning an error leaves it pending, to be delivered again.
+type StreamHandler func(ctx context.Context, entry StreamEntry) error
+
+// ConsumerConfig configures Consume
+type ConsumerConfig struct {
+	// Consumer names this consumer within the group. Restarting a consumer
+	// under the same name lets it pick up its own pending entries. Defaults
+	// to a random name.
+	Consumer string
+
+	// BatchSize is the maximum number of entries read at a time. Defaults
+	// to 10.
+	BatchSize int64
+
+	// Block is how long a read waits for new entries. Defaults to 5 seconds.
+	Block time.Duration
+
+	// ClaimIdle is how long an entry may stay pending, because its handler
+	// failed or its consumer died, before it is claimed and delivered again.
+	// Defaults to one minute.
+	ClaimIdle time.Duration
+
+	// MaxDeliveries is how often an entry is delivered before it is moved
+	// to the dead-letter stream instead. Defaults to 5.
+	MaxDeliveries int64
+
+	// DeadLetter is the stream failed entries are moved to, carrying the ID
+	// of the original entry in an "origin_id" field. Defaults to the stream
+	// name followed by ":dead".
+	DeadLetter string
+}
+
+// XAdd encodes payload with the configured codec and appends it to stream,
+// returning the ID of the new entry
+func (c *Client) XAdd(ctx context.Context, stream string, payload interface{}) (string, error) {
+	return c.XAddCapped(ctx, stream, 0, payload)
+}
+
+// XAddCapped is like XAdd, trimming the stream to about maxLen entries, the
+// oldest being removed first. A maxLen of zero does not trim.
+func (c *Client) XAddCapped(ctx context.Context, stream string, maxLen int64, payload interface{}) (string, error) {
+	encoded, err := c.codec.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.key(stream),
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: []interface{}{streamPayloadField, encoded},
+	}).Result()
+}
+
+// consumer reads a stream as part of a consumer group
+type consumer struct {
+	client  *Client
+	stream  string
+	group   string
+	name    string
+	cfg     ConsumerConfig
+	handler StreamHandler
+}
+
+// Consume processes the entries of stream as a member of group until ctx is
+// cancelled, creating the stream and group when missing; a new group starts
+// with the entries already in the stream. Entries are passed to handler one
+// at a time and acknowledged when it returns nil. Entries left pending for
+// ClaimIdle, whether their handler failed or their consumer died, are
+// claimed and delivered again, up to MaxDeliveries times, after which they
+// are moved to the dead-letter stream. Failed reads are retried, so
+// Consume only returns an error when the group cannot be created.
+func (c *Client) Consume(ctx context.Context, stream, group string, handler StreamHandler, cfg ConsumerConfig) error {
+	if handler == nil {
+		return ErrNilCallback
+	}
+
+	if cfg.Consumer == "" {
+		name, err := newLockOwner()
+		if err != nil {
+			return err
+		}
+		cfg.Consumer = name
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultStreamBatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaultStreamBlock
+	}
+	if cfg.ClaimIdle <= 0 {
+		cfg.ClaimIdle = defaultStreamClaimIdle
+	}
+	if cfg.MaxDeliveries <= 0 {
+		cfg.MaxDeliveries = defaultStreamMaxDeliveries
+	}
+	if cfg.DeadLetter == "" {
+		cfg.DeadLetter = stream + ":dead"
+	}
+
+	w := &consumer{client: c, stream: c.key(stream), group: group, name: cfg.Consumer, cfg: cfg, handler: handler}
+	if err := w.createGroup(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+			if isNoGroup(err) {
+				// The stream was deleted along with its groups
+				_ = w.createGroup(ctx)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(streamRetryInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// createGroup creates the stream and the group, if missing
+func (w *consumer) createGroup(ctx context.Context) error {
+	err := w.client.client.XGroupCreateMkStream(ctx, w.stream, w.group, "0").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// isNoGroup reports whether err says the group or stream does not exist
+func isNoGroup(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOGROUP")
+}
+
+// poll claims idle pending entries, reads new ones and processes both
+func (w *consumer) poll(ctx context.Context) error {
+	claimed, _, err := w.client.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   w.stream,
+		Group:    w.group,
+		Consumer: w.name,
+		MinIdle:  w.cfg.ClaimIdle,
+		Start:    "0-0",
+		Count:    w.cfg.BatchSize,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	if len(claimed) > 0 {
+		deliveries, err := w.deliveries(ctx, claimed)
+		if err != nil {
+			return err
+		}
+		for _, msg := range claimed {
+			w.process(ctx, msg, deliveries[msg.ID])
+		}
+		// Read new entries without blocking while there may be more to claim
+		if int64(len(claimed)) == w.cfg.BatchSize {
+			return nil
+		}
+	}
+
+	streams, err := w.client.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    w.group,
+		Consumer: w.name,
+		Streams:  []string{w.stream, ">"},
+		Count:    w.cfg.BatchSize,
+		Block:    w.cfg.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			w.process(ctx, msg, 1)
+		}
+	}
+	return nil
+}
+
+// deliveries returns how often each claimed entry has been delivered
+func (w *consumer) deliveries(ctx context.Context, msgs []redis.XMessage) (map[string]int64, error) {
+	pending, err := w.client.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: w.stream,
+		Group:  w.group,
+		Start:  msgs[0].ID,
+		End:    msgs[len(msgs)-1].ID,
+		Count:  int64(len(msgs)),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		counts[p.ID] = p.RetryCount
+	}
+	return counts, nil
+}
+
+// process hands msg to the handler, or to the dead-letter stream once it was
+// delivered too often, and acknowledges it when done
+func (w *consumer) process(ctx context.Context, msg redis.XMessage, deliveries int64) {
+	if deliveries > w.cfg.MaxDeliveries {
+		if err := w.deadLetter(ctx, msg); err == nil {
+			w.ack(ctx, msg.ID)
+		}
+		return
+	}
+
+	entry := StreamEntry{
+		ID:         msg.ID,
+		Stream:     strings.TrimPrefix(w.stream, w.client.prefix),
+		Values:     msg.Values,
+		Deliveries: deliveries,
+		codec:      w.client.codec,
+	}
+	if payload, ok := msg.Values[streamPayloadField].(string); ok {
+		entry.Payload = payload
+	}
+	if err := w.handler(ctx, entry); err == nil {
+		w.ack(ctx, msg.ID)
+	}
+}
+
+// deadLetter copies msg to the dead-letter stream
+func (w *consumer) deadLetter(ctx context.Context, msg redis.XMessage) error {
+	values := make(map[string]interface{}, len(msg.Values)+1)
+	for field, value := range msg.Values {
+		values[field] = value
+	}
+	values[streamOriginField] = msg.ID
+
+	return w.client.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: w.client.key(w.cfg.DeadLetter),
+		Values: values,
+	}).Err()
+}
+
+// ack acknowledges the entry with the given ID. Should it fail, the entry is
+// delivered again once claimable.
+func (w *consumer) ack(ctx context.Context, id string) {
+	w.client.client.XAck(ctx, w.stream, w.group, id)
+}
@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamEntries collects the entries passed to a handler
+type streamEntries struct {
+	mu      sync.Mutex
+	entries []StreamEntry
+}
+
+func (s *streamEntries) add(entry StreamEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func (s *streamEntries) list() []StreamEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StreamEntry(nil), s.entries...)
+}
+
+// consume runs Consume in the background until the test finishes
+func consume(t *testing.T, client *Client, stream, group string, handler StreamHandler, cfg ConsumerConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Consume(ctx, stream, group, handler, cfg) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestClient_XAdd(t *testing.T) {
+	ctx := context.Background()
+	client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+	defer mr.Close()
+	defer client.Close()
+
+	id, err := client.XAdd(ctx, "orders", map[string]int{"id": 1})
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	entries, err := client.client.XRange(ctx, "app:orders", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, id, entries[0].ID)
+	assert.Equal(t, `{"id":1}`, entries[0].Values["payload"])
+
+	for i := 0; i < 5; i++ {
+		_, err := client.XAddCapped(ctx, "capped", 2, i)
+		require.NoError(t, err)
+	}
+	length, err := client.client.XLen(ctx, "app:capped").Result()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, length, int64(5))
+	assert.GreaterOrEqual(t, length, int64(2))
+
+	_, err = client.XAdd(ctx, "orders", make(chan int))
+	assert.ErrorContains(t, err, "failed to marshal payload")
+}
+
+func TestClient_Consume(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("processes and acknowledges entries", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		defer client.Close()
+
+		// Entries added before the group exists are processed too
+		_, err := client.XAdd(ctx, "orders", 1)
+		require.NoError(t, err)
+
+		var received streamEntries
+		consume(t, client, "orders", "billing", func(ctx context.Context, entry StreamEntry) error {
+			received.add(entry)
+			return nil
+		}, ConsumerConfig{Block: 50 * time.Millisecond})
+
+		_, err = client.XAdd(ctx, "orders", 2)
+		require.NoError(t, err)
+		mr.XAdd("app:orders", "*", []string{"raw", "value"})
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 3 }, 2*time.Second, 10*time.Millisecond)
+		entries := received.list()
+		for i, want := range []int{1, 2} {
+			var got int
+			require.NoError(t, entries[i].Decode(&got))
+			assert.Equal(t, want, got)
+			assert.Equal(t, "orders", entries[i].Stream)
+			assert.Equal(t, int64(1), entries[i].Deliveries)
+		}
+		assert.Empty(t, entries[2].Payload)
+		assert.Equal(t, "value", entries[2].Values["raw"])
+
+		assert.Eventually(t, func() bool {
+			pending, err := client.client.XPending(ctx, "app:orders", "billing").Result()
+			return err == nil && pending.Count == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("redelivers failed entries", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		var received streamEntries
+		consume(t, client, "orders", "billing", func(ctx context.Context, entry StreamEntry) error {
+			received.add(entry)
+			if entry.Deliveries == 1 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		}, ConsumerConfig{Block: 20 * time.Millisecond, ClaimIdle: 50 * time.Millisecond})
+
+		id, err := client.XAdd(ctx, "orders", "order")
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 2 }, 2*time.Second, 10*time.Millisecond)
+		entries := received.list()
+		assert.Equal(t, id, entries[1].ID)
+		assert.Equal(t, int64(2), entries[1].Deliveries)
+
+		assert.Eventually(t, func() bool {
+			pending, err := client.client.XPending(ctx, "orders", "billing").Result()
+			return err == nil && pending.Count == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("claims entries of dead consumers", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		require.NoError(t, client.client.XGroupCreateMkStream(ctx, "orders", "billing", "0").Err())
+		id, err := client.XAdd(ctx, "orders", "order")
+		require.NoError(t, err)
+
+		// A consumer reads the entry and dies before acknowledging it
+		_, err = client.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    "billing",
+			Consumer: "dead",
+			Streams:  []string{"orders", ">"},
+		}).Result()
+		require.NoError(t, err)
+
+		var received streamEntries
+		consume(t, client, "orders", "billing", func(ctx context.Context, entry StreamEntry) error {
+			received.add(entry)
+			return nil
+		}, ConsumerConfig{Consumer: "alive", Block: 20 * time.Millisecond, ClaimIdle: 50 * time.Millisecond})
+
+		assert.Eventually(t, func() bool { return len(received.list()) == 1 }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, id, received.list()[0].ID)
+	})
+
+	t.Run("dead-letters entries failing too often", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+		defer client.Close()
+
+		var received streamEntries
+		consume(t, client, "orders", "billing", func(ctx context.Context, entry StreamEntry) error {
+			received.add(entry)
+			return errors.New("permanent failure")
+		}, ConsumerConfig{Block: 20 * time.Millisecond, ClaimIdle: 20 * time.Millisecond, MaxDeliveries: 2})
+
+		id, err := client.XAdd(ctx, "orders", "poison")
+		require.NoError(t, err)
+
+		var dead []redis.XMessage
+		assert.Eventually(t, func() bool {
+			dead, err = client.client.XRange(ctx, "app:orders:dead", "-", "+").Result()
+			return err == nil && len(dead) == 1
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, id, dead[0].Values["origin_id"])
+		assert.Equal(t, `"poison"`, dead[0].Values["payload"])
+		assert.Len(t, received.list(), 2)
+
+		pending, err := client.client.XPending(ctx, "app:orders", "billing").Result()
+		require.NoError(t, err)
+		assert.Zero(t, pending.Count)
+	})
+
+	t.Run("nil handler", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		err := client.Consume(ctx, "orders", "billing", nil, ConsumerConfig{})
+		assert.ErrorIs(t, err, ErrNilCallback)
+	})
+
+	t.Run("returns when the group cannot be created", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer client.Close()
+		mr.Close()
+
+		err := client.Consume(ctx, "orders", "billing", func(context.Context, StreamEntry) error { return nil }, ConsumerConfig{})
+		assert.ErrorContains(t, err, "failed to create consumer group")
+	})
+}
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetSet atomically stores value under key and returns the value it
+// replaced, keeping the item's remaining TTL. When the key did not exist the
+// value is stored without an expiry and ErrKeyNotFound is returned.
+func (c *Client) GetSet(ctx context.Context, key, value string) (string, error) {
+	encoded, err := c.encodeValue(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	previous, err := c.client.SetArgs(ctx, c.key(key), encoded, redis.SetArgs{KeepTTL: true, Get: true}).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	previous, err = c.decodeValue(key, previous)
+	return previous, hideCachedMiss(err)
+}
+
+// CompareAndSwap replaces the item stored under key with newValue only if it
+// currently holds oldValue, keeping its remaining TTL, and reports whether
+// it was replaced. Missing keys are never swapped. The comparison is made on
+// decoded values under WATCH, so it works with compression and encryption;
+// a concurrent write to the key makes the swap fail.
+func (c *Client) CompareAndSwap(ctx context.Context, key, oldValue, newValue string) (bool, error) {
+	encoded, err := c.encodeValue(key, newValue)
+	if err != nil {
+		return false, err
+	}
+
+	swapped := false
+	err = c.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, c.key(key)).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current, err = c.decodeValue(key, current)
+		if errors.Is(err, errCachedMiss) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if current != oldValue {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, c.key(key), encoded, redis.SetArgs{KeepTTL: true})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		swapped = true
+		return nil
+	}, c.key(key))
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return swapped, nil
+}
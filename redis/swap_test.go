@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetSet(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("returns the replaced value", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "token", "1", time.Hour))
+
+		previous, err := client.GetSet(ctx, "token", "2")
+		require.NoError(t, err)
+		assert.Equal(t, "1", previous)
+
+		value, err := client.Get(ctx, "token")
+		require.NoError(t, err)
+		assert.Equal(t, "2", value)
+		assert.Equal(t, time.Hour, mr.TTL("token"))
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := client.GetSet(ctx, "new", "value")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		value, err := client.Get(ctx, "new")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
+
+func TestClient_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("swaps a matching value", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "record", "v1", time.Hour))
+
+		swapped, err := client.CompareAndSwap(ctx, "record", "v1", "v2")
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		value, err := client.Get(ctx, "record")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value)
+		assert.Equal(t, time.Hour, mr.TTL("record"))
+	})
+
+	t.Run("leaves a different value", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "record", "v2", time.Hour))
+
+		swapped, err := client.CompareAndSwap(ctx, "record", "v1", "v3")
+		require.NoError(t, err)
+		assert.False(t, swapped)
+
+		value, err := client.Get(ctx, "record")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		swapped, err := client.CompareAndSwap(ctx, "missing", "", "v1")
+		require.NoError(t, err)
+		assert.False(t, swapped)
+		assert.False(t, mr.Exists("missing"))
+	})
+
+	t.Run("compares decoded values", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{
+			EncryptionKeys:  map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)},
+			EncryptionKeyID: "v1",
+		})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "record", "v1", time.Hour))
+
+		swapped, err := client.CompareAndSwap(ctx, "record", "v1", "v2")
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		value, err := client.Get(ctx, "record")
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value)
+	})
+}
@@ -0,0 +1,214 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+const (
+	// tagSetKeyPrefix namespaces the sets recording the entries of each tag
+	tagSetKeyPrefix = "gofacades:tag:"
+
+	// taggedKeyPrefix namespaces entries stored through a TaggedCache
+	taggedKeyPrefix = "gofacades:tagged:"
+)
+
+var _ cache.CodecStore = (*TaggedCache)(nil)
+
+// trackTagScript adds the item at KEYS[2] to the tag set at KEYS[1], as
+// ARGV[1], and keeps the set for at least as long as the item. Permanent
+// items make the set permanent, and items already gone are not added.
+var trackTagScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[2])
+if ttl == -2 then
+	return 0
+end
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+if ttl == -1 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local current = redis.call('PTTL', KEYS[1])
+if existed == 0 or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// storeHookKey is the context key of the function Remember runs after
+// storing a value, see withStoreHook
+type storeHookKey struct{}
+
+// withStoreHook returns a context making Remember run fn after it stores a
+// value under key, including when refreshing it in the background
+func withStoreHook(ctx context.Context, fn func(ctx context.Context, key string) error) context.Context {
+	return context.WithValue(ctx, storeHookKey{}, fn)
+}
+
+// stored runs the store hook of ctx, if any, for the value just stored
+// under key
+func stored(ctx context.Context, key string) error {
+	if fn, ok := ctx.Value(storeHookKey{}).(func(context.Context, string) error); ok {
+		return fn(ctx, key)
+	}
+	return nil
+}
+
+// TaggedCache stores items associated with a set of tags, so that all items
+// carrying a tag can be flushed together without touching the rest of the
+// cache. The set recording the items of a tag expires with the last of them.
+type TaggedCache struct {
+	client    *Client
+	tags      []string
+	namespace string
+}
+
+// Tags returns a cache whose items are associated with the given tags.
+// Items must be read back with the same tags, in the same order, that they
+// were written with.
+func (c *Client) Tags(tags ...string) *TaggedCache {
+	sum := sha1.Sum([]byte(strings.Join(tags, "|")))
+	return &TaggedCache{
+		client:    c,
+		tags:      tags,
+		namespace: taggedKeyPrefix + hex.EncodeToString(sum[:]) + ":",
+	}
+}
+
+// tagSetKey returns the key of the set holding the entries of tag
+func tagSetKey(tag string) string {
+	return tagSetKeyPrefix + tag + ":entries"
+}
+
+// itemKey returns the key an item is stored under, before the client prefix
+func (t *TaggedCache) itemKey(key string) string {
+	return t.namespace + key
+}
+
+// track records the item stored at itemKey as belonging to every tag,
+// extending the tag sets to the item's TTL
+func (t *TaggedCache) track(ctx context.Context, pipe redis.Pipeliner, itemKey string) {
+	for _, tag := range t.tags {
+		trackTagScript.Eval(ctx, pipe, []string{t.client.key(tagSetKey(tag)), t.client.key(itemKey)}, itemKey)
+	}
+}
+
+// untrack removes the item stored at itemKey from every tag
+func (t *TaggedCache) untrack(ctx context.Context, itemKey string) error {
+	_, err := t.client.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range t.tags {
+			pipe.SRem(ctx, t.client.key(tagSetKey(tag)), itemKey)
+		}
+		return nil
+	})
+	return err
+}
+
+// Get retrieves a tagged item from the cache by key
+func (t *TaggedCache) Get(ctx context.Context, key string) (string, error) {
+	return t.client.Get(ctx, t.itemKey(key))
+}
+
+// Has checks if a tagged item exists in the cache
+func (t *TaggedCache) Has(ctx context.Context, key string) (bool, error) {
+	return t.client.Has(ctx, t.itemKey(key))
+}
+
+// Put stores a tagged item in the cache for a given duration
+func (t *TaggedCache) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	itemKey := t.itemKey(key)
+	encoded, err := t.client.encodeValue(itemKey, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, t.client.key(itemKey), encoded, t.client.jitter(ttl))
+		t.track(ctx, pipe, itemKey)
+		return nil
+	})
+	return err
+}
+
+// Forever stores a tagged item in the cache permanently
+func (t *TaggedCache) Forever(ctx context.Context, key, value string) error {
+	return t.Put(ctx, key, value, 0)
+}
+
+// Remember gets a tagged item from the cache, or stores the result of the
+// callback
+func (t *TaggedCache) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	itemKey := t.itemKey(key)
+
+	// Track the item whenever Remember stores it, background refreshes
+	// included, so the tag sets never expire before it does
+	ctx = withStoreHook(ctx, func(ctx context.Context, key string) error {
+		_, err := t.client.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			t.track(ctx, pipe, key)
+			return nil
+		})
+		return err
+	})
+	return t.client.Remember(ctx, itemKey, ttl, callback)
+}
+
+// Pull retrieves and deletes a tagged item from the cache
+func (t *TaggedCache) Pull(ctx context.Context, key string) (string, error) {
+	itemKey := t.itemKey(key)
+
+	value, err := t.client.Pull(ctx, itemKey)
+	if err != nil {
+		return "", err
+	}
+	if err := t.untrack(ctx, itemKey); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// Forget removes a tagged item from the cache
+func (t *TaggedCache) Forget(ctx context.Context, key string) error {
+	itemKey := t.itemKey(key)
+
+	if err := t.client.Forget(ctx, itemKey); err != nil {
+		return err
+	}
+	return t.untrack(ctx, itemKey)
+}
+
+// Flush removes every item associated with any of the tags, whichever tags
+// it was stored with. Untagged items and items of other tags are kept.
+func (t *TaggedCache) Flush(ctx context.Context) error {
+	for _, tag := range t.tags {
+		setKey := t.client.key(tagSetKey(tag))
+
+		entries, err := t.client.client.SMembers(ctx, setKey).Result()
+		if err != nil {
+			return err
+		}
+
+		if _, err := t.client.del(ctx, append(t.client.keys(entries), setKey)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Codec returns the client's codec
+func (t *TaggedCache) Codec() cache.Codec {
+	return t.client.codec
+}
+
+// Close is a no-op; close the Client the tagged cache was created from instead
+func (t *TaggedCache) Close() error {
+	return nil
+}
@@ -0,0 +1,179 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+func TestTaggedCache(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("put and get", func(t *testing.T) {
+		users := client.Tags("users", "profiles")
+		require.NoError(t, users.Put(ctx, "user:1", "alice", time.Hour))
+
+		val, err := users.Get(ctx, "user:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", val)
+
+		exists, err := users.Has(ctx, "user:1")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		// Tagged items are namespaced away from untagged ones
+		_, err = client.Get(ctx, "user:1")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("flush only removes tagged items", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "untagged", "value", time.Hour))
+		require.NoError(t, client.Tags("users").Put(ctx, "user:2", "bob", time.Hour))
+		require.NoError(t, client.Tags("posts").Put(ctx, "post:1", "hello", time.Hour))
+		require.NoError(t, client.Tags("users", "profiles").Put(ctx, "user:3", "carol", time.Hour))
+
+		require.NoError(t, client.Tags("users").Flush(ctx))
+
+		_, err := client.Tags("users").Get(ctx, "user:2")
+		assert.Equal(t, ErrKeyNotFound, err)
+		_, err = client.Tags("users", "profiles").Get(ctx, "user:3")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		val, err := client.Tags("posts").Get(ctx, "post:1")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", val)
+
+		val, err = client.Get(ctx, "untagged")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+
+	t.Run("flushing any tag of an item removes it", func(t *testing.T) {
+		tagged := client.Tags("a", "b")
+		require.NoError(t, tagged.Forever(ctx, "item", "value"))
+
+		require.NoError(t, client.Tags("b").Flush(ctx))
+
+		_, err := tagged.Get(ctx, "item")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("remember tracks computed items", func(t *testing.T) {
+		tagged := client.Tags("reports")
+
+		callCount := 0
+		callback := func() (interface{}, error) {
+			callCount++
+			return map[string]int{"total": 10}, nil
+		}
+
+		val, err := tagged.Remember(ctx, "daily", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"total":10}`, val)
+
+		_, err = tagged.Remember(ctx, "daily", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, callCount)
+
+		require.NoError(t, tagged.Flush(ctx))
+
+		_, err = tagged.Remember(ctx, "daily", time.Hour, callback)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, callCount)
+	})
+
+	t.Run("tag sets expire with their items", func(t *testing.T) {
+		tagged := client.Tags("feeds")
+		require.NoError(t, tagged.Put(ctx, "short", "value", time.Minute))
+		assert.Equal(t, time.Minute, mr.TTL(tagSetKey("feeds")))
+
+		// The set lasts as long as its longest-lived item
+		require.NoError(t, tagged.Put(ctx, "long", "value", time.Hour))
+		require.NoError(t, tagged.Put(ctx, "shorter", "value", time.Second))
+		assert.Equal(t, time.Hour, mr.TTL(tagSetKey("feeds")))
+
+		_, err := tagged.Remember(ctx, "computed", 2*time.Hour, func() (interface{}, error) {
+			return "value", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Hour, mr.TTL(tagSetKey("feeds")))
+
+		// Permanent items keep the set for good
+		require.NoError(t, tagged.Forever(ctx, "permanent", "value"))
+		assert.Zero(t, mr.TTL(tagSetKey("feeds")))
+		require.NoError(t, tagged.Put(ctx, "later", "value", time.Minute))
+		assert.Zero(t, mr.TTL(tagSetKey("feeds")))
+	})
+
+	t.Run("background refreshes extend tag sets", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{StaleWhileRevalidate: time.Hour})
+		defer mr.Close()
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		tagged := client.Tags("reports")
+		callback := func() (interface{}, error) { return "value", nil }
+		_, err := tagged.Remember(ctx, "daily", time.Minute, callback)
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour+time.Minute, mr.TTL(tagSetKey("reports")))
+
+		now = now.Add(30 * time.Minute)
+		mr.FastForward(30 * time.Minute)
+		_, err = tagged.Remember(ctx, "daily", time.Minute, callback)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			return mr.TTL(tagSetKey("reports")) == time.Hour+time.Minute
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("remember nil callback", func(t *testing.T) {
+		_, err := client.Tags("reports").Remember(ctx, "missing", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+
+	t.Run("pull and forget untrack items", func(t *testing.T) {
+		tagged := client.Tags("sessions")
+		require.NoError(t, tagged.Put(ctx, "s1", "one", time.Hour))
+		require.NoError(t, tagged.Put(ctx, "s2", "two", time.Hour))
+
+		val, err := tagged.Pull(ctx, "s1")
+		assert.NoError(t, err)
+		assert.Equal(t, "one", val)
+
+		require.NoError(t, tagged.Forget(ctx, "s2"))
+
+		// Redis removes sets once their last member is gone
+		assert.False(t, mr.Exists(tagSetKey("sessions")))
+		assert.NoError(t, tagged.Close())
+	})
+}
+
+func TestTaggedCache_RememberAs(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Codec: cache.MsgpackCodec{}})
+	defer mr.Close()
+
+	ctx := context.Background()
+	tagged := client.Tags("users")
+	assert.Equal(t, cache.MsgpackCodec{}, tagged.Codec())
+
+	want := testStruct{Name: "test", Value: 123}
+	got, err := cache.RememberAs(ctx, tagged, "profile", time.Hour, func() (testStruct, error) {
+		return want, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	result, err := GetAs[testStruct](ctx, client, tagged.itemKey("profile"))
+	require.NoError(t, err)
+	assert.Equal(t, want, result)
+}
@@ -0,0 +1,283 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultL1Size is the number of entries kept in process when
+	// TieredConfig does not set a size
+	defaultL1Size = 10000
+
+	// defaultL1TTL is how long an entry is kept in process when TieredConfig
+	// does not set a TTL
+	defaultL1TTL = 5 * time.Second
+
+	// tieredChannel is the pub/sub channel, namespaced by the client prefix,
+	// on which tiered stores announce invalidations
+	tieredChannel = "__gofacades:tiered:invalidate"
+)
+
+// Invalidations are published as "<instance id> <op>[ <key>]"
+const (
+	tieredForget = "forget"
+	tieredFlush  = "flush"
+)
+
+// TieredConfig configures a TieredStore
+type TieredConfig struct {
+	// L1Size is the maximum number of entries kept in process, the least
+	// recently used being evicted first. Defaults to 10000.
+	L1Size int
+
+	// L1TTL bounds how long an entry is served from process memory, and so
+	// how stale it can get should an invalidation be missed. Defaults to 5
+	// seconds.
+	L1TTL time.Duration
+}
+
+// TieredStore serves reads from an in-process LRU cache (L1) in front of
+// Redis (L2). Stores created with Tiered announce their writes over pub/sub,
+// so every other instance drops its L1 copy of the key; writes made directly
+// through the Client, such as PutMany or Increment, are not announced and may
+// be served stale for up to L1TTL. Stores created with Tracking are instead
+// told about every change by Redis itself.
+type TieredStore struct {
+	client *Client
+	l1     *lruCache
+	l1TTL  time.Duration
+
+	id      string
+	channel string
+	pubsub  *redis.PubSub
+	done    chan struct{}
+
+	// tracking is set for stores invalidated by Redis, listening on their
+	// own connection
+	tracking bool
+	listener *redis.Client
+
+	closeOnce sync.Once
+}
+
+var _ cache.Store = (*TieredStore)(nil)
+
+// Tiered returns a store caching reads from c in process, subscribing to
+// invalidations from other instances. Closing the store closes c.
+func (c *Client) Tiered(ctx context.Context, cfg TieredConfig) (*TieredStore, error) {
+	size := cfg.L1Size
+	if size <= 0 {
+		size = defaultL1Size
+	}
+	ttl := cfg.L1TTL
+	if ttl <= 0 {
+		ttl = defaultL1TTL
+	}
+
+	id, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	channel := c.key(tieredChannel)
+	pubsub := c.client.Subscribe(ctx, channel)
+	// Wait for the subscription so no invalidation is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to invalidations: %w", err)
+	}
+
+	t := &TieredStore{
+		client:  c,
+		l1:      newLRUCache(size),
+		l1TTL:   ttl,
+		id:      id,
+		channel: channel,
+		pubsub:  pubsub,
+		done:    make(chan struct{}),
+	}
+	go t.listen()
+
+	return t, nil
+}
+
+// listen applies invalidations announced by other instances until the
+// subscription is closed
+func (t *TieredStore) listen() {
+	defer close(t.done)
+
+	for msg := range t.pubsub.ChannelWithSubscriptions() {
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			// Invalidations may have been missed while reconnecting
+			t.l1.purge()
+		case *redis.Message:
+			if t.tracking {
+				t.trackingInvalidate(msg)
+				continue
+			}
+			parts := strings.SplitN(msg.Payload, " ", 3)
+			if len(parts) < 2 || parts[0] == t.id {
+				continue
+			}
+			switch {
+			case parts[1] == tieredFlush:
+				t.l1.purge()
+			case parts[1] == tieredForget && len(parts) == 3:
+				t.l1.remove(parts[2])
+			}
+		}
+	}
+}
+
+// invalidate drops key from L1 and tells the other instances to do the same
+func (t *TieredStore) invalidate(ctx context.Context, key string) {
+	t.l1.remove(key)
+	if !t.tracking {
+		t.client.client.Publish(ctx, t.channel, t.id+" "+tieredForget+" "+key)
+	}
+}
+
+// invalidateAll empties L1 and tells the other instances to do the same
+func (t *TieredStore) invalidateAll(ctx context.Context) {
+	t.l1.purge()
+	if !t.tracking {
+		t.client.client.Publish(ctx, t.channel, t.id+" "+tieredFlush)
+	}
+}
+
+// cache keeps a value just written in L1. Tracking stores leave it to the
+// next read, as Redis is about to report the write as an invalidation.
+func (t *TieredStore) cache(key, value string, ttl time.Duration) {
+	if !t.tracking {
+		t.l1.set(key, value, ttl)
+	}
+}
+
+// ttl returns how long a value written with ttl may be kept in L1
+func (t *TieredStore) ttl(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < t.l1TTL {
+		return ttl
+	}
+	return t.l1TTL
+}
+
+// Get retrieves an item from L1, or from Redis when it is not cached
+// locally. Items read from Redis are kept in L1 no longer than they remain
+// in Redis.
+func (t *TieredStore) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := t.l1.get(key); ok {
+		return value, nil
+	}
+
+	version := t.l1.current()
+	value, remaining, err := t.client.getWithTTL(ctx, key)
+	if err != nil {
+		return "", hideCachedMiss(err)
+	}
+	t.l1.setIfCurrent(version, key, value, t.ttl(remaining))
+	return value, nil
+}
+
+// Put stores an item in Redis and L1 for a given duration
+func (t *TieredStore) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := t.client.Put(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	t.invalidate(ctx, key)
+	t.cache(key, value, t.ttl(ttl))
+	return nil
+}
+
+// Has checks if an item exists in L1 or Redis
+func (t *TieredStore) Has(ctx context.Context, key string) (bool, error) {
+	if _, ok := t.l1.get(key); ok {
+		return true, nil
+	}
+	return t.client.Has(ctx, key)
+}
+
+// Remember gets an item from L1 or Redis, or stores the encoded result of
+// the callback
+func (t *TieredStore) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	if value, ok := t.l1.get(key); ok {
+		return value, nil
+	}
+
+	version := t.l1.current()
+	value, err := t.client.Remember(ctx, key, ttl, callback)
+	if err != nil {
+		return "", err
+	}
+	// The item may have been stored earlier, or with a jittered TTL, so it
+	// is kept in L1 for no longer than it remains in Redis
+	if remaining, err := t.client.GetTTL(ctx, key); err == nil {
+		t.l1.setIfCurrent(version, key, value, t.ttl(remaining))
+	}
+	return value, nil
+}
+
+// Pull retrieves and deletes an item
+func (t *TieredStore) Pull(ctx context.Context, key string) (string, error) {
+	value, err := t.client.Pull(ctx, key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return "", err
+	}
+	t.invalidate(ctx, key)
+	return value, err
+}
+
+// Forever stores an item in Redis permanently, and in L1 for L1TTL
+func (t *TieredStore) Forever(ctx context.Context, key, value string) error {
+	if err := t.client.Forever(ctx, key, value); err != nil {
+		return err
+	}
+	t.invalidate(ctx, key)
+	t.cache(key, value, t.l1TTL)
+	return nil
+}
+
+// Forget removes an item from Redis and every instance's L1
+func (t *TieredStore) Forget(ctx context.Context, key string) error {
+	if err := t.client.Forget(ctx, key); err != nil {
+		return err
+	}
+	t.invalidate(ctx, key)
+	return nil
+}
+
+// Flush removes all items from Redis and every instance's L1
+func (t *TieredStore) Flush(ctx context.Context) error {
+	if err := t.client.Flush(ctx); err != nil {
+		return err
+	}
+	t.invalidateAll(ctx)
+	return nil
+}
+
+// Codec returns the client's codec
+func (t *TieredStore) Codec() cache.Codec {
+	return t.client.codec
+}
+
+// Close stops listening for invalidations and closes the underlying client
+func (t *TieredStore) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		err = t.pubsub.Close()
+		<-t.done
+		if t.listener != nil {
+			err = errors.Join(err, t.listener.Close())
+		}
+		err = errors.Join(err, t.client.Close())
+	})
+	return err
+}
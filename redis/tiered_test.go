@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredStore(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	first, err := client.Tiered(ctx, TieredConfig{L1TTL: time.Hour})
+	require.NoError(t, err)
+	defer first.Close()
+
+	second, err := newClientFor(t, mr, Config{}).Tiered(ctx, TieredConfig{L1TTL: time.Hour})
+	require.NoError(t, err)
+	defer second.Close()
+
+	t.Run("reads are served from L1", func(t *testing.T) {
+		mr.Set("hot", "value")
+
+		value, err := second.Get(ctx, "hot")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		// Changed behind the store's back, L1 still answers
+		mr.Set("hot", "changed")
+		value, err = second.Get(ctx, "hot")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("writes invalidate other instances", func(t *testing.T) {
+		mr.Set("shared", "old")
+		value, err := second.Get(ctx, "shared")
+		require.NoError(t, err)
+		require.Equal(t, "old", value)
+
+		require.NoError(t, first.Put(ctx, "shared", "new", time.Hour))
+		assert.Eventually(t, func() bool {
+			value, err := second.Get(ctx, "shared")
+			return err == nil && value == "new"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("forget invalidates other instances", func(t *testing.T) {
+		mr.Set("gone", "value")
+		_, err := second.Get(ctx, "gone")
+		require.NoError(t, err)
+
+		require.NoError(t, first.Forget(ctx, "gone"))
+		assert.Eventually(t, func() bool {
+			_, err := second.Get(ctx, "gone")
+			return err == ErrKeyNotFound
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("flush invalidates other instances", func(t *testing.T) {
+		mr.Set("flushed", "value")
+		_, err := second.Get(ctx, "flushed")
+		require.NoError(t, err)
+
+		require.NoError(t, first.Flush(ctx))
+		assert.Eventually(t, func() bool {
+			exists, err := second.Has(ctx, "flushed")
+			return err == nil && !exists
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		calls := 0
+		callback := func() (interface{}, error) {
+			calls++
+			return "computed", nil
+		}
+
+		value, err := first.Remember(ctx, "remembered", time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, `"computed"`, value)
+
+		value, err = first.Remember(ctx, "remembered", time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, `"computed"`, value)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("pull", func(t *testing.T) {
+		require.NoError(t, first.Put(ctx, "pulled", "value", time.Hour))
+
+		value, err := first.Pull(ctx, "pulled")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		_, err = first.Get(ctx, "pulled")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("L1 entries do not outlive short TTLs", func(t *testing.T) {
+		require.NoError(t, first.Put(ctx, "short", "value", 10*time.Millisecond))
+		time.Sleep(20 * time.Millisecond)
+		mr.FastForward(time.Second)
+
+		_, err := first.Get(ctx, "short")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("reads from Redis do not outlive the remaining TTL", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "filled", "value", 50*time.Millisecond))
+
+		value, err := second.Get(ctx, "filled")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		time.Sleep(60 * time.Millisecond)
+		mr.FastForward(time.Second)
+
+		_, err = second.Get(ctx, "filled")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("remembered items do not outlive the remaining TTL", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "stored", "value", 50*time.Millisecond))
+
+		value, err := second.Remember(ctx, "stored", time.Hour, func() (interface{}, error) {
+			return "computed", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		time.Sleep(60 * time.Millisecond)
+		mr.FastForward(time.Second)
+
+		_, err = second.Get(ctx, "stored")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate generates a self-signed certificate valid for
+// 127.0.0.1 and writes it and its key to PEM files in dir
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gofacades test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+// setupTestRedisTLS starts a mock Redis server that only accepts TLS
+// connections, returning it with the certificate and key it serves
+func setupTestRedisTLS(t *testing.T) (*miniredis.Miniredis, string, string) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+
+	mr, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return mr, certFile, keyFile
+}
+
+func TestClient_TLS(t *testing.T) {
+	ctx := context.Background()
+	mr, certFile, keyFile := setupTestRedisTLS(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	t.Run("verifies the server against a CA file", func(t *testing.T) {
+		client, err := New(Config{Host: "127.0.0.1", Port: port, TLSCAFile: certFile})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		val, err := client.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+	})
+
+	t.Run("unknown server certificate is rejected", func(t *testing.T) {
+		_, err := New(Config{Host: "127.0.0.1", Port: port, TLS: true})
+		assert.Error(t, err)
+	})
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		client, err := New(Config{Host: "127.0.0.1", Port: port, TLSInsecureSkipVerify: true})
+		require.NoError(t, err)
+		client.Close()
+	})
+
+	t.Run("custom tls config", func(t *testing.T) {
+		pemData, err := os.ReadFile(certFile)
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(pemData))
+
+		client, err := New(Config{Host: "127.0.0.1", Port: port, TLSConfig: &tls.Config{RootCAs: pool}})
+		require.NoError(t, err)
+		client.Close()
+	})
+
+	t.Run("plaintext connection fails", func(t *testing.T) {
+		_, err := New(Config{Host: "127.0.0.1", Port: port})
+		assert.Error(t, err)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		cfg := Config{TLSCAFile: certFile, TLSCertFile: certFile, TLSKeyFile: keyFile}
+		tlsConfig, err := cfg.tlsConfig()
+		require.NoError(t, err)
+		assert.Len(t, tlsConfig.Certificates, 1)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := Config{TLSCertFile: certFile}.tlsConfig()
+		assert.Error(t, err)
+
+		_, err = Config{TLSCAFile: filepath.Join(t.TempDir(), "missing.pem")}.tlsConfig()
+		assert.Error(t, err)
+
+		tlsConfig, err := Config{}.tlsConfig()
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+}
@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// trackingChannel is where Redis publishes invalidations for connections
+// redirecting their tracking to a subscriber
+const trackingChannel = "__redis__:invalidate"
+
+// TrackingConfig configures a store using server-assisted client-side
+// caching
+type TrackingConfig struct {
+	// Size is the maximum number of entries kept in process, the least
+	// recently used being evicted first. Defaults to 10000.
+	Size int
+
+	// TTL bounds how long an entry is served from process memory. Defaults
+	// to 5 seconds.
+	TTL time.Duration
+}
+
+// enableTracking turns on broadcast tracking for keys under prefix on cn,
+// redirecting invalidations to cn itself
+var enableTracking = func(ctx context.Context, cn *redis.Conn, prefix string) error {
+	id, err := cn.ClientID(ctx).Result()
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{"CLIENT", "TRACKING", "ON", "REDIRECT", id, "BCAST"}
+	if prefix != "" {
+		args = append(args, "PREFIX", prefix)
+	}
+	cmd := redis.NewStatusCmd(ctx, args...)
+	if err := cn.Process(ctx, cmd); err != nil {
+		return err
+	}
+	return cmd.Err()
+}
+
+// Tracking returns a store caching reads from c in process using Redis 6
+// client-side caching. A dedicated connection enables tracking in broadcast
+// mode for the client prefix and subscribes to the invalidations, so Redis
+// reports every change to a cached key, whoever made it and including
+// expiries and evictions. Tracking is re-enabled and the local cache emptied
+// whenever that connection is re-established.
+//
+// The invalidations are read over RESP2 redirection rather than RESP3 push
+// messages, which go-redis does not expose. Without a prefix Redis reports
+// changes to every key in the server. Cluster mode is not supported. Closing
+// the store closes c.
+func (c *Client) Tracking(ctx context.Context, cfg TrackingConfig) (*TieredStore, error) {
+	primary, ok := c.client.(*redis.Client)
+	if !ok {
+		return nil, ErrClusterUnsupported
+	}
+
+	size := cfg.Size
+	if size <= 0 {
+		size = defaultL1Size
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultL1TTL
+	}
+
+	opts := *primary.Options()
+	opts.PoolSize = 1
+	onConnect := opts.OnConnect
+	opts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		if onConnect != nil {
+			if err := onConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		return enableTracking(ctx, cn, c.prefix)
+	}
+	listener := redis.NewClient(&opts)
+
+	pubsub := listener.Subscribe(ctx, trackingChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		listener.Close()
+		return nil, fmt.Errorf("failed to enable client tracking: %w", err)
+	}
+
+	t := &TieredStore{
+		client:   c,
+		l1:       newLRUCache(size),
+		l1TTL:    ttl,
+		channel:  trackingChannel,
+		pubsub:   pubsub,
+		done:     make(chan struct{}),
+		tracking: true,
+		listener: listener,
+	}
+	go t.listen()
+
+	return t, nil
+}
+
+// trackingInvalidate drops the keys named in a Redis invalidation message
+// from L1. A message without keys follows a FLUSHALL or FLUSHDB.
+func (t *TieredStore) trackingInvalidate(msg *redis.Message) {
+	keys := msg.PayloadSlice
+	if len(keys) == 0 && msg.Payload != "" {
+		keys = []string{msg.Payload}
+	}
+	if len(keys) == 0 {
+		t.l1.purge()
+		return
+	}
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, t.client.prefix) {
+			t.l1.remove(strings.TrimPrefix(key, t.client.prefix))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracking replaces enableTracking for the duration of a test, as the
+// mock server does not implement CLIENT TRACKING. Invalidations are then
+// published by the test itself.
+func fakeTracking(t *testing.T) *atomic.Value {
+	var prefix atomic.Value
+	original := enableTracking
+	enableTracking = func(ctx context.Context, cn *redis.Conn, p string) error {
+		prefix.Store(p)
+		return nil
+	}
+	t.Cleanup(func() { enableTracking = original })
+	return &prefix
+}
+
+func TestClient_Tracking(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unsupported server", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		_, err := client.Tracking(ctx, TrackingConfig{})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalidations", func(t *testing.T) {
+		prefix := fakeTracking(t)
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+
+		store, err := client.Tracking(ctx, TrackingConfig{TTL: time.Hour})
+		require.NoError(t, err)
+		defer store.Close()
+		assert.Equal(t, "app:", prefix.Load())
+
+		mr.Set("app:key", "value")
+		value, err := store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		// Served from L1 until Redis reports the change
+		mr.Set("app:key", "changed")
+		value, err = store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		mr.Publish(trackingChannel, "app:key")
+		assert.Eventually(t, func() bool {
+			value, err := store.Get(ctx, "key")
+			return err == nil && value == "changed"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("writes are not cached until read", func(t *testing.T) {
+		fakeTracking(t)
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		store, err := client.Tracking(ctx, TrackingConfig{})
+		require.NoError(t, err)
+		defer store.Close()
+
+		require.NoError(t, store.Put(ctx, "key", "value", time.Hour))
+		assert.Zero(t, store.l1.len())
+
+		_, err = store.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, 1, store.l1.len())
+	})
+
+	t.Run("message payloads", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+		defer client.Close()
+
+		store := &TieredStore{client: client, l1: newLRUCache(10), tracking: true}
+		store.l1.set("a", "1", time.Hour)
+		store.l1.set("b", "2", time.Hour)
+
+		store.trackingInvalidate(&redis.Message{PayloadSlice: []string{"a"}})
+		_, ok := store.l1.get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, store.l1.len())
+
+		store.trackingInvalidate(&redis.Message{})
+		assert.Zero(t, store.l1.len())
+	})
+
+	t.Run("cluster mode", func(t *testing.T) {
+		client, _ := setupTestCluster(t, Config{})
+		defer client.Close()
+
+		_, err := client.Tracking(ctx, TrackingConfig{})
+		assert.Equal(t, ErrClusterUnsupported, err)
+	})
+}
@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tx is a transaction started by Client.Transaction. Reads run immediately,
+// so they see the watched keys as they are before any write; writes are
+// queued and applied together with MULTI/EXEC once the transaction function
+// returns, their results being filled in then.
+type Tx struct {
+	client *Client
+	tx     *redis.Tx
+	ops    *Pipeline
+}
+
+// Transaction runs fn and applies the writes it queues atomically. When
+// keys are given they are watched first: should another client change one
+// of them before the writes are applied, nothing is written and
+// ErrTxConflict is returned, so the caller can retry with fresh reads. An
+// error returned by fn, or by a write that could not be queued such as a
+// value failing to encode, aborts the transaction without writing anything.
+// In cluster mode and behind a Ring at least one key must be given, or
+// ErrNoKeys is returned, and every key involved must hash to the same slot.
+func (c *Client) Transaction(ctx context.Context, fn func(tx *Tx) error, keys ...string) error {
+	if fn == nil {
+		return ErrNilCallback
+	}
+	if len(keys) == 0 && c.sharded() {
+		// The watched keys pick the node the transaction runs on
+		return ErrNoKeys
+	}
+
+	err := c.client.Watch(ctx, func(rtx *redis.Tx) error {
+		tx := &Tx{
+			client: c,
+			tx:     rtx,
+			ops:    &Pipeline{client: c, pipe: rtx.TxPipeline()},
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.ops.err; err != nil {
+			tx.ops.pipe.Discard()
+			return err
+		}
+		return tx.ops.Exec(ctx)
+	}, c.keys(keys)...)
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrTxConflict
+	}
+	return err
+}
+
+// Get retrieves an item immediately
+func (tx *Tx) Get(ctx context.Context, key string) (string, error) {
+	value, err := tx.tx.Get(ctx, tx.client.key(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	value, err = tx.client.decodeValue(key, value)
+	return value, hideCachedMiss(err)
+}
+
+// Has checks immediately if an item exists
+func (tx *Tx) Has(ctx context.Context, key string) (bool, error) {
+	exists, err := tx.tx.Exists(ctx, tx.client.key(key)).Result()
+	return exists > 0, err
+}
+
+// Put queues storing an item for a given duration
+func (tx *Tx) Put(ctx context.Context, key, value string, ttl time.Duration) *Result[struct{}] {
+	return tx.ops.Put(ctx, key, value, ttl)
+}
+
+// Add queues storing an item only if the key does not already exist
+func (tx *Tx) Add(ctx context.Context, key, value string, ttl time.Duration) *Result[bool] {
+	return tx.ops.Add(ctx, key, value, ttl)
+}
+
+// Forever queues storing an item permanently
+func (tx *Tx) Forever(ctx context.Context, key, value string) *Result[struct{}] {
+	return tx.ops.Forever(ctx, key, value)
+}
+
+// Forget queues the removal of an item, leaving its dependents in place
+func (tx *Tx) Forget(ctx context.Context, key string) *Result[bool] {
+	return tx.ops.Forget(ctx, key)
+}
+
+// Increment queues incrementing the integer stored at key
+func (tx *Tx) Increment(ctx context.Context, key string, by int64) *Result[int64] {
+	return tx.ops.Increment(ctx, key, by)
+}
+
+// Decrement queues decrementing the integer stored at key
+func (tx *Tx) Decrement(ctx context.Context, key string, by int64) *Result[int64] {
+	return tx.ops.Decrement(ctx, key, by)
+}
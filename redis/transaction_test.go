@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Transaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies queued writes", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{Prefix: "app:"})
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "balance:a", "100", time.Hour))
+		require.NoError(t, client.Put(ctx, "balance:b", "0", time.Hour))
+
+		var incr *Result[int64]
+		err := client.Transaction(ctx, func(tx *Tx) error {
+			value, err := tx.Get(ctx, "balance:a")
+			if err != nil {
+				return err
+			}
+			balance, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+
+			tx.Put(ctx, "balance:a", strconv.Itoa(balance-30), time.Hour)
+			incr = tx.Increment(ctx, "balance:b", 30)
+			tx.Forget(ctx, "pending")
+			return nil
+		}, "balance:a", "balance:b")
+		require.NoError(t, err)
+
+		value, err := client.Get(ctx, "balance:a")
+		require.NoError(t, err)
+		assert.Equal(t, "70", value)
+		assert.Equal(t, int64(30), incr.Val())
+	})
+
+	t.Run("a changed watched key aborts", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		require.NoError(t, client.Put(ctx, "version", "1", time.Hour))
+
+		err := client.Transaction(ctx, func(tx *Tx) error {
+			exists, err := tx.Has(ctx, "version")
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			// Another client writes between the read and EXEC
+			require.NoError(t, client.Put(ctx, "version", "2", time.Hour))
+
+			tx.Put(ctx, "version", "3", time.Hour)
+			tx.Put(ctx, "other", "value", time.Hour)
+			return nil
+		}, "version")
+		assert.Equal(t, ErrTxConflict, err)
+
+		value, err := client.Get(ctx, "version")
+		require.NoError(t, err)
+		assert.Equal(t, "2", value)
+		assert.False(t, mr.Exists("other"))
+	})
+
+	t.Run("an error from fn aborts", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		failure := errors.New("invariant violated")
+		err := client.Transaction(ctx, func(tx *Tx) error {
+			tx.Put(ctx, "key", "value", time.Hour)
+			return failure
+		})
+		assert.Equal(t, failure, err)
+		assert.False(t, mr.Exists("key"))
+	})
+
+	t.Run("a write failing to queue aborts", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{CompressionThreshold: 1})
+		defer mr.Close()
+
+		var put *Result[struct{}]
+		err := client.Transaction(ctx, func(tx *Tx) error {
+			tx.Forever(ctx, "first", "value")
+			// An unknown algorithm makes the next value fail to encode
+			client.compression = Compression(-1)
+			put = tx.Put(ctx, "second", "value", time.Hour)
+			return nil
+		})
+		require.Error(t, err)
+		assert.Equal(t, err, put.Err())
+		assert.False(t, mr.Exists("first"))
+		assert.False(t, mr.Exists("second"))
+	})
+
+	t.Run("sharded clients need keys", func(t *testing.T) {
+		client := NewFromClient(redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{"127.0.0.1:0"}}))
+		defer client.Close()
+
+		err := client.Transaction(ctx, func(tx *Tx) error { return nil })
+		assert.Equal(t, ErrNoKeys, err)
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		var added *Result[bool]
+		err := client.Transaction(ctx, func(tx *Tx) error {
+			_, err := tx.Get(ctx, "missing")
+			assert.Equal(t, ErrKeyNotFound, err)
+
+			added = tx.Add(ctx, "missing", "value", time.Hour)
+			tx.Forever(ctx, "forever", "value")
+			tx.Decrement(ctx, "counter", 1)
+			return nil
+		}, "missing")
+		require.NoError(t, err)
+		assert.True(t, added.Val())
+		assert.Zero(t, mr.TTL("forever"))
+	})
+
+	t.Run("nil function", func(t *testing.T) {
+		client, mr := setupTestRedis(t)
+		defer mr.Close()
+
+		assert.Equal(t, ErrNilCallback, client.Transaction(ctx, nil))
+	})
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+// DecodeError is returned when a cached value cannot be decoded into the
+// requested type
+type DecodeError = cache.DecodeError
+
+// Codec returns the codec Remember, PutAny and GetAs encode values with
+func (c *Client) Codec() cache.Codec {
+	return c.codec
+}
+
+// PutAny encodes value with the configured codec and stores it in the cache
+// for a given duration. It is the write side of GetAs.
+func (c *Client) PutAny(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	encoded, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return c.Put(ctx, key, string(encoded), ttl)
+}
+
+// ForeverAny encodes value with the configured codec and stores it in the
+// cache permanently
+func (c *Client) ForeverAny(ctx context.Context, key string, value interface{}) error {
+	return c.PutAny(ctx, key, value, 0)
+}
+
+// GetAs retrieves an item from the cache and decodes it into T with the
+// client's codec
+func GetAs[T any](ctx context.Context, c *Client, key string) (T, error) {
+	var result T
+
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return result, err
+	}
+
+	if err := c.codec.Unmarshal([]byte(value), &result); err != nil {
+		return result, &DecodeError{Key: key, Err: err}
+	}
+
+	return result, nil
+}
+
+// GetAsOr is GetAs returning fallback when the key does not exist
+func GetAsOr[T any](ctx context.Context, c *Client, key string, fallback T) (T, error) {
+	result, err := GetAs[T](ctx, c, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fallback, nil
+	}
+	return result, err
+}
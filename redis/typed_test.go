@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+func TestGetAs(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("decode struct", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "test-key", `{"name":"test","value":123}`, time.Hour))
+
+		result, err := GetAs[testStruct](ctx, client, "test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+	})
+
+	t.Run("decode value stored by Remember", func(t *testing.T) {
+		_, err := client.Remember(ctx, "remembered", time.Hour, func() (interface{}, error) {
+			return []int{1, 2, 3}, nil
+		})
+		require.NoError(t, err)
+
+		result, err := GetAs[[]int](ctx, client, "remembered")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("non-existent key", func(t *testing.T) {
+		result, err := GetAs[testStruct](ctx, client, "non-existent-key")
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Zero(t, result)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "invalid", "not json", time.Hour))
+
+		_, err := GetAs[testStruct](ctx, client, "invalid")
+		var decodeErr *DecodeError
+		require.True(t, errors.As(err, &decodeErr))
+		assert.Equal(t, "invalid", decodeErr.Key)
+		assert.Error(t, decodeErr.Unwrap())
+	})
+}
+
+func TestGetAsOr(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	fallback := testStruct{Name: "default"}
+
+	t.Run("existing key", func(t *testing.T) {
+		require.NoError(t, client.PutAny(ctx, "stored", testStruct{Name: "stored", Value: 1}, time.Hour))
+
+		result, err := GetAsOr(ctx, client, "stored", fallback)
+		require.NoError(t, err)
+		assert.Equal(t, testStruct{Name: "stored", Value: 1}, result)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		result, err := GetAsOr(ctx, client, "missing", fallback)
+		require.NoError(t, err)
+		assert.Equal(t, fallback, result)
+	})
+
+	t.Run("decode errors are returned", func(t *testing.T) {
+		require.NoError(t, client.Put(ctx, "invalid", "not json", time.Hour))
+
+		_, err := GetAsOr(ctx, client, "invalid", fallback)
+		var decodeErr *DecodeError
+		assert.True(t, errors.As(err, &decodeErr))
+	})
+}
+
+func TestClient_PutAny(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	t.Run("round trips through GetAs", func(t *testing.T) {
+		err := client.PutAny(ctx, "test-key", testStruct{Name: "test", Value: 123}, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, mr.TTL("test-key"))
+
+		result, err := GetAs[testStruct](ctx, client, "test-key")
+		require.NoError(t, err)
+		assert.Equal(t, testStruct{Name: "test", Value: 123}, result)
+	})
+
+	t.Run("forever", func(t *testing.T) {
+		err := client.ForeverAny(ctx, "numbers", []int{1, 2, 3})
+		require.NoError(t, err)
+		assert.Zero(t, mr.TTL("numbers"))
+
+		val, err := client.Get(ctx, "numbers")
+		require.NoError(t, err)
+		assert.Equal(t, "[1,2,3]", val)
+	})
+
+	t.Run("unencodable value", func(t *testing.T) {
+		err := client.PutAny(ctx, "bad", make(chan int), time.Hour)
+		assert.Error(t, err)
+		assert.False(t, mr.Exists("bad"))
+	})
+}
+
+func TestClient_Codec(t *testing.T) {
+	ctx := context.Background()
+
+	for name, codec := range map[string]cache.Codec{
+		"gob":     cache.GobCodec{},
+		"msgpack": cache.MsgpackCodec{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, mr := setupTestRedisWith(t, Config{Codec: codec})
+			defer mr.Close()
+
+			want := testStruct{Name: "test", Value: 123}
+			val, err := client.Remember(ctx, "remembered", time.Hour, func() (interface{}, error) {
+				return want, nil
+			})
+			require.NoError(t, err)
+
+			encoded, err := codec.Marshal(want)
+			require.NoError(t, err)
+			assert.Equal(t, string(encoded), val)
+
+			result, err := GetAs[testStruct](ctx, client, "remembered")
+			require.NoError(t, err)
+			assert.Equal(t, want, result)
+
+			require.NoError(t, client.PutAny(ctx, "put", want, time.Hour))
+			result, err = GetAs[testStruct](ctx, client, "put")
+			require.NoError(t, err)
+			assert.Equal(t, want, result)
+		})
+	}
+}
+
+func TestClient_RememberAs(t *testing.T) {
+	client, mr := setupTestRedisWith(t, Config{Codec: cache.MsgpackCodec{}})
+	defer mr.Close()
+
+	ctx := context.Background()
+
+	type profile struct {
+		Name  string
+		Score int
+	}
+
+	assert.Equal(t, cache.MsgpackCodec{}, client.Codec())
+
+	calls := 0
+	load := func() (profile, error) {
+		calls++
+		return profile{Name: "Ada", Score: 42}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		p, err := cache.RememberAs(ctx, client, "profile", time.Hour, load)
+		require.NoError(t, err)
+		assert.Equal(t, profile{Name: "Ada", Score: 42}, p)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromURL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("database and options", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+
+		client, err := NewFromURL(fmt.Sprintf("redis://%s/2?dial_timeout=2s&read_timeout=1s", mr.Addr()))
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		val, err := mr.DB(2).Get("key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+		assert.False(t, mr.Exists("key"))
+		assert.Equal(t, 2, client.db)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+		mr.RequireUserAuth("app", "secret")
+
+		_, err = NewFromURL(fmt.Sprintf("redis://%s", mr.Addr()))
+		assert.Error(t, err)
+
+		client, err := NewFromURL(fmt.Sprintf("redis://app:secret@%s", mr.Addr()))
+		require.NoError(t, err)
+		client.Close()
+	})
+
+	t.Run("rediss uses TLS", func(t *testing.T) {
+		mr, certFile, _ := setupTestRedisTLS(t)
+
+		client, err := New(Config{URL: fmt.Sprintf("rediss://%s", mr.Addr()), TLSCAFile: certFile})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, mr.Exists("key"))
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+
+		client, err := New(Config{URL: fmt.Sprintf("redis://%s", mr.Addr()), Cluster: true})
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Put(ctx, "key", "value", time.Hour))
+		assert.True(t, mr.Exists("key"))
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		_, err := NewFromURL("http://localhost:6379")
+		assert.Error(t, err)
+
+		_, err = NewFromURL("redis://localhost:6379/not-a-db")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// deltaKeyPrefix namespaces the keys recording how long a value took to
+// compute, used by early expiration
+const deltaKeyPrefix = "gofacades:delta:"
+
+// deltaKey returns the key recording how long the value of key took to compute
+func deltaKey(key string) string {
+	return deltaKeyPrefix + key
+}
+
+// rememberEarly is Remember with probabilistic early expiration, following
+// the XFetch algorithm: a cached value is recomputed once
+// -delta * beta * ln(rand) exceeds its remaining TTL, where delta is how long
+// the value took to compute.
+func (c *Client) rememberEarly(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	var get, delta *redis.StringCmd
+	var remaining *redis.DurationCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.key(key))
+		remaining = pipe.PTTL(ctx, c.key(key))
+		delta = pipe.Get(ctx, c.key(deltaKey(key)))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	value, err := get.Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	hit := err == nil
+	if hit {
+		if value, err = c.decodeValue(key, value); err != nil {
+			return "", hideCachedMiss(err)
+		}
+	}
+	if hit && !c.expireEarly(delta.Val(), remaining.Val()) {
+		return value, nil
+	}
+
+	if callback == nil {
+		if hit {
+			return value, nil
+		}
+		return "", ErrNilCallback
+	}
+
+	result, err := c.share(ctx, key, func(ctx context.Context) (string, error) {
+		if hit {
+			// The value is still valid, so recompute it without waiting on
+			// other processes
+			return c.store(ctx, key, ttl, callback)
+		}
+		return c.compute(ctx, key, ttl, callback)
+	})
+	if err != nil {
+		if hit {
+			// Keep serving the current value when an early recompute fails
+			return value, nil
+		}
+		return "", err
+	}
+	return result, nil
+}
+
+// expireEarly reports whether a value with the given remaining TTL, whose
+// computation took delta milliseconds, should be recomputed now
+func (c *Client) expireEarly(delta string, remaining time.Duration) bool {
+	ms, err := strconv.ParseInt(delta, 10, 64)
+	if err != nil || ms <= 0 || remaining <= 0 {
+		return false
+	}
+
+	gap := -float64(ms) * c.earlyExpiration * math.Log(1-rand.Float64())
+	return gap >= float64(remaining.Milliseconds())
+}
+
+// putWithDelta stores value under key along with how long it took to compute
+func (c *Client) putWithDelta(ctx context.Context, key, value string, ttl, delta time.Duration) error {
+	ms := delta.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+
+	encoded, err := c.encodeValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	ttl = c.jitter(ttl)
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.key(key), encoded, ttl)
+		pipe.Set(ctx, c.key(deltaKey(key)), ms, ttl)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RememberEarlyExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	callback := func() (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return atomic.AddInt32(&calls, 1), nil
+	}
+
+	t.Run("records the computation time", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1})
+		defer mr.Close()
+
+		val, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		assert.NotEmpty(t, val)
+
+		delta, err := mr.Get(deltaKey("report"))
+		require.NoError(t, err)
+		assert.NotEqual(t, "0", delta)
+		assert.Equal(t, time.Hour, mr.TTL(deltaKey("report")))
+	})
+
+	t.Run("far from expiry the cached value is served", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1})
+		defer mr.Close()
+
+		first, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		second, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("close to expiry the value is recomputed early", func(t *testing.T) {
+		// A huge beta makes early recomputation all but certain
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1e9})
+		defer mr.Close()
+
+		first, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		second, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, time.Hour, mr.TTL("report"))
+	})
+
+	t.Run("failed early recompute serves the cached value", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1e9})
+		defer mr.Close()
+
+		first, err := client.Remember(ctx, "report", time.Hour, callback)
+		require.NoError(t, err)
+		second, err := client.Remember(ctx, "report", time.Hour, func() (interface{}, error) {
+			return nil, errors.New("backend down")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("values without a TTL never expire early", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1e9})
+		defer mr.Close()
+
+		first, err := client.RememberForever(ctx, "report", callback)
+		require.NoError(t, err)
+		second, err := client.RememberForever(ctx, "report", callback)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.False(t, mr.Exists(deltaKey("report")))
+	})
+
+	t.Run("nil callback", func(t *testing.T) {
+		client, mr := setupTestRedisWith(t, Config{EarlyExpiration: 1})
+		defer mr.Close()
+
+		_, err := client.Remember(ctx, "missing", time.Hour, nil)
+		assert.Equal(t, ErrNilCallback, err)
+	})
+}
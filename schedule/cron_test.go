@@ -0,0 +1,125 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at returns the given minute of 2024 in UTC. 1 January 2024 is a Monday.
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseCron(t *testing.T) {
+	t.Run("valid expressions", func(t *testing.T) {
+		for _, expr := range []string{
+			"* * * * *",
+			"*/15 * * * *",
+			"0 9-17 * * 1-5",
+			"0,30 8,12,18 * * *",
+			"0-30/10 * * * *",
+			"5/20 * * * *",
+			"0 0 1 JAN,jul *",
+			"0 0 * * MON-FRI",
+			"0 0 * * 7",
+			"@daily",
+			" @HOURLY ",
+		} {
+			_, err := ParseCron(expr)
+			assert.NoError(t, err, expr)
+		}
+	})
+
+	t.Run("invalid expressions", func(t *testing.T) {
+		for _, expr := range []string{
+			"",
+			"* * * *",
+			"* * * * * *",
+			"60 * * * *",
+			"* 24 * * *",
+			"* * 0 * *",
+			"* * * 13 *",
+			"* * * * 8",
+			"5-1 * * * *",
+			"*/0 * * * *",
+			"*/x * * * *",
+			"a * * * *",
+			"1-x * * * *",
+			"@sometimes",
+		} {
+			_, err := ParseCron(expr)
+			assert.ErrorIs(t, err, ErrInvalidCron, expr)
+		}
+	})
+}
+
+func TestCron_Matches(t *testing.T) {
+	tests := []struct {
+		expr  string
+		time  time.Time
+		match bool
+	}{
+		{"* * * * *", at(time.March, 5, 13, 37), true},
+		{"*/15 * * * *", at(time.March, 5, 13, 45), true},
+		{"*/15 * * * *", at(time.March, 5, 13, 46), false},
+		{"5/20 * * * *", at(time.March, 5, 13, 25), true},
+		{"5/20 * * * *", at(time.March, 5, 13, 5), true},
+		{"5/20 * * * *", at(time.March, 5, 13, 15), false},
+		{"0 9-17 * * MON-FRI", at(time.January, 1, 9, 0), true},
+		{"0 9-17 * * MON-FRI", at(time.January, 6, 9, 0), false},
+		{"0 9-17 * * MON-FRI", at(time.January, 1, 18, 0), false},
+		{"0 0 * * 7", at(time.January, 7, 0, 0), true},
+		{"0 0 * * 0", at(time.January, 7, 0, 0), true},
+		{"0 0 1 jul *", at(time.July, 1, 0, 0), true},
+		{"0 0 1 jul *", at(time.June, 1, 0, 0), false},
+		// Restricted days of the month and of the week match either
+		{"0 0 13 * 5", at(time.January, 13, 0, 0), true},
+		{"0 0 13 * 5", at(time.January, 5, 0, 0), true},
+		{"0 0 13 * 5", at(time.January, 6, 0, 0), false},
+		{"@monthly", at(time.April, 1, 0, 0), true},
+		{"@monthly", at(time.April, 2, 0, 0), false},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		require.NoError(t, err)
+		assert.Equal(t, tt.match, cron.Matches(tt.time), "%s at %s", tt.expr, tt.time)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		next time.Time
+	}{
+		{"* * * * *", at(time.March, 5, 13, 37).Add(30 * time.Second), at(time.March, 5, 13, 38)},
+		{"* * * * *", at(time.March, 5, 13, 37), at(time.March, 5, 13, 38)},
+		{"*/15 * * * *", at(time.March, 5, 13, 46), at(time.March, 5, 14, 0)},
+		{"30 2 * * *", at(time.March, 5, 13, 0), at(time.March, 6, 2, 30)},
+		{"0 9 * * MON", at(time.January, 1, 9, 0), at(time.January, 8, 9, 0)},
+		{"0 0 1 * *", at(time.December, 15, 0, 0), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", at(time.March, 1, 0, 0), time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", at(time.March, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		require.NoError(t, err)
+		assert.Equal(t, tt.next, cron.Next(tt.from), tt.expr)
+	}
+
+	t.Run("in the time's location", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip("time zone database unavailable")
+		}
+		cron, err := ParseCron("30 2 * * *")
+		require.NoError(t, err)
+
+		// 2:30 does not exist on the day clocks go forward
+		from := time.Date(2024, time.March, 9, 12, 0, 0, 0, loc)
+		assert.Equal(t, time.Date(2024, time.March, 11, 2, 30, 0, 0, loc), cron.Next(from))
+	})
+}
@@ -0,0 +1,233 @@
+// Package schedule runs tasks on cron schedules, in the manner of Laravel's
+// task scheduler. Tasks may be limited to one server, so that a fleet of
+// instances runs each of their ticks exactly once.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/redis"
+)
+
+const (
+	// defaultPrefix namespaces the locks of tasks running on one server when
+	// Config does not set a prefix
+	defaultPrefix = "schedule:"
+
+	// oneServerTTL is how long the lock claiming a tick is kept. It is never
+	// released, so that an instance whose clock lags cannot run the tick
+	// again, and must outlast any clock skew between instances.
+	oneServerTTL = time.Hour
+)
+
+var (
+	// ErrNilHandler is returned when adding a task without a handler
+	ErrNilHandler = errors.New("task handler is required")
+
+	// ErrDuplicateTask is returned when adding a task under a name already
+	// taken
+	ErrDuplicateTask = errors.New("a task with this name is already scheduled")
+
+	// ErrNoClient is reported for tasks running on one server when the
+	// scheduler has no Redis client to lock their ticks with
+	ErrNoClient = errors.New("tasks running on one server need a redis client")
+)
+
+// Handler runs a task for the tick it is due at
+type Handler func(ctx context.Context, tick time.Time) error
+
+// Config configures a Scheduler
+type Config struct {
+	// Prefix namespaces the locks of tasks running on one server, within
+	// the client's own prefix. Defaults to "schedule:".
+	Prefix string
+
+	// Location is the time zone cron expressions are evaluated in. Defaults
+	// to the local time zone.
+	Location *time.Location
+
+	// OnError is called with the tasks whose handler failed or panicked,
+	// and with the tasks running on one server whose tick could not be
+	// locked, which are then skipped
+	OnError func(task string, err error)
+}
+
+// Task is a handler registered with a schedule
+type Task struct {
+	name      string
+	cron      *Cron
+	handler   Handler
+	oneServer bool
+}
+
+// Name returns the name the task was added under
+func (t *Task) Name() string {
+	return t.name
+}
+
+// OnOneServer makes only one instance run each tick of the task: the first
+// to lock the tick in Redis. Instances should share the same clock, within
+// an hour. It must be called before the scheduler runs.
+func (t *Task) OnOneServer() *Task {
+	t.oneServer = true
+	return t
+}
+
+// Scheduler runs tasks when their cron expression is due
+type Scheduler struct {
+	client   *redis.Client
+	prefix   string
+	location *time.Location
+	onError  func(task string, err error)
+
+	mu    sync.Mutex
+	tasks []*Task
+	names map[string]bool
+
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// New returns a scheduler locking the ticks of tasks running on one server
+// with client, which may be nil when no task does
+func New(client *redis.Client, cfg Config) *Scheduler {
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	location := cfg.Location
+	if location == nil {
+		location = time.Local
+	}
+	return &Scheduler{
+		client:   client,
+		prefix:   prefix,
+		location: location,
+		onError:  cfg.OnError,
+		names:    make(map[string]bool),
+		now:      time.Now,
+	}
+}
+
+// Add schedules handler to run under name whenever expr, a cron expression
+// as accepted by ParseCron, is due. Names identify tasks across instances
+// and must be unique.
+func (s *Scheduler) Add(name, expr string, handler Handler) (*Task, error) {
+	if handler == nil {
+		return nil, ErrNilHandler
+	}
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.names[name] {
+		return nil, fmt.Errorf("%w: %q", ErrDuplicateTask, name)
+	}
+	task := &Task{name: name, cron: cron, handler: handler}
+	s.tasks = append(s.tasks, task)
+	s.names[name] = true
+	return task, nil
+}
+
+// Tasks returns the scheduled tasks, in the order they were added
+func (s *Scheduler) Tasks() []*Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Task(nil), s.tasks...)
+}
+
+// Next returns when the task next runs after t
+func (s *Scheduler) Next(task *Task, t time.Time) time.Time {
+	return task.cron.Next(t.In(s.location))
+}
+
+// Run runs the due tasks at the start of every minute until ctx is
+// cancelled, then waits for the running tasks to return. Each task runs on
+// its own goroutine, with ctx, so a task still running when its next tick
+// is due runs twice at once.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		now := s.now()
+		tick := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(tick.Sub(now)):
+		}
+		s.runDue(ctx, tick, &wg)
+	}
+}
+
+// runDue starts the tasks due at tick
+func (s *Scheduler) runDue(ctx context.Context, tick time.Time, wg *sync.WaitGroup) {
+	tick = tick.In(s.location)
+	for _, task := range s.Tasks() {
+		if !task.cron.Matches(tick) {
+			continue
+		}
+		wg.Add(1)
+		go func(task *Task) {
+			defer wg.Done()
+			s.run(ctx, task, tick)
+		}(task)
+	}
+}
+
+// run runs task for tick, once across instances when it runs on one server
+func (s *Scheduler) run(ctx context.Context, task *Task, tick time.Time) {
+	if task.oneServer {
+		claimed, err := s.claim(ctx, task, tick)
+		if err != nil {
+			s.report(task, err)
+			return
+		}
+		if !claimed {
+			return
+		}
+	}
+
+	if err := call(ctx, task, tick); err != nil {
+		s.report(task, err)
+	}
+}
+
+// claim locks tick of task for this instance, reporting whether it did
+func (s *Scheduler) claim(ctx context.Context, task *Task, tick time.Time) (bool, error) {
+	if s.client == nil {
+		return false, ErrNoClient
+	}
+	name := s.prefix + task.name + ":" + strconv.FormatInt(tick.Unix(), 10)
+	claimed, err := s.client.Lock(name, oneServerTTL).Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock tick: %w", err)
+	}
+	return claimed, nil
+}
+
+// call runs the task's handler, turning a panic into an error
+func call(ctx context.Context, task *Task, tick time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task %q panicked: %v", task.name, r)
+		}
+	}()
+	return task.handler(ctx, tick)
+}
+
+// report passes the failure of task to OnError
+func (s *Scheduler) report(task *Task, err error) {
+	if s.onError != nil {
+		s.onError(task.name, err)
+	}
+}
@@ -0,0 +1,214 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nanaaikinson/gofacades/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupClient returns a client on mr using the "app:" prefix
+func setupClient(t *testing.T, mr *miniredis.Miniredis) *redis.Client {
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+	client, err := redis.New(redis.Config{Host: mr.Host(), Port: port, Prefix: "app:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// tick runs the tasks of s due at tick and waits for them to return
+func tick(s *Scheduler, at time.Time) {
+	var wg sync.WaitGroup
+	s.runDue(context.Background(), at, &wg)
+	wg.Wait()
+}
+
+func TestScheduler_Add(t *testing.T) {
+	s := New(nil, Config{})
+	noop := func(context.Context, time.Time) error { return nil }
+
+	task, err := s.Add("prune", "@daily", noop)
+	require.NoError(t, err)
+	assert.Equal(t, "prune", task.Name())
+
+	_, err = s.Add("prune", "@hourly", noop)
+	assert.ErrorIs(t, err, ErrDuplicateTask)
+	_, err = s.Add("report", "not cron", noop)
+	assert.ErrorIs(t, err, ErrInvalidCron)
+	_, err = s.Add("report", "@hourly", nil)
+	assert.ErrorIs(t, err, ErrNilHandler)
+
+	assert.Equal(t, []*Task{task}, s.Tasks())
+}
+
+func TestScheduler_Next(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	s := New(nil, Config{Location: loc})
+	task, err := s.Add("report", "0 9 * * *", func(context.Context, time.Time) error { return nil })
+	require.NoError(t, err)
+
+	next := s.Next(task, at(time.March, 5, 6, 0))
+	assert.Equal(t, at(time.March, 5, 7, 0), next.UTC())
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	t.Run("runs the due tasks", func(t *testing.T) {
+		s := New(nil, Config{Location: time.UTC})
+
+		var mu sync.Mutex
+		var ran []string
+		var ticks []time.Time
+		record := func(name string) Handler {
+			return func(_ context.Context, tick time.Time) error {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+				ticks = append(ticks, tick)
+				return nil
+			}
+		}
+		_, err := s.Add("every-minute", "* * * * *", record("every-minute"))
+		require.NoError(t, err)
+		_, err = s.Add("quarter-hourly", "*/15 * * * *", record("quarter-hourly"))
+		require.NoError(t, err)
+		_, err = s.Add("daily", "@daily", record("daily"))
+		require.NoError(t, err)
+
+		tick(s, at(time.March, 5, 13, 1))
+		assert.Equal(t, []string{"every-minute"}, ran)
+
+		ran = nil
+		tick(s, at(time.March, 5, 13, 15))
+		assert.ElementsMatch(t, []string{"every-minute", "quarter-hourly"}, ran)
+		assert.Equal(t, at(time.March, 5, 13, 15), ticks[1])
+	})
+
+	t.Run("reports failures and panics", func(t *testing.T) {
+		var mu sync.Mutex
+		failures := map[string]error{}
+		s := New(nil, Config{OnError: func(task string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures[task] = err
+		}})
+
+		_, err := s.Add("failing", "* * * * *", func(context.Context, time.Time) error {
+			return errors.New("report failed")
+		})
+		require.NoError(t, err)
+		_, err = s.Add("panicking", "* * * * *", func(context.Context, time.Time) error {
+			panic("boom")
+		})
+		require.NoError(t, err)
+
+		tick(s, at(time.March, 5, 13, 1))
+		assert.EqualError(t, failures["failing"], "report failed")
+		assert.EqualError(t, failures["panicking"], `task "panicking" panicked: boom`)
+	})
+}
+
+func TestTask_OnOneServer(t *testing.T) {
+	t.Run("runs each tick once across instances", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+
+		var runs atomic.Int32
+		var schedulers []*Scheduler
+		for i := 0; i < 3; i++ {
+			s := New(setupClient(t, mr), Config{})
+			task, err := s.Add("report", "* * * * *", func(context.Context, time.Time) error {
+				runs.Add(1)
+				return nil
+			})
+			require.NoError(t, err)
+			task.OnOneServer()
+			schedulers = append(schedulers, s)
+		}
+
+		for _, minute := range []int{1, 2} {
+			var wg sync.WaitGroup
+			for _, s := range schedulers {
+				wg.Add(1)
+				go func(s *Scheduler) {
+					defer wg.Done()
+					tick(s, at(time.March, 5, 13, minute))
+				}(s)
+			}
+			wg.Wait()
+		}
+		assert.Equal(t, int32(2), runs.Load())
+		assert.True(t, mr.Exists("app:schedule:report:"+strconv.FormatInt(at(time.March, 5, 13, 1).Unix(), 10)))
+
+		// An instance whose clock lags cannot run a tick again
+		tick(schedulers[0], at(time.March, 5, 13, 2))
+		assert.Equal(t, int32(2), runs.Load())
+	})
+
+	t.Run("skips ticks that cannot be locked", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		var failure error
+		s := New(setupClient(t, mr), Config{OnError: func(_ string, err error) { failure = err }})
+		task, err := s.Add("report", "* * * * *", func(context.Context, time.Time) error {
+			t.Fatal("task should not run")
+			return nil
+		})
+		require.NoError(t, err)
+		task.OnOneServer()
+
+		mr.Close()
+		tick(s, at(time.March, 5, 13, 1))
+		assert.Error(t, failure)
+
+		s = New(nil, Config{OnError: func(_ string, err error) { failure = err }})
+		task, err = s.Add("report", "* * * * *", func(context.Context, time.Time) error {
+			t.Fatal("task should not run")
+			return nil
+		})
+		require.NoError(t, err)
+		task.OnOneServer()
+
+		tick(s, at(time.March, 5, 13, 1))
+		assert.ErrorIs(t, failure, ErrNoClient)
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	s := New(nil, Config{})
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	_, err := s.Add("slow", "* * * * *", func(context.Context, time.Time) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The next minute starts in 10ms, and the one after in a minute
+	now := at(time.March, 5, 13, 1).Add(-10 * time.Millisecond)
+	s.now = func() time.Time {
+		defer func() { now = at(time.March, 5, 13, 1) }()
+		return now
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	<-started
+	cancel()
+	require.NoError(t, <-done)
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Run returned before the running task")
+	}
+}
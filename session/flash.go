@@ -0,0 +1,138 @@
+package session
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// oldInputKey is the session key the input flashed by FlashInput is kept
+// under
+const oldInputKey = "_old_input"
+
+// Flash stores value under key for the current and the next request only
+func (sess *Session) Flash(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.put(key, data)
+	sess.record.Flash = appendKey(sess.record.Flash, key)
+	sess.record.Old = removeKey(sess.record.Old, key)
+	return nil
+}
+
+// ageFlash removes the values flashed during the previous request and
+// keeps those flashed during this one for the next, with the lock held
+func (sess *Session) ageFlash() {
+	for _, key := range sess.record.Old {
+		delete(sess.record.Values, key)
+	}
+	sess.record.Old = sess.record.Flash
+	sess.record.Flash = nil
+}
+
+// Now stores value under key for the current request only
+func (sess *Session) Now(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.put(key, data)
+	sess.record.Flash = removeKey(sess.record.Flash, key)
+	sess.record.Old = appendKey(sess.record.Old, key)
+	return nil
+}
+
+// Reflash keeps every value flashed during the previous request for one
+// more request
+func (sess *Session) Reflash() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	for _, key := range sess.record.Old {
+		sess.record.Flash = appendKey(sess.record.Flash, key)
+	}
+	sess.record.Old = nil
+}
+
+// Keep keeps the named values flashed during the previous request for one
+// more request
+func (sess *Session) Keep(keys ...string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	for _, key := range keys {
+		if containsKey(sess.record.Old, key) {
+			sess.record.Old = removeKey(sess.record.Old, key)
+			sess.record.Flash = appendKey(sess.record.Flash, key)
+		}
+	}
+}
+
+// FlashInput flashes the submitted form values, except the named fields
+// such as passwords, so that a form redisplayed after failing validation
+// can be filled with them through Old
+func (sess *Session) FlashInput(input url.Values, except ...string) error {
+	kept := make(url.Values, len(input))
+	for field, values := range input {
+		if !containsKey(except, field) {
+			kept[field] = values
+		}
+	}
+	return sess.Flash(oldInputKey, kept)
+}
+
+// Old returns the first value of the named field flashed by FlashInput
+// during the previous request, or an empty string
+func (sess *Session) Old(field string) string {
+	return sess.OldInput().Get(field)
+}
+
+// HasOldInput reports whether the named field was flashed by FlashInput
+// during the previous request
+func (sess *Session) HasOldInput(field string) bool {
+	return sess.OldInput().Has(field)
+}
+
+// OldInput returns the values flashed by FlashInput during the previous
+// request, or nil
+func (sess *Session) OldInput() url.Values {
+	var input url.Values
+	if err := sess.Get(oldInputKey, &input); err != nil {
+		return nil
+	}
+	return input
+}
+
+// appendKey adds key to keys unless it is already there
+func appendKey(keys []string, key string) []string {
+	if containsKey(keys, key) {
+		return keys
+	}
+	return append(keys, key)
+}
+
+// containsKey reports whether keys holds key
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// removeKey returns keys without key
+func removeKey(keys []string, key string) []string {
+	kept := keys[:0]
+	for _, k := range keys {
+		if k != key {
+			kept = append(kept, k)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSession returns a new session of store
+func newSession(t *testing.T, store *Store) *Session {
+	t.Helper()
+	sess, err := store.Load(context.Background(), "")
+	require.NoError(t, err)
+	require.NoError(t, sess.Put("user_id", 42))
+	return sess
+}
+
+func TestSession_Flash(t *testing.T) {
+	store, _ := setupStore(t, Config{})
+	sess, err := store.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	require.NoError(t, sess.Put("user_id", 42))
+	require.NoError(t, sess.Flash("status", "Profile updated"))
+	assert.True(t, sess.Has("status"))
+
+	// The next request sees the flashed value
+	sess = reload(t, store, sess)
+	var status string
+	require.NoError(t, sess.Get("status", &status))
+	assert.Equal(t, "Profile updated", status)
+
+	// The one after does not
+	sess = reload(t, store, sess)
+	assert.False(t, sess.Has("status"))
+	assert.True(t, sess.Has("user_id"))
+
+	// Flashing a key again keeps it for another request
+	require.NoError(t, sess.Flash("status", "first"))
+	sess = reload(t, store, sess)
+	require.NoError(t, sess.Flash("status", "second"))
+	sess = reload(t, store, sess)
+	require.NoError(t, sess.Get("status", &status))
+	assert.Equal(t, "second", status)
+}
+
+func TestSession_Now(t *testing.T) {
+	store, _ := setupStore(t, Config{})
+	sess := newSession(t, store)
+
+	require.NoError(t, sess.Flash("status", "flashed"))
+	require.NoError(t, sess.Now("status", "now"))
+	var status string
+	require.NoError(t, sess.Get("status", &status))
+	assert.Equal(t, "now", status)
+
+	sess = reload(t, store, sess)
+	assert.False(t, sess.Has("status"))
+}
+
+func TestSession_Reflash(t *testing.T) {
+	store, _ := setupStore(t, Config{})
+
+	t.Run("keeps every value", func(t *testing.T) {
+		sess := newSession(t, store)
+		require.NoError(t, sess.Flash("status", "saved"))
+		require.NoError(t, sess.Flash("warning", "check your email"))
+
+		sess = reload(t, store, sess)
+		sess.Reflash()
+		sess = reload(t, store, sess)
+		assert.True(t, sess.Has("status"))
+		assert.True(t, sess.Has("warning"))
+
+		sess = reload(t, store, sess)
+		assert.False(t, sess.Has("status"))
+		assert.False(t, sess.Has("warning"))
+	})
+
+	t.Run("keeps the named values", func(t *testing.T) {
+		sess := newSession(t, store)
+		require.NoError(t, sess.Flash("status", "saved"))
+		require.NoError(t, sess.Flash("warning", "check your email"))
+
+		sess = reload(t, store, sess)
+		sess.Keep("warning", "unknown")
+		sess = reload(t, store, sess)
+		assert.False(t, sess.Has("status"))
+		assert.True(t, sess.Has("warning"))
+		assert.False(t, sess.Has("unknown"))
+	})
+}
+
+func TestSession_OldInput(t *testing.T) {
+	store, _ := setupStore(t, Config{})
+	sess := newSession(t, store)
+	assert.Nil(t, sess.OldInput())
+	assert.Empty(t, sess.Old("email"))
+
+	input := url.Values{
+		"email":    {"ada@example.com"},
+		"tags":     {"go", "redis"},
+		"password": {"secret"},
+	}
+	require.NoError(t, sess.FlashInput(input, "password"))
+
+	sess = reload(t, store, sess)
+	assert.Equal(t, "ada@example.com", sess.Old("email"))
+	assert.Equal(t, []string{"go", "redis"}, sess.OldInput()["tags"])
+	assert.True(t, sess.HasOldInput("email"))
+	assert.False(t, sess.HasOldInput("password"))
+	assert.Empty(t, sess.Old("password"))
+
+	sess = reload(t, store, sess)
+	assert.False(t, sess.HasOldInput("email"))
+}
+
+func TestSession_OldInputAcrossRequests(t *testing.T) {
+	store, _ := setupStore(t, Config{})
+	handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := FromContext(r.Context())
+		if r.Method == http.MethodPost {
+			require.NoError(t, r.ParseForm())
+			require.NoError(t, sess.FlashInput(r.PostForm, "password"))
+			require.NoError(t, sess.Flash("error", "The name is taken"))
+			http.Redirect(w, r, "/register", http.StatusSeeOther)
+			return
+		}
+		var message string
+		sess.Get("error", &message)
+		w.Write([]byte(message + "|" + sess.Old("name")))
+	}))
+
+	form := strings.NewReader(url.Values{"name": {"ada"}, "password": {"secret"}}.Encode())
+	req := httptest.NewRequest(http.MethodPost, "/register", form)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	cookie := sessionCookie(t, rec)
+
+	assert.Equal(t, "The name is taken|ada", serve(handler, cookie).Body.String())
+	assert.Equal(t, "|", serve(handler, cookie).Body.String())
+}
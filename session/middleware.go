@@ -0,0 +1,118 @@
+package session
+
+import (
+	"context"
+	"net/http"
+)
+
+// contextKey is the type of the request context key holding the session
+type contextKey struct{}
+
+// FromContext returns the session loaded by Middleware, or nil outside of it
+func FromContext(ctx context.Context) *Session {
+	sess, _ := ctx.Value(contextKey{}).(*Session)
+	return sess
+}
+
+// NewContext returns a copy of ctx carrying sess
+func NewContext(ctx context.Context, sess *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, sess)
+}
+
+// Middleware returns HTTP middleware loading the session named by the
+// request's cookie, making it available through FromContext, and saving it
+// once the handler starts writing its response, so that the cookie can be
+// set. Changes made after the response is first written are not saved.
+// Requests whose session cannot be loaded or saved get 503 Service
+// Unavailable.
+func (s *Store) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string
+		if cookie, err := r.Cookie(s.cfg.CookieName); err == nil {
+			id = cookie.Value
+		}
+
+		sess, err := s.Load(r.Context(), id)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		sw := &writer{ResponseWriter: w, store: s, ctx: r.Context(), session: sess, hadCookie: id != ""}
+		next.ServeHTTP(sw, r.WithContext(NewContext(r.Context(), sess)))
+		sw.commit()
+	})
+}
+
+// writer saves the session and sets its cookie before the response is
+// written
+type writer struct {
+	http.ResponseWriter
+	store     *Store
+	ctx       context.Context
+	session   *Session
+	hadCookie bool
+
+	committed bool
+	failed    bool
+}
+
+// commit saves the session and sets its cookie, once. Should saving fail,
+// the response is replaced with a 503 Service Unavailable.
+func (w *writer) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	if err := w.store.Save(w.ctx, w.session); err != nil {
+		w.failed = true
+		http.Error(w.ResponseWriter, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.session.mu.Lock()
+	id, stored := w.session.id, w.session.stored
+	w.session.mu.Unlock()
+	switch {
+	case stored:
+		http.SetCookie(w.ResponseWriter, w.store.cookie(id, int(w.store.cfg.Lifetime.Seconds())))
+	case w.hadCookie:
+		// The session was emptied, drop its cookie
+		http.SetCookie(w.ResponseWriter, w.store.cookie("", -1))
+	}
+}
+
+func (w *writer) WriteHeader(status int) {
+	w.commit()
+	if !w.failed {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.commit()
+	if w.failed {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// cookie returns the session cookie carrying id
+func (s *Store) cookie(id string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    id,
+		Path:     s.cfg.Path,
+		Domain:   s.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.cfg.Secure,
+		HttpOnly: true,
+		SameSite: s.cfg.SameSite,
+	}
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+// failingStore is a cache store that cannot be reached
+type failingStore struct {
+	cache.Store
+}
+
+func (failingStore) Get(context.Context, string) (string, error) {
+	return "", errors.New("store down")
+}
+
+func (failingStore) Put(context.Context, string, string, time.Duration) error {
+	return errors.New("store down")
+}
+
+// serve sends a request carrying cookies through handler
+func serve(handler http.Handler, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// sessionCookie returns the session cookie set by a response
+func sessionCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == "session" {
+			return cookie
+		}
+	}
+	t.Fatal("no session cookie set")
+	return nil
+}
+
+func TestStore_Middleware(t *testing.T) {
+	t.Run("keeps values across requests", func(t *testing.T) {
+		store, _ := setupStore(t, Config{Secure: true})
+		handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess := FromContext(r.Context())
+			var visits int
+			sess.Get("visits", &visits)
+			require.NoError(t, sess.Put("visits", visits+1))
+			w.Write([]byte("ok"))
+		}))
+
+		rec := serve(handler)
+		assert.Equal(t, "ok", rec.Body.String())
+		cookie := sessionCookie(t, rec)
+		assert.Equal(t, "/", cookie.Path)
+		assert.Equal(t, 7200, cookie.MaxAge)
+		assert.True(t, cookie.HttpOnly)
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+
+		serve(handler, cookie)
+		sess, err := store.Load(context.Background(), cookie.Value)
+		require.NoError(t, err)
+		var visits int
+		require.NoError(t, sess.Get("visits", &visits))
+		assert.Equal(t, 2, visits)
+	})
+
+	t.Run("saves handlers that do not write", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		rec := serve(store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			FromContext(r.Context()).Put("user_id", 42)
+		})))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		sessionCookie(t, rec)
+	})
+
+	t.Run("no cookie for empty sessions", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		rec := serve(store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NotNil(t, FromContext(r.Context()))
+			w.WriteHeader(http.StatusNoContent)
+		})))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("regenerates and invalidates", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		var action string
+		handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess := FromContext(r.Context())
+			switch action {
+			case "login":
+				require.NoError(t, sess.Regenerate())
+				require.NoError(t, sess.Put("user_id", 42))
+			case "logout":
+				require.NoError(t, sess.Invalidate())
+			}
+		}))
+
+		action = "login"
+		first := sessionCookie(t, serve(handler))
+		second := sessionCookie(t, serve(handler, first))
+		assert.NotEqual(t, first.Value, second.Value)
+
+		action = "logout"
+		cleared := sessionCookie(t, serve(handler, second))
+		assert.Empty(t, cleared.Value)
+		assert.Less(t, cleared.MaxAge, 0)
+	})
+
+	t.Run("unreachable store", func(t *testing.T) {
+		store := New(failingStore{}, Config{})
+		called := false
+		handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			FromContext(r.Context()).Put("user_id", 42)
+			w.Write([]byte("ok"))
+		}))
+
+		rec := serve(handler, &http.Cookie{Name: "session", Value: "id"})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.False(t, called)
+
+		rec = serve(handler)
+		assert.True(t, called)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "ok")
+	})
+
+	t.Run("outside the middleware", func(t *testing.T) {
+		assert.Nil(t, FromContext(context.Background()))
+	})
+}
@@ -0,0 +1,267 @@
+// Package session keeps per-visitor state in a cache store, identified by a
+// session ID cookie, in the manner of Laravel's Session facade
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+)
+
+const (
+	// defaultPrefix namespaces sessions when Config does not set a prefix
+	defaultPrefix = "session:"
+
+	// defaultCookieName names the session cookie when Config does not
+	defaultCookieName = "session"
+
+	// defaultLifetime is how long an idle session is kept when Config does
+	// not set a lifetime
+	defaultLifetime = 2 * time.Hour
+
+	// idBytes is the number of random bytes in a session ID
+	idBytes = 32
+)
+
+// ErrKeyNotFound is returned when reading a value the session does not hold
+var ErrKeyNotFound = errors.New("session key not found")
+
+// record is what is stored under a session ID
+type record struct {
+	Values map[string]json.RawMessage `json:"values,omitempty"`
+
+	// Flash lists the keys flashed during the current request, and Old the
+	// keys flashed during the previous one, removed once it is saved
+	Flash []string `json:"flash,omitempty"`
+	Old   []string `json:"old,omitempty"`
+}
+
+// Config configures a Store
+type Config struct {
+	// Prefix namespaces the sessions within the cache store. Defaults to
+	// "session:".
+	Prefix string
+
+	// Lifetime is how long a session is kept after the request that last
+	// saved it, and the max age of its cookie. Defaults to two hours.
+	Lifetime time.Duration
+
+	// CookieName names the cookie carrying the session ID. Defaults to
+	// "session".
+	CookieName string
+
+	// Path and Domain scope the cookie. Path defaults to "/".
+	Path   string
+	Domain string
+
+	// Secure restricts the cookie to HTTPS requests
+	Secure bool
+
+	// SameSite sets the cookie's SameSite attribute. Defaults to Lax.
+	SameSite http.SameSite
+}
+
+// Store loads and saves sessions
+type Store struct {
+	store cache.Store
+	cfg   Config
+}
+
+// New returns a session store keeping its sessions in store. Closing store
+// is left to the caller.
+func New(store cache.Store, cfg Config) *Store {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+	if cfg.Lifetime <= 0 {
+		cfg.Lifetime = defaultLifetime
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = defaultCookieName
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &Store{store: store, cfg: cfg}
+}
+
+// Session is the state of one visitor. It is safe for concurrent use.
+type Session struct {
+	mu     sync.Mutex
+	id     string
+	record record
+
+	// stored is set for sessions loaded from the store, and previous holds
+	// the ID to delete once a regenerated session is saved
+	stored   bool
+	previous string
+}
+
+// newID returns a random session ID
+func newID() (string, error) {
+	buf := make([]byte, idBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Load returns the session with the given ID, or a new session with a fresh
+// ID when id is empty or unknown, so that visitors cannot choose their own
+func (s *Store) Load(ctx context.Context, id string) (*Session, error) {
+	if id != "" {
+		data, err := s.store.Get(ctx, s.key(id))
+		switch {
+		case err == nil:
+			sess := &Session{id: id, stored: true}
+			if err := json.Unmarshal([]byte(data), &sess.record); err != nil {
+				return nil, fmt.Errorf("failed to decode session: %w", err)
+			}
+			return sess, nil
+		case !errors.Is(err, cache.ErrKeyNotFound):
+			return nil, err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{id: id}, nil
+}
+
+// Save stores the session for the configured lifetime, removing the values
+// flashed during the previous request. A new session holding no values is not
+// stored.
+func (s *Store) Save(ctx context.Context, sess *Session) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.ageFlash()
+	if sess.previous != "" {
+		if err := s.store.Forget(ctx, s.key(sess.previous)); err != nil {
+			return err
+		}
+		sess.previous = ""
+	}
+	if !sess.stored && len(sess.record.Values) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(sess.record)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Put(ctx, s.key(sess.id), string(data), s.cfg.Lifetime); err != nil {
+		return err
+	}
+	sess.stored = true
+	return nil
+}
+
+// Destroy removes the session with the given ID from the store
+func (s *Store) Destroy(ctx context.Context, id string) error {
+	return s.store.Forget(ctx, s.key(id))
+}
+
+// key returns the cache key of the session with the given ID
+func (s *Store) key(id string) string {
+	return s.cfg.Prefix + id
+}
+
+// ID returns the session ID
+func (sess *Session) ID() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.id
+}
+
+// Get decodes the value stored under key into v, returning ErrKeyNotFound
+// when there is none
+func (sess *Session) Get(key string, v interface{}) error {
+	sess.mu.Lock()
+	data, ok := sess.record.Values[key]
+	sess.mu.Unlock()
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Has reports whether the session holds a value under key
+func (sess *Session) Has(key string) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	_, ok := sess.record.Values[key]
+	return ok
+}
+
+// Put stores value under key, encoded as JSON
+func (sess *Session) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.put(key, data)
+	return nil
+}
+
+// put stores an encoded value, with the lock held
+func (sess *Session) put(key string, data json.RawMessage) {
+	if sess.record.Values == nil {
+		sess.record.Values = make(map[string]json.RawMessage)
+	}
+	sess.record.Values[key] = data
+}
+
+// Forget removes the value stored under key
+func (sess *Session) Forget(key string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	delete(sess.record.Values, key)
+}
+
+// Regenerate gives the session a new ID, keeping its values and deleting
+// the old ID once saved. Regenerating on login and logout protects against
+// session fixation.
+func (sess *Session) Regenerate() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.stored && sess.previous == "" {
+		sess.previous = sess.id
+	}
+	sess.id = id
+	sess.stored = false
+	return nil
+}
+
+// Invalidate removes every value and regenerates the session ID
+func (sess *Session) Invalidate() error {
+	if err := sess.Regenerate(); err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.record = record{}
+	return nil
+}
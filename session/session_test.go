@@ -0,0 +1,152 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"github.com/nanaaikinson/gofacades/memory"
+)
+
+// setupStore creates a session store kept in memory
+func setupStore(t *testing.T, cfg Config) (*Store, *memory.Store) {
+	backend := memory.New(memory.Config{})
+	t.Cleanup(func() { backend.Close() })
+	return New(backend, cfg), backend
+}
+
+// reload saves sess and loads it again, as the next request would
+func reload(t *testing.T, store *Store, sess *Session) *Session {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, store.Save(ctx, sess))
+	loaded, err := store.Load(ctx, sess.ID())
+	require.NoError(t, err)
+	return loaded
+}
+
+func TestStore_Load(t *testing.T) {
+	ctx := context.Background()
+	store, _ := setupStore(t, Config{})
+
+	sess, err := store.Load(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, sess.ID(), 43)
+
+	// Unknown IDs are not adopted
+	other, err := store.Load(ctx, "chosen-by-the-visitor")
+	require.NoError(t, err)
+	assert.NotEqual(t, "chosen-by-the-visitor", other.ID())
+	assert.NotEqual(t, sess.ID(), other.ID())
+}
+
+func TestSession_Values(t *testing.T) {
+	store, backend := setupStore(t, Config{})
+	sess, err := store.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	type cart struct {
+		Items []string `json:"items"`
+	}
+	require.NoError(t, sess.Put("user_id", 42))
+	require.NoError(t, sess.Put("cart", cart{Items: []string{"book"}}))
+	require.NoError(t, sess.Put("temporary", true))
+	sess.Forget("temporary")
+
+	sess = reload(t, store, sess)
+	var userID int
+	require.NoError(t, sess.Get("user_id", &userID))
+	assert.Equal(t, 42, userID)
+	var c cart
+	require.NoError(t, sess.Get("cart", &c))
+	assert.Equal(t, []string{"book"}, c.Items)
+	assert.True(t, sess.Has("cart"))
+	assert.False(t, sess.Has("temporary"))
+	assert.ErrorIs(t, sess.Get("temporary", new(bool)), ErrKeyNotFound)
+
+	exists, err := backend.Has(context.Background(), "session:"+sess.ID())
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestStore_Save(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("skips new empty sessions", func(t *testing.T) {
+		store, backend := setupStore(t, Config{})
+		sess, err := store.Load(ctx, "")
+		require.NoError(t, err)
+		require.NoError(t, store.Save(ctx, sess))
+
+		exists, err := backend.Has(ctx, "session:"+sess.ID())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("expires idle sessions", func(t *testing.T) {
+		store, backend := setupStore(t, Config{Prefix: "sessions:", Lifetime: 50 * time.Millisecond})
+		sess, err := store.Load(ctx, "")
+		require.NoError(t, err)
+		require.NoError(t, sess.Put("user_id", 42))
+		require.NoError(t, store.Save(ctx, sess))
+
+		_, err = backend.Get(ctx, "sessions:"+sess.ID())
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		_, err = backend.Get(ctx, "sessions:"+sess.ID())
+		assert.ErrorIs(t, err, cache.ErrKeyNotFound)
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		store, _ := setupStore(t, Config{})
+		sess, err := store.Load(ctx, "")
+		require.NoError(t, err)
+		require.NoError(t, sess.Put("user_id", 42))
+		require.NoError(t, store.Save(ctx, sess))
+
+		require.NoError(t, store.Destroy(ctx, sess.ID()))
+		loaded, err := store.Load(ctx, sess.ID())
+		require.NoError(t, err)
+		assert.NotEqual(t, sess.ID(), loaded.ID())
+	})
+
+	t.Run("undecodable session", func(t *testing.T) {
+		store, backend := setupStore(t, Config{})
+		require.NoError(t, backend.Put(ctx, "session:broken", "{", time.Minute))
+
+		_, err := store.Load(ctx, "broken")
+		assert.Error(t, err)
+	})
+}
+
+func TestSession_Regenerate(t *testing.T) {
+	ctx := context.Background()
+	store, backend := setupStore(t, Config{})
+	sess, err := store.Load(ctx, "")
+	require.NoError(t, err)
+	require.NoError(t, sess.Put("user_id", 42))
+	require.NoError(t, store.Save(ctx, sess))
+	oldID := sess.ID()
+
+	require.NoError(t, sess.Regenerate())
+	require.NoError(t, sess.Regenerate())
+	assert.NotEqual(t, oldID, sess.ID())
+
+	sess = reload(t, store, sess)
+	assert.True(t, sess.Has("user_id"))
+	exists, err := backend.Has(ctx, "session:"+oldID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, sess.Invalidate())
+	assert.False(t, sess.Has("user_id"))
+	invalidatedID := sess.ID()
+	require.NoError(t, store.Save(ctx, sess))
+	exists, err = backend.Has(ctx, "session:"+invalidatedID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,182 @@
+// Package tracing wraps cache stores in OpenTelemetry spans
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer creating the spans
+const instrumentationName = "github.com/nanaaikinson/gofacades/tracing"
+
+// Config configures a traced store
+type Config struct {
+	// TracerProvider creates the tracer, defaulting to the global provider
+	TracerProvider trace.TracerProvider
+
+	// Name is recorded as the cache.store attribute, telling apart several
+	// stores in one trace
+	Name string
+
+	// HashKeys records a SHA-256 digest of each key instead of the key
+	// itself, for keys holding personal or secret data
+	HashKeys bool
+}
+
+// Store wraps a cache store, recording every operation as a client span that
+// is a child of the span in the operation's context
+type Store struct {
+	store    cache.Store
+	tracer   trace.Tracer
+	name     string
+	hashKeys bool
+}
+
+var _ cache.Store = (*Store)(nil)
+
+// Instrument wraps store in tracing spans
+func Instrument(store cache.Store, cfg Config) *Store {
+	provider := cfg.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Store{
+		store:    store,
+		tracer:   provider.Tracer(instrumentationName),
+		name:     cfg.Name,
+		hashKeys: cfg.HashKeys,
+	}
+}
+
+// start opens the span for op on key
+func (s *Store) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("cache.operation", op)}
+	if s.name != "" {
+		attrs = append(attrs, attribute.String("cache.store", s.name))
+	}
+	if key != "" {
+		if s.hashKeys {
+			sum := sha256.Sum256([]byte(key))
+			key = hex.EncodeToString(sum[:])
+		}
+		attrs = append(attrs, attribute.String("cache.key", key))
+	}
+
+	return s.tracer.Start(ctx, "cache."+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// end closes span, recording err unless it is a miss
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hit records whether a read found the key
+func hit(span trace.Span, found bool) {
+	span.SetAttributes(attribute.Bool("cache.hit", found))
+}
+
+// Get retrieves an item from the cache by key
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	ctx, span := s.start(ctx, "get", key)
+	value, err := s.store.Get(ctx, key)
+	hit(span, err == nil)
+	end(span, err)
+	return value, err
+}
+
+// Put stores an item in the cache for a given duration
+func (s *Store) Put(ctx context.Context, key, value string, ttl time.Duration) error {
+	ctx, span := s.start(ctx, "put", key)
+	span.SetAttributes(attribute.String("cache.ttl", ttl.String()))
+	err := s.store.Put(ctx, key, value, ttl)
+	end(span, err)
+	return err
+}
+
+// Has checks if an item exists in the cache
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	ctx, span := s.start(ctx, "has", key)
+	exists, err := s.store.Has(ctx, key)
+	hit(span, exists)
+	end(span, err)
+	return exists, err
+}
+
+// Remember gets an item from the cache, or stores the encoded result of
+// the callback. The callback runs in its own child span.
+func (s *Store) Remember(ctx context.Context, key string, ttl time.Duration, callback func() (interface{}, error)) (string, error) {
+	ctx, span := s.start(ctx, "remember", key)
+	var wrapped func() (interface{}, error)
+	if callback != nil {
+		wrapped = func() (interface{}, error) {
+			_, callbackSpan := s.tracer.Start(ctx, "cache.remember.callback")
+			result, err := callback()
+			end(callbackSpan, err)
+			return result, err
+		}
+	}
+
+	tracked := cache.TrackCallback(wrapped)
+	value, err := s.store.Remember(ctx, key, ttl, tracked.Func())
+	missed, _, _ := tracked.Done()
+	hit(span, err == nil && !missed)
+	end(span, err)
+	return value, err
+}
+
+// Pull retrieves and deletes an item from the cache
+func (s *Store) Pull(ctx context.Context, key string) (string, error) {
+	ctx, span := s.start(ctx, "pull", key)
+	value, err := s.store.Pull(ctx, key)
+	hit(span, err == nil)
+	end(span, err)
+	return value, err
+}
+
+// Forever stores an item in the cache permanently
+func (s *Store) Forever(ctx context.Context, key, value string) error {
+	ctx, span := s.start(ctx, "forever", key)
+	err := s.store.Forever(ctx, key, value)
+	end(span, err)
+	return err
+}
+
+// Forget removes an item from the cache
+func (s *Store) Forget(ctx context.Context, key string) error {
+	ctx, span := s.start(ctx, "forget", key)
+	err := s.store.Forget(ctx, key)
+	end(span, err)
+	return err
+}
+
+// Flush removes all items from the cache
+func (s *Store) Flush(ctx context.Context) error {
+	ctx, span := s.start(ctx, "flush", "")
+	err := s.store.Flush(ctx)
+	end(span, err)
+	return err
+}
+
+// Codec returns the wrapped store's codec
+func (s *Store) Codec() cache.Codec {
+	return cache.CodecOf(s.store)
+}
+
+// Close closes the wrapped store
+func (s *Store) Close() error {
+	return s.store.Close()
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nanaaikinson/gofacades/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// setupTracing returns a traced memory store and the recorder of its spans
+func setupTracing(t *testing.T, cfg Config) (*Store, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	cfg.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	backend := memory.New(memory.Config{})
+	t.Cleanup(func() { backend.Close() })
+
+	return Instrument(backend, cfg), recorder
+}
+
+// attrs flattens span attributes into a map
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("spans", func(t *testing.T) {
+		store, recorder := setupTracing(t, Config{Name: "sessions"})
+
+		require.NoError(t, store.Put(ctx, "key", "value", time.Minute))
+		store.Get(ctx, "key")
+		store.Get(ctx, "missing")
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 3)
+		assert.Equal(t, "cache.put", spans[0].Name())
+		assert.Equal(t, "cache.get", spans[1].Name())
+
+		hitAttrs := attrs(spans[1])
+		assert.Equal(t, "key", hitAttrs["cache.key"].AsString())
+		assert.Equal(t, "sessions", hitAttrs["cache.store"].AsString())
+		assert.True(t, hitAttrs["cache.hit"].AsBool())
+
+		// A miss is not an error
+		assert.False(t, attrs(spans[2])["cache.hit"].AsBool())
+		assert.Equal(t, codes.Unset, spans[2].Status().Code)
+	})
+
+	t.Run("hashed keys", func(t *testing.T) {
+		store, recorder := setupTracing(t, Config{HashKeys: true})
+
+		store.Get(ctx, "user:42:email")
+
+		sum := sha256.Sum256([]byte("user:42:email"))
+		assert.Equal(t, hex.EncodeToString(sum[:]), attrs(recorder.Ended()[0])["cache.key"].AsString())
+	})
+
+	t.Run("parent span", func(t *testing.T) {
+		store, recorder := setupTracing(t, Config{})
+
+		parentCtx, parent := store.tracer.Start(ctx, "handler")
+		store.Get(parentCtx, "key")
+		parent.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	})
+
+	t.Run("remember", func(t *testing.T) {
+		store, recorder := setupTracing(t, Config{})
+
+		callback := func() (interface{}, error) { return "computed", nil }
+		store.Remember(ctx, "key", time.Minute, callback)
+		store.Remember(ctx, "key", time.Minute, callback)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 3)
+		assert.Equal(t, "cache.remember.callback", spans[0].Name())
+		assert.False(t, attrs(spans[1])["cache.hit"].AsBool())
+		assert.True(t, attrs(spans[2])["cache.hit"].AsBool())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		store, recorder := setupTracing(t, Config{})
+
+		store.Remember(ctx, "key", time.Minute, func() (interface{}, error) {
+			return nil, errors.New("query failed")
+		})
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.Contains(t, spans[1].Status().Description, "query failed")
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+}